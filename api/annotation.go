@@ -0,0 +1,415 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var annotationKey = contextKey("annotation")
+
+func getArticle(repo repo.Annotation, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		annotations, err := articleAnnotations(repo, article, user)
+		if err != nil {
+			fatal(w, log, "Error getting article annotations: %+v", err)
+			return
+		}
+
+		args{"article": article, "annotations": annotations}.WriteJSON(w)
+	}
+}
+
+func getArticleAnnotations(repo repo.Annotation, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		annotations, err := articleAnnotations(repo, article, user)
+		if err != nil {
+			fatal(w, log, "Error getting article annotations: %+v", err)
+			return
+		}
+
+		args{"annotations": annotations}.WriteJSON(w)
+	}
+}
+
+func addAnnotation(repo repo.Annotation, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		annotation := content.Annotation{User: user.Login, ArticleID: article.ID}
+		if err := annotationFromForm(r, &annotation); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		annotation, err := repo.Create(annotation)
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error creating annotation: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "annotation": annotation}.WriteJSON(w)
+	}
+}
+
+func updateAnnotation(repo repo.Annotation, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		annotation, stop := annotationFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := annotationFromForm(r, &annotation); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		annotation.UpdatedAt = time.Now().UTC()
+
+		if err := repo.Update(annotation); err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error updating annotation: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "annotation": annotation}.WriteJSON(w)
+	}
+}
+
+func deleteAnnotation(repo repo.Annotation, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		annotation, stop := annotationFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(annotation); err != nil {
+			fatal(w, log, "Error deleting annotation: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+func listAnnotations(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		annotations, stop := filteredAnnotations(w, r, service, user, log)
+		if stop {
+			return
+		}
+
+		args{"annotations": annotations}.WriteJSON(w)
+	}
+}
+
+// exportAnnotations writes the user's annotations as a markdown document,
+// grouped by their article.
+func exportAnnotations(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		annotations, stop := filteredAnnotations(w, r, service, user, log)
+		if stop {
+			return
+		}
+
+		ids := []content.ArticleID{}
+		grouped := map[content.ArticleID][]content.Annotation{}
+		for _, a := range annotations {
+			if _, ok := grouped[a.ArticleID]; !ok {
+				ids = append(ids, a.ArticleID)
+			}
+			grouped[a.ArticleID] = append(grouped[a.ArticleID], a)
+		}
+
+		articles := map[content.ArticleID]content.Article{}
+		if len(ids) > 0 {
+			list, err := service.ArticleRepo().ForUser(user, content.IDs(ids))
+			if err != nil {
+				fatal(w, log, "Error getting annotated articles: %+v", err)
+				return
+			}
+
+			for _, a := range list {
+				articles[a.ID] = a
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write(annotationsMarkdown(ids, grouped, articles))
+	}
+}
+
+func annotationsMarkdown(
+	ids []content.ArticleID,
+	annotations map[content.ArticleID][]content.Annotation,
+	articles map[content.ArticleID]content.Article,
+) []byte {
+	buf := bytes.NewBufferString("# Annotations\n")
+
+	for _, id := range ids {
+		article, ok := articles[id]
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(buf, "\n## [%s](%s)\n", markdownEscaper.Replace(article.Title), article.Link)
+
+		for _, a := range annotations[id] {
+			buf.WriteString("\n")
+			if a.Quote != "" {
+				for _, line := range strings.Split(a.Quote, "\n") {
+					buf.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+				}
+			}
+
+			if a.Note != "" {
+				if a.Quote != "" {
+					buf.WriteString("\n")
+				}
+				buf.WriteString(a.Note + "\n")
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+var markdownEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// filteredAnnotations returns the user's annotations, filtered by the
+// tagID, feedID, afterTime and beforeTime query values.
+func filteredAnnotations(
+	w http.ResponseWriter,
+	r *http.Request,
+	service repo.Service,
+	user content.User,
+	log log.Log,
+) ([]content.Annotation, bool) {
+	query := r.URL.Query()
+	filter := content.AnnotationFilter{}
+
+	if v := query.Get("feedID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, true
+		}
+
+		filter.FeedIDs = []content.FeedID{content.FeedID(id)}
+	}
+
+	if v := query.Get("tagID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, true
+		}
+
+		tagRepo := service.TagRepo()
+		tag, err := tagRepo.Get(content.TagID(id), user)
+		if err != nil {
+			if content.IsNoContent(err) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error getting tag: %+v", err)
+			}
+			return nil, true
+		}
+
+		feedIDs, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			fatal(w, log, "Error getting tag feed ids: %+v", err)
+			return nil, true
+		}
+
+		if len(filter.FeedIDs) > 0 {
+			feedIDs = intersectFeedIDs(filter.FeedIDs, feedIDs)
+		}
+
+		if len(feedIDs) == 0 {
+			return []content.Annotation{}, false
+		}
+
+		filter.FeedIDs = feedIDs
+	}
+
+	for name, t := range map[string]*time.Time{"afterTime": &filter.After, "beforeTime": &filter.Before} {
+		if v := query.Get(name); v != "" {
+			seconds, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return nil, true
+			}
+			*t = time.Unix(seconds, 0)
+		}
+	}
+
+	annotations, err := service.AnnotationRepo().ForUser(user, filter)
+	if err != nil {
+		fatal(w, log, "Error getting annotations: %+v", err)
+		return nil, true
+	}
+
+	if annotations == nil {
+		annotations = []content.Annotation{}
+	}
+
+	return annotations, false
+}
+
+func intersectFeedIDs(a, b []content.FeedID) []content.FeedID {
+	set := map[content.FeedID]bool{}
+	for _, id := range a {
+		set[id] = true
+	}
+
+	ids := []content.FeedID{}
+	for _, id := range b {
+		if set[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func articleAnnotations(repo repo.Annotation, article content.Article, user content.User) ([]content.Annotation, error) {
+	annotations, err := repo.ForArticles([]content.ArticleID{article.ID}, user)
+	if err != nil {
+		return nil, err
+	}
+
+	a := annotations[article.ID]
+	if a == nil {
+		a = []content.Annotation{}
+	}
+
+	return a, nil
+}
+
+// annotationFromForm overrides the annotation fields with the ones present
+// in the request form.
+func annotationFromForm(r *http.Request, annotation *content.Annotation) error {
+	if _, ok := r.Form["note"]; ok {
+		annotation.Note = r.Form.Get("note")
+	}
+
+	if _, ok := r.Form["quote"]; ok {
+		annotation.Quote = r.Form.Get("quote")
+	}
+
+	for name, v := range map[string]*int{"start": &annotation.Start, "end": &annotation.End} {
+		if _, ok := r.Form[name]; ok {
+			i, err := strconv.Atoi(r.Form.Get(name))
+			if err != nil {
+				return fmt.Errorf("Invalid %s: %s", name, r.Form.Get(name))
+			}
+			*v = i
+		}
+	}
+
+	return annotation.Validate()
+}
+
+func annotationContext(repo repo.Annotation, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			article, stop := articleFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "annotationID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			annotation, err := repo.Get(content.AnnotationID(id), user)
+			if err == nil && annotation.ArticleID != article.ID {
+				err = content.ErrNoContent
+			}
+
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting annotation: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), annotationKey, annotation)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func annotationFromRequest(w http.ResponseWriter, r *http.Request) (annotation content.Annotation, stop bool) {
+	var ok bool
+	if annotation, ok = r.Context().Value(annotationKey).(content.Annotation); ok {
+		return annotation, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Annotation{}, true
+}
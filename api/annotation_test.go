@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_addAnnotation(t *testing.T) {
+	tests := []struct {
+		name      string
+		noUser    bool
+		form      url.Values
+		createErr error
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "empty", form: url.Values{}, code: 400},
+		{name: "invalid range", form: url.Values{"quote": {"quote"}, "start": {"5"}, "end": {"2"}}, code: 400},
+		{name: "invalid offset", form: url.Values{"quote": {"quote"}, "start": {"a"}}, code: 400},
+		{name: "create err", form: url.Values{"note": {"note"}}, createErr: errors.New("err"), code: 500},
+		{name: "note", form: url.Values{"note": {"note"}}, code: 200},
+		{name: "highlight", form: url.Values{"quote": {"quote"}, "start": {"2"}, "end": {"7"}}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			annotationRepo := mock_repo.NewMockAnnotation(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			r = r.WithContext(context.WithValue(r.Context(), articleKey, content.Article{ID: 3}))
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.code == 400 {
+					break
+				}
+
+				annotationRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(a content.Annotation) (content.Annotation, error) {
+					if a.User != user.Login || a.ArticleID != 3 || a.Note != tt.form.Get("note") || a.Quote != tt.form.Get("quote") {
+						t.Errorf("addAnnotation() annotation = %#v", a)
+					}
+
+					a.ID = 5
+					return a, tt.createErr
+				})
+			}
+
+			addAnnotation(annotationRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("addAnnotation() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_annotationContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		annotation content.Annotation
+		getErr     error
+		code       int
+	}{
+		{name: "invalid id", id: "a", code: 400},
+		{name: "not found", id: "1", getErr: content.ErrNoContent, code: 404},
+		{name: "get err", id: "1", getErr: errors.New("err"), code: 500},
+		{name: "other article", id: "1", annotation: content.Annotation{ID: 1, ArticleID: 4, Note: "note"}, code: 404},
+		{name: "annotation", id: "1", annotation: content.Annotation{ID: 1, ArticleID: 3, Note: "note"}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			annotationRepo := mock_repo.NewMockAnnotation(ctrl)
+
+			user := content.User{Login: "test"}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("annotationID", tt.id)
+
+			r := httptest.NewRequest("PUT", "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			r = r.WithContext(context.WithValue(r.Context(), articleKey, content.Article{ID: 3}))
+			w := httptest.NewRecorder()
+
+			if tt.code != 400 {
+				annotationRepo.EXPECT().Get(content.AnnotationID(1), userMatcher{user}).Return(tt.annotation, tt.getErr)
+			}
+
+			annotationContext(annotationRepo, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if a, stop := annotationFromRequest(w, r); !stop && a.ID != tt.annotation.ID {
+					t.Errorf("annotationContext() annotation = %#v, want %#v", a, tt.annotation)
+				}
+			})).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("annotationContext() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_exportAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		tagFeedIDs  []content.FeedID
+		filter      content.AnnotationFilter
+		annotations []content.Annotation
+		articles    []content.Article
+		code        int
+		want        string
+	}{
+		{name: "invalid feed", url: "/?feedID=a", code: 400},
+		{name: "invalid time", url: "/?afterTime=a", code: 400},
+		{name: "empty tag", url: "/?tagID=1", code: 200, want: "# Annotations\n"},
+		{name: "tag", url: "/?tagID=1&afterTime=1500000000", tagFeedIDs: []content.FeedID{1, 2},
+			filter: content.AnnotationFilter{FeedIDs: []content.FeedID{1, 2}}, code: 200, want: "# Annotations\n"},
+		{name: "annotations", url: "/", annotations: []content.Annotation{
+			{ID: 1, ArticleID: 2, Note: "first"},
+			{ID: 2, ArticleID: 1, Quote: "some\nquote", Note: "second"},
+			{ID: 3, ArticleID: 2, Quote: "third"},
+		}, articles: []content.Article{
+			{ID: 1, Title: "Article [1]", Link: "http://example.com/1"},
+			{ID: 2, Title: "Article 2", Link: "http://example.com/2"},
+		}, code: 200, want: "# Annotations\n\n" +
+			"## [Article 2](http://example.com/2)\n\nfirst\n\n> third\n\n" +
+			"## [Article \\[1\\]](http://example.com/1)\n\n> some\n> quote\n\nsecond\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			annotationRepo := mock_repo.NewMockAnnotation(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+
+			service.EXPECT().AnnotationRepo().Return(annotationRepo).AnyTimes()
+			service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+			service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+
+			user := content.User{Login: "test"}
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			w := httptest.NewRecorder()
+
+			if tt.code == 200 {
+				if r.URL.Query().Get("tagID") != "" {
+					tag := content.Tag{ID: 1, Value: "tag"}
+					tagRepo.EXPECT().Get(content.TagID(1), userMatcher{user}).Return(tag, nil)
+					tagRepo.EXPECT().FeedIDs(tag, userMatcher{user}).Return(tt.tagFeedIDs, nil)
+				}
+
+				if r.URL.Query().Get("tagID") == "" || len(tt.tagFeedIDs) > 0 {
+					annotationRepo.EXPECT().ForUser(userMatcher{user}, gomock.Any()).DoAndReturn(
+						func(u content.User, filter content.AnnotationFilter) ([]content.Annotation, error) {
+							if len(filter.FeedIDs) != len(tt.filter.FeedIDs) {
+								t.Errorf("exportAnnotations() filter = %#v, want %#v", filter, tt.filter)
+							}
+
+							if (r.URL.Query().Get("afterTime") != "") == filter.After.IsZero() {
+								t.Errorf("exportAnnotations() filter = %#v", filter)
+							}
+
+							return tt.annotations, nil
+						})
+				}
+
+				if len(tt.annotations) > 0 {
+					articleRepo.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return(tt.articles, nil)
+				}
+			}
+
+			exportAnnotations(service, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("exportAnnotations() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != 200 {
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+				t.Errorf("exportAnnotations() content type = %v", ct)
+			}
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("exportAnnotations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/urandom/handler/method"
 	"github.com/urandom/readeef"
 	"github.com/urandom/readeef/api/fever"
+	"github.com/urandom/readeef/api/greader"
+	"github.com/urandom/readeef/api/miniflux"
+	"github.com/urandom/readeef/api/nextcloud"
 	"github.com/urandom/readeef/api/token"
 	"github.com/urandom/readeef/api/ttrss"
 	"github.com/urandom/readeef/config"
@@ -58,7 +61,19 @@ func Mux(
 		return nil, errors.Wrap(err, "initializing token storage")
 	}
 
-	routes := []routes{tokenRoutes(service.UserRepo(), storage, []byte(config.Auth.Secret), log, gzip, access)}
+	events, err := initEventLog(ctx, service, config.API, log)
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing event log")
+	}
+
+	spec, err := openAPIRoutes(openAPISpec(features, config.Hubbub.CallbackURL != "", config.API.Version), gzip, access)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []routes{spec, tokenRoutes(service.UserRepo(), storage, []byte(config.Auth.Secret), log, gzip, access)}
+
+	routes = append(routes, outputFeedRoutes(service, processors, config.API.Limits.ArticlesPerQuery, log, gzip, access))
 
 	if config.Hubbub.CallbackURL != "" {
 		routes = append(routes, hubbubRoutes(service, log, gzip, access))
@@ -72,10 +87,15 @@ func Mux(
 		featureRoutes(features, gzip, access),
 		feedsRoutes(service, feedManager, log, gzip, access),
 		tagRoutes(service.TagRepo(), log, gzip, access),
+		labelRoutes(service, log, gzip, access),
+		annotationRoutes(service, log, gzip, access),
 		articlesRoutes(service, extractor, searchProvider, processors, config, log, gzip, access),
 		opmlRoutes(service, feedManager, log, gzip, access),
-		eventsRoutes(ctx, service, storage, feedManager, log),
+		eventsRoutes(ctx, service, storage, events, log),
 		userRoutes(service, []byte(config.Auth.Secret), log, gzip, access),
+		webhookRoutes(service.WebhookRepo(), []byte(config.Auth.Secret), log, gzip, access),
+		ruleRoutes(service, log, gzip, access),
+		outputRoutes(service, log, gzip, access),
 	))
 
 	r := chi.NewRouter()
@@ -113,6 +133,39 @@ func initTokenStorage(config config.Auth) (token.Storage, error) {
 	return token.NewBoltStorage(config.TokenStoragePath)
 }
 
+// initEventLog creates the event log, shared by the event streams, and
+// persists it if a storage path is configured.
+func initEventLog(ctx context.Context, service eventable.Service, config config.API, log log.Log) (*eventLog, error) {
+	size := config.Events.LogSize
+	if size <= 0 {
+		size = eventLogSize
+	}
+
+	// Microseconds since the epoch still fit in a javascript number.
+	events := newEventLog(size, time.Now().UnixNano()/int64(time.Microsecond))
+
+	if path := config.Events.StoragePath; path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return nil, errors.Wrapf(err, "creating event storage path %s", path)
+		}
+
+		store, err := newEventStore(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = events.persist(store, log); err != nil {
+			return nil, err
+		}
+
+		go events.autosave(ctx, eventSaveInterval)
+	}
+
+	go events.listen(ctx, service)
+
+	return events, nil
+}
+
 type routes struct {
 	path  string
 	route func(r chi.Router)
@@ -132,6 +185,16 @@ func tokenRoutes(repo repo.User, storage token.Storage, secret []byte, log log.L
 	}}
 }
 
+// outputFeedRoutes serves the published output feeds. They are public, and
+// are only protected by their unguessable tokens.
+func outputFeedRoutes(service repo.Service, processors []processor.Article, articlesLimit int, log log.Log, gzip, access mw) routes {
+	return routes{path: "/out", route: func(r chi.Router) {
+		r.Use(timeout(30*time.Second), gzip, access)
+		r.With(outputFeedContext(service, log)).Get(
+			"/{token:[0-9a-f]+}.{format:[a-z]+}", serveOutputFeed(service, processors, articlesLimit, log))
+	}}
+}
+
 func hubbubRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
 	handler := hubbubRegistration(service, log)
 
@@ -178,6 +241,36 @@ func emulatorRoutes(
 					r.Post("/", fever.Handler(service, processors, log))
 				},
 			})
+		case "greader":
+			rr = append(rr, routes{
+				path: "/greader",
+				route: func(r chi.Router) {
+					r.Use(timeout(30*time.Second), gzip, access)
+					r.Mount("/", greader.Handler(
+						service, feedManager, processors, []byte(config.Auth.Secret), log,
+					))
+				},
+			})
+		case "miniflux":
+			rr = append(rr, routes{
+				path: "/miniflux",
+				route: func(r chi.Router) {
+					r.Use(timeout(30*time.Second), gzip, access)
+					r.Mount("/", miniflux.Handler(
+						service, feedManager, processors, []byte(config.Auth.Secret), log,
+					))
+				},
+			})
+		case "nextcloud":
+			rr = append(rr, routes{
+				path: "/nextcloud/index.php/apps/news/api/v1-2",
+				route: func(r chi.Router) {
+					r.Use(timeout(30*time.Second), gzip, access)
+					r.Mount("/", nextcloud.Handler(
+						service, feedManager, processors, []byte(config.Auth.Secret), log,
+					))
+				},
+			})
 		}
 	}
 
@@ -240,16 +333,35 @@ func feedsRoutes(service repo.Service, feedManager *readeef.FeedManager, log log
 		r.With(timeout(30*time.Second)).Post("/", addFeed(feedRepo, feedManager))
 
 		r.With(timeout(30*time.Second)).Get("/discover", discoverFeeds(feedRepo, feedManager, log))
+		r.With(adminValidator, timeout(5*time.Second)).Get("/scheduler", getSchedulerStats(feedManager))
+
+		r.With(timeout(30*time.Second)).Post("/scraper", addScraperFeed(feedRepo, feedManager))
+		r.With(timeout(30*time.Second)).Post("/scraper/preview", previewScraper(feedManager, log))
 
 		r.Route("/{feedID:[0-9]+}", func(r chi.Router) {
 			r.Use(feedContext(service.FeedRepo(), log))
-			r.Use(timeout(5 * time.Second))
 
-			r.Delete("/", deleteFeed(feedRepo, feedManager, log))
+			r.Group(func(r chi.Router) {
+				r.Use(timeout(5 * time.Second))
+
+				r.Delete("/", deleteFeed(feedRepo, feedManager, log))
+
+				r.Get("/tags", getFeedTags(service.TagRepo(), log))
+				r.Put("/tags", setFeedTags(feedRepo, log))
+
+				r.Post("/extract", feedExtractChange(feedRepo, subscriptionSetting, log))
+				r.Delete("/extract", feedExtractChange(feedRepo, subscriptionSetting, log))
+				r.With(adminValidator).Post("/extract/feed", feedExtractChange(feedRepo, feedSetting, log))
+				r.With(adminValidator).Delete("/extract/feed", feedExtractChange(feedRepo, feedSetting, log))
 
-			r.Get("/tags", getFeedTags(service.TagRepo(), log))
-			r.Put("/tags", setFeedTags(feedRepo, log))
+				r.Put("/retention", feedRetentionChange(feedRepo, subscriptionSetting, log))
+				r.Delete("/retention", feedRetentionChange(feedRepo, subscriptionSetting, log))
+				r.With(adminValidator).Put("/retention/feed", feedRetentionChange(feedRepo, feedSetting, log))
+				r.With(adminValidator).Delete("/retention/feed", feedRetentionChange(feedRepo, feedSetting, log))
+			})
 
+			r.With(timeout(45*time.Second)).Post("/refresh", refreshFeed(feedManager, log))
+			r.With(timeout(30*time.Second)).Put("/scraper", setFeedScraper(feedManager, log))
 		})
 	}}
 }
@@ -268,6 +380,37 @@ func tagRoutes(repo repo.Tag, log log.Log, gzip, access mw) routes {
 	}}
 }
 
+func labelRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
+	repo := service.LabelRepo()
+
+	return routes{path: "/label", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+
+		r.Get("/", listLabels(repo, log))
+		r.Post("/", addLabel(repo, log))
+
+		r.Route("/{labelID:[0-9]+}", func(r chi.Router) {
+			r.Use(labelContext(repo, log))
+
+			r.Get("/", getLabel)
+			r.Put("/", updateLabel(repo, log))
+			r.Delete("/", deleteLabel(repo, log))
+
+			r.Post("/articles", labelArticles(service, log))
+			r.Delete("/articles", labelArticles(service, log))
+		})
+	}}
+}
+
+func annotationRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
+	return routes{path: "/annotation", route: func(r chi.Router) {
+		r.Use(timeout(30*time.Second), gzip, access)
+
+		r.Get("/", listAnnotations(service, log))
+		r.Get("/export", exportAnnotations(service, log))
+	}}
+}
+
 func articlesRoutes(
 	service repo.Service,
 	extractor extract.Generator,
@@ -280,6 +423,8 @@ func articlesRoutes(
 	articleRepo := service.ArticleRepo()
 	feedRepo := service.FeedRepo()
 	tagRepo := service.TagRepo()
+	labelRepo := service.LabelRepo()
+	annotationRepo := service.AnnotationRepo()
 
 	return routes{path: "/article", route: func(r chi.Router) {
 		r.Use(timeout(30*time.Second), gzip, access)
@@ -304,7 +449,7 @@ func articlesRoutes(
 		r.Route("/{articleID:[0-9]+}", func(r chi.Router) {
 			r.Use(articleContext(articleRepo, processors, log))
 
-			r.Get("/", getArticle)
+			r.Get("/", getArticle(annotationRepo, log))
 			if extractor != nil {
 				r.Get("/format", formatArticle(service.ExtractRepo(), extractor, processors, log))
 			}
@@ -312,6 +457,25 @@ func articlesRoutes(
 			r.Delete("/read", articleStateChange(articleRepo, read, log))
 			r.Post("/favorite", articleStateChange(articleRepo, favorite, log))
 			r.Delete("/favorite", articleStateChange(articleRepo, favorite, log))
+			r.Post("/queue", articleStateChange(articleRepo, queued, log))
+			r.Delete("/queue", articleStateChange(articleRepo, queued, log))
+
+			r.Get("/playback", getPlayback(service.PlaybackRepo(), log))
+			r.Put("/playback", updatePlayback(service.PlaybackRepo(), log))
+
+			r.Get("/labels", getArticleLabels(labelRepo, log))
+
+			r.Route("/annotations", func(r chi.Router) {
+				r.Get("/", getArticleAnnotations(annotationRepo, log))
+				r.Post("/", addAnnotation(annotationRepo, log))
+
+				r.Route("/{annotationID:[0-9]+}", func(r chi.Router) {
+					r.Use(annotationContext(annotationRepo, log))
+
+					r.Put("/", updateAnnotation(annotationRepo, log))
+					r.Delete("/", deleteAnnotation(annotationRepo, log))
+				})
+			})
 		})
 
 		r.Route("/favorite", func(r chi.Router) {
@@ -325,6 +489,19 @@ func articlesRoutes(
 			r.Delete("/read", articlesStateChange(service, favoriteRepoType, read, log))
 		})
 
+		r.Route("/queue", func(r chi.Router) {
+			r.Get("/", getArticles(service, queueRepoType, noRepoType, processors, config.API.Limits.ArticlesPerQuery, log))
+			r.Post("/", articlesStateChange(service, userRepoType, queued, log))
+			r.Delete("/", articlesStateChange(service, userRepoType, queued, log))
+
+			r.Put("/order", reorderQueue(articleRepo, log))
+
+			r.Get("/ids", getIDs(service, queueRepoType, noRepoType, config.API.Limits.ArticlesPerQuery, log))
+
+			r.Post("/read", articlesStateChange(service, queueRepoType, read, log))
+			r.Delete("/read", articlesStateChange(service, queueRepoType, read, log))
+		})
+
 		r.Route("/popular", func(r chi.Router) {
 
 			r.Route("/feed/{feedID:[0-9]+}", func(r chi.Router) {
@@ -365,6 +542,16 @@ func articlesRoutes(
 			r.Delete("/read", articlesStateChange(service, tagRepoType, read, log))
 		})
 
+		r.Route("/label/{labelID:[0-9]+}", func(r chi.Router) {
+			r.Use(labelContext(labelRepo, log))
+
+			r.Get("/", getArticles(service, labelRepoType, noRepoType, processors, config.API.Limits.ArticlesPerQuery, log))
+			r.Get("/ids", getIDs(service, labelRepoType, noRepoType, config.API.Limits.ArticlesPerQuery, log))
+
+			r.Post("/read", articlesStateChange(service, labelRepoType, read, log))
+			r.Delete("/read", articlesStateChange(service, labelRepoType, read, log))
+		})
+
 	}}
 }
 
@@ -380,11 +567,12 @@ func eventsRoutes(
 	ctx context.Context,
 	service eventable.Service,
 	storage token.Storage,
-	feedManager *readeef.FeedManager,
+	events *eventLog,
 	log log.Log,
 ) routes {
 	return routes{path: "/events", route: func(r chi.Router) {
-		r.Get("/", eventSocket(ctx, service, storage, log))
+		r.Get("/", eventSocket(ctx, service, storage, events, log))
+		r.Get("/ws", eventWebSocket(ctx, service, storage, events, log))
 	}}
 }
 
@@ -417,6 +605,57 @@ func userRoutes(service repo.Service, secret []byte, log log.Log, gzip, access m
 	}}
 }
 
+func webhookRoutes(repo repo.Webhook, secret []byte, log log.Log, gzip, access mw) routes {
+	return routes{path: "/webhook", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+
+		r.Get("/", listWebhooks(repo, secret, log))
+		r.Post("/", addWebhook(repo, secret, log))
+
+		r.Route("/{webhookID:[0-9]+}", func(r chi.Router) {
+			r.Use(webhookContext(repo, log))
+
+			r.Get("/", getWebhook(secret))
+			r.Put("/", updateWebhook(repo, secret, log))
+			r.Delete("/", deleteWebhook(repo, log))
+
+			r.Get("/deliveries", getWebhookDeliveries(repo, log))
+		})
+	}}
+}
+
+func ruleRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
+	return routes{path: "/rules", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+
+		ruleRepo := service.RuleRepo()
+
+		r.Get("/", listRules(ruleRepo, log))
+		r.Post("/", addRule(ruleRepo, log))
+		r.Post("/test", testRule(service, log))
+
+		r.Route("/{ruleID:[0-9]+}", func(r chi.Router) {
+			r.Use(ruleContext(ruleRepo, log))
+
+			r.Get("/", getRule)
+			r.Put("/", updateRule(ruleRepo, log))
+			r.Delete("/", deleteRule(ruleRepo, log))
+
+			r.Get("/test", testRule(service, log))
+		})
+	}}
+}
+
+func outputRoutes(service repo.Service, log log.Log, gzip, access mw) routes {
+	return routes{path: "/output", route: func(r chi.Router) {
+		r.Use(timeout(5*time.Second), gzip, access)
+
+		r.Get("/", listOutputFeeds(service.OutputFeedRepo(), log))
+		r.Post("/", addOutputFeed(service, log))
+		r.Delete("/{token:[0-9a-f]+}", deleteOutputFeed(service.OutputFeedRepo(), log))
+	}}
+}
+
 func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
 	log.Printf(format, err)
 	http.Error(w, fmt.Sprintf(format, err.Error()), http.StatusInternalServerError)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"html"
 	"net/http"
 	"strconv"
@@ -19,14 +20,6 @@ import (
 
 var articleKey = contextKey("article")
 
-func getArticle(w http.ResponseWriter, r *http.Request) {
-	if article, stop := articleFromRequest(w, r); stop {
-		return
-	} else {
-		args{"article": article}.WriteJSON(w)
-	}
-}
-
 type articleRepoType int
 
 const (
@@ -36,6 +29,8 @@ const (
 	popularRepoType
 	tagRepoType
 	feedRepoType
+	labelRepoType
+	queueRepoType
 )
 
 func getArticles(
@@ -62,66 +57,13 @@ func getArticles(
 
 		o = append(o, content.Filters(content.GetUserFilters(user)))
 
-		switch repoType {
-		case favoriteRepoType:
-			o = append(o, content.FavoriteOnly)
-		case userRepoType:
-		case popularRepoType:
-			o = append(o, content.IncludeScores)
-			o = append(o, content.HighScoredFirst)
-			o = append(o, content.TimeRange(time.Now().AddDate(0, 0, -5), time.Now().Add(-15*time.Minute)))
-
-			switch subType {
-			case userRepoType:
-			case tagRepoType:
-				tag, stop := tagFromRequest(w, r)
-				if stop {
-					return
-				}
-
-				ids, err := tagRepo.FeedIDs(tag, user)
-				if err != nil {
-					fatal(w, log, "Error getting tag feed ids: %+v", err)
-					return
-				}
-
-				o = append(o, content.FeedIDs(ids))
-			case feedRepoType:
-				feed, stop := feedFromRequest(w, r)
-				if stop {
-					return
-				}
-
-				o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
-			default:
-				http.Error(w, "Unknown article repository", http.StatusBadRequest)
-				return
-			}
-		case tagRepoType:
-			tag, stop := tagFromRequest(w, r)
-			if stop {
-				return
-			}
-
-			ids, err := tagRepo.FeedIDs(tag, user)
-			if err != nil {
-				fatal(w, log, "Error getting tag feed ids: %+v", err)
-				return
-			}
-
-			o = append(o, content.FeedIDs(ids))
-		case feedRepoType:
-			feed, stop := feedFromRequest(w, r)
-			if stop {
-				return
-			}
-
-			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
-		default:
-			http.Error(w, "Unknown article repository", http.StatusBadRequest)
+		repoOpts, stop := repoTypeOptions(w, r, user, repoType, subType, time.Now().Add(-15*time.Minute), tagRepo, log)
+		if stop {
 			return
 		}
 
+		o = append(o, repoOpts...)
+
 		articles, err := repo.ForUser(user, o...)
 
 		if err != nil {
@@ -237,66 +179,13 @@ func getIDs(
 
 		o = append(o, content.Filters(content.GetUserFilters(user)))
 
-		switch repoType {
-		case favoriteRepoType:
-			o = append(o, content.FavoriteOnly)
-		case userRepoType:
-		case popularRepoType:
-			o = append(o, content.IncludeScores)
-			o = append(o, content.HighScoredFirst)
-			o = append(o, content.TimeRange(time.Now().AddDate(0, 0, -5), time.Now()))
-
-			switch subType {
-			case userRepoType:
-			case tagRepoType:
-				tag, stop := tagFromRequest(w, r)
-				if stop {
-					return
-				}
-
-				ids, err := tagRepo.FeedIDs(tag, user)
-				if err != nil {
-					fatal(w, log, "Error getting tag feed ids: %+v", err)
-					return
-				}
-
-				o = append(o, content.FeedIDs(ids))
-			case feedRepoType:
-				feed, stop := feedFromRequest(w, r)
-				if stop {
-					return
-				}
-
-				o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
-			default:
-				http.Error(w, "Unknown article repository", http.StatusBadRequest)
-				return
-			}
-		case tagRepoType:
-			tag, stop := tagFromRequest(w, r)
-			if stop {
-				return
-			}
-
-			ids, err := tagRepo.FeedIDs(tag, user)
-			if err != nil {
-				fatal(w, log, "Error getting tag feed ids: %+v", err)
-				return
-			}
-
-			o = append(o, content.FeedIDs(ids))
-		case feedRepoType:
-			feed, stop := feedFromRequest(w, r)
-			if stop {
-				return
-			}
-
-			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
-		default:
-			http.Error(w, "Unknown article repository", http.StatusBadRequest)
+		repoOpts, stop := repoTypeOptions(w, r, user, repoType, subType, time.Now(), tagRepo, log)
+		if stop {
 			return
 		}
 
+		o = append(o, repoOpts...)
+
 		ids, err := repo.IDs(user, o...)
 
 		if err != nil {
@@ -356,6 +245,7 @@ type articleState int
 const (
 	read articleState = iota
 	favorite
+	queued
 )
 
 func articleStateChange(
@@ -378,20 +268,26 @@ func articleStateChange(
 
 		var previousState bool
 
-		if state == read {
+		switch state {
+		case read:
 			previousState = article.Read
-		} else {
+		case favorite:
 			previousState = article.Favorite
+		case queued:
+			previousState = article.Queued
 		}
 
 		if previousState != value {
 			var err error
 			ids := []content.ArticleID{article.ID}
 
-			if state == read {
+			switch state {
+			case read:
 				err = repo.Read(value, user, content.IDs(ids))
-			} else {
+			case favorite:
 				err = repo.Favor(value, user, content.IDs(ids))
+			case queued:
+				err = repo.Queue(value, user, content.IDs(ids))
 			}
 
 			if err != nil {
@@ -407,6 +303,40 @@ func articleStateChange(
 	}
 }
 
+// reorderQueue moves the given articles to the front of the user's
+// read-later queue, in the order of their ids.
+func reorderQueue(repo repo.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		ids := []content.ArticleID{}
+		for _, v := range r.Form["id"] {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid id: %s", v), http.StatusBadRequest)
+				return
+			}
+
+			ids = append(ids, content.ArticleID(id))
+		}
+
+		if len(ids) == 0 {
+			http.Error(w, "No article ids provided", http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.ReorderQueue(user, ids); err != nil {
+			fatal(w, log, "Error reordering the article queue: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
 func articlesStateChange(
 	service repo.Service,
 	repoType articleRepoType,
@@ -429,10 +359,18 @@ func articlesStateChange(
 			return
 		}
 
+		// Without explicit ids, every matching article would be queued.
+		if state == queued && value && len(r.Form["id"]) == 0 {
+			http.Error(w, "Queueing requires explicit article ids", http.StatusBadRequest)
+			return
+		}
+
 		switch repoType {
 		case userRepoType:
 		case favoriteRepoType:
 			o = append(o, content.FavoriteOnly)
+		case queueRepoType:
+			o = append(o, content.QueuedOnly)
 		case tagRepoType:
 			tag, stop := tagFromRequest(w, r)
 			if stop {
@@ -453,16 +391,26 @@ func articlesStateChange(
 			}
 
 			o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
+		case labelRepoType:
+			label, stop := labelFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			o = append(o, content.LabelIDs([]content.LabelID{label.ID}))
 		default:
 			http.Error(w, "Unknown type", http.StatusBadRequest)
 			return
 		}
 
 		var err error
-		if state == read {
+		switch state {
+		case read:
 			err = articleRepo.Read(value, user, o...)
-		} else {
+		case favorite:
 			err = articleRepo.Favor(value, user, o...)
+		case queued:
+			err = articleRepo.Queue(value, user, o...)
 		}
 
 		if err != nil {
@@ -474,6 +422,82 @@ func articlesStateChange(
 	}
 }
 
+// repoTypeOptions returns the query options that limit the user's articles
+// to the ones of the given repository type. The tag, feed and label types
+// expect the tag, feed or label in the request context. Popular articles are limited to
+// the last few days, up to popularUntil.
+func repoTypeOptions(
+	w http.ResponseWriter,
+	r *http.Request,
+	user content.User,
+	repoType articleRepoType,
+	subType articleRepoType,
+	popularUntil time.Time,
+	tagRepo repo.Tag,
+	log log.Log,
+) ([]content.QueryOpt, bool) {
+	o := []content.QueryOpt{}
+
+	switch repoType {
+	case favoriteRepoType:
+		o = append(o, content.FavoriteOnly)
+	case queueRepoType:
+		// The queue has its own order, overriding the requested one.
+		o = append(o, content.QueuedOnly, content.Sorting(content.SortByQueue, content.AscendingOrder))
+	case userRepoType:
+	case popularRepoType:
+		o = append(o, content.IncludeScores)
+		o = append(o, content.HighScoredFirst)
+		o = append(o, content.TimeRange(time.Now().AddDate(0, 0, -5), popularUntil))
+
+		switch subType {
+		case userRepoType:
+		case tagRepoType, feedRepoType:
+			sub, stop := repoTypeOptions(w, r, user, subType, noRepoType, popularUntil, tagRepo, log)
+			if stop {
+				return o, true
+			}
+
+			o = append(o, sub...)
+		default:
+			http.Error(w, "Unknown article repository", http.StatusBadRequest)
+			return o, true
+		}
+	case tagRepoType:
+		tag, stop := tagFromRequest(w, r)
+		if stop {
+			return o, true
+		}
+
+		ids, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			fatal(w, log, "Error getting tag feed ids: %+v", err)
+			return o, true
+		}
+
+		o = append(o, content.FeedIDs(ids))
+	case feedRepoType:
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return o, true
+		}
+
+		o = append(o, content.FeedIDs([]content.FeedID{feed.ID}))
+	case labelRepoType:
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return o, true
+		}
+
+		o = append(o, content.LabelIDs([]content.LabelID{label.ID}))
+	default:
+		http.Error(w, "Unknown article repository", http.StatusBadRequest)
+		return o, true
+	}
+
+	return o, false
+}
+
 func articleQueryOptions(w http.ResponseWriter, r *http.Request, articlesLimit int) ([]content.QueryOpt, bool) {
 	o := []content.QueryOpt{}
 
@@ -18,28 +18,60 @@ import (
 
 func Test_getArticle(t *testing.T) {
 	tests := []struct {
-		name      string
-		noArticle bool
-		code      int
+		name           string
+		noArticle      bool
+		annotations    map[content.ArticleID][]content.Annotation
+		annotationsErr error
+		want           int
+		code           int
 	}{
 		{name: "no article", noArticle: true, code: http.StatusBadRequest},
+		{name: "annotations err", annotationsErr: errors.New("err"), code: http.StatusInternalServerError},
 		{name: "article", code: http.StatusOK},
+		{name: "annotated article", annotations: map[content.ArticleID][]content.Annotation{
+			1: {{ID: 1, ArticleID: 1, Note: "note"}, {ID: 2, ArticleID: 1, Quote: "quote"}},
+		}, want: 2, code: http.StatusOK},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			annotationRepo := mock_repo.NewMockAnnotation(ctrl)
+
 			r := httptest.NewRequest("GET", "/", nil)
 			w := NewCloseNotifier()
 
+			user := content.User{Login: "test"}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
 			if !tt.noArticle {
 				r = r.WithContext(context.WithValue(r.Context(), articleKey, content.Article{ID: 1}))
+
+				annotationRepo.EXPECT().ForArticles([]content.ArticleID{1}, userMatcher{user}).Return(tt.annotations, tt.annotationsErr)
 			}
 
-			getArticle(w, r)
+			getArticle(annotationRepo, logger)(w, r)
 
 			if tt.code != w.Code {
 				t.Errorf("getArticle() code = %v, want %v", w.Code, tt.code)
 				return
 			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Article     content.Article      `json:"article"`
+				Annotations []content.Annotation `json:"annotations"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Article.ID != 1 || got.Annotations == nil || len(got.Annotations) != tt.want {
+				t.Errorf("getArticle() = %s", w.Body.String())
+			}
 		})
 	}
 }
@@ -589,12 +621,17 @@ func Test_articleStateChange(t *testing.T) {
 		{name: "change read false", state: read, current: true, code: 200},
 		{name: "change favorite true", state: favorite, value: true, code: 200},
 		{name: "change favorite false", state: favorite, current: true, code: 200},
+		{name: "no change queued true", state: queued, current: true, value: true, code: 200},
+		{name: "queue err", state: queued, value: true, stateErr: errors.New("err"), code: 500},
+		{name: "change queued true", state: queued, value: true, code: 200},
+		{name: "change queued false", state: queued, current: true, code: 200},
 	}
 
 	type data struct {
 		Success  bool `json:"success"`
 		Read     bool `json:"read"`
 		Favorite bool `json:"favorite"`
+		Queued   bool `json:"queued"`
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -627,10 +664,13 @@ func Test_articleStateChange(t *testing.T) {
 
 				article = content.Article{ID: 4, Link: "http://example.com"}
 				if tt.current {
-					if tt.state == read {
+					switch tt.state {
+					case read:
 						article.Read = tt.current
-					} else {
+					case favorite:
 						article.Favorite = tt.current
+					case queued:
+						article.Queued = tt.current
 					}
 				}
 				r = r.WithContext(context.WithValue(r.Context(), articleKey, article))
@@ -639,10 +679,13 @@ func Test_articleStateChange(t *testing.T) {
 					break
 				}
 
-				if tt.state == read {
+				switch tt.state {
+				case read:
 					articleRepo.EXPECT().Read(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
-				} else {
+				case favorite:
 					articleRepo.EXPECT().Favor(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
+				case queued:
+					articleRepo.EXPECT().Queue(tt.value, userMatcher{user}, gomock.Any()).Return(tt.stateErr)
 				}
 
 				if tt.stateErr != nil {
@@ -664,10 +707,13 @@ func Test_articleStateChange(t *testing.T) {
 			var want data
 			if tt.code == 200 {
 				want = data{Success: true}
-				if tt.state == read {
+				switch tt.state {
+				case read:
 					want.Read = tt.value
-				} else {
+				case favorite:
 					want.Favorite = tt.value
+				case queued:
+					want.Queued = tt.value
 				}
 			}
 
@@ -712,6 +758,9 @@ func Test_articlesStateChange(t *testing.T) {
 		{name: "no feed", url: "/?limit=25&unreadFirst", repoType: feedRepoType, code: 400, noFeed: true},
 		{name: "user", url: "/?limit=25&beforeTime=100000&afterTime=500", repoType: userRepoType, code: 200, opts: content.QueryOptions{Limit: 25, AfterDate: time.Unix(500, 0), BeforeDate: time.Unix(100000, 0), SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
 		{name: "user favorite", url: "/?id=5&id=13&id=14", repoType: userRepoType, state: favorite, code: 200, opts: content.QueryOptions{IDs: []content.ArticleID{5, 13, 14}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
+		{name: "user queue", url: "/?id=5&id=13", value: true, repoType: userRepoType, state: queued, code: 200, opts: content.QueryOptions{IDs: []content.ArticleID{5, 13}, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
+		{name: "user queue without ids", url: "/", value: true, repoType: userRepoType, state: queued, badQuery: true, code: 400},
+		{name: "queue read", url: "/", value: true, repoType: queueRepoType, code: 200, opts: content.QueryOptions{QueuedOnly: true, SortField: content.SortByDate, SortOrder: content.DescendingOrder}},
 	}
 	type data struct {
 		Success bool `json:"success"`
@@ -801,6 +850,8 @@ func Test_articlesStateChange(t *testing.T) {
 					articleRepo.EXPECT().Read(tt.value, userMatcher{user}, gomock.Any()).DoAndReturn(do)
 				} else if tt.state == favorite {
 					articleRepo.EXPECT().Favor(tt.value, userMatcher{user}, gomock.Any()).DoAndReturn(do)
+				} else if tt.state == queued {
+					articleRepo.EXPECT().Queue(tt.value, userMatcher{user}, gomock.Any()).DoAndReturn(do)
 				}
 			}
 
@@ -824,6 +875,46 @@ func Test_articlesStateChange(t *testing.T) {
 	}
 }
 
+func Test_reorderQueue(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		ids        []content.ArticleID
+		reorderErr error
+		code       int
+	}{
+		{name: "no ids", url: "/", code: 400},
+		{name: "invalid id", url: "/?id=1&id=a", code: 400},
+		{name: "reorder err", url: "/?id=3", ids: []content.ArticleID{3}, reorderErr: errors.New("err"), code: 500},
+		{name: "reorder", url: "/?id=3&id=1&id=2", ids: []content.ArticleID{3, 1, 2}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+
+			user := content.User{Login: "test"}
+
+			r := httptest.NewRequest("PUT", tt.url, nil)
+			r.ParseForm()
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			w := httptest.NewRecorder()
+
+			if tt.ids != nil {
+				articleRepo.EXPECT().ReorderQueue(userMatcher{user}, tt.ids).Return(tt.reorderErr)
+			}
+
+			reorderQueue(articleRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("reorderQueue() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
 func Test_articleContext(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -4,9 +4,9 @@ package api
 
 import "fmt"
 
-const _articleRepoType_name = "userRepoTypefavoriteRepoTypepopularRepoTypetagRepoTypefeedRepoType"
+const _articleRepoType_name = "noRepoTypeuserRepoTypefavoriteRepoTypepopularRepoTypetagRepoTypefeedRepoTypelabelRepoTypequeueRepoType"
 
-var _articleRepoType_index = [...]uint8{0, 12, 28, 43, 54, 66}
+var _articleRepoType_index = [...]uint8{0, 10, 22, 38, 53, 64, 76, 89, 102}
 
 func (i articleRepoType) String() string {
 	if i < 0 || i >= articleRepoType(len(_articleRepoType_index)-1) {
@@ -0,0 +1,101 @@
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+)
+
+type contextKey string
+
+var userKey = contextKey("user")
+
+// Authenticator returns the user that made the request.
+type Authenticator func(r *http.Request) (content.User, error)
+
+// ErrorWriter responds with an error message, in the format of the emulated
+// api.
+type ErrorWriter func(w http.ResponseWriter, message string, code int)
+
+// ParseForm parses the query and form data of every request.
+func ParseForm(next http.Handler) http.Handler {
+	return FormParser(http.Error)(next)
+}
+
+// FormParser parses the query and form data of every request, reporting
+// parse errors with the given writer.
+func FormParser(writeError ErrorWriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseForm(); err != nil {
+				writeError(w, "Error parsing form data", http.StatusBadRequest)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUser stores the authenticated user in the request context. Failed
+// authentication is passed to the unauthorized handler, which is responsible
+// for the response.
+func RequireUser(
+	authenticate Authenticator,
+	unauthorized func(w http.ResponseWriter, r *http.Request, err error),
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := authenticate(r)
+			if err != nil {
+				unauthorized(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserFromRequest returns the user stored by RequireUser.
+func UserFromRequest(r *http.Request) content.User {
+	user, _ := r.Context().Value(userKey).(content.User)
+	return user
+}
+
+// WriteJSON responds with the json encoded data.
+func WriteJSON(w http.ResponseWriter, data interface{}) {
+	WriteJSONStatus(w, data, http.StatusOK)
+}
+
+// WriteJSONStatus responds with the json encoded data and the given status
+// code.
+func WriteJSONStatus(w http.ResponseWriter, data interface{}, code int) {
+	if b, err := json.Marshal(data); err == nil {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		w.Write(b)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// FilterProcessors drops the http proxy processor, since the clients of the
+// emulated apis don't go through the readeef proxy.
+func FilterProcessors(input []processor.Article) []processor.Article {
+	processors := make([]processor.Article, 0, len(input))
+
+	for i := range input {
+		if _, ok := input[i].(processor.ProxyHTTP); ok {
+			continue
+		}
+
+		processors = append(processors, input[i])
+	}
+
+	return processors
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+const (
+	eventLogSize      = 200
+	eventSaveInterval = 5 * time.Second
+)
+
+// loggedEvent is a service event, as stored in the user event logs. The ids
+// are monotonic across all users, and across restarts.
+type loggedEvent struct {
+	ID     int64          `json:"id"`
+	Type   string         `json:"type"`
+	Data   interface{}    `json:"data,omitempty"`
+	FeedID content.FeedID `json:"-"`
+}
+
+// eventLog keeps a bounded log of the recent events for each user that has
+// connected, so that clients can resume from the last event they saw.
+type eventLog struct {
+	mu     sync.Mutex
+	size   int
+	lastID int64
+	users  map[content.Login]*userEventLog
+
+	store *eventStore
+	// dirty holds the users whose logs have changed since the last save.
+	dirty map[content.Login]struct{}
+	log   log.Log
+}
+
+type userEventLog struct {
+	feeds  feedSet
+	events []loggedEvent
+	// truncated is the id of the newest event that is no longer in the
+	// log. Resuming from an older event would skip events.
+	truncated int64
+	acks      map[string]int64
+	listeners map[chan struct{}]struct{}
+}
+
+// newEventLog creates an event log, whose ids start after the given one.
+// Starting from a different id on each run avoids confusing the events of an
+// older run with newer ones.
+func newEventLog(size int, start int64) *eventLog {
+	return &eventLog{
+		size: size, lastID: start,
+		users: map[content.Login]*userEventLog{}, dirty: map[content.Login]struct{}{},
+	}
+}
+
+// persist loads the user logs from the store. Changed logs are saved there
+// by autosave.
+func (l *eventLog) persist(store eventStore, log log.Log) error {
+	lastID, users, err := store.load()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lastID > l.lastID {
+		l.lastID = lastID
+	}
+
+	for login, u := range users {
+		l.users[login] = u
+	}
+
+	l.store = &store
+	l.log = log
+
+	return nil
+}
+
+// listen adds the service events to the logs of the users they concern.
+func (l *eventLog) listen(ctx context.Context, service eventable.Service) {
+	listener := service.Listener()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-listener:
+			l.add(event)
+		}
+	}
+}
+
+// autosave periodically saves the changed user logs, and once more when the
+// context is done.
+func (l *eventLog) autosave(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.save()
+			return
+		case <-ticker.C:
+			l.save()
+		}
+	}
+}
+
+func (l *eventLog) add(ev eventable.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var feedID content.FeedID
+	if fd, ok := ev.Data.(eventable.FeedData); ok {
+		feedID = fd.FeedID()
+	}
+
+	var id int64
+	for login, u := range l.users {
+		if ud, ok := ev.Data.(eventable.UserData); ok && login != ud.UserLogin() {
+			continue
+		}
+
+		if _, ok := ev.Data.(eventable.FeedData); ok {
+			if _, ok := u.feeds[feedID]; !ok {
+				continue
+			}
+		}
+
+		// Events that are sent to several users share the same id.
+		if id == 0 {
+			l.lastID++
+			id = l.lastID
+		}
+
+		u.events = append(u.events, loggedEvent{ID: id, Type: ev.Name, Data: ev.Data, FeedID: feedID})
+		if len(u.events) > l.size {
+			u.truncated = u.events[0].ID
+			u.events = u.events[1:]
+		}
+		l.changed(login)
+
+		for c := range u.listeners {
+			select {
+			case c <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// register creates the user log, if needed, and updates the feeds whose
+// events are logged for the user. The returned channel is notified when
+// new events are logged, until the cancel function is called. The id of
+// the newest event at the time of the registration is also returned.
+func (l *eventLog) register(login content.Login, feeds feedSet) (<-chan struct{}, int64, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.user(login)
+	u.feeds = feeds
+	l.changed(login)
+
+	c := make(chan struct{}, 1)
+	u.listeners[c] = struct{}{}
+
+	return c, l.lastID, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(u.listeners, c)
+	}
+}
+
+func (l *eventLog) user(login content.Login) *userEventLog {
+	u, ok := l.users[login]
+	if !ok {
+		// Events before the creation of the log are unknown.
+		u = &userEventLog{
+			truncated: l.lastID,
+			acks:      map[string]int64{},
+			listeners: map[chan struct{}]struct{}{},
+		}
+		l.users[login] = u
+	}
+
+	return u
+}
+
+// since returns the user events after the given id. If some of those
+// events are no longer in the log, or the id is unknown, it also returns
+// false.
+func (l *eventLog) since(login content.Login, id int64) ([]loggedEvent, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, ok := l.users[login]
+	if !ok {
+		return nil, false
+	}
+
+	complete := id >= u.truncated && id <= l.lastID
+
+	var events []loggedEvent
+	for _, e := range u.events {
+		if e.ID > id {
+			events = append(events, e)
+		}
+	}
+
+	return events, complete
+}
+
+// ack stores the last event that the user's client has processed.
+func (l *eventLog) ack(login content.Login, client string, id int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.user(login)
+	if id > u.acks[client] {
+		u.acks[client] = id
+		l.changed(login)
+	}
+}
+
+// acked returns the last event that the user's client has acknowledged.
+func (l *eventLog) acked(login content.Login, client string) (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if u, ok := l.users[login]; ok {
+		id, ok := u.acks[client]
+		return id, ok
+	}
+
+	return 0, false
+}
+
+// changed marks the user log for saving, if the event log is persisted. It
+// has to be called with the lock held.
+func (l *eventLog) changed(login content.Login) {
+	if l.store != nil {
+		l.dirty[login] = struct{}{}
+	}
+}
+
+// save stores the changed user logs, if the event log is persisted. The
+// logs are copied with the lock held, and stored after it is released.
+func (l *eventLog) save() {
+	l.mu.Lock()
+	if l.store == nil || len(l.dirty) == 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	store, lastID := l.store, l.lastID
+	users := make(map[content.Login]*userEventLog, len(l.dirty))
+	for login := range l.dirty {
+		u := l.users[login]
+
+		c := &userEventLog{
+			feeds:     make(feedSet, len(u.feeds)),
+			events:    append([]loggedEvent(nil), u.events...),
+			truncated: u.truncated,
+			acks:      make(map[string]int64, len(u.acks)),
+		}
+		for id := range u.feeds {
+			c.feeds[id] = struct{}{}
+		}
+		for client, id := range u.acks {
+			c.acks[client] = id
+		}
+
+		users[login] = c
+	}
+	l.dirty = map[content.Login]struct{}{}
+	l.mu.Unlock()
+
+	if err := store.save(lastID, users); err != nil {
+		l.log.Printf("Error saving event logs: %+v", err)
+
+		l.mu.Lock()
+		for login := range users {
+			l.dirty[login] = struct{}{}
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+)
+
+func Test_eventLog(t *testing.T) {
+	l := newEventLog(2, 0)
+
+	_, current, cancel := l.register("a", feedSet{1: {}})
+	defer cancel()
+
+	if current != 0 {
+		t.Errorf("eventLog.register() id = %d, want 0", current)
+	}
+
+	_, _, cancel = l.register("b", feedSet{2: {}})
+	defer cancel()
+
+	l.add(eventable.Event{Name: eventable.FeedUpdateEvent, Data: eventable.FeedUpdateData{Feed: content.Feed{ID: 1}}})
+	l.add(eventable.Event{Name: eventable.ArticleStateEvent, Data: eventable.ArticleStateData{User: "b"}})
+	l.add(eventable.Event{Name: eventable.FeedUpdateEvent, Data: eventable.FeedUpdateData{Feed: content.Feed{ID: 3}}})
+
+	if events, complete := l.since("a", 0); !complete || len(events) != 1 || events[0].ID != 1 || events[0].FeedID != 1 {
+		t.Errorf("eventLog.since(a) = %v, %v", events, complete)
+	}
+
+	if events, complete := l.since("b", 0); !complete || len(events) != 1 || events[0].ID != 2 {
+		t.Errorf("eventLog.since(b) = %v, %v", events, complete)
+	}
+
+	l.add(eventable.Event{Name: eventable.FeedUpdateEvent, Data: eventable.FeedUpdateData{Feed: content.Feed{ID: 1}}})
+	l.add(eventable.Event{Name: eventable.FeedDeleteEvent, Data: eventable.FeedDeleteData{Feed: content.Feed{ID: 1}}})
+
+	if events, complete := l.since("a", 0); complete || len(events) != 2 {
+		t.Errorf("eventLog.since(a, 0) = %v, %v, want an incomplete log", events, complete)
+	}
+
+	if events, complete := l.since("a", 1); !complete || len(events) != 2 || events[0].ID != 3 || events[1].ID != 4 {
+		t.Errorf("eventLog.since(a, 1) = %v, %v", events, complete)
+	}
+
+	if events, complete := l.since("a", 4); !complete || len(events) != 0 {
+		t.Errorf("eventLog.since(a, 4) = %v, %v", events, complete)
+	}
+
+	if _, complete := l.since("a", 10); complete {
+		t.Errorf("eventLog.since(a, 10) is complete for an unknown id")
+	}
+
+	if _, complete := l.since("c", 0); complete {
+		t.Errorf("eventLog.since(c, 0) is complete for an unknown user")
+	}
+
+	_, current, cancel = l.register("c", feedSet{})
+	defer cancel()
+
+	if _, complete := l.since("c", 0); complete {
+		t.Errorf("eventLog.since(c, 0) is complete for events before the log creation")
+	}
+
+	if _, complete := l.since("c", current); !complete {
+		t.Errorf("eventLog.since(c, %d) is incomplete", current)
+	}
+
+	l.ack("a", "phone", 3)
+	l.ack("a", "phone", 2)
+	if id, ok := l.acked("a", "phone"); !ok || id != 3 {
+		t.Errorf("eventLog.acked() = %d, %v, want 3", id, ok)
+	}
+
+	if _, ok := l.acked("a", "desktop"); ok {
+		t.Errorf("eventLog.acked() found an ack for an unknown client")
+	}
+}
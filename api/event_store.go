@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+var (
+	eventStateBucket = []byte("event-state-bucket")
+	eventUserBucket  = []byte("event-user-bucket")
+	eventLastIDKey   = []byte("last-id")
+)
+
+// eventStore persists the user event logs, so that clients can resume their
+// event streams after a restart.
+type eventStore struct {
+	db *bolt.DB
+}
+
+type storedUserEventLog struct {
+	Feeds     []content.FeedID `json:"feeds"`
+	Events    []storedEvent    `json:"events"`
+	Truncated int64            `json:"truncated"`
+	Acks      map[string]int64 `json:"acks"`
+}
+
+type storedEvent struct {
+	ID     int64           `json:"id"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	FeedID content.FeedID  `json:"feedID,omitempty"`
+}
+
+func newEventStore(path string) (eventStore, error) {
+	db, err := bolt.Open(path, 0660, nil)
+	if err != nil {
+		return eventStore{}, errors.Wrapf(err, "opening event bolt storage %s", path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{eventStateBucket, eventUserBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return eventStore{}, errors.Wrap(err, "creating event bolt buckets")
+	}
+
+	return eventStore{db}, nil
+}
+
+// load returns the id of the last logged event, and the stored user logs.
+func (s eventStore) load() (int64, map[content.Login]*userEventLog, error) {
+	var lastID int64
+	users := map[content.Login]*userEventLog{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(eventStateBucket).Get(eventLastIDKey); v != nil {
+			lastID = int64(binary.BigEndian.Uint64(v))
+		}
+
+		return tx.Bucket(eventUserBucket).ForEach(func(k, v []byte) error {
+			var stored storedUserEventLog
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return errors.Wrapf(err, "unmarshaling event log of user %s", k)
+			}
+
+			u := &userEventLog{
+				feeds:     feedSet{},
+				events:    make([]loggedEvent, len(stored.Events)),
+				truncated: stored.Truncated,
+				acks:      stored.Acks,
+				listeners: map[chan struct{}]struct{}{},
+			}
+
+			if u.acks == nil {
+				u.acks = map[string]int64{}
+			}
+
+			for _, id := range stored.Feeds {
+				u.feeds[id] = struct{}{}
+			}
+
+			for i, e := range stored.Events {
+				u.events[i] = loggedEvent{ID: e.ID, Type: e.Type, FeedID: e.FeedID}
+				if len(e.Data) > 0 {
+					u.events[i].Data = e.Data
+				}
+			}
+
+			users[content.Login(k)] = u
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		err = errors.Wrap(err, "loading event logs")
+	}
+
+	return lastID, users, err
+}
+
+// save stores the id of the last logged event, along with the logs of the
+// given users.
+func (s eventStore) save(lastID int64, users map[content.Login]*userEventLog) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(lastID))
+
+		if err := tx.Bucket(eventStateBucket).Put(eventLastIDKey, buf); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(eventUserBucket)
+		for login, u := range users {
+			stored := storedUserEventLog{
+				Feeds:     make([]content.FeedID, 0, len(u.feeds)),
+				Events:    make([]storedEvent, len(u.events)),
+				Truncated: u.truncated,
+				Acks:      u.acks,
+			}
+
+			for id := range u.feeds {
+				stored.Feeds = append(stored.Feeds, id)
+			}
+
+			for i, e := range u.events {
+				stored.Events[i] = storedEvent{ID: e.ID, Type: e.Type, FeedID: e.FeedID}
+
+				if e.Data != nil {
+					data, err := json.Marshal(e.Data)
+					if err != nil {
+						return errors.Wrapf(err, "marshaling data of event %d", e.ID)
+					}
+
+					stored.Events[i].Data = data
+				}
+			}
+
+			v, err := json.Marshal(stored)
+			if err != nil {
+				return errors.Wrapf(err, "marshaling event log of user %s", login)
+			}
+
+			if err := b.Put([]byte(login), v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		err = errors.Wrap(err, "saving event logs")
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+)
+
+func Test_eventStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "readeef-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := newEventStore(filepath.Join(dir, "events.db"))
+	if err != nil {
+		t.Fatalf("newEventStore() error = %v", err)
+	}
+	defer store.db.Close()
+
+	l := newEventLog(2, 10)
+	if err := l.persist(store, logger); err != nil {
+		t.Fatalf("eventLog.persist() error = %v", err)
+	}
+
+	_, _, cancel := l.register("a", feedSet{1: {}})
+	cancel()
+
+	for i := 1; i <= 3; i++ {
+		l.add(eventable.Event{Name: eventable.FeedUpdateEvent, Data: eventable.FeedUpdateData{
+			Feed: content.Feed{ID: 1}, NewArticles: []content.Article{{ID: content.ArticleID(i)}},
+		}})
+	}
+	l.ack("a", "phone", 12)
+
+	// Nothing is stored until the changes are saved.
+	if unsaved := newEventLog(2, 5); unsaved.persist(store, logger) == nil && unsaved.lastID != 5 {
+		t.Errorf("unsaved lastID = %d, want 5", unsaved.lastID)
+	}
+
+	l.save()
+
+	// A restart with an older start id continues from the stored one.
+	restored := newEventLog(2, 5)
+	if err := restored.persist(store, logger); err != nil {
+		t.Fatalf("eventLog.persist() error = %v", err)
+	}
+
+	if restored.lastID != 13 {
+		t.Errorf("restored lastID = %d, want 13", restored.lastID)
+	}
+
+	events, complete := restored.since("a", 11)
+	if !complete || len(events) != 2 || events[0].ID != 12 || events[1].FeedID != 1 {
+		t.Fatalf("restored.since(a, 11) = %v, %v", events, complete)
+	}
+
+	want := `{"articleIDs":[3],"feedID":1}`
+	if b, err := json.Marshal(events[1].Data); err != nil || string(b) != want {
+		t.Errorf("restored event data = %s, %v, want %s", b, err, want)
+	}
+
+	if _, complete := restored.since("a", 10); complete {
+		t.Errorf("restored.since(a, 10) is complete for a truncated event")
+	}
+
+	if id, ok := restored.acked("a", "phone"); !ok || id != 12 {
+		t.Errorf("restored.acked() = %d, %v, want 12", id, ok)
+	}
+
+	// The stored feeds are still used for logging events.
+	restored.add(eventable.Event{Name: eventable.FeedDeleteEvent, Data: eventable.FeedDeleteData{Feed: content.Feed{ID: 1}}})
+	if events, _ := restored.since("a", 13); len(events) != 1 || events[0].ID != 14 {
+		t.Errorf("restored.since(a, 13) = %v", events)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,21 +16,26 @@ import (
 	"github.com/urandom/readeef/log"
 )
 
+const eventPingInterval = 10 * time.Second
+
 type event struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data,omitempty"`
 }
 
+// eventSocket streams the user's events as server-sent events. Each event
+// has an id, and reconnecting clients receive the events they missed after
+// the one in the Last-Event-ID header, or the lastEventID query parameter.
+// If those events are no longer available, a resync-required event is sent
+// instead.
 func eventSocket(
 	ctx context.Context,
 	service eventable.Service,
 	storage token.Storage,
+	events *eventLog,
 	log log.Log,
 ) http.HandlerFunc {
 	repo := service.FeedRepo()
-	monitor := &feedMonitor{ops: make(chan func(connMap), 10), service: service, log: log}
-
-	go monitor.loop(ctx)
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Debugln("Event connection initializing")
@@ -45,6 +51,20 @@ func eventSocket(
 			return
 		}
 
+		lastID := int64(-1)
+		v := r.Header.Get("Last-Event-ID")
+		if v == "" {
+			v = r.Form.Get("lastEventID")
+		}
+
+		if v != "" {
+			var err error
+			if lastID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
 		feeds, err := repo.ForUser(user)
 		if err != nil {
 			fatal(w, log, "Error getting user feeds: %+v", err)
@@ -62,25 +82,51 @@ func eventSocket(
 		w.Header().Set("Connection", "keep-alive")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-		defer monitor.removeConn(r.RemoteAddr)
+		notify, current, cancel := events.register(user.Login, feedSet)
+		defer cancel()
 
-		done := monitor.addConn(w, flusher, connectionValidator(storage, r), r.RemoteAddr, feedSet, user)
+		c := sseConn{
+			writer: w, flusher: flusher, login: user.Login, events: events,
+			validator: connectionValidator(storage, r), log: log, lastID: current,
+		}
 
 		log.Debugln("Initializing event stream")
-		err = event{Type: "connection-established"}.Write(w, flusher, log)
-		if err != nil {
+		if lastID >= 0 {
+			if err = c.resume(lastID); err != nil {
+				log.Printf("Error resuming event stream: %+v", err)
+				return
+			}
+		}
+
+		// The event is sent after the missed ones, so that its id doesn't
+		// make the client skip them if it reconnects during the replay,
+		// while still letting it resume from this point, even if it
+		// doesn't receive any events before reconnecting.
+		if err = c.write(event{Type: "connection-established"}, c.lastID); err != nil {
 			log.Printf("Error sending initial data: %+v", err)
 			return
 		}
 
+		ping := time.NewTicker(eventPingInterval)
+		defer ping.Stop()
+
 		for {
 			select {
-			case <-time.After(10 * time.Second):
+			case <-ping.C:
+				if !c.validator() {
+					log.Debugln("Connection no longer valid")
+					return
+				}
+
 				log.Debugln("Sending ping")
-				monitor.ping(r.RemoteAddr)
-			case <-done:
-				log.Debugln("Connection done")
-				return
+				err = event{}.Write(w, flusher, 0, log)
+			case <-notify:
+				if !c.validator() {
+					log.Debugln("Connection no longer valid")
+					return
+				}
+
+				err = c.sendEvents()
 			case <-w.(http.CloseNotifier).CloseNotify():
 				log.Debugln("Connection closed")
 				return
@@ -88,6 +134,11 @@ func eventSocket(
 				log.Debugln("Context cancelled")
 				return
 			}
+
+			if err != nil {
+				log.Printf("Error sending events: %+v", err)
+				return
+			}
 		}
 	}
 }
@@ -110,25 +161,62 @@ func connectionValidator(storage token.Storage, r *http.Request) func() bool {
 	}
 }
 
-type feedMonitor struct {
-	ops     chan func(connMap)
-	service eventable.Service
-	log     log.Log
-}
-
-type connMap map[string]connData
 type feedSet map[content.FeedID]struct{}
 
-type connData struct {
+type sseConn struct {
 	writer    io.Writer
 	flusher   http.Flusher
-	validator func() bool
-	feedSet   feedSet
 	login     content.Login
-	done      chan struct{}
+	events    *eventLog
+	validator func() bool
+	log       log.Log
+
+	lastID int64
+}
+
+// resume sends the events after the given id, or a resync-required event,
+// if some of them are missing.
+func (c *sseConn) resume(id int64) error {
+	events, complete := c.events.since(c.login, id)
+	if !complete {
+		return c.write(event{Type: "resync-required"}, c.lastID)
+	}
+
+	return c.writeEvents(events)
+}
+
+func (c *sseConn) sendEvents() error {
+	events, complete := c.events.since(c.login, c.lastID)
+	if !complete {
+		// The connection fell too far behind.
+		if len(events) > 0 {
+			c.lastID = events[len(events)-1].ID
+		}
+		return c.write(event{Type: "resync-required"}, c.lastID)
+	}
+
+	return c.writeEvents(events)
+}
+
+func (c *sseConn) writeEvents(events []loggedEvent) error {
+	for _, e := range events {
+		c.lastID = e.ID
+
+		if err := c.write(event{Type: e.Type, Data: e.Data}, e.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (e event) Write(w io.Writer, flusher http.Flusher, log log.Log) error {
+func (c *sseConn) write(e event, id int64) error {
+	return e.Write(c.writer, c.flusher, id, c.log)
+}
+
+// Write sends the event, with the given id, to the server-sent event stream.
+// An event without a type is sent as a keep-alive comment.
+func (e event) Write(w io.Writer, flusher http.Flusher, id int64, log log.Log) error {
 	if e.Type == "" {
 		// Comment event to keep the connection alive
 		if _, err := w.Write([]byte(": ping\n\n")); err != nil {
@@ -140,7 +228,8 @@ func (e event) Write(w io.Writer, flusher http.Flusher, log log.Log) error {
 		return nil
 	}
 
-	data := []byte("event: " + e.Type + "\n")
+	data := []byte("id: " + strconv.FormatInt(id, 10) + "\n")
+	data = append(data, []byte("event: "+e.Type+"\n")...)
 	if e.Data != nil {
 		b, err := json.Marshal(e.Data)
 		if err != nil {
@@ -163,94 +252,3 @@ func (e event) Write(w io.Writer, flusher http.Flusher, log log.Log) error {
 
 	return nil
 }
-
-func (fm *feedMonitor) processEvent(ev eventable.Event) {
-	fm.ops <- func(conns connMap) {
-		for _, d := range conns {
-			if ud, ok := ev.Data.(eventable.UserData); ok && d.login != ud.UserLogin() {
-				continue
-			}
-
-			if fd, ok := ev.Data.(eventable.FeedData); ok {
-				if _, ok := d.feedSet[fd.FeedID()]; !ok {
-					continue
-				}
-			}
-
-			if !d.validator() {
-				close(d.done)
-				continue
-			}
-
-			event := event{Type: ev.Name, Data: ev.Data}
-
-			err := event.Write(d.writer, d.flusher, fm.log)
-			if err != nil {
-				fm.log.Printf("Error sending article state event: %+v", err)
-				close(d.done)
-			}
-		}
-	}
-}
-
-func (fm *feedMonitor) ping(addr string) {
-	fm.ops <- func(conns connMap) {
-		if d, ok := conns[addr]; ok {
-			if !d.validator() {
-				close(d.done)
-				return
-			}
-
-			err := event{}.Write(d.writer, d.flusher, fm.log)
-			if err != nil {
-				fm.log.Printf("Error sending ping event: %+v", err)
-				close(d.done)
-			}
-		}
-	}
-}
-
-func (fm *feedMonitor) addConn(w io.Writer, flusher http.Flusher, validator func() bool, addr string, feedSet feedSet, user content.User) chan struct{} {
-	done := make(chan struct{})
-
-	fm.ops <- func(conns connMap) {
-		conns[addr] = connData{w, flusher, validator, feedSet, user.Login, done}
-	}
-
-	return done
-}
-
-func (fm *feedMonitor) removeConn(addr string) {
-	fm.ops <- func(conns connMap) {
-		delete(conns, addr)
-	}
-}
-
-func (fm *feedMonitor) loop(ctx context.Context) {
-	conns := make(connMap)
-	done := make(chan struct{})
-
-	listener := fm.service.Listener()
-
-	closed := false
-	for {
-		select {
-		case <-ctx.Done():
-			if !closed {
-				closed = true
-				// Give some time for the ops channel to drain the connection
-				// removal actions before exiting the loop.
-				time.AfterFunc(100*time.Millisecond, func() {
-					close(done)
-				})
-			}
-		case op := <-fm.ops:
-			op(conns)
-		case event := <-listener:
-			fm.log.Debugf("Got service event %s", event.Name)
-			go fm.processEvent(event)
-		case <-done:
-			return
-		}
-	}
-}
@@ -35,14 +35,14 @@ func Test_eventSocket(t *testing.T) {
 			name:    "initial data",
 			ctx:     lazyContext(time.Millisecond),
 			feeds:   []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data:    "event: connection-established\n\n",
+			data:    "id: 0\nevent: connection-established\n\n",
 			noToken: true,
 		},
 		{
 			name:    "no claim",
 			ctx:     lazyContext(10 * time.Millisecond),
 			feeds:   []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data:    "event: connection-established\n\n",
+			data:    "id: 0\nevent: connection-established\n\n",
 			noToken: true,
 			worker: func(ctx context.Context, s eventable.Service, a *mock_repo.MockArticle, f *mock_repo.MockFeed) {
 				time.Sleep(time.Millisecond)
@@ -55,7 +55,7 @@ func Test_eventSocket(t *testing.T) {
 			name:           "logged out",
 			ctx:            lazyContext(10 * time.Millisecond),
 			feeds:          []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data:           "event: connection-established\n\n",
+			data:           "id: 0\nevent: connection-established\n\n",
 			blacklistToken: true,
 			worker: func(ctx context.Context, s eventable.Service, a *mock_repo.MockArticle, f *mock_repo.MockFeed) {
 				time.Sleep(time.Millisecond)
@@ -68,7 +68,7 @@ func Test_eventSocket(t *testing.T) {
 			name:          "expired token",
 			ctx:           lazyContext(10 * time.Millisecond),
 			feeds:         []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data:          "event: connection-established\n\n",
+			data:          "id: 0\nevent: connection-established\n\n",
 			expiredClaims: true,
 			worker: func(ctx context.Context, s eventable.Service, a *mock_repo.MockArticle, f *mock_repo.MockFeed) {
 				time.Sleep(time.Millisecond)
@@ -81,15 +81,17 @@ func Test_eventSocket(t *testing.T) {
 			name:             "closed connection",
 			ctx:              lazyContext(10 * time.Millisecond),
 			feeds:            []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data:             "event: connection-established\n\n",
+			data:             "id: 0\nevent: connection-established\n\n",
 			closedConnection: true,
 		},
 		{
 			name:  "one event",
 			ctx:   lazyContext(10 * time.Millisecond),
 			feeds: []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data: `event: connection-established
+			data: `id: 0
+event: connection-established
 
+id: 1
 event: feed-update
 data: {"articleIDs":[1,2],"feedID":1}
 
@@ -106,8 +108,10 @@ data: {"articleIDs":[1,2],"feedID":1}
 			ctx:              lazyContext(10 * time.Millisecond),
 			feeds:            []content.Feed{{ID: 1, Link: "http://example.com"}},
 			closedConnection: true,
-			data: `event: connection-established
+			data: `id: 0
+event: connection-established
 
+id: 1
 event: feed-update
 data: {"articleIDs":[1,2],"feedID":1}
 
@@ -127,8 +131,10 @@ data: {"articleIDs":[1,2],"feedID":1}
 			name:  "one event and ping",
 			ctx:   lazyContext(10*time.Second + 10*time.Millisecond),
 			feeds: []content.Feed{{ID: 1, Link: "http://example.com"}},
-			data: `event: connection-established
+			data: `id: 0
+event: connection-established
 
+id: 1
 event: feed-update
 data: {"articleIDs":[1,2],"feedID":1}
 
@@ -158,9 +164,12 @@ data: {"articleIDs":[1,2],"feedID":1}
 
 			service.EXPECT().FeedRepo().Return(feedRepo)
 			service.EXPECT().ArticleRepo().Return(articleRepo)
+			service.EXPECT().AnnotationRepo().Return(mock_repo.NewMockAnnotation(ctrl))
 
 			ctx := tt.ctx()
 			ev := eventable.NewService(ctx, service, logger)
+			events := newEventLog(eventLogSize, 0)
+			go events.listen(ctx, ev)
 
 			code := http.StatusOK
 			var flush bool
@@ -185,7 +194,23 @@ data: {"articleIDs":[1,2],"feedID":1}
 				flush = true
 
 				if tt.worker != nil {
-					go tt.worker(ctx, ev, articleRepo, feedRepo)
+					go func() {
+						// Events are only logged after the connection registers,
+						// and the log subscribes to the service.
+						for {
+							if _, ok := events.since(user.Login, 0); ok {
+								break
+							}
+							time.Sleep(100 * time.Microsecond)
+						}
+						time.Sleep(time.Millisecond)
+
+						if tt.closedConnection {
+							time.AfterFunc(time.Millisecond, func() { w.Notify(true) })
+						}
+
+						tt.worker(ctx, ev, articleRepo, feedRepo)
+					}()
 				}
 
 				if !tt.noToken && tt.worker != nil {
@@ -195,12 +220,12 @@ data: {"articleIDs":[1,2],"feedID":1}
 					r = r.WithContext(context.WithValue(r.Context(), auth.ClaimsKey, claims{!tt.expiredClaims}))
 				}
 
-				if tt.closedConnection {
+				if tt.closedConnection && tt.worker == nil {
 					time.AfterFunc(time.Millisecond, func() { w.Notify(true) })
 				}
 			}
 
-			eventSocket(ctx, ev, storage, logger).ServeHTTP(w, r)
+			eventSocket(ctx, ev, storage, events, logger).ServeHTTP(w, r)
 
 			if code != w.Code {
 				t.Errorf("eventSocket() code = %v, want %v", w.Code, code)
@@ -231,6 +256,93 @@ data: {"articleIDs":[1,2],"feedID":1}
 	}
 }
 
+func Test_eventSocket_resume(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		code   int
+		data   string
+	}{
+		{name: "invalid id", header: "abc", code: http.StatusBadRequest},
+		{name: "no id", code: http.StatusOK, data: "id: 3\nevent: connection-established\n\n"},
+		{name: "last event", header: "3", code: http.StatusOK, data: "id: 3\nevent: connection-established\n\n"},
+		{name: "missed events", header: "1", code: http.StatusOK, data: `id: 2
+event: feed-update
+data: {"articleIDs":[2],"feedID":1}
+
+id: 3
+event: feed-update
+data: {"articleIDs":[3],"feedID":1}
+
+id: 3
+event: connection-established
+
+`},
+		{name: "query id", query: "2", code: http.StatusOK, data: `id: 3
+event: feed-update
+data: {"articleIDs":[3],"feedID":1}
+
+id: 3
+event: connection-established
+
+`},
+		{name: "truncated events", header: "0", code: http.StatusOK, data: "id: 3\nevent: resync-required\n\nid: 3\nevent: connection-established\n\n"},
+		{name: "unknown id", header: "10", code: http.StatusOK, data: "id: 3\nevent: resync-required\n\nid: 3\nevent: connection-established\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			service := mock_repo.NewMockService(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			storage := NewMockStorage(ctrl)
+
+			service.EXPECT().FeedRepo().Return(feedRepo)
+			service.EXPECT().ArticleRepo().Return(mock_repo.NewMockArticle(ctrl))
+			service.EXPECT().AnnotationRepo().Return(mock_repo.NewMockAnnotation(ctrl))
+
+			ctx := lazyContext(5 * time.Millisecond)()
+			ev := eventable.NewService(ctx, service, logger)
+
+			user := content.User{Login: "test"}
+
+			events := newEventLog(2, 0)
+			_, _, cancel := events.register(user.Login, feedSet{1: {}})
+			cancel()
+
+			for i := 1; i <= 3; i++ {
+				events.add(eventable.Event{Name: eventable.FeedUpdateEvent, Data: eventable.FeedUpdateData{
+					Feed: content.Feed{ID: 1}, NewArticles: []content.Article{{ID: content.ArticleID(i)}},
+				}})
+			}
+
+			r := httptest.NewRequest("GET", "/?lastEventID="+tt.query, nil)
+			r.ParseForm()
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			if tt.header != "" {
+				r.Header.Set("Last-Event-ID", tt.header)
+			}
+
+			if tt.code == http.StatusOK {
+				feedRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{{ID: 1}}, nil)
+			}
+
+			w := NewCloseNotifier()
+			eventSocket(ctx, ev, storage, events, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("eventSocket() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code == http.StatusOK && w.Body.String() != tt.data {
+				t.Errorf("eventSocket() data = %v, want %v", w.Body.String(), tt.data)
+			}
+		})
+	}
+}
+
 func lazyContext(t time.Duration) func() context.Context {
 	return func() context.Context {
 		ctx, cancel := context.WithTimeout(context.Background(), t)
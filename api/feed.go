@@ -8,11 +8,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/feed"
 	"github.com/urandom/readeef/log"
 )
 
@@ -86,9 +88,20 @@ func (e addFeedError) Error() string {
 }
 
 type feedManager interface {
-	AddFeedByLink(link string) (content.Feed, error)
+	AddFeedByLink(link string, auth content.FeedAuth) (content.Feed, error)
 	RemoveFeed(feed content.Feed)
-	DiscoverFeeds(link string) ([]content.Feed, error)
+	DiscoverFeeds(link string, auth content.FeedAuth) ([]content.Feed, error)
+	RefreshFeed(feed content.Feed) (int, error)
+}
+
+type feedScheduler interface {
+	SchedulerStats() feed.Stats
+}
+
+func getSchedulerStats(scheduler feedScheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		args{"stats": scheduler.SchedulerStats()}.WriteJSON(w)
+	}
 }
 
 func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
@@ -100,6 +113,12 @@ func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
 
 		links := r.Form["link"]
 
+		auth, err := feedAuthFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		var wait sync.WaitGroup
 		wait.Add(len(links))
 
@@ -112,7 +131,7 @@ func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
 		for i, link := range links {
 			go func(i int, link string) {
 				defer wait.Done()
-				feed, err := addFeedByURL(link, user, repo, feedManager)
+				feed, err := addFeedByURL(link, auth, user, repo, feedManager)
 				if err == nil {
 					feedResp[i].link = link
 					feedResp[i].feed = feed
@@ -142,8 +161,53 @@ func addFeed(repo repo.Feed, feedManager feedManager) http.HandlerFunc {
 	}
 }
 
+// feedAuthFromRequest reads the optional feed credentials from the request
+// form. Headers are given as "Name: value", and cookies as "name=value".
+func feedAuthFromRequest(r *http.Request) (content.FeedAuth, error) {
+	auth := content.FeedAuth{
+		Username:  r.Form.Get("username"),
+		Password:  r.Form.Get("password"),
+		Token:     r.Form.Get("token"),
+		UserAgent: r.Form.Get("userAgent"),
+	}
+
+	if v := r.Form.Get("cookieJar"); v != "" {
+		var err error
+		if auth.CookieJar, err = strconv.ParseBool(v); err != nil {
+			return auth, errors.Errorf("invalid cookieJar value %s", v)
+		}
+	}
+
+	for _, h := range r.Form["header"] {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return auth, errors.Errorf("invalid header %s", h)
+		}
+
+		if auth.Headers == nil {
+			auth.Headers = map[string]string{}
+		}
+		auth.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	for _, c := range r.Form["cookie"] {
+		parts := strings.SplitN(c, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return auth, errors.Errorf("invalid cookie %s", c)
+		}
+
+		if auth.Cookies == nil {
+			auth.Cookies = map[string]string{}
+		}
+		auth.Cookies[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return auth, nil
+}
+
 func addFeedByURL(
 	link string,
+	auth content.FeedAuth,
 	user content.User,
 	repo repo.Feed,
 	feedManager feedManager,
@@ -157,7 +221,7 @@ func addFeedByURL(
 		return content.Feed{}, addFeedError{Link: link, Message: "Link is not absolute"}
 	}
 
-	if f, err := feedManager.AddFeedByLink(link); err == nil {
+	if f, err := feedManager.AddFeedByLink(link, auth); err == nil {
 		err = repo.AttachTo(f, user)
 		if err != nil {
 			return content.Feed{}, addFeedError{Link: link, Title: f.Title, Message: fmt.Sprintf("adding feed to user %s: %s", user, err.Error())}
@@ -205,6 +269,135 @@ func deleteFeed(repo repo.Feed, feedManager feedManager, log log.Log) http.Handl
 	}
 }
 
+func refreshFeed(feedManager feedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		count, err := feedManager.RefreshFeed(feed)
+		if err != nil {
+			log.Infof("Error refreshing feed %s: %+v", feed, err)
+			args{"success": false, "error": err.Error()}.WriteJSON(w)
+			return
+		}
+
+		args{"success": true, "newArticles": count}.WriteJSON(w)
+	}
+}
+
+// settingScope selects whether a feed option is set on the user's
+// subscription, or on the feed itself.
+type settingScope int
+
+const (
+	subscriptionSetting settingScope = iota
+	feedSetting
+)
+
+// feedExtractChange enables the background content extraction of a feed on
+// POST, and disables it on DELETE. The option is set either on the user's
+// subscription, or on the feed itself.
+func feedExtractChange(repo repo.Feed, scope settingScope, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		value := r.Method == http.MethodPost
+
+		var err error
+		if scope == feedSetting {
+			err = repo.SetExtract(feed, value)
+			feed.ExtractContent = value
+		} else {
+			err = repo.SetUserExtract(feed, user, value)
+			feed.UserExtractContent = value
+		}
+
+		if err != nil {
+			fatal(w, log, "Error setting feed content extraction: %+v", err)
+			return
+		}
+
+		args{"success": true, "extractContent": feed.ExtractContent, "userExtractContent": feed.UserExtractContent}.WriteJSON(w)
+	}
+}
+
+// feedRetentionChange sets the article retention of a feed on PUT, using
+// the maxArticles and maxAge form values, and clears it on DELETE.
+func feedRetentionChange(repo repo.Feed, scope settingScope, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		var retention content.Retention
+		if r.Method == http.MethodPut {
+			var err error
+			if retention, err = retentionFromForm(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		var err error
+		if scope == feedSetting {
+			err = repo.SetRetention(feed, retention)
+			feed.Retention = retention
+		} else {
+			err = repo.SetUserRetention(feed, user, retention)
+			feed.UserRetention = retention
+		}
+
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error setting feed retention: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "retention": feed.Retention, "userRetention": feed.UserRetention}.WriteJSON(w)
+	}
+}
+
+func retentionFromForm(r *http.Request) (content.Retention, error) {
+	var retention content.Retention
+
+	if v := r.Form.Get("maxArticles"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return retention, fmt.Errorf("Invalid maxArticles: %s", v)
+		}
+		retention.MaxArticles = i
+	}
+
+	if v := r.Form.Get("maxAge"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return retention, fmt.Errorf("Invalid maxAge: %s", v)
+		}
+		retention.MaxAge = d
+	}
+
+	return retention, retention.Validate()
+}
+
 func discoverFeeds(repo repo.Feed, discoverer feedManager, log log.Log) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		query := r.Form.Get("query")
@@ -246,7 +439,7 @@ func discoverFeedsByQuery(query string, user content.User, repo repo.Feed, disco
 		}
 	}
 
-	feeds, err := discoverer.DiscoverFeeds(query)
+	feeds, err := discoverer.DiscoverFeeds(query, content.FeedAuth{})
 	if err != nil {
 		return nil, err
 	}
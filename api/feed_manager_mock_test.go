@@ -35,16 +35,16 @@ func (m *MockfeedManager) EXPECT() *MockfeedManagerMockRecorder {
 }
 
 // AddFeedByLink mocks base method
-func (m *MockfeedManager) AddFeedByLink(link string) (content.Feed, error) {
-	ret := m.ctrl.Call(m, "AddFeedByLink", link)
+func (m *MockfeedManager) AddFeedByLink(link string, auth content.FeedAuth) (content.Feed, error) {
+	ret := m.ctrl.Call(m, "AddFeedByLink", link, auth)
 	ret0, _ := ret[0].(content.Feed)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddFeedByLink indicates an expected call of AddFeedByLink
-func (mr *MockfeedManagerMockRecorder) AddFeedByLink(link interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFeedByLink", reflect.TypeOf((*MockfeedManager)(nil).AddFeedByLink), link)
+func (mr *MockfeedManagerMockRecorder) AddFeedByLink(link, auth interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFeedByLink", reflect.TypeOf((*MockfeedManager)(nil).AddFeedByLink), link, auth)
 }
 
 // RemoveFeed mocks base method
@@ -58,14 +58,27 @@ func (mr *MockfeedManagerMockRecorder) RemoveFeed(feed interface{}) *gomock.Call
 }
 
 // DiscoverFeeds mocks base method
-func (m *MockfeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
-	ret := m.ctrl.Call(m, "DiscoverFeeds", link)
+func (m *MockfeedManager) DiscoverFeeds(link string, auth content.FeedAuth) ([]content.Feed, error) {
+	ret := m.ctrl.Call(m, "DiscoverFeeds", link, auth)
 	ret0, _ := ret[0].([]content.Feed)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DiscoverFeeds indicates an expected call of DiscoverFeeds
-func (mr *MockfeedManagerMockRecorder) DiscoverFeeds(link interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverFeeds", reflect.TypeOf((*MockfeedManager)(nil).DiscoverFeeds), link)
+func (mr *MockfeedManagerMockRecorder) DiscoverFeeds(link, auth interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverFeeds", reflect.TypeOf((*MockfeedManager)(nil).DiscoverFeeds), link, auth)
+}
+
+// RefreshFeed mocks base method
+func (m *MockfeedManager) RefreshFeed(feed content.Feed) (int, error) {
+	ret := m.ctrl.Call(m, "RefreshFeed", feed)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshFeed indicates an expected call of RefreshFeed
+func (mr *MockfeedManagerMockRecorder) RefreshFeed(feed interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshFeed", reflect.TypeOf((*MockfeedManager)(nil).RefreshFeed), feed)
 }
@@ -10,11 +10,13 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/feed"
 )
 
 func Test_feedContext(t *testing.T) {
@@ -161,6 +163,7 @@ func Test_addFeed(t *testing.T) {
 		name         string
 		form         url.Values
 		noUser       bool
+		auth         content.FeedAuth
 		addedFeed    []content.Feed
 		addedFeedErr []error
 		attachErr    []error
@@ -174,6 +177,24 @@ func Test_addFeed(t *testing.T) {
 			addedFeedErr: []error{nil},
 			attachErr:    []error{nil},
 		},
+		{
+			name: "credentials",
+			form: url.Values{
+				"link":     []string{"http://example.com"},
+				"username": []string{"user"},
+				"password": []string{"pass"},
+				"header":   []string{"X-Key: value"},
+				"cookie":   []string{"session=abc"},
+			},
+			auth: content.FeedAuth{
+				Username: "user", Password: "pass",
+				Headers: map[string]string{"X-Key": "value"},
+				Cookies: map[string]string{"session": "abc"},
+			},
+			addedFeed:    []content.Feed{{ID: 1, Link: "http://example.com"}},
+			addedFeedErr: []error{nil},
+			attachErr:    []error{nil},
+		},
 		{
 			name:         "multi links",
 			form:         url.Values{"link": []string{"http://example.com", "http://broken.com"}},
@@ -221,7 +242,7 @@ func Test_addFeed(t *testing.T) {
 				want.Errors = []addErr{}
 				want.Feeds = map[string]content.Feed{}
 				for i, link := range tt.form["link"] {
-					feedManager.EXPECT().AddFeedByLink(link).Return(tt.addedFeed[i], tt.addedFeedErr[i])
+					feedManager.EXPECT().AddFeedByLink(link, tt.auth).Return(tt.addedFeed[i], tt.addedFeedErr[i])
 
 					if tt.addedFeedErr[i] != nil {
 						want.Errors = append(want.Errors, addErr{Link: link, Error: "adding feed to the database: " + tt.addedFeedErr[i].Error()})
@@ -260,6 +281,38 @@ func Test_addFeed(t *testing.T) {
 	}
 }
 
+type schedulerStatsFunc func() feed.Stats
+
+func (f schedulerStatsFunc) SchedulerStats() feed.Stats {
+	return f()
+}
+
+func Test_getSchedulerStats(t *testing.T) {
+	want := feed.Stats{Feeds: 3, Queued: 1, Workers: 2, Busy: 1, Hosts: map[string]int{"example.com": 1}}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	getSchedulerStats(schedulerStatsFunc(func() feed.Stats {
+		return want
+	})).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("getSchedulerStats() code = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var got struct {
+		Stats feed.Stats `json:"stats"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("getSchedulerStats() body = %s, error = %+v", w.Body, err)
+	}
+
+	if !reflect.DeepEqual(got.Stats, want) {
+		t.Errorf("getSchedulerStats() got = %v, want = %v", got.Stats, want)
+	}
+}
+
 func Test_deleteFeed(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -340,6 +393,76 @@ func Test_deleteFeed(t *testing.T) {
 	}
 }
 
+func Test_refreshFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		noFeed     bool
+		count      int
+		refreshErr error
+	}{
+		{name: "no feed", noFeed: true},
+		{name: "refresh err", refreshErr: errors.New("HTTP Status: 500")},
+		{name: "not modified"},
+		{name: "success", count: 3},
+	}
+
+	type data struct {
+		Success     bool   `json:"success"`
+		NewArticles int    `json:"newArticles"`
+		Error       string `json:"error"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedManager := NewMockfeedManager(ctrl)
+
+			r := httptest.NewRequest("POST", "/", nil)
+			w := httptest.NewRecorder()
+
+			code := http.StatusOK
+			want := data{}
+			switch {
+			default:
+				if tt.noFeed {
+					code = http.StatusBadRequest
+					break
+				}
+
+				feed := content.Feed{ID: 1, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+				feedManager.EXPECT().RefreshFeed(feed).Return(tt.count, tt.refreshErr)
+
+				if tt.refreshErr != nil {
+					want.Error = tt.refreshErr.Error()
+					break
+				}
+
+				want.Success = true
+				want.NewArticles = tt.count
+			}
+
+			refreshFeed(feedManager, logger).ServeHTTP(w, r)
+
+			if code != w.Code {
+				t.Errorf("refreshFeed() code = %v, want %v", w.Code, code)
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); (err != nil) && (code == http.StatusOK) {
+				t.Errorf("refreshFeed() body = %s, error = %+v", w.Body, err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("refreshFeed() got = %v, want = %v", got, want)
+			}
+		})
+	}
+}
+
 func Test_discoverFeeds(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -402,7 +525,7 @@ func Test_discoverFeeds(t *testing.T) {
 					break
 				}
 
-				feedManager.EXPECT().DiscoverFeeds(query).Return(tt.discoverFeeds, tt.discoverFeedsErr)
+				feedManager.EXPECT().DiscoverFeeds(query, content.FeedAuth{}).Return(tt.discoverFeeds, tt.discoverFeedsErr)
 
 				if tt.discoverFeedsErr != nil {
 					code = http.StatusInternalServerError
@@ -431,3 +554,190 @@ func Test_discoverFeeds(t *testing.T) {
 		})
 	}
 }
+
+func Test_feedExtractChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		scope  settingScope
+		noUser bool
+		noFeed bool
+		setErr error
+		code   int
+	}{
+		{name: "no user", method: "POST", noUser: true, code: 400},
+		{name: "no feed", method: "POST", noFeed: true, code: 400},
+		{name: "subscription err", method: "POST", setErr: errors.New("err"), code: 500},
+		{name: "subscription enable", method: "POST", code: 200},
+		{name: "subscription disable", method: "DELETE", code: 200},
+		{name: "feed err", method: "POST", scope: feedSetting, setErr: errors.New("err"), code: 500},
+		{name: "feed enable", method: "POST", scope: feedSetting, code: 200},
+		{name: "feed disable", method: "DELETE", scope: feedSetting, code: 200},
+	}
+
+	type data struct {
+		Success            bool `json:"success"`
+		ExtractContent     bool `json:"extractContent"`
+		UserExtractContent bool `json:"userExtractContent"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+
+			r := httptest.NewRequest(tt.method, "/", nil)
+			w := httptest.NewRecorder()
+
+			value := tt.method == "POST"
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.noFeed {
+					break
+				}
+
+				feed := content.Feed{ID: 1, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+				if tt.scope == feedSetting {
+					feedRepo.EXPECT().SetExtract(feed, value).Return(tt.setErr)
+				} else {
+					feedRepo.EXPECT().SetUserExtract(feed, userMatcher{user}, value).Return(tt.setErr)
+				}
+			}
+
+			feedExtractChange(feedRepo, tt.scope, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("feedExtractChange() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("feedExtractChange() body = %s, error = %+v", w.Body, err)
+				return
+			}
+
+			want := data{Success: true}
+			if tt.scope == feedSetting {
+				want.ExtractContent = value
+			} else {
+				want.UserExtractContent = value
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("feedExtractChange() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_feedRetentionChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		scope     settingScope
+		noUser    bool
+		noFeed    bool
+		retention content.Retention
+		setErr    error
+		code      int
+	}{
+		{name: "no user", method: "PUT", noUser: true, code: 400},
+		{name: "no feed", method: "PUT", noFeed: true, code: 400},
+		{name: "invalid max articles", method: "PUT", url: "?maxArticles=a", code: 400},
+		{name: "invalid max age", method: "PUT", url: "?maxAge=a", code: 400},
+		{name: "negative max articles", method: "PUT", url: "?maxArticles=-1", code: 400},
+		{name: "subscription err", method: "PUT", url: "?maxArticles=10", retention: content.Retention{MaxArticles: 10}, setErr: errors.New("err"), code: 500},
+		{name: "subscription set", method: "PUT", url: "?maxArticles=10&maxAge=720h", retention: content.Retention{MaxArticles: 10, MaxAge: 720 * time.Hour}, code: 200},
+		{name: "subscription clear", method: "DELETE", code: 200},
+		{name: "feed err", method: "PUT", url: "?maxAge=1h", scope: feedSetting, retention: content.Retention{MaxAge: time.Hour}, setErr: errors.New("err"), code: 500},
+		{name: "feed set", method: "PUT", url: "?maxAge=1h", scope: feedSetting, retention: content.Retention{MaxAge: time.Hour}, code: 200},
+		{name: "feed clear", method: "DELETE", scope: feedSetting, code: 200},
+	}
+
+	type data struct {
+		Success       bool              `json:"success"`
+		Retention     content.Retention `json:"retention"`
+		UserRetention content.Retention `json:"userRetention"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+
+			r := httptest.NewRequest(tt.method, "/"+tt.url, nil)
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.noFeed {
+					break
+				}
+
+				feed := content.Feed{ID: 1, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, feed))
+
+				if tt.code == 400 {
+					break
+				}
+
+				if tt.scope == feedSetting {
+					feedRepo.EXPECT().SetRetention(feed, tt.retention).Return(tt.setErr)
+				} else {
+					feedRepo.EXPECT().SetUserRetention(feed, userMatcher{user}, tt.retention).Return(tt.setErr)
+				}
+			}
+
+			feedRetentionChange(feedRepo, tt.scope, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("feedRetentionChange() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("feedRetentionChange() body = %s, error = %+v", w.Body, err)
+				return
+			}
+
+			want := data{Success: true}
+			if tt.scope == feedSetting {
+				want.Retention = tt.retention
+			} else {
+				want.UserRetention = tt.retention
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("feedRetentionChange() = %v, want %v", got, want)
+			}
+		})
+	}
+}
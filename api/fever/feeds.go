@@ -36,6 +36,16 @@ func feeds(
 		return errors.WithMessage(err, "getting user feeds")
 	}
 
+	kindling, err := kindlingFeedIDs(user, service)
+	if err != nil {
+		return err
+	}
+
+	grouped := make(map[content.FeedID]bool, len(kindling))
+	for _, id := range kindling {
+		grouped[id] = true
+	}
+
 	now := time.Now().Unix()
 	for _, f := range feeds {
 		feed := feed{
@@ -43,6 +53,11 @@ func feeds(
 			SiteUrl: f.SiteLink, UpdateTime: now,
 		}
 
+		// Feeds that aren't in any group are sparks.
+		if !grouped[f.ID] {
+			feed.IsSpark = 1
+		}
+
 		feverFeeds = append(feverFeeds, feed)
 	}
 
@@ -21,6 +21,31 @@ type feedsGroup struct {
 	FeedIds string `json:"feed_ids"`
 }
 
+// The special groups of the mark action. Kindling holds all feeds that
+// belong to a group, while sparks are the feeds without one.
+const (
+	kindlingGroupID = 0
+	sparksGroupID   = -1
+)
+
+// User labels are exposed as groups with ids in a reserved range above
+// labelGroupOffset, so that they do not clash with the tag ids. Clients
+// expect group ids to be positive.
+const labelGroupOffset = 1 << 30
+
+func labelGroupID(id content.LabelID) int64 {
+	return labelGroupOffset + int64(id)
+}
+
+// groupLabelID converts a group id back to the label id.
+func groupLabelID(id int64) content.LabelID {
+	return content.LabelID(id - labelGroupOffset)
+}
+
+func isLabelGroup(id int64) bool {
+	return id > labelGroupOffset
+}
+
 func groups(
 	r *http.Request,
 	resp resp,
@@ -55,11 +80,62 @@ func groups(
 		fg[i] = feedsGroup{GroupId: int64(tag.ID), FeedIds: strings.Join(ids, ",")}
 	}
 
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return errors.WithMessage(err, "getting user labels")
+	}
+
+	labelRepo := service.LabelRepo()
+	for _, label := range labels {
+		g = append(g, group{Id: labelGroupID(label.ID), Title: label.Caption})
+
+		// A label group contains the feeds of its labeled articles.
+		feedIDs, err := labelRepo.FeedIDs(label)
+		if err != nil {
+			return errors.WithMessage(err, "getting label feed ids")
+		}
+
+		ids := make([]string, len(feedIDs))
+		for j := range feedIDs {
+			ids[j] = strconv.FormatInt(int64(feedIDs[j]), 10)
+		}
+
+		fg = append(fg, feedsGroup{GroupId: labelGroupID(label.ID), FeedIds: strings.Join(ids, ",")})
+	}
+
 	resp["groups"], resp["feeds_groups"] = g, fg
 
 	return nil
 }
 
+// kindlingFeedIDs returns the ids of the user's feeds that are in at least
+// one group.
+func kindlingFeedIDs(user content.User, service repo.Service) ([]content.FeedID, error) {
+	tagRepo := service.TagRepo()
+	tags, err := tagRepo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	seen := map[content.FeedID]bool{}
+	var ids []content.FeedID
+	for _, tag := range tags {
+		feedIDs, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range feedIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
 func init() {
 	actions["groups"] = groups
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/urandom/readeef/api/compat"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/processor"
 	"github.com/urandom/readeef/content/repo"
@@ -29,7 +30,7 @@ func Handler(
 	log log.Log,
 ) http.HandlerFunc {
 
-	processors = filterProcessors(processors)
+	processors = compat.FilterProcessors(processors)
 
 	registerItemActions(processors)
 	registerLinkActions(processors)
@@ -90,17 +91,3 @@ func writeJSON(w http.ResponseWriter, data interface{}) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
-
-func filterProcessors(input []processor.Article) []processor.Article {
-	processors := make([]processor.Article, 0, len(input))
-
-	for i := range input {
-		if _, ok := input[i].(processor.ProxyHTTP); ok {
-			continue
-		}
-
-		processors = append(processors, input[i])
-	}
-
-	return processors
-}
@@ -105,7 +105,7 @@ func items(
 		for _, a := range articles {
 			item := item{
 				Id: a.ID, FeedId: a.FeedID, Title: a.Title, Html: a.Description,
-				Url: a.Link, CreatedOnTime: a.Date.Unix(),
+				Url: a.Link, Author: a.Author, CreatedOnTime: a.Date.Unix(),
 			}
 			if a.Read {
 				item.IsRead = 1
@@ -1,12 +1,16 @@
 package fever
 
 import (
-	"fmt"
+	"hash/fnv"
 	"math"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/processor"
@@ -15,7 +19,7 @@ import (
 )
 
 type link struct {
-	Id          content.ArticleID `json:"id"`
+	Id          int64             `json:"id"`
 	FeedId      content.FeedID    `json:"feed_id"`
 	ItemId      content.ArticleID `json:"item_id"`
 	Temperature float64           `json:"temperature"`
@@ -27,12 +31,37 @@ type link struct {
 	ItemIds     string            `json:"item_ids"`
 }
 
+// hotLink collects the items that reference a single url.
+type hotLink struct {
+	url   string
+	title string
+
+	// The item whose own link is the url, if any.
+	item *content.Article
+
+	items  []content.Article
+	feeds  map[content.FeedID]struct{}
+	newest time.Time
+}
+
+const (
+	linksPerPage    = 50
+	linksMaxPages   = 3
+	linksMaxSources = 1000
+
+	// Every additional feed that links to a url heats it up by this many
+	// degrees.
+	linkSourceDegrees = 10
+)
+
 func registerLinkActions(processors []processor.Article) {
 	actions["links"] = func(r *http.Request, resp resp, user content.User, service repo.Service, log log.Log) error {
 		return links(r, resp, user, service, processors, log)
 	}
 }
 
+// links returns the hot links, which are the urls that are referenced by
+// items from more than one feed, as well as the popular items themselves.
 func links(
 	r *http.Request,
 	resp resp,
@@ -44,37 +73,30 @@ func links(
 	log.Infoln("Fetching fever links")
 	offset, _ := strconv.ParseInt(r.FormValue("offset"), 10, 64)
 
-	rng, e := strconv.ParseInt(r.FormValue("range"), 10, 64)
-	if e != nil {
+	rng, err := strconv.ParseInt(r.FormValue("range"), 10, 64)
+	if err != nil || rng <= 0 {
 		rng = 7
 	}
 
 	page, err := strconv.ParseInt(r.FormValue("page"), 10, 64)
-	if e != nil {
-		return errors.Wrapf(err, "parsing page value %s", r.FormValue("page"))
+	if err != nil || page < 1 {
+		page = 1
 	}
 
-	if page > 3 {
+	if page > linksMaxPages {
 		resp["links"] = []link{}
 		return nil
 	}
 
-	var from, to time.Time
-
-	if offset == 0 {
-		from = time.Now().AddDate(0, 0, int(-1*rng))
-		to = time.Now()
-	} else {
-		from = time.Now().AddDate(0, 0, int(-1*rng-offset))
-		to = time.Now().AddDate(0, 0, int(-1*offset))
-	}
+	to := time.Now().AddDate(0, 0, int(-1*offset))
+	from := to.AddDate(0, 0, int(-1*rng))
 
 	articles, err := service.ArticleRepo().ForUser(
 		user,
 		content.TimeRange(from, to),
-		content.Paging(50, 50*int(page-1)),
+		content.Paging(linksMaxSources, 0),
 		content.IncludeScores,
-		content.Sorting(content.DefaultSort, content.DescendingOrder),
+		content.Sorting(content.SortByDate, content.DescendingOrder),
 		content.Filters(content.GetUserFilters(user)),
 	)
 
@@ -84,26 +106,185 @@ func links(
 
 	articles = processor.Articles(processors).Process(articles)
 
-	links := make([]link, len(articles))
-	for i, a := range articles {
-		link := link{
-			Id: a.ID, FeedId: a.FeedID, ItemId: a.ID, IsItem: 1,
-			IsLocal: 1, Title: a.Title, Url: a.Link, ItemIds: fmt.Sprintf("%d", a.ID),
+	hot := hotLinks(articles)
+
+	links := make([]link, 0, len(hot))
+	for _, h := range hot {
+		if l, ok := h.link(to, time.Duration(rng)*24*time.Hour); ok {
+			links = append(links, l)
+		}
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Temperature > links[j].Temperature
+	})
+
+	start := int(page-1) * linksPerPage
+	if start > len(links) {
+		start = len(links)
+	}
+
+	end := start + linksPerPage
+	if end > len(links) {
+		end = len(links)
+	}
+
+	resp["links"] = links[start:end]
+
+	return nil
+}
+
+// hotLinks groups the articles by the urls they link to, both through
+// their own link, and through the anchors in their content.
+func hotLinks(articles []content.Article) []*hotLink {
+	byURL := map[string]*hotLink{}
+	var ordered []*hotLink
+
+	add := func(u, title string, a content.Article) *hotLink {
+		h, ok := byURL[u]
+		if !ok {
+			h = &hotLink{url: u, title: title, feeds: map[content.FeedID]struct{}{}}
+			byURL[u] = h
+			ordered = append(ordered, h)
 		}
 
-		if a.Score == 0 {
-			link.Temperature = 0
-		} else {
-			link.Temperature = math.Log10(float64(a.Score)) / math.Log10(1.1)
+		if !containsArticle(h.items, a.ID) {
+			h.items = append(h.items, a)
 		}
+		h.feeds[a.FeedID] = struct{}{}
 
-		if a.Favorite {
-			link.IsSaved = 1
+		if a.Date.After(h.newest) {
+			h.newest = a.Date
 		}
 
-		links[i] = link
+		return h
 	}
-	resp["links"] = links
 
-	return nil
+	for i := range articles {
+		a := articles[i]
+
+		if u := normalizeLink(a.Link); u != "" {
+			h := add(u, a.Title, a)
+			h.item = &articles[i]
+			h.title = a.Title
+		}
+
+		for u, title := range contentLinks(a) {
+			add(u, title, a)
+		}
+	}
+
+	return ordered
+}
+
+// link converts the hot link to a fever link. Links that are neither
+// shared, nor popular are not returned.
+func (h hotLink) link(now time.Time, period time.Duration) (link, bool) {
+	var temperature float64
+
+	if sources := len(h.feeds); sources > 1 {
+		temperature += float64(linkSourceDegrees * (sources - 1))
+	}
+
+	if h.item != nil && h.item.Score > 1 {
+		temperature += math.Log10(float64(h.item.Score)) / math.Log10(1.1)
+	}
+
+	if temperature == 0 {
+		return link{}, false
+	}
+
+	// Links cool down as their newest reference ages.
+	if age := now.Sub(h.newest); age > 0 && period > 0 {
+		temperature *= 1 - 0.5*math.Min(1, float64(age)/float64(period))
+	}
+
+	ids := make([]string, len(h.items))
+	for i := range h.items {
+		ids[i] = strconv.FormatInt(int64(h.items[i].ID), 10)
+	}
+
+	l := link{
+		Temperature: math.Round(temperature*10) / 10,
+		Title:       h.title,
+		Url:         h.url,
+		ItemIds:     strings.Join(ids, ","),
+	}
+
+	if h.item != nil {
+		l.Id = int64(h.item.ID)
+		l.FeedId = h.item.FeedID
+		l.ItemId = h.item.ID
+		l.IsItem = 1
+		l.IsLocal = 1
+
+		if h.item.Favorite {
+			l.IsSaved = 1
+		}
+	} else {
+		// External links get a hashed id, well above the item ids, but
+		// still within the integer precision of javascript clients.
+		hash := fnv.New64a()
+		hash.Write([]byte(h.url))
+
+		l.Id = int64(hash.Sum64()>>12) | 1<<51
+		l.FeedId = h.items[0].FeedID
+		l.ItemId = h.items[0].ID
+	}
+
+	if l.Title == "" {
+		l.Title = h.url
+	}
+
+	return l, true
+}
+
+// contentLinks returns the absolute urls of the anchors in the article
+// content, along with their text.
+func contentLinks(a content.Article) map[string]string {
+	links := map[string]string{}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(a.Description))
+	if err != nil {
+		return links
+	}
+
+	own := normalizeLink(a.Link)
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+
+		u := normalizeLink(href)
+		if u == "" || u == own {
+			return
+		}
+
+		if _, ok := links[u]; !ok {
+			links[u] = strings.TrimSpace(s.Text())
+		}
+	})
+
+	return links
+}
+
+// normalizeLink returns the absolute http url without its fragment, or an
+// empty string for other urls.
+func normalizeLink(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	u.Fragment = ""
+
+	return u.String()
+}
+
+func containsArticle(articles []content.Article, id content.ArticleID) bool {
+	for _, a := range articles {
+		if a.ID == id {
+			return true
+		}
+	}
+
+	return false
 }
@@ -20,13 +20,17 @@ func unreadRecent(
 ) error {
 	log.Infoln("Marking recently read fever items as unread")
 
-	t := time.Now().Add(-24 * time.Hour)
-	err := service.ArticleRepo().Read(false, user,
-		content.TimeRange(t, time.Now()),
-		content.Filters(content.GetUserFilters(user)),
-	)
-
+	articleRepo := service.ArticleRepo()
+	ids, err := articleRepo.RecentlyReadIDs(user, time.Now().Add(-24*time.Hour))
 	if err != nil {
+		return errors.WithMessage(err, "getting recently read article ids")
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := articleRepo.Read(false, user, content.IDs(ids)); err != nil {
 		return errors.WithMessage(err, "marking recently read articles as unread")
 	}
 
@@ -59,15 +63,37 @@ func markItem(
 			return errors.Wrapf(err, "parsing before value %s", r.FormValue("before"))
 		}
 
-		opts = append(opts, content.TimeRange(time.Time{}, time.Unix(timestamp, 0)))
+		// The items that were published in the same second as the before
+		// timestamp were also seen by the client.
+		opts = append(opts, content.TimeRange(time.Time{}, time.Unix(timestamp+1, 0)))
 
-		if id == 0 {
+		if val == "feed" {
+			opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(id)}))
 			break
 		}
 
-		if val == "feed" {
-			opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(id)}))
-		} else {
+		switch {
+		case id == sparksGroupID:
+			opts = append(opts, content.UntaggedOnly)
+		case id == kindlingGroupID:
+			ids, err := kindlingFeedIDs(user, service)
+			if err != nil {
+				return err
+			}
+
+			if len(ids) == 0 {
+				return nil
+			}
+
+			opts = append(opts, content.FeedIDs(ids))
+		case isLabelGroup(id):
+			label, err := service.LabelRepo().Get(groupLabelID(id), user)
+			if err != nil {
+				return errors.WithMessage(err, "getting user label")
+			}
+
+			opts = append(opts, content.LabelIDs([]content.LabelID{label.ID}))
+		default:
 			tagRepo := service.TagRepo()
 			tag, err := tagRepo.Get(content.TagID(id), user)
 			if err != nil {
@@ -79,6 +105,10 @@ func markItem(
 				return errors.WithMessage(err, "getting tag feed ids")
 			}
 
+			if len(ids) == 0 {
+				return nil
+			}
+
 			opts = append(opts, content.FeedIDs(ids))
 		}
 	default:
@@ -90,10 +120,12 @@ func markItem(
 		return service.ArticleRepo().Read(false, user, opts...)
 	case "read":
 		return service.ArticleRepo().Read(true, user, opts...)
-	case "saved":
-		return service.ArticleRepo().Favor(true, user, opts...)
-	case "unsaved":
-		return service.ArticleRepo().Favor(false, user, opts...)
+	case "saved", "unsaved":
+		if val != "item" {
+			return errors.Errorf("only items can be %s", action)
+		}
+
+		return service.ArticleRepo().Favor(action == "saved", user, opts...)
 	default:
 		return errors.Errorf("unknown action %s", action)
 	}
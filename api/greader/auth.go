@@ -0,0 +1,98 @@
+package greader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const authPrefix = "GoogleLogin auth="
+
+// authToken generates the token of the user. It is bound to the user's
+// api hash, and is thus invalidated when the api key is rotated, or by a
+// password change while the key is still derived from the password.
+func authToken(user content.User, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(user.MD5API)
+
+	return string(user.Login) + "/" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func userFromToken(repo repo.User, token string, secret []byte) (content.User, error) {
+	parts := strings.SplitN(token, "/", 2)
+	if len(parts) != 2 {
+		return content.User{}, errors.New("malformed token")
+	}
+
+	user, err := repo.Get(content.Login(parts[0]))
+	if err != nil {
+		return content.User{}, errors.WithMessage(err, "getting token user")
+	}
+
+	if !user.Active {
+		return content.User{}, errors.Errorf("user %s is not active", user.Login)
+	}
+
+	if !hmac.Equal([]byte(token), []byte(authToken(user, secret))) {
+		return content.User{}, errors.New("token mismatch")
+	}
+
+	return user, nil
+}
+
+func clientLogin(repo repo.User, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := repo.Get(content.Login(r.Form.Get("Email")))
+		if err == nil {
+			var ok bool
+			if ok, err = user.Authenticate(r.Form.Get("Passwd"), secret); err == nil && (!ok || !user.Active) {
+				err = errors.New("invalid credentials")
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if err != nil {
+			log.Infof("Google reader login for %s: %+v", r.Form.Get("Email"), err)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Error=BadAuthentication\n"))
+			return
+		}
+
+		token := authToken(user, secret)
+		fmt.Fprintf(w, "SID=%s\nLSID=null\nAuth=%s\n", token, token)
+	}
+}
+
+// token returns the token used to validate write requests. The requests
+// are already authenticated by the auth token, so it is only provided for
+// compatibility. Some clients expect it to be 57 characters long.
+func token(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		token := authToken(user, secret)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(token[len(token)-57:] + "\n"))
+	}
+}
+
+func userInfo(w http.ResponseWriter, r *http.Request) {
+	user := compat.UserFromRequest(r)
+
+	compat.WriteJSON(w, map[string]string{
+		"userId":        string(user.Login),
+		"userName":      string(user.Login),
+		"userProfileId": string(user.Login),
+		"userEmail":     user.Email,
+	})
+}
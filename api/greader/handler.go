@@ -0,0 +1,86 @@
+package greader
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// Handler emulates the Google Reader API, as served by FreshRSS and
+// Inoreader. Clients log in through ClientLogin and send the returned token
+// with every subsequent request.
+func Handler(
+	service repo.Service,
+	feedManager *readeef.FeedManager,
+	processors []processor.Article,
+	secret []byte,
+	log log.Log,
+) http.Handler {
+	processors = compat.FilterProcessors(processors)
+
+	r := chi.NewRouter()
+	r.Use(compat.ParseForm)
+
+	r.Get("/accounts/ClientLogin", clientLogin(service.UserRepo(), secret, log))
+	r.Post("/accounts/ClientLogin", clientLogin(service.UserRepo(), secret, log))
+
+	r.Route("/reader/api/0", func(r chi.Router) {
+		r.Use(requireUser(service.UserRepo(), secret, log))
+
+		r.Get("/token", token(secret))
+		r.Get("/user-info", userInfo)
+
+		r.Get("/subscription/list", subscriptionList(service, log))
+		r.Post("/subscription/edit", subscriptionEdit(service, feedManager, log))
+		r.Post("/subscription/quickadd", subscriptionQuickAdd(service, feedManager, log))
+
+		r.Get("/tag/list", tagList(service, log))
+		r.Get("/unread-count", unreadCount(service, log))
+
+		r.Get("/stream/contents/*", streamContents(service, processors, log))
+		r.Get("/stream/contents", streamContents(service, processors, log))
+		r.Get("/stream/items/ids", streamItemIDs(service, log))
+		r.Post("/stream/items/contents", streamItemContents(service, processors, log))
+		r.Get("/stream/items/contents", streamItemContents(service, processors, log))
+
+		r.Post("/edit-tag", editTag(service, log))
+		r.Post("/mark-all-as-read", markAllAsRead(service, log))
+	})
+
+	return r
+}
+
+func requireUser(repo repo.User, secret []byte, log log.Log) func(http.Handler) http.Handler {
+	return compat.RequireUser(
+		func(r *http.Request) (content.User, error) {
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, authPrefix) {
+				return content.User{}, errors.New("missing auth token")
+			}
+
+			return userFromToken(repo, header[len(authPrefix):], secret)
+		},
+		func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Infof("Invalid google reader token: %+v", err)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		},
+	)
+}
+
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("OK"))
+}
+
+func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
+	log.Printf(format, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
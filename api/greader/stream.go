@@ -0,0 +1,463 @@
+package greader
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const (
+	itemIDPrefix = "tag:google.com,2005:reader/item/"
+	feedPrefix   = "feed/"
+
+	readingList = "user/-/state/com.google/reading-list"
+	readState   = "user/-/state/com.google/read"
+	starred     = "user/-/state/com.google/starred"
+
+	defaultCount = 20
+	maxCount     = 1000
+	maxIDCount   = 10000
+)
+
+var (
+	labelPattern = regexp.MustCompile(`^user/[^/]+/label/(.+)$`)
+	statePattern = regexp.MustCompile(`^user/[^/]+/state/com\.google/(.+)$`)
+)
+
+type item struct {
+	ID            string     `json:"id"`
+	CrawlTimeMsec string     `json:"crawlTimeMsec"`
+	TimestampUsec string     `json:"timestampUsec"`
+	Published     int64      `json:"published"`
+	Updated       int64      `json:"updated"`
+	Title         string     `json:"title"`
+	Author        string     `json:"author,omitempty"`
+	Canonical     []itemLink `json:"canonical"`
+	Alternate     []itemLink `json:"alternate"`
+	Categories    []string   `json:"categories"`
+	Origin        origin     `json:"origin"`
+	Summary       summary    `json:"summary"`
+}
+
+type itemLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type origin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+type summary struct {
+	Content string `json:"content"`
+}
+
+type itemRef struct {
+	ID string `json:"id"`
+}
+
+// page holds the requested stream paging. Clients use the continuation,
+// which is the offset of the next page, to fetch more items.
+type page struct {
+	count  int
+	offset int
+}
+
+func (p page) continuation(length int) string {
+	if length == 0 || length < p.count {
+		return ""
+	}
+
+	return strconv.Itoa(p.offset + p.count)
+}
+
+// streamOptions converts a stream id to article query options. A false
+// result means that the stream is known to be empty.
+func streamOptions(id string, user content.User, service repo.Service) ([]content.QueryOpt, bool, error) {
+	if id == "" {
+		id = readingList
+	}
+
+	if m := statePattern.FindStringSubmatch(id); m != nil {
+		switch m[1] {
+		case "reading-list":
+			return nil, true, nil
+		case "starred":
+			return []content.QueryOpt{content.FavoriteOnly}, true, nil
+		case "read":
+			return []content.QueryOpt{content.ReadOnly}, true, nil
+		default:
+			return nil, false, nil
+		}
+	}
+
+	if m := labelPattern.FindStringSubmatch(id); m != nil {
+		ids, err := labelFeedIDs(m[1], user, service)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return []content.QueryOpt{content.FeedIDs(ids)}, len(ids) > 0, nil
+	}
+
+	if strings.HasPrefix(id, feedPrefix) {
+		feed, err := streamFeed(id, user, service)
+		if err != nil {
+			if content.IsNoContent(err) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+
+		return []content.QueryOpt{content.FeedIDs([]content.FeedID{feed.ID})}, true, nil
+	}
+
+	return nil, false, errors.Errorf("unknown stream %s", id)
+}
+
+// streamFeed returns the user feed of a feed stream. The stream is
+// either the feed id, or its link.
+func streamFeed(id string, user content.User, service repo.Service) (content.Feed, error) {
+	id = strings.TrimPrefix(id, feedPrefix)
+
+	if feedID, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return service.FeedRepo().Get(content.FeedID(feedID), user)
+	}
+
+	feed, err := service.FeedRepo().FindByLink(id)
+	if err != nil {
+		return content.Feed{}, err
+	}
+
+	return service.FeedRepo().Get(feed.ID, user)
+}
+
+func labelFeedIDs(label string, user content.User, service repo.Service) ([]content.FeedID, error) {
+	tags, err := service.TagRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	for _, tag := range tags {
+		if string(tag.Value) == label {
+			return service.TagRepo().FeedIDs(tag, user)
+		}
+	}
+
+	return nil, nil
+}
+
+// queryOptions converts the common stream request parameters to article
+// query options.
+func queryOptions(r *http.Request, stream string, limit int, user content.User, service repo.Service) ([]content.QueryOpt, page, bool, error) {
+	opts, ok, err := streamOptions(stream, user, service)
+	if err != nil || !ok {
+		return nil, page{}, ok, err
+	}
+
+	opts = append(opts, content.Filters(content.GetUserFilters(user)))
+
+	if m := statePattern.FindStringSubmatch(r.Form.Get("xt")); m != nil && m[1] == "read" {
+		opts = append(opts, content.UnreadOnly)
+	}
+
+	if m := statePattern.FindStringSubmatch(r.Form.Get("it")); m != nil {
+		switch m[1] {
+		case "starred":
+			opts = append(opts, content.FavoriteOnly)
+		case "read":
+			opts = append(opts, content.ReadOnly)
+		}
+	}
+
+	var after, before time.Time
+	if ot, err := strconv.ParseInt(r.Form.Get("ot"), 10, 64); err == nil && ot > 0 {
+		after = time.Unix(ot, 0)
+	}
+	if nt, err := strconv.ParseInt(r.Form.Get("nt"), 10, 64); err == nil && nt > 0 {
+		before = time.Unix(nt, 0)
+	}
+	if !after.IsZero() || !before.IsZero() {
+		opts = append(opts, content.TimeRange(after, before))
+	}
+
+	p := page{count: defaultCount}
+	if n, err := strconv.Atoi(r.Form.Get("n")); err == nil && n > 0 {
+		p.count = n
+	}
+	if p.count > limit {
+		p.count = limit
+	}
+
+	if c, err := strconv.Atoi(r.Form.Get("c")); err == nil && c > 0 {
+		p.offset = c
+	}
+
+	order := content.DescendingOrder
+	if r.Form.Get("r") == "o" {
+		order = content.AscendingOrder
+	}
+
+	opts = append(opts,
+		content.Paging(p.count, p.offset),
+		content.Sorting(content.SortByDate, order),
+	)
+
+	return opts, p, true, nil
+}
+
+func streamContents(service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		stream := chi.URLParam(r, "*")
+		if stream == "" {
+			stream = r.Form.Get("s")
+		}
+
+		opts, p, ok, err := queryOptions(r, stream, maxCount, user, service)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var articles []content.Article
+		if ok {
+			if articles, err = service.ArticleRepo().ForUser(user, opts...); err != nil {
+				fatal(w, log, "Error getting stream articles: %+v", err)
+				return
+			}
+		}
+
+		items, err := convertArticles(articles, user, service, processors)
+		if err != nil {
+			fatal(w, log, "Error converting stream articles: %+v", err)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"id":      stream,
+			"updated": time.Now().Unix(),
+			"items":   items,
+		}
+
+		if c := p.continuation(len(items)); c != "" {
+			resp["continuation"] = c
+		}
+
+		compat.WriteJSON(w, resp)
+	}
+}
+
+func streamItemIDs(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		opts, p, ok, err := queryOptions(r, r.Form.Get("s"), maxIDCount, user, service)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var ids []content.ArticleID
+		if ok {
+			if ids, err = service.ArticleRepo().IDs(user, opts...); err != nil {
+				fatal(w, log, "Error getting stream article ids: %+v", err)
+				return
+			}
+		}
+
+		refs := make([]itemRef, len(ids))
+		for i := range ids {
+			refs[i] = itemRef{ID: strconv.FormatInt(int64(ids[i]), 10)}
+		}
+
+		resp := map[string]interface{}{"itemRefs": refs}
+
+		if c := p.continuation(len(ids)); c != "" {
+			resp["continuation"] = c
+		}
+
+		compat.WriteJSON(w, resp)
+	}
+}
+
+func streamItemContents(service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		ids, err := parseItemIDs(r.Form["i"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var articles []content.Article
+		if len(ids) > 0 {
+			order := content.DescendingOrder
+			if r.Form.Get("r") == "o" {
+				order = content.AscendingOrder
+			}
+
+			articles, err = service.ArticleRepo().ForUser(user,
+				content.IDs(ids),
+				content.Paging(len(ids), 0),
+				content.Sorting(content.SortByDate, order),
+			)
+			if err != nil {
+				fatal(w, log, "Error getting articles: %+v", err)
+				return
+			}
+		}
+
+		items, err := convertArticles(articles, user, service, processors)
+		if err != nil {
+			fatal(w, log, "Error converting articles: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{
+			"id":      readingList,
+			"updated": time.Now().Unix(),
+			"items":   items,
+		})
+	}
+}
+
+// parseItemIDs accepts both the long hexadecimal form of the item ids, and
+// the short decimal one.
+func parseItemIDs(values []string) ([]content.ArticleID, error) {
+	ids := make([]content.ArticleID, 0, len(values))
+
+	for _, v := range values {
+		var id int64
+		var err error
+
+		if strings.HasPrefix(v, itemIDPrefix) {
+			var u uint64
+			u, err = strconv.ParseUint(v[len(itemIDPrefix):], 16, 64)
+			id = int64(u)
+		} else {
+			id, err = strconv.ParseInt(v, 10, 64)
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing item id %s", v)
+		}
+
+		ids = append(ids, content.ArticleID(id))
+	}
+
+	return ids, nil
+}
+
+func convertArticles(
+	articles []content.Article,
+	user content.User,
+	service repo.Service,
+	processors []processor.Article,
+) ([]item, error) {
+	items := make([]item, 0, len(articles))
+	if len(articles) == 0 {
+		return items, nil
+	}
+
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user feeds")
+	}
+
+	feedMap := make(map[content.FeedID]content.Feed, len(feeds))
+	for _, f := range feeds {
+		feedMap[f.ID] = f
+	}
+
+	labels, err := feedLabels(user, service)
+	if err != nil {
+		return nil, err
+	}
+
+	articles = processor.Articles(processors).Process(articles)
+
+	for _, a := range articles {
+		feed := feedMap[a.FeedID]
+
+		categories := []string{readingList}
+		if a.Read {
+			categories = append(categories, readState)
+		}
+		if a.Favorite {
+			categories = append(categories, starred)
+		}
+		for _, l := range labels[a.FeedID] {
+			categories = append(categories, labelID(l))
+		}
+
+		usec := strconv.FormatInt(a.Date.UnixNano()/int64(time.Microsecond), 10)
+		items = append(items, item{
+			ID:            fmt.Sprintf("%s%016x", itemIDPrefix, uint64(a.ID)),
+			CrawlTimeMsec: strconv.FormatInt(a.Date.UnixNano()/int64(time.Millisecond), 10),
+			TimestampUsec: usec,
+			Published:     a.Date.Unix(),
+			Updated:       a.Date.Unix(),
+			Title:         a.Title,
+			Author:        a.Author,
+			Canonical:     []itemLink{{Href: a.Link}},
+			Alternate:     []itemLink{{Href: a.Link, Type: "text/html"}},
+			Categories:    categories,
+			Origin: origin{
+				StreamID: feedID(a.FeedID),
+				Title:    feed.Title,
+				HTMLURL:  feed.SiteLink,
+			},
+			Summary: summary{Content: a.Description},
+		})
+	}
+
+	return items, nil
+}
+
+// feedLabels returns the tags of every user feed.
+func feedLabels(user content.User, service repo.Service) (map[content.FeedID][]string, error) {
+	tagRepo := service.TagRepo()
+
+	tags, err := tagRepo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	labels := map[content.FeedID][]string{}
+	for _, tag := range tags {
+		ids, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range ids {
+			labels[id] = append(labels[id], string(tag.Value))
+		}
+	}
+
+	return labels, nil
+}
+
+func feedID(id content.FeedID) string {
+	return feedPrefix + strconv.FormatInt(int64(id), 10)
+}
+
+func labelID(label string) string {
+	return "user/-/label/" + label
+}
@@ -0,0 +1,278 @@
+package greader
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+	// FirstItemMsec is required by some clients.
+	FirstItemMsec string `json:"firstitemmsec"`
+}
+
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type tag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+type unread struct {
+	ID                      string `json:"id"`
+	Count                   int64  `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+func subscriptionList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		labels, err := feedLabels(user, service)
+		if err != nil {
+			fatal(w, log, "Error getting feed labels: %+v", err)
+			return
+		}
+
+		subscriptions := make([]subscription, 0, len(feeds))
+		for _, f := range feeds {
+			categories := []category{}
+			for _, l := range labels[f.ID] {
+				categories = append(categories, category{ID: labelID(l), Label: l})
+			}
+
+			subscriptions = append(subscriptions, subscription{
+				ID:            feedID(f.ID),
+				Title:         f.Title,
+				Categories:    categories,
+				URL:           f.Link,
+				HTMLURL:       f.SiteLink,
+				FirstItemMsec: "0",
+			})
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"subscriptions": subscriptions})
+	}
+}
+
+// subscriptionEdit subscribes to, or unsubscribes from a feed, or changes
+// its labels. Readeef has no per-user feed titles, so the title is
+// ignored.
+func subscriptionEdit(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		feedRepo := service.FeedRepo()
+
+		for _, s := range r.Form["s"] {
+			var feed content.Feed
+			var err error
+
+			switch r.Form.Get("ac") {
+			case "subscribe":
+				if feed, err = subscribe(strings.TrimPrefix(s, feedPrefix), user, feedRepo, feedManager); err != nil {
+					break
+				}
+				err = editLabels(feed, user, r.Form["a"], nil, service)
+			case "unsubscribe":
+				if feed, err = streamFeed(s, user, service); err != nil {
+					break
+				}
+
+				if err = feedRepo.DetachFrom(feed, user); err == nil {
+					feedManager.RemoveFeed(feed)
+				}
+			case "edit":
+				if feed, err = streamFeed(s, user, service); err != nil {
+					break
+				}
+				err = editLabels(feed, user, r.Form["a"], r.Form["r"], service)
+			default:
+				http.Error(w, "Unknown action", http.StatusBadRequest)
+				return
+			}
+
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Not found", http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error editing subscription: %+v", err)
+				}
+				return
+			}
+		}
+
+		writeOK(w)
+	}
+}
+
+func subscriptionQuickAdd(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		link := strings.TrimPrefix(r.Form.Get("quickadd"), feedPrefix)
+
+		feed, err := subscribe(link, user, service.FeedRepo(), feedManager)
+		if err != nil {
+			log.Infof("Error adding feed %s: %+v", link, err)
+			compat.WriteJSON(w, map[string]interface{}{"numResults": 0, "query": link, "error": err.Error()})
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{
+			"numResults": 1,
+			"query":      link,
+			"streamId":   feedID(feed.ID),
+			"streamName": feed.Title,
+		})
+	}
+}
+
+func subscribe(link string, user content.User, repo repo.Feed, feedManager *readeef.FeedManager) (content.Feed, error) {
+	feed, err := feedManager.AddFeedByLink(link, content.FeedAuth{})
+	if err != nil {
+		return content.Feed{}, errors.WithMessage(err, "adding feed "+link)
+	}
+
+	if err = repo.AttachTo(feed, user); err != nil {
+		return content.Feed{}, errors.WithMessage(err, "attaching feed to user")
+	}
+
+	return feed, nil
+}
+
+// editLabels adds and removes the feed tags, given as label streams.
+func editLabels(feed content.Feed, user content.User, add, remove []string, service repo.Service) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	tags, err := service.TagRepo().ForFeed(feed, user)
+	if err != nil {
+		return errors.WithMessage(err, "getting feed tags")
+	}
+
+	values := map[string]bool{}
+	for _, t := range tags {
+		values[string(t.Value)] = true
+	}
+
+	for _, l := range add {
+		if m := labelPattern.FindStringSubmatch(l); m != nil {
+			values[m[1]] = true
+		}
+	}
+
+	for _, l := range remove {
+		if m := labelPattern.FindStringSubmatch(l); m != nil {
+			delete(values, m[1])
+		}
+	}
+
+	newTags := make([]*content.Tag, 0, len(values))
+	for v := range values {
+		newTags = append(newTags, &content.Tag{Value: content.TagValue(v)})
+	}
+
+	return service.FeedRepo().SetUserTags(feed, user, newTags)
+}
+
+func tagList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		userTags, err := service.TagRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		tags := []tag{{ID: starred}}
+		for _, t := range userTags {
+			tags = append(tags, tag{ID: labelID(string(t.Value)), Type: "folder"})
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"tags": tags})
+	}
+}
+
+func unreadCount(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		articleRepo := service.ArticleRepo()
+		filters := content.Filters(content.GetUserFilters(user))
+		// The newest item of each stream isn't tracked, so the current time
+		// makes the clients always check for new items.
+		now := strconv.FormatInt(time.Now().UnixNano()/int64(time.Microsecond), 10)
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		total, err := articleRepo.Count(user, content.UnreadOnly, filters)
+		if err != nil {
+			fatal(w, log, "Error getting unread count: %+v", err)
+			return
+		}
+
+		counts := []unread{{ID: readingList, Count: total, NewestItemTimestampUsec: now}}
+		feedCounts := map[content.FeedID]int64{}
+		for _, f := range feeds {
+			count, err := articleRepo.Count(user, content.UnreadOnly, filters, content.FeedIDs([]content.FeedID{f.ID}))
+			if err != nil {
+				fatal(w, log, "Error getting feed unread count: %+v", err)
+				return
+			}
+
+			feedCounts[f.ID] = count
+			if count > 0 {
+				counts = append(counts, unread{ID: feedID(f.ID), Count: count, NewestItemTimestampUsec: now})
+			}
+		}
+
+		labels, err := feedLabels(user, service)
+		if err != nil {
+			fatal(w, log, "Error getting feed labels: %+v", err)
+			return
+		}
+
+		labelCounts := map[string]int64{}
+		for id, ls := range labels {
+			for _, l := range ls {
+				labelCounts[l] += feedCounts[id]
+			}
+		}
+
+		for l, count := range labelCounts {
+			if count > 0 {
+				counts = append(counts, unread{ID: labelID(l), Count: count, NewestItemTimestampUsec: now})
+			}
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"max": total, "unreadcounts": counts})
+	}
+}
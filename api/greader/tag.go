@@ -0,0 +1,167 @@
+package greader
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// editTag changes the read and starred state of the items, and assigns or
+// removes the user labels of the label tags. Labels are created when they
+// are first added to an item. Any other tag is rejected.
+func editTag(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		ids, err := parseItemIDs(r.Form["i"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var changes []tagChange
+		for _, change := range []struct {
+			tags  []string
+			value bool
+		}{{r.Form["a"], true}, {r.Form["r"], false}} {
+			for _, t := range change.tags {
+				c, err := parseTagChange(t, change.value)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				changes = append(changes, c)
+			}
+		}
+
+		if len(ids) == 0 {
+			writeOK(w)
+			return
+		}
+
+		for _, c := range changes {
+			if err := c.apply(ids, user, service); err != nil {
+				fatal(w, log, "Error editing item tags: %+v", err)
+				return
+			}
+		}
+
+		writeOK(w)
+	}
+}
+
+// tagChange adds or removes an item tag, which is either one of the
+// supported states, or a user label.
+type tagChange struct {
+	state string
+	label string
+	value bool
+}
+
+func parseTagChange(tag string, value bool) (tagChange, error) {
+	if m := statePattern.FindStringSubmatch(tag); m != nil {
+		switch m[1] {
+		case "read", "kept-unread", "starred":
+			return tagChange{state: m[1], value: value}, nil
+		}
+	} else if m := labelPattern.FindStringSubmatch(tag); m != nil {
+		return tagChange{label: m[1], value: value}, nil
+	}
+
+	return tagChange{}, errors.Errorf("unsupported tag %s", tag)
+}
+
+func (c tagChange) apply(ids []content.ArticleID, user content.User, service repo.Service) error {
+	if c.label != "" {
+		return setLabel(c.label, c.value, ids, user, service)
+	}
+
+	repo := service.ArticleRepo()
+	switch c.state {
+	case "read":
+		return errors.WithMessage(repo.Read(c.value, user, content.IDs(ids)), "changing read state")
+	case "kept-unread":
+		return errors.WithMessage(repo.Read(!c.value, user, content.IDs(ids)), "changing read state")
+	case "starred":
+		return errors.WithMessage(repo.Favor(c.value, user, content.IDs(ids)), "changing starred state")
+	}
+
+	return nil
+}
+
+// setLabel assigns the user label with the given caption to the user's
+// items, or removes it from them.
+func setLabel(caption string, value bool, ids []content.ArticleID, user content.User, service repo.Service) error {
+	labelRepo := service.LabelRepo()
+
+	labels, err := labelRepo.ForUser(user)
+	if err != nil {
+		return errors.WithMessage(err, "getting user labels")
+	}
+
+	var label content.Label
+	for _, l := range labels {
+		if l.Caption == caption {
+			label = l
+			break
+		}
+	}
+
+	if label.ID == 0 {
+		if !value {
+			return nil
+		}
+
+		if label, err = labelRepo.Create(content.Label{User: user.Login, Caption: caption}); err != nil {
+			return errors.WithMessage(err, "creating user label")
+		}
+	}
+
+	if ids, err = service.ArticleRepo().IDs(user, content.IDs(ids)); err != nil {
+		return errors.WithMessage(err, "getting user article ids")
+	}
+
+	if value {
+		return errors.WithMessage(labelRepo.Assign(label, ids), "assigning user label")
+	}
+
+	return errors.WithMessage(labelRepo.Unassign(label, ids), "removing user label")
+}
+
+func markAllAsRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		opts, ok, err := streamOptions(r.Form.Get("s"), user, service)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !ok {
+			writeOK(w)
+			return
+		}
+
+		opts = append(opts, content.Filters(content.GetUserFilters(user)))
+
+		// The timestamp is in microseconds.
+		if ts, err := strconv.ParseInt(r.Form.Get("ts"), 10, 64); err == nil && ts > 0 {
+			opts = append(opts, content.TimeRange(time.Time{}, time.Unix(0, ts*int64(time.Microsecond))))
+		}
+
+		if err := service.ArticleRepo().Read(true, user, opts...); err != nil {
+			fatal(w, log, "Error marking articles as read: %+v", err)
+			return
+		}
+
+		writeOK(w)
+	}
+}
@@ -70,7 +70,7 @@ func hubbubRegistration(
 				return
 			}
 
-			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
+			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
 				f.Refresh(pf)
 
 				if _, err = feedRepo.Update(&f); err != nil {
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var labelKey = contextKey("label")
+
+func listLabels(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		labels, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting labels: %+v", err)
+			return
+		}
+
+		args{"labels": labels}.WriteJSON(w)
+	}
+}
+
+func getLabel(w http.ResponseWriter, r *http.Request) {
+	if label, stop := labelFromRequest(w, r); stop {
+		return
+	} else {
+		args{"label": label}.WriteJSON(w)
+	}
+}
+
+func addLabel(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		label := content.Label{User: user.Login}
+		if err := labelFromForm(r, &label); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if stop := labelCaptionTaken(w, repo, user, label, log); stop {
+			return
+		}
+
+		label, err := repo.Create(label)
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error creating label: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "label": label}.WriteJSON(w)
+	}
+}
+
+func updateLabel(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := labelFromForm(r, &label); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if stop := labelCaptionTaken(w, repo, user, label, log); stop {
+			return
+		}
+
+		if err := repo.Update(label); err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error updating label: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "label": label}.WriteJSON(w)
+	}
+}
+
+func deleteLabel(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(label); err != nil {
+			fatal(w, log, "Error deleting label: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// labelArticles assigns the label to the given articles on POST, and
+// removes it from them on DELETE. Only the user's own articles are
+// affected.
+func labelArticles(service repo.Service, log log.Log) http.HandlerFunc {
+	labelRepo := service.LabelRepo()
+	articleRepo := service.ArticleRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		label, stop := labelFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		requested := []content.ArticleID{}
+		for _, v := range r.Form["id"] {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid id: %s", v), http.StatusBadRequest)
+				return
+			}
+
+			requested = append(requested, content.ArticleID(id))
+		}
+
+		if len(requested) == 0 {
+			http.Error(w, "No article ids provided", http.StatusBadRequest)
+			return
+		}
+
+		ids, err := articleRepo.IDs(user, content.IDs(requested))
+		if err != nil {
+			fatal(w, log, "Error getting user article ids: %+v", err)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			err = labelRepo.Assign(label, ids)
+		} else {
+			err = labelRepo.Unassign(label, ids)
+		}
+
+		if err != nil {
+			fatal(w, log, "Error changing article labels: %+v", err)
+			return
+		}
+
+		args{"success": true, "ids": ids}.WriteJSON(w)
+	}
+}
+
+func getArticleLabels(repo repo.Label, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		labels, err := repo.ForArticles([]content.ArticleID{article.ID}, user)
+		if err != nil {
+			fatal(w, log, "Error getting article labels: %+v", err)
+			return
+		}
+
+		l := labels[article.ID]
+		if l == nil {
+			l = []content.Label{}
+		}
+
+		args{"labels": l}.WriteJSON(w)
+	}
+}
+
+// labelFromForm overrides the label fields with the ones present in the
+// request form.
+func labelFromForm(r *http.Request, label *content.Label) error {
+	if _, ok := r.Form["caption"]; ok {
+		label.Caption = r.Form.Get("caption")
+	}
+
+	if _, ok := r.Form["fgColor"]; ok {
+		label.FgColor = r.Form.Get("fgColor")
+	}
+
+	if _, ok := r.Form["bgColor"]; ok {
+		label.BgColor = r.Form.Get("bgColor")
+	}
+
+	return label.Validate()
+}
+
+// labelCaptionTaken responds with a conflict if another label of the user
+// already has the same caption.
+func labelCaptionTaken(w http.ResponseWriter, repo repo.Label, user content.User, label content.Label, log log.Log) bool {
+	labels, err := repo.ForUser(user)
+	if err != nil {
+		fatal(w, log, "Error getting labels: %+v", err)
+		return true
+	}
+
+	for _, l := range labels {
+		if l.ID != label.ID && l.Caption == label.Caption {
+			http.Error(w, fmt.Sprintf("Label %s already exists", label.Caption), http.StatusConflict)
+			return true
+		}
+	}
+
+	return false
+}
+
+func labelContext(repo repo.Label, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "labelID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			label, err := repo.Get(content.LabelID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting label: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), labelKey, label)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func labelFromRequest(w http.ResponseWriter, r *http.Request) (label content.Label, stop bool) {
+	var ok bool
+	if label, ok = r.Context().Value(labelKey).(content.Label); ok {
+		return label, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Label{}, true
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_addLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		noUser    bool
+		form      url.Values
+		existing  []content.Label
+		createErr error
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "no caption", form: url.Values{}, code: 400},
+		{name: "invalid color", form: url.Values{"caption": {"label"}, "fgColor": {"red"}}, code: 400},
+		{name: "duplicate", form: url.Values{"caption": {"label"}}, existing: []content.Label{{ID: 1, Caption: "label"}}, code: 409},
+		{name: "create err", form: url.Values{"caption": {"label"}}, createErr: errors.New("err"), code: 500},
+		{name: "create", form: url.Values{"caption": {"label"}, "fgColor": {"#fff"}, "bgColor": {"#000000"}}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.code == 400 {
+					break
+				}
+
+				labelRepo.EXPECT().ForUser(userMatcher{user}).Return(tt.existing, nil)
+
+				if tt.code == 409 {
+					break
+				}
+
+				labelRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(label content.Label) (content.Label, error) {
+					if label.User != user.Login || label.Caption != tt.form.Get("caption") ||
+						label.FgColor != tt.form.Get("fgColor") || label.BgColor != tt.form.Get("bgColor") {
+						t.Errorf("addLabel() label = %#v", label)
+					}
+
+					label.ID = 5
+					return label, tt.createErr
+				})
+			}
+
+			addLabel(labelRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("addLabel() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success bool          `json:"success"`
+				Label   content.Label `json:"label"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("addLabel() body = %s", w.Body)
+				return
+			}
+
+			if !got.Success || got.Label.ID != 5 || got.Label.Caption != tt.form.Get("caption") {
+				t.Errorf("addLabel() = %s", w.Body)
+			}
+		})
+	}
+}
+
+func Test_updateLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		noLabel   bool
+		form      url.Values
+		updateErr error
+		want      content.Label
+		code      int
+	}{
+		{name: "no label", noLabel: true, code: 400},
+		{name: "empty caption", form: url.Values{"caption": {""}}, code: 400},
+		{name: "duplicate", form: url.Values{"caption": {"other"}}, code: 409},
+		{name: "update err", form: url.Values{"caption": {"renamed"}}, updateErr: errors.New("err"), code: 500},
+		{name: "rename", form: url.Values{"caption": {"renamed"}}, want: content.Label{
+			ID: 2, User: "test", Caption: "renamed", FgColor: "#fff",
+		}, code: 200},
+		{name: "recolor", form: url.Values{"fgColor": {""}, "bgColor": {"#123"}}, want: content.Label{
+			ID: 2, User: "test", Caption: "label", BgColor: "#123",
+		}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			user := content.User{Login: "test"}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+			if !tt.noLabel {
+				label := content.Label{ID: 2, User: "test", Caption: "label", FgColor: "#fff"}
+				r = r.WithContext(context.WithValue(r.Context(), labelKey, label))
+
+				if tt.code != 400 {
+					labelRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Label{label, {ID: 3, Caption: "other"}}, nil)
+				}
+
+				if tt.code != 400 && tt.code != 409 {
+					labelRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(label content.Label) error {
+						if tt.updateErr == nil && label != tt.want {
+							t.Errorf("updateLabel() label = %#v, want %#v", label, tt.want)
+						}
+						return tt.updateErr
+					})
+				}
+			}
+
+			updateLabel(labelRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("updateLabel() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_labelArticles(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		form    url.Values
+		userIDs []content.ArticleID
+		err     error
+		code    int
+	}{
+		{name: "no ids", method: "POST", form: url.Values{}, code: 400},
+		{name: "invalid id", method: "POST", form: url.Values{"id": {"a"}}, code: 400},
+		{name: "assign err", method: "POST", form: url.Values{"id": {"1"}}, userIDs: []content.ArticleID{1}, err: errors.New("err"), code: 500},
+		{name: "assign", method: "POST", form: url.Values{"id": {"1", "2", "3"}}, userIDs: []content.ArticleID{1, 3}, code: 200},
+		{name: "unassign", method: "DELETE", form: url.Values{"id": {"1", "2"}}, userIDs: []content.ArticleID{2}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			labelRepo := mock_repo.NewMockLabel(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+			service := mock_repo.NewMockService(ctrl)
+			service.EXPECT().LabelRepo().Return(labelRepo)
+			service.EXPECT().ArticleRepo().Return(articleRepo)
+
+			var r *http.Request
+			if tt.method == "DELETE" {
+				// The values of delete requests are sent in the query.
+				r = httptest.NewRequest(tt.method, "/?"+tt.form.Encode(), nil)
+			} else {
+				r = httptest.NewRequest(tt.method, "/", strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			user := content.User{Login: "test"}
+			label := content.Label{ID: 2, User: "test", Caption: "label"}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			r = r.WithContext(context.WithValue(r.Context(), labelKey, label))
+
+			if tt.code != 400 {
+				articleRepo.EXPECT().IDs(userMatcher{user}, gomock.Any()).Return(tt.userIDs, nil)
+
+				if tt.method == "POST" {
+					labelRepo.EXPECT().Assign(label, tt.userIDs).Return(tt.err)
+				} else {
+					labelRepo.EXPECT().Unassign(label, tt.userIDs).Return(tt.err)
+				}
+			}
+
+			labelArticles(service, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("labelArticles() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success bool                `json:"success"`
+				IDs     []content.ArticleID `json:"ids"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("labelArticles() body = %s", w.Body)
+				return
+			}
+
+			if !got.Success || len(got.IDs) != len(tt.userIDs) {
+				t.Errorf("labelArticles() = %s", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package miniflux
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// The entry list scopes, set by the url.
+type scope int
+
+const (
+	allEntries scope = iota
+	feedEntries
+	categoryEntries
+)
+
+// defaultLimit is the number of entries returned when no limit is given.
+const defaultLimit = 100
+
+const (
+	readStatus   = "read"
+	unreadStatus = "unread"
+)
+
+type entry struct {
+	ID          content.ArticleID `json:"id"`
+	UserID      int64             `json:"user_id"`
+	FeedID      content.FeedID    `json:"feed_id"`
+	Status      string            `json:"status"`
+	Hash        string            `json:"hash"`
+	Title       string            `json:"title"`
+	URL         string            `json:"url"`
+	CommentsURL string            `json:"comments_url"`
+	PublishedAt string            `json:"published_at"`
+	CreatedAt   string            `json:"created_at"`
+	ChangedAt   string            `json:"changed_at"`
+	Content     string            `json:"content"`
+	Author      string            `json:"author"`
+	ShareCode   string            `json:"share_code"`
+	Starred     bool              `json:"starred"`
+	ReadingTime int               `json:"reading_time"`
+	Enclosures  []enclosure       `json:"enclosures"`
+	Feed        feed              `json:"feed"`
+	Tags        []string          `json:"tags"`
+}
+
+type enclosure struct {
+	ID       int64             `json:"id"`
+	UserID   int64             `json:"user_id"`
+	EntryID  content.ArticleID `json:"entry_id"`
+	URL      string            `json:"url"`
+	MimeType string            `json:"mime_type"`
+	Size     int64             `json:"size"`
+}
+
+// entryList returns the entries of the scope, filtered by the status,
+// starred, feed_id, category_id, before, after, before_entry_id and
+// after_entry_id parameters. The total is the number of entries before
+// applying the limit and offset.
+func entryList(s scope, service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		articleRepo := service.ArticleRepo()
+
+		opts, ok := scopeOptions(w, r, s, user, service, log)
+		if !ok {
+			return
+		}
+		empty := opts == nil
+
+		filterOpts, ok, err := filterOptions(r, user, service.TagRepo())
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]interface{}{"total": 0, "entries": []entry{}}
+		if empty || !ok {
+			compat.WriteJSONStatus(w, resp, http.StatusOK)
+			return
+		}
+
+		// The scope options come last, so that the url takes precedence
+		// over the parameters.
+		opts = append(filterOpts, opts...)
+
+		total, err := articleRepo.Count(user, opts...)
+		if err != nil {
+			fatal(w, log, "Error getting entry count: %+v", err)
+			return
+		}
+
+		limit, offset := defaultLimit, 0
+		if v, err := strconv.Atoi(r.Form.Get("limit")); err == nil && v > 0 {
+			limit = v
+		}
+		if v, err := strconv.Atoi(r.Form.Get("offset")); err == nil && v > 0 {
+			offset = v
+		}
+
+		opts = append(opts, sortingOption(r), content.Paging(limit, offset))
+
+		articles, err := articleRepo.ForUser(user, opts...)
+		if err != nil {
+			fatal(w, log, "Error getting entries: %+v", err)
+			return
+		}
+
+		feeds, err := userFeeds(user, service)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		resp["total"] = total
+		resp["entries"] = convertArticles(articles, feeds, processors)
+
+		compat.WriteJSONStatus(w, resp, http.StatusOK)
+	}
+}
+
+func entryGet(service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		a, ok := userArticle(w, r, user, service.ArticleRepo(), log)
+		if !ok {
+			return
+		}
+
+		feeds, err := userFeeds(user, service)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		compat.WriteJSONStatus(w, convertArticles([]content.Article{a}, feeds, processors)[0], http.StatusOK)
+	}
+}
+
+// entriesStatus changes the read state of the given entries.
+func entriesStatus(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		var req struct {
+			EntryIDs []content.ArticleID `json:"entry_ids"`
+			Status   string              `json:"status"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(req.EntryIDs) == 0 {
+			writeError(w, "The list of entries is empty", http.StatusBadRequest)
+			return
+		}
+
+		if req.Status != readStatus && req.Status != unreadStatus {
+			writeError(w, "Invalid entry status", http.StatusBadRequest)
+			return
+		}
+
+		if err := service.ArticleRepo().Read(req.Status == readStatus, user, content.IDs(req.EntryIDs)); err != nil {
+			fatal(w, log, "Error changing entry status: %+v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func toggleBookmark(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		a, ok := userArticle(w, r, user, service.ArticleRepo(), log)
+		if !ok {
+			return
+		}
+
+		if err := service.ArticleRepo().Favor(!a.Favorite, user, content.IDs([]content.ArticleID{a.ID})); err != nil {
+			fatal(w, log, "Error changing entry bookmark: %+v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func markAllRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := service.ArticleRepo().Read(true, compat.UserFromRequest(r)); err != nil {
+			fatal(w, log, "Error marking entries as read: %+v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// scopeOptions converts the feed or category from the url to query
+// options, writing an error response when they can't be found. A nil
+// result means that the query is known to be empty.
+func scopeOptions(w http.ResponseWriter, r *http.Request, s scope, user content.User, service repo.Service, log log.Log) ([]content.QueryOpt, bool) {
+	opts := []content.QueryOpt{content.Filters(content.GetUserFilters(user))}
+
+	switch s {
+	case feedEntries:
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return nil, false
+		}
+
+		opts = append(opts, content.FeedIDs([]content.FeedID{f.ID}))
+	case categoryEntries:
+		tag, ok := userCategory(w, r, user, service.TagRepo(), log)
+		if !ok {
+			return nil, false
+		}
+
+		ids, tagged, err := categoryFeedIDs(tag.ID, user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting category feeds: %+v", err)
+			return nil, false
+		}
+
+		if !tagged {
+			opts = append(opts, content.UntaggedOnly)
+		} else if len(ids) == 0 {
+			return nil, true
+		} else {
+			opts = append(opts, content.FeedIDs(ids))
+		}
+	}
+
+	return opts, true
+}
+
+// filterOptions converts the query parameters to query options. A false
+// result means that the query is known to be empty.
+func filterOptions(r *http.Request, user content.User, tagRepo repo.Tag) ([]content.QueryOpt, bool, error) {
+	var opts []content.QueryOpt
+
+	statuses := map[string]bool{}
+	for _, s := range r.Form["status"] {
+		statuses[s] = true
+	}
+
+	switch {
+	case len(statuses) == 0, statuses[readStatus] && statuses[unreadStatus]:
+	case statuses[readStatus]:
+		opts = append(opts, content.ReadOnly)
+	case statuses[unreadStatus]:
+		opts = append(opts, content.UnreadOnly)
+	default:
+		// Readeef doesn't remove entries.
+		return nil, false, nil
+	}
+
+	if starred, err := strconv.ParseBool(r.Form.Get("starred")); err == nil && starred {
+		opts = append(opts, content.FavoriteOnly)
+	}
+
+	if v := r.Form.Get("feed_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, false, errors.Errorf("invalid feed id %s", v)
+		}
+
+		opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(id)}))
+	}
+
+	if v := r.Form.Get("category_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, false, errors.Errorf("invalid category id %s", v)
+		}
+
+		ids, tagged, err := categoryFeedIDs(content.TagID(id), user, tagRepo)
+		if err != nil {
+			if content.IsNoContent(err) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+
+		if !tagged {
+			opts = append(opts, content.UntaggedOnly)
+		} else if len(ids) == 0 {
+			return nil, false, nil
+		} else {
+			opts = append(opts, content.FeedIDs(ids))
+		}
+	}
+
+	var after, before int64
+	for name, v := range map[string]*int64{"after": &after, "before": &before} {
+		if s := r.Form.Get(name); s != "" {
+			var err error
+			if *v, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return nil, false, errors.Errorf("invalid %s timestamp %s", name, s)
+			}
+		}
+	}
+
+	if after > 0 || before > 0 {
+		opts = append(opts, content.TimeRange(unixTime(after), unixTime(before)))
+	}
+
+	var afterID, beforeID int64
+	for name, v := range map[string]*int64{"after_entry_id": &afterID, "before_entry_id": &beforeID} {
+		if s := r.Form.Get(name); s != "" {
+			var err error
+			if *v, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return nil, false, errors.Errorf("invalid %s %s", name, s)
+			}
+		}
+	}
+
+	if afterID > 0 || beforeID > 0 {
+		opts = append(opts, content.IDRange(content.ArticleID(afterID), content.ArticleID(beforeID)))
+	}
+
+	return opts, true, nil
+}
+
+// sortingOption converts the order and direction parameters. Entries can
+// only be sorted by id or date, which is the default.
+func sortingOption(r *http.Request) content.QueryOpt {
+	order := content.DescendingOrder
+	if r.Form.Get("direction") == "asc" {
+		order = content.AscendingOrder
+	}
+
+	if r.Form.Get("order") == "id" {
+		return content.Sorting(content.SortByID, order)
+	}
+
+	return content.Sorting(content.SortByDate, order)
+}
+
+// userArticle returns the user article from the url, writing an error
+// response when it can't be found.
+func userArticle(w http.ResponseWriter, r *http.Request, user content.User, repo repo.Article, log log.Log) (content.Article, bool) {
+	id, err := urlParamID(r, "entryID")
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return content.Article{}, false
+	}
+
+	articles, err := repo.ForUser(user, content.IDs([]content.ArticleID{content.ArticleID(id)}))
+	if err != nil {
+		fatal(w, log, "Error getting entry: %+v", err)
+		return content.Article{}, false
+	}
+
+	if len(articles) == 0 {
+		writeError(w, "Entry not found", http.StatusNotFound)
+		return content.Article{}, false
+	}
+
+	return articles[0], true
+}
+
+func unixTime(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(ts, 0)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func convertArticles(articles []content.Article, feeds []feed, processors []processor.Article) []entry {
+	entries := make([]entry, 0, len(articles))
+	if len(articles) == 0 {
+		return entries
+	}
+
+	feedMap := make(map[content.FeedID]feed, len(feeds))
+	for _, f := range feeds {
+		feedMap[f.ID] = f
+	}
+
+	articles = processor.Articles(processors).Process(articles)
+
+	for _, a := range articles {
+		guid := a.Guid.String
+		if guid == "" {
+			guid = a.Link
+		}
+		if guid == "" {
+			guid = strconv.FormatInt(int64(a.ID), 10)
+		}
+		hash := sha256.Sum256([]byte(guid))
+
+		status := unreadStatus
+		if a.Read {
+			status = readStatus
+		}
+
+		date := formatTime(a.Date)
+
+		e := entry{
+			ID:          a.ID,
+			UserID:      userID,
+			FeedID:      a.FeedID,
+			Status:      status,
+			Hash:        hex.EncodeToString(hash[:]),
+			Title:       a.Title,
+			URL:         a.Link,
+			PublishedAt: date,
+			CreatedAt:   date,
+			ChangedAt:   date,
+			Content:     a.Description,
+			Author:      a.Author,
+			Starred:     a.Favorite,
+			Enclosures:  make([]enclosure, len(a.Enclosures)),
+			Feed:        feedMap[a.FeedID],
+			Tags:        a.Categories,
+		}
+
+		if e.Tags == nil {
+			e.Tags = []string{}
+		}
+
+		for i, enc := range a.Enclosures {
+			e.Enclosures[i] = enclosure{
+				ID:       int64(i + 1),
+				UserID:   userID,
+				EntryID:  a.ID,
+				URL:      enc.URL,
+				MimeType: enc.Type,
+				Size:     enc.Length,
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
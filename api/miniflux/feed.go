@@ -0,0 +1,598 @@
+package miniflux
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// uncategorized holds the feeds without tags. Miniflux feeds always have a
+// category, and some clients don't expect it to be missing.
+var uncategorized = content.Tag{ID: 0, Value: "Uncategorized"}
+
+type category struct {
+	ID          content.TagID `json:"id"`
+	Title       string        `json:"title"`
+	UserID      int64         `json:"user_id"`
+	FeedCount   *int          `json:"feed_count,omitempty"`
+	TotalUnread *int64        `json:"total_unread,omitempty"`
+}
+
+type feed struct {
+	ID                  content.FeedID `json:"id"`
+	UserID              int64          `json:"user_id"`
+	FeedURL             string         `json:"feed_url"`
+	SiteURL             string         `json:"site_url"`
+	Title               string         `json:"title"`
+	CheckedAt           string         `json:"checked_at"`
+	ParsingErrorMessage string         `json:"parsing_error_message"`
+	ParsingErrorCount   int            `json:"parsing_error_count"`
+	Disabled            bool           `json:"disabled"`
+	Category            category       `json:"category"`
+	Icon                *struct{}      `json:"icon"`
+}
+
+type subscription struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Type  string `json:"type"`
+}
+
+type feedRequest struct {
+	FeedURL    string         `json:"feed_url"`
+	CategoryID *content.TagID `json:"category_id"`
+	Username   string         `json:"username"`
+	Password   string         `json:"password"`
+}
+
+// categoryList returns the user tags as categories. Tags exist only while
+// they are attached to feeds, so categories can't be created or renamed.
+func categoryList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		tags, err := service.TagRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		tags = append([]content.Tag{uncategorized}, tags...)
+
+		categories := make([]category, len(tags))
+		for i, t := range tags {
+			categories[i] = convertCategory(t)
+		}
+
+		if r.Form.Get("counts") == "true" {
+			if err := categoryCounts(categories, user, service); err != nil {
+				fatal(w, log, "Error getting category counts: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSONStatus(w, categories, http.StatusOK)
+	}
+}
+
+func categoryFeeds(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		tag, ok := userCategory(w, r, user, service.TagRepo(), log)
+		if !ok {
+			return
+		}
+
+		feeds, err := userFeeds(user, service)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		resp := []feed{}
+		for _, f := range feeds {
+			if f.Category.ID == tag.ID {
+				resp = append(resp, f)
+			}
+		}
+
+		compat.WriteJSONStatus(w, resp, http.StatusOK)
+	}
+}
+
+func markCategoryRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		tag, ok := userCategory(w, r, user, service.TagRepo(), log)
+		if !ok {
+			return
+		}
+
+		ids, tagged, err := categoryFeedIDs(tag.ID, user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting category feeds: %+v", err)
+			return
+		}
+
+		opts := []content.QueryOpt{content.UntaggedOnly}
+		if tagged {
+			opts = []content.QueryOpt{content.FeedIDs(ids)}
+		}
+
+		if !tagged || len(ids) > 0 {
+			if err := service.ArticleRepo().Read(true, user, opts...); err != nil {
+				fatal(w, log, "Error marking category as read: %+v", err)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func feedList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feeds, err := userFeeds(compat.UserFromRequest(r), service)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		compat.WriteJSONStatus(w, feeds, http.StatusOK)
+	}
+}
+
+func feedGet(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		categories, err := feedCategories(user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting feed categories: %+v", err)
+			return
+		}
+
+		compat.WriteJSONStatus(w, convertFeed(f, categories[f.ID]), http.StatusOK)
+	}
+}
+
+func feedCreate(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		feedRepo := service.FeedRepo()
+
+		var req feedRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.FeedURL == "" {
+			writeError(w, "The feed URL is mandatory", http.StatusBadRequest)
+			return
+		}
+
+		var tag content.Tag
+		if req.CategoryID != nil && *req.CategoryID != 0 {
+			var err error
+			if tag, err = service.TagRepo().Get(*req.CategoryID, user); err != nil {
+				if content.IsNoContent(err) {
+					writeError(w, "This category does not exist or does not belong to this user", http.StatusBadRequest)
+				} else {
+					fatal(w, log, "Error getting category: %+v", err)
+				}
+				return
+			}
+		}
+
+		f, err := feedManager.AddFeedByLink(req.FeedURL, content.FeedAuth{Username: req.Username, Password: req.Password})
+		if err != nil {
+			log.Infof("Error adding feed %s: %+v", req.FeedURL, err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := feedRepo.Get(f.ID, user); err == nil {
+			writeError(w, "This feed already exists", http.StatusConflict)
+			return
+		} else if !content.IsNoContent(err) {
+			fatal(w, log, "Error getting user feed: %+v", err)
+			return
+		}
+
+		if err := feedRepo.AttachTo(f, user); err != nil {
+			fatal(w, log, "Error attaching feed to user: %+v", err)
+			return
+		}
+
+		if tag.ID != 0 {
+			if err := feedRepo.SetUserTags(f, user, []*content.Tag{&tag}); err != nil {
+				fatal(w, log, "Error setting feed category: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSONStatus(w, map[string]content.FeedID{"feed_id": f.ID}, http.StatusCreated)
+	}
+}
+
+// feedUpdate changes the category of the feed. Readeef feeds may have more
+// than one tag, all of which are replaced. Other changes, like the title,
+// are not supported, since feeds are shared between users.
+func feedUpdate(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		var req feedRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.CategoryID != nil {
+			tags := []*content.Tag{}
+
+			if *req.CategoryID != 0 {
+				tag, err := service.TagRepo().Get(*req.CategoryID, user)
+				if err != nil {
+					if content.IsNoContent(err) {
+						writeError(w, "This category does not exist or does not belong to this user", http.StatusBadRequest)
+					} else {
+						fatal(w, log, "Error getting category: %+v", err)
+					}
+					return
+				}
+
+				tags = append(tags, &tag)
+			}
+
+			if err := service.FeedRepo().SetUserTags(f, user, tags); err != nil {
+				fatal(w, log, "Error setting feed category: %+v", err)
+				return
+			}
+		}
+
+		categories, err := feedCategories(user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting feed categories: %+v", err)
+			return
+		}
+
+		compat.WriteJSONStatus(w, convertFeed(f, categories[f.ID]), http.StatusCreated)
+	}
+}
+
+func feedDelete(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		if err := service.FeedRepo().DetachFrom(f, user); err != nil {
+			fatal(w, log, "Error detaching feed from user: %+v", err)
+			return
+		}
+
+		feedManager.RemoveFeed(f)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func refreshFeed(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, ok := userFeed(w, r, compat.UserFromRequest(r), service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		if _, err := feedManager.RefreshFeed(f); err != nil {
+			log.Infof("Error refreshing feed %s: %+v", f, err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// refreshFeeds refreshes all user feeds in the background, like miniflux
+// does.
+func refreshFeeds(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feeds, err := service.FeedRepo().ForUser(compat.UserFromRequest(r))
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		go func() {
+			for _, f := range feeds {
+				if _, err := feedManager.RefreshFeed(f); err != nil {
+					log.Infof("Error refreshing feed %s: %+v", f, err)
+				}
+			}
+		}()
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func markFeedRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		if err := service.ArticleRepo().Read(true, user, content.FeedIDs([]content.FeedID{f.ID})); err != nil {
+			fatal(w, log, "Error marking feed as read: %+v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func feedCounters(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		articleRepo := service.ArticleRepo()
+		filters := content.Filters(content.GetUserFilters(user))
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		reads := map[content.FeedID]int64{}
+		unreads := map[content.FeedID]int64{}
+		for _, f := range feeds {
+			ids := content.FeedIDs([]content.FeedID{f.ID})
+
+			if reads[f.ID], err = articleRepo.Count(user, content.ReadOnly, filters, ids); err != nil {
+				fatal(w, log, "Error getting feed read count: %+v", err)
+				return
+			}
+
+			if unreads[f.ID], err = articleRepo.Count(user, content.UnreadOnly, filters, ids); err != nil {
+				fatal(w, log, "Error getting feed unread count: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSONStatus(w, map[string]interface{}{"reads": reads, "unreads": unreads}, http.StatusOK)
+	}
+}
+
+func discover(feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			URL      string `json:"url"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.URL == "" {
+			writeError(w, "The URL is mandatory", http.StatusBadRequest)
+			return
+		}
+
+		feeds, err := feedManager.DiscoverFeeds(req.URL, content.FeedAuth{Username: req.Username, Password: req.Password})
+		if err != nil {
+			log.Infof("Error discovering feeds at %s: %+v", req.URL, err)
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(feeds) == 0 {
+			writeError(w, "No subscription found", http.StatusNotFound)
+			return
+		}
+
+		subscriptions := make([]subscription, len(feeds))
+		for i, f := range feeds {
+			subscriptions[i] = subscription{Title: f.Title, URL: f.Link, Type: "rss"}
+		}
+
+		compat.WriteJSONStatus(w, subscriptions, http.StatusOK)
+	}
+}
+
+func categoryCounts(categories []category, user content.User, service repo.Service) error {
+	feeds, err := userFeeds(user, service)
+	if err != nil {
+		return err
+	}
+
+	ids := map[content.TagID][]content.FeedID{}
+	for _, f := range feeds {
+		ids[f.Category.ID] = append(ids[f.Category.ID], f.ID)
+	}
+
+	filters := content.Filters(content.GetUserFilters(user))
+	for i := range categories {
+		feedIDs := ids[categories[i].ID]
+		feedCount := len(feedIDs)
+
+		var unread int64
+		if feedCount > 0 {
+			if unread, err = service.ArticleRepo().Count(user, content.UnreadOnly, filters, content.FeedIDs(feedIDs)); err != nil {
+				return errors.WithMessage(err, "getting category unread count")
+			}
+		}
+
+		categories[i].FeedCount = &feedCount
+		categories[i].TotalUnread = &unread
+	}
+
+	return nil
+}
+
+func userFeeds(user content.User, service repo.Service) ([]feed, error) {
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user feeds")
+	}
+
+	categories, err := feedCategories(user, service.TagRepo())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]feed, len(feeds))
+	for i, f := range feeds {
+		resp[i] = convertFeed(f, categories[f.ID])
+	}
+
+	return resp, nil
+}
+
+// userFeed returns the user feed from the url, writing an error response
+// when it can't be found.
+func userFeed(w http.ResponseWriter, r *http.Request, user content.User, repo repo.Feed, log log.Log) (content.Feed, bool) {
+	id, err := urlParamID(r, "feedID")
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return content.Feed{}, false
+	}
+
+	f, err := repo.Get(content.FeedID(id), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			writeError(w, "Feed not found", http.StatusNotFound)
+		} else {
+			fatal(w, log, "Error getting user feed: %+v", err)
+		}
+		return content.Feed{}, false
+	}
+
+	return f, true
+}
+
+// userCategory returns the user tag from the url, writing an error response
+// when it can't be found.
+func userCategory(w http.ResponseWriter, r *http.Request, user content.User, repo repo.Tag, log log.Log) (content.Tag, bool) {
+	id, err := urlParamID(r, "categoryID")
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return content.Tag{}, false
+	}
+
+	if id == int64(uncategorized.ID) {
+		return uncategorized, true
+	}
+
+	tag, err := repo.Get(content.TagID(id), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			writeError(w, "Category not found", http.StatusNotFound)
+		} else {
+			fatal(w, log, "Error getting category: %+v", err)
+		}
+		return content.Tag{}, false
+	}
+
+	return tag, true
+}
+
+// categoryFeedIDs returns the ids of the feeds with the given tag. A false
+// result means that the category holds the untagged feeds, whose ids are
+// not returned.
+func categoryFeedIDs(id content.TagID, user content.User, repo repo.Tag) ([]content.FeedID, bool, error) {
+	if id == uncategorized.ID {
+		return nil, false, nil
+	}
+
+	tag, err := repo.Get(id, user)
+	if err != nil {
+		return nil, true, errors.WithMessage(err, "getting category tag")
+	}
+
+	ids, err := repo.FeedIDs(tag, user)
+	return ids, true, errors.WithMessage(err, "getting category feed ids")
+}
+
+// feedCategories returns the category of every tagged user feed. Since a
+// feed can only be in one category, its first tag is used.
+func feedCategories(user content.User, repo repo.Tag) (map[content.FeedID]content.Tag, error) {
+	tags, err := repo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	categories := map[content.FeedID]content.Tag{}
+	for _, tag := range tags {
+		ids, err := repo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range ids {
+			if _, ok := categories[id]; !ok {
+				categories[id] = tag
+			}
+		}
+	}
+
+	return categories, nil
+}
+
+func convertCategory(t content.Tag) category {
+	return category{ID: t.ID, Title: string(t.Value), UserID: userID}
+}
+
+// convertFeed converts the feed, placing it in the given category, or in
+// the uncategorized one if the tag is empty.
+func convertFeed(f content.Feed, tag content.Tag) feed {
+	if tag.ID == 0 {
+		tag = uncategorized
+	}
+
+	site := f.SiteLink
+	if site == "" {
+		site = f.Link
+	}
+
+	return feed{
+		ID:      f.ID,
+		UserID:  userID,
+		FeedURL: f.Link,
+		SiteURL: site,
+		Title:   f.Title,
+		// The time of the last check isn't stored.
+		CheckedAt:           formatTime(time.Time{}),
+		ParsingErrorMessage: f.UpdateError,
+		ParsingErrorCount:   f.FailureCount,
+		Disabled:            f.Dead,
+		Category:            convertCategory(tag),
+	}
+}
@@ -0,0 +1,169 @@
+package miniflux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// userID is reported as the id of every user. Readeef users only have a
+// login, and every response is limited to the authenticated user anyway.
+const userID = 1
+
+type me struct {
+	ID                     int64  `json:"id"`
+	Username               string `json:"username"`
+	IsAdmin                bool   `json:"is_admin"`
+	Theme                  string `json:"theme"`
+	Language               string `json:"language"`
+	Timezone               string `json:"timezone"`
+	EntrySortingDirection  string `json:"entry_sorting_direction"`
+	EntriesPerPage         int    `json:"entries_per_page"`
+	KeyboardShortcuts      bool   `json:"keyboard_shortcuts"`
+	ShowReadingTime        bool   `json:"show_reading_time"`
+	EntrySwipe             bool   `json:"entry_swipe"`
+	LastLoginAt            *int64 `json:"last_login_at"`
+	DisplayMode            string `json:"display_mode"`
+	DefaultReadingSpeed    int    `json:"default_reading_speed"`
+	CJKReadingSpeed        int    `json:"cjk_reading_speed"`
+	DefaultHomePage        string `json:"default_home_page"`
+	CategoriesSortingOrder string `json:"categories_sorting_order"`
+}
+
+// Handler emulates the v1 REST API of Miniflux. Clients authenticate with
+// an opaque api token in the X-Auth-Token header, whose hash identifies the
+// user, or with the user's login and password, using basic authentication.
+// Tags are presented as categories.
+func Handler(
+	service repo.Service,
+	feedManager *readeef.FeedManager,
+	processors []processor.Article,
+	secret []byte,
+	log log.Log,
+) http.Handler {
+	processors = compat.FilterProcessors(processors)
+
+	r := chi.NewRouter()
+	r.Use(compat.FormParser(writeError), requireUser(service.UserRepo(), secret, log))
+
+	r.Route("/v1", func(r chi.Router) {
+		r.Get("/me", userInfo)
+
+		r.Post("/discover", discover(feedManager, log))
+
+		r.Get("/categories", categoryList(service, log))
+		r.Get("/categories/{categoryID}/feeds", categoryFeeds(service, log))
+		r.Get("/categories/{categoryID}/entries", entryList(categoryEntries, service, processors, log))
+		r.Get("/categories/{categoryID}/entries/{entryID}", entryGet(service, processors, log))
+		r.Put("/categories/{categoryID}/mark-all-as-read", markCategoryRead(service, log))
+
+		r.Get("/feeds", feedList(service, log))
+		r.Post("/feeds", feedCreate(service, feedManager, log))
+		r.Get("/feeds/counters", feedCounters(service, log))
+		r.Put("/feeds/refresh", refreshFeeds(service, feedManager, log))
+		r.Get("/feeds/{feedID}", feedGet(service, log))
+		r.Put("/feeds/{feedID}", feedUpdate(service, log))
+		r.Delete("/feeds/{feedID}", feedDelete(service, feedManager, log))
+		r.Put("/feeds/{feedID}/refresh", refreshFeed(service, feedManager, log))
+		r.Put("/feeds/{feedID}/mark-all-as-read", markFeedRead(service, log))
+		r.Get("/feeds/{feedID}/entries", entryList(feedEntries, service, processors, log))
+		r.Get("/feeds/{feedID}/entries/{entryID}", entryGet(service, processors, log))
+
+		r.Get("/entries", entryList(allEntries, service, processors, log))
+		r.Put("/entries", entriesStatus(service, log))
+		r.Get("/entries/{entryID}", entryGet(service, processors, log))
+		r.Put("/entries/{entryID}/bookmark", toggleBookmark(service, log))
+
+		r.Put("/users/{userID}/mark-all-as-read", markAllRead(service, log))
+	})
+
+	return r
+}
+
+func requireUser(repo repo.User, secret []byte, log log.Log) func(http.Handler) http.Handler {
+	return compat.RequireUser(
+		func(r *http.Request) (content.User, error) {
+			if token := r.Header.Get("X-Auth-Token"); token != "" {
+				user, err := repo.FindByAPIToken(content.APITokenHash(token))
+				if err == nil && !user.Active {
+					err = errors.New("inactive user")
+				}
+
+				return user, errors.WithMessage(err, fmt.Sprintf("login %s", user.Login))
+			}
+
+			login, password, ok := r.BasicAuth()
+			if !ok {
+				return content.User{}, errors.New("missing credentials")
+			}
+
+			user, err := repo.Get(content.Login(login))
+			if err == nil {
+				if ok, err = user.Authenticate(password, secret); err == nil && (!ok || !user.Active) {
+					err = errors.New("invalid credentials")
+				}
+			}
+
+			return user, errors.WithMessage(err, fmt.Sprintf("login %s", login))
+		},
+		func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Infof("Miniflux login: %+v", err)
+			writeError(w, "Access Unauthorized", http.StatusUnauthorized)
+		},
+	)
+}
+
+func userInfo(w http.ResponseWriter, r *http.Request) {
+	user := compat.UserFromRequest(r)
+
+	compat.WriteJSONStatus(w, me{
+		ID:                     userID,
+		Username:               string(user.Login),
+		IsAdmin:                user.Admin,
+		Theme:                  "system_serif",
+		Language:               "en_US",
+		Timezone:               "UTC",
+		EntrySortingDirection:  "desc",
+		EntriesPerPage:         100,
+		DisplayMode:            "standalone",
+		DefaultReadingSpeed:    265,
+		CJKReadingSpeed:        500,
+		DefaultHomePage:        "unread",
+		CategoriesSortingOrder: "unread_count",
+	}, http.StatusOK)
+}
+
+// decodeBody reads the json request body.
+func decodeBody(r *http.Request, data interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(data); err != nil && err != io.EOF {
+		return errors.Wrap(err, "decoding request body")
+	}
+
+	return nil
+}
+
+func urlParamID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	return id, errors.Wrapf(err, "parsing %s", name)
+}
+
+// writeError responds with the error format used by miniflux.
+func writeError(w http.ResponseWriter, message string, code int) {
+	compat.WriteJSONStatus(w, map[string]string{"error_message": message}, code)
+}
+
+func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
+	log.Printf(format, err)
+	writeError(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
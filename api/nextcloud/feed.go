@@ -0,0 +1,597 @@
+package nextcloud
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type folder struct {
+	ID   content.TagID `json:"id"`
+	Name string        `json:"name"`
+}
+
+type feed struct {
+	ID               content.FeedID `json:"id"`
+	URL              string         `json:"url"`
+	Title            string         `json:"title"`
+	FaviconLink      *string        `json:"faviconLink"`
+	Added            int64          `json:"added"`
+	FolderID         content.TagID  `json:"folderId"`
+	UnreadCount      int64          `json:"unreadCount"`
+	Ordering         int            `json:"ordering"`
+	Link             string         `json:"link"`
+	Pinned           bool           `json:"pinned"`
+	UpdateErrorCount int            `json:"updateErrorCount"`
+	LastUpdateError  string         `json:"lastUpdateError"`
+}
+
+type readRequest struct {
+	NewestItemID int64 `json:"newestItemId"`
+}
+
+// folderList returns the user tags as folders.
+func folderList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		tags, err := service.TagRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		folders := make([]folder, len(tags))
+		for i, t := range tags {
+			folders[i] = folder{ID: t.ID, Name: string(t.Value)}
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"folders": folders})
+	}
+}
+
+// folderCreate only reports the documented errors. Tags exist only while
+// they are attached to feeds, so empty folders can't be stored. Clients may
+// still create a folder when adding a feed to it.
+func folderCreate(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		name, ok := folderName(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := userTagByValue(name, user, service.TagRepo()); err == nil {
+			writeError(w, "Folder already exists", http.StatusConflict)
+			return
+		} else if !content.IsNoContent(err) {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		writeError(w, "Folders without feeds are not supported", http.StatusUnprocessableEntity)
+	}
+}
+
+// folderRename moves the feeds of the folder to a tag with the new name.
+// Tags are shared between users, so the tag itself is left intact.
+func folderRename(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		tagRepo := service.TagRepo()
+
+		tag, ok := userFolder(w, r, user, tagRepo, log)
+		if !ok {
+			return
+		}
+
+		name, ok := folderName(w, r)
+		if !ok {
+			return
+		}
+
+		if existing, err := userTagByValue(name, user, tagRepo); err == nil {
+			if existing.ID != tag.ID {
+				writeError(w, "Folder already exists", http.StatusConflict)
+			} else {
+				compat.WriteJSON(w, struct{}{})
+			}
+			return
+		} else if !content.IsNoContent(err) {
+			fatal(w, log, "Error getting user tags: %+v", err)
+			return
+		}
+
+		feeds, err := folderFeeds(tag, user, service)
+		if err != nil {
+			fatal(w, log, "Error getting folder feeds: %+v", err)
+			return
+		}
+
+		for _, f := range feeds {
+			tags, err := tagRepo.ForFeed(f, user)
+			if err != nil {
+				fatal(w, log, "Error getting feed tags: %+v", err)
+				return
+			}
+
+			renamed := make([]*content.Tag, len(tags))
+			for i := range tags {
+				if tags[i].ID == tag.ID {
+					renamed[i] = &content.Tag{Value: name}
+				} else {
+					renamed[i] = &tags[i]
+				}
+			}
+
+			if err := service.FeedRepo().SetUserTags(f, user, renamed); err != nil {
+				fatal(w, log, "Error setting feed tags: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// folderDelete removes the folder along with all of its feeds, as the news
+// app does.
+func folderDelete(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		tag, ok := userFolder(w, r, user, service.TagRepo(), log)
+		if !ok {
+			return
+		}
+
+		feeds, err := folderFeeds(tag, user, service)
+		if err != nil {
+			fatal(w, log, "Error getting folder feeds: %+v", err)
+			return
+		}
+
+		for _, f := range feeds {
+			if err := service.FeedRepo().DetachFrom(f, user); err != nil {
+				fatal(w, log, "Error detaching feed from user: %+v", err)
+				return
+			}
+
+			feedManager.RemoveFeed(f)
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+func markFolderRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		id, err := urlParamID(r, "folderID")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req readRequest
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ids, err := folderFeedIDs(content.TagID(id), user, service.TagRepo())
+		if err != nil {
+			if content.IsNoContent(err) {
+				writeError(w, "Folder not found", http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error getting folder feeds: %+v", err)
+			}
+			return
+		}
+
+		if len(ids) > 0 {
+			if err := markRead(user, intParam(req.NewestItemID, r, "newestItemId"), service.ArticleRepo(), content.FeedIDs(ids)); err != nil {
+				fatal(w, log, "Error marking folder as read: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+func feedList(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		articleRepo := service.ArticleRepo()
+		filters := content.Filters(content.GetUserFilters(user))
+
+		feeds, err := service.FeedRepo().ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		folders, err := feedFolders(user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting feed folders: %+v", err)
+			return
+		}
+
+		resp := make([]feed, 0, len(feeds))
+		for _, f := range feeds {
+			count, err := articleRepo.Count(user, content.UnreadOnly, filters, content.FeedIDs([]content.FeedID{f.ID}))
+			if err != nil {
+				fatal(w, log, "Error getting feed unread count: %+v", err)
+				return
+			}
+
+			resp = append(resp, convertFeed(f, folders[f.ID], count))
+		}
+
+		starred, err := articleRepo.Count(user, content.FavoriteOnly)
+		if err != nil {
+			fatal(w, log, "Error getting starred count: %+v", err)
+			return
+		}
+
+		newest, err := newestItemID(user, articleRepo)
+		if err != nil {
+			fatal(w, log, "Error getting newest item id: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{
+			"feeds":        resp,
+			"starredCount": starred,
+			"newestItemId": newest,
+		})
+	}
+}
+
+func feedCreate(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+		feedRepo := service.FeedRepo()
+
+		var req struct {
+			URL      string `json:"url"`
+			FolderID int64  `json:"folderId"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.URL == "" {
+			req.URL = r.Form.Get("url")
+		}
+		folderID := content.TagID(intParam(req.FolderID, r, "folderId"))
+
+		f, err := feedManager.AddFeedByLink(req.URL, content.FeedAuth{})
+		if err != nil {
+			log.Infof("Error adding feed %s: %+v", req.URL, err)
+			writeError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		if _, err := feedRepo.Get(f.ID, user); err == nil {
+			writeError(w, "Feed already exists", http.StatusConflict)
+			return
+		} else if !content.IsNoContent(err) {
+			fatal(w, log, "Error getting user feed: %+v", err)
+			return
+		}
+
+		if err := feedRepo.AttachTo(f, user); err != nil {
+			fatal(w, log, "Error attaching feed to user: %+v", err)
+			return
+		}
+
+		if folderID > 0 {
+			if err := moveFeed(f, folderID, user, service); err != nil {
+				fatal(w, log, "Error moving feed to folder: %+v", err)
+				return
+			}
+		}
+
+		newest, err := newestItemID(user, service.ArticleRepo())
+		if err != nil {
+			fatal(w, log, "Error getting newest item id: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{
+			"feeds":        []feed{convertFeed(f, folderID, 0)},
+			"newestItemId": newest,
+		})
+	}
+}
+
+func feedDelete(service repo.Service, feedManager *readeef.FeedManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		if err := service.FeedRepo().DetachFrom(f, user); err != nil {
+			fatal(w, log, "Error detaching feed from user: %+v", err)
+			return
+		}
+
+		feedManager.RemoveFeed(f)
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// feedMove places the feed in the given folder. Readeef feeds may have
+// more than one tag, all of which are replaced. A zero folder id removes
+// all tags.
+func feedMove(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			FolderID int64 `json:"folderId"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := moveFeed(f, content.TagID(intParam(req.FolderID, r, "folderId")), user, service); err != nil {
+			if content.IsNoContent(err) {
+				writeError(w, "Folder not found", http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error moving feed: %+v", err)
+			}
+			return
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// feedRename only reports the documented errors. Feeds are shared between
+// users and their titles come from the feed document, so they can't be
+// renamed.
+func feedRename(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		if _, ok := userFeed(w, r, user, service.FeedRepo(), log); !ok {
+			return
+		}
+
+		writeError(w, "Renaming feeds is not supported", http.StatusUnprocessableEntity)
+	}
+}
+
+func markFeedRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		f, ok := userFeed(w, r, user, service.FeedRepo(), log)
+		if !ok {
+			return
+		}
+
+		var req readRequest
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := markRead(user, intParam(req.NewestItemID, r, "newestItemId"), service.ArticleRepo(), content.FeedIDs([]content.FeedID{f.ID})); err != nil {
+			fatal(w, log, "Error marking feed as read: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// userFeed returns the user feed from the url, writing an error response
+// when it can't be found.
+func userFeed(w http.ResponseWriter, r *http.Request, user content.User, repo repo.Feed, log log.Log) (content.Feed, bool) {
+	id, err := urlParamID(r, "feedID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return content.Feed{}, false
+	}
+
+	f, err := repo.Get(content.FeedID(id), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			writeError(w, "Feed not found", http.StatusNotFound)
+		} else {
+			fatal(w, log, "Error getting user feed: %+v", err)
+		}
+		return content.Feed{}, false
+	}
+
+	return f, true
+}
+
+// userFolder returns the user tag from the url, writing an error response
+// when it can't be found.
+func userFolder(w http.ResponseWriter, r *http.Request, user content.User, repo repo.Tag, log log.Log) (content.Tag, bool) {
+	id, err := urlParamID(r, "folderID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return content.Tag{}, false
+	}
+
+	tag, err := repo.Get(content.TagID(id), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			writeError(w, "Folder not found", http.StatusNotFound)
+		} else {
+			fatal(w, log, "Error getting user tag: %+v", err)
+		}
+		return content.Tag{}, false
+	}
+
+	return tag, true
+}
+
+// folderName returns the folder name from the request, writing an error
+// response when it is invalid.
+func folderName(w http.ResponseWriter, r *http.Request) (content.TagValue, bool) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+
+	if req.Name == "" {
+		req.Name = r.Form.Get("name")
+	}
+
+	name := content.TagValue(strings.TrimSpace(req.Name))
+	if name == "" {
+		writeError(w, "Folder name is invalid", http.StatusUnprocessableEntity)
+		return "", false
+	}
+
+	return name, true
+}
+
+func userTagByValue(value content.TagValue, user content.User, repo repo.Tag) (content.Tag, error) {
+	tags, err := repo.ForUser(user)
+	if err != nil {
+		return content.Tag{}, errors.WithMessage(err, "getting user tags")
+	}
+
+	for _, tag := range tags {
+		if tag.Value == value {
+			return tag, nil
+		}
+	}
+
+	return content.Tag{}, content.ErrNoContent
+}
+
+func folderFeeds(tag content.Tag, user content.User, service repo.Service) ([]content.Feed, error) {
+	ids, err := service.TagRepo().FeedIDs(tag, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting tag feed ids")
+	}
+
+	feeds := make([]content.Feed, 0, len(ids))
+	for _, id := range ids {
+		f, err := service.FeedRepo().Get(id, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting user feed")
+		}
+
+		feeds = append(feeds, f)
+	}
+
+	return feeds, nil
+}
+
+func moveFeed(f content.Feed, folderID content.TagID, user content.User, service repo.Service) error {
+	tags := []*content.Tag{}
+
+	if folderID > 0 {
+		tag, err := service.TagRepo().Get(folderID, user)
+		if err != nil {
+			return errors.WithMessage(err, "getting folder tag")
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	return service.FeedRepo().SetUserTags(f, user, tags)
+}
+
+// markRead marks the articles up to, and including the newest item id as
+// read.
+func markRead(user content.User, newest int64, repo repo.Article, opts ...content.QueryOpt) error {
+	if newest > 0 {
+		opts = append(opts, content.IDRange(0, content.ArticleID(newest+1)))
+	}
+
+	return repo.Read(true, user, opts...)
+}
+
+func folderFeedIDs(id content.TagID, user content.User, repo repo.Tag) ([]content.FeedID, error) {
+	tag, err := repo.Get(id, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting folder tag")
+	}
+
+	return repo.FeedIDs(tag, user)
+}
+
+// feedFolders returns the folder of every tagged user feed. Since a feed
+// can only be in one folder, its first tag is used.
+func feedFolders(user content.User, repo repo.Tag) (map[content.FeedID]content.TagID, error) {
+	tags, err := repo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user tags")
+	}
+
+	folders := map[content.FeedID]content.TagID{}
+	for _, tag := range tags {
+		ids, err := repo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		for _, id := range ids {
+			if _, ok := folders[id]; !ok {
+				folders[id] = tag.ID
+			}
+		}
+	}
+
+	return folders, nil
+}
+
+func newestItemID(user content.User, repo repo.Article) (content.ArticleID, error) {
+	ids, err := repo.IDs(user, content.Paging(1, 0), content.Sorting(content.SortByID, content.DescendingOrder))
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	return ids[0], nil
+}
+
+func convertFeed(f content.Feed, folderID content.TagID, unread int64) feed {
+	link := f.SiteLink
+	if link == "" {
+		link = f.Link
+	}
+
+	return feed{
+		ID:               f.ID,
+		URL:              f.Link,
+		Title:            f.Title,
+		FolderID:         folderID,
+		UnreadCount:      unread,
+		Link:             link,
+		UpdateErrorCount: f.FailureCount,
+		LastUpdateError:  f.UpdateError,
+	}
+}
@@ -0,0 +1,173 @@
+package nextcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// newsVersion is the reported version of the Nextcloud News app. Clients
+// use it to enable features, and some refuse to work with older versions.
+const newsVersion = "15.0.0"
+
+var errMissingCredentials = errors.New("missing credentials")
+
+// Handler emulates the v1.2 API of the Nextcloud News app. Clients
+// authenticate every request with the user's login and password, using
+// basic authentication.
+func Handler(
+	service repo.Service,
+	feedManager *readeef.FeedManager,
+	processors []processor.Article,
+	secret []byte,
+	log log.Log,
+) http.Handler {
+	processors = compat.FilterProcessors(processors)
+
+	r := chi.NewRouter()
+	r.Use(compat.ParseForm, requireUser(service.UserRepo(), secret, log))
+
+	r.Get("/version", version)
+	r.Get("/status", status)
+	r.Get("/user", userInfo)
+
+	r.Get("/folders", folderList(service, log))
+	r.Post("/folders", folderCreate(service, log))
+	r.Put("/folders/{folderID}", folderRename(service, log))
+	r.Delete("/folders/{folderID}", folderDelete(service, feedManager, log))
+	r.Put("/folders/{folderID}/read", markFolderRead(service, log))
+
+	r.Get("/feeds", feedList(service, log))
+	r.Post("/feeds", feedCreate(service, feedManager, log))
+	r.Delete("/feeds/{feedID}", feedDelete(service, feedManager, log))
+	r.Put("/feeds/{feedID}/move", feedMove(service, log))
+	r.Put("/feeds/{feedID}/rename", feedRename(service, log))
+	r.Put("/feeds/{feedID}/read", markFeedRead(service, log))
+
+	r.Get("/items", itemList(service, processors, log))
+	r.Get("/items/updated", itemUpdatedList(service, processors, log))
+	r.Put("/items/read", markAllRead(service, log))
+	r.Put("/items/{itemID}/read", itemState(readState, true, service, log))
+	r.Put("/items/{itemID}/unread", itemState(readState, false, service, log))
+	r.Put("/items/{feedID}/{guidHash}/star", itemState(starState, true, service, log))
+	r.Put("/items/{feedID}/{guidHash}/unstar", itemState(starState, false, service, log))
+	r.Put("/items/read/multiple", itemsState(readState, true, service, log))
+	r.Put("/items/unread/multiple", itemsState(readState, false, service, log))
+	r.Put("/items/star/multiple", itemsState(starState, true, service, log))
+	r.Put("/items/unstar/multiple", itemsState(starState, false, service, log))
+
+	return r
+}
+
+func requireUser(repo repo.User, secret []byte, log log.Log) func(http.Handler) http.Handler {
+	return compat.RequireUser(
+		func(r *http.Request) (content.User, error) {
+			login, password, ok := r.BasicAuth()
+			if !ok {
+				return content.User{}, errMissingCredentials
+			}
+
+			user, err := repo.Get(content.Login(login))
+			if err == nil {
+				if ok, err = user.Authenticate(password, secret); err == nil && (!ok || !user.Active) {
+					err = errors.New("invalid credentials")
+				}
+			}
+
+			return user, errors.WithMessage(err, fmt.Sprintf("login %s", login))
+		},
+		func(w http.ResponseWriter, r *http.Request, err error) {
+			if err == errMissingCredentials {
+				w.Header().Set("WWW-Authenticate", `Basic realm="readeef"`)
+			} else {
+				log.Infof("Nextcloud news login: %+v", err)
+			}
+
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		},
+	)
+}
+
+func version(w http.ResponseWriter, r *http.Request) {
+	compat.WriteJSON(w, map[string]string{"version": newsVersion})
+}
+
+func status(w http.ResponseWriter, r *http.Request) {
+	compat.WriteJSON(w, map[string]interface{}{
+		"version": newsVersion,
+		"warnings": map[string]bool{
+			"improperlyConfiguredCron": false,
+			"incorrectDbCharset":       false,
+		},
+	})
+}
+
+func userInfo(w http.ResponseWriter, r *http.Request) {
+	user := compat.UserFromRequest(r)
+
+	name := user.FirstName
+	if user.LastName != "" {
+		name += " " + user.LastName
+	}
+	if name == "" {
+		name = string(user.Login)
+	}
+
+	compat.WriteJSON(w, map[string]interface{}{
+		"userId":             string(user.Login),
+		"displayName":        name,
+		"lastLoginTimestamp": 0,
+		"avatar":             nil,
+	})
+}
+
+// decodeBody reads the json request body. Empty bodies are ignored, since
+// some clients send the parameters in the query instead.
+func decodeBody(r *http.Request, data interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(data); err != nil && err != io.EOF {
+		return errors.Wrap(err, "decoding request body")
+	}
+
+	return nil
+}
+
+// intParam returns the named parameter from the request body, falling back
+// to the query or form data.
+func intParam(body int64, r *http.Request, name string) int64 {
+	if body != 0 {
+		return body
+	}
+
+	v, _ := strconv.ParseInt(r.Form.Get(name), 10, 64)
+	return v
+}
+
+func urlParamID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	return id, errors.Wrapf(err, "parsing %s", name)
+}
+
+// writeError responds with the message format used by the news app.
+func writeError(w http.ResponseWriter, message string, code int) {
+	b, _ := json.Marshal(map[string]string{"message": message})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func fatal(w http.ResponseWriter, log log.Log, format string, err error) {
+	log.Printf(format, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
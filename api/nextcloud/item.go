@@ -0,0 +1,373 @@
+package nextcloud
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/compat"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type state int
+
+const (
+	readState state = iota
+	starState
+)
+
+// The item query types.
+const (
+	feedType = iota
+	folderType
+	starredType
+	allType
+)
+
+type item struct {
+	ID             content.ArticleID `json:"id"`
+	GUID           string            `json:"guid"`
+	GUIDHash       string            `json:"guidHash"`
+	URL            string            `json:"url"`
+	Title          string            `json:"title"`
+	Author         string            `json:"author"`
+	PubDate        int64             `json:"pubDate"`
+	Body           string            `json:"body"`
+	EnclosureMime  *string           `json:"enclosureMime"`
+	EnclosureLink  *string           `json:"enclosureLink"`
+	MediaThumbnail *string           `json:"mediaThumbnail"`
+	FeedID         content.FeedID    `json:"feedId"`
+	Unread         bool              `json:"unread"`
+	Starred        bool              `json:"starred"`
+	LastModified   int64             `json:"lastModified"`
+	RTL            bool              `json:"rtl"`
+}
+
+// starredItem identifies an item by its feed and guid hash, which is the
+// string form of its id.
+type starredItem struct {
+	FeedID   int64  `json:"feedId"`
+	GUIDHash string `json:"guidHash"`
+}
+
+func itemList(service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		opts, ok, err := typeOptions(r, user, service)
+		if err != nil {
+			fatal(w, log, "Error getting item query options: %+v", err)
+			return
+		}
+
+		if !ok {
+			compat.WriteJSON(w, map[string]interface{}{"items": []item{}})
+			return
+		}
+
+		if getRead, err := strconv.ParseBool(r.Form.Get("getRead")); err == nil && !getRead {
+			opts = append(opts, content.UnreadOnly)
+		}
+
+		oldestFirst, _ := strconv.ParseBool(r.Form.Get("oldestFirst"))
+		order := content.DescendingOrder
+		if oldestFirst {
+			order = content.AscendingOrder
+		}
+		opts = append(opts, content.Sorting(content.SortByID, order))
+
+		// The offset is the id of the last item the client has, and the
+		// next batch starts after it.
+		if offset, err := strconv.ParseInt(r.Form.Get("offset"), 10, 64); err == nil && offset > 0 {
+			if oldestFirst {
+				opts = append(opts, content.IDRange(content.ArticleID(offset), 0))
+			} else {
+				opts = append(opts, content.IDRange(0, content.ArticleID(offset)))
+			}
+		}
+
+		if batchSize, err := strconv.Atoi(r.Form.Get("batchSize")); err == nil && batchSize > 0 {
+			opts = append(opts, content.Paging(batchSize, 0))
+		}
+
+		articles, err := service.ArticleRepo().ForUser(user, opts...)
+		if err != nil {
+			fatal(w, log, "Error getting items: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"items": convertArticles(articles, processors)})
+	}
+}
+
+// itemUpdatedList returns the items that were published, or whose state was
+// changed by the user, after the last modification time.
+func itemUpdatedList(service repo.Service, processors []processor.Article, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		opts, ok, err := typeOptions(r, user, service)
+		if err != nil {
+			fatal(w, log, "Error getting item query options: %+v", err)
+			return
+		}
+
+		if !ok {
+			compat.WriteJSON(w, map[string]interface{}{"items": []item{}})
+			return
+		}
+
+		lastModified, _ := strconv.ParseInt(r.Form.Get("lastModified"), 10, 64)
+		opts = append(opts,
+			content.ModifiedSince(modifiedTime(lastModified)),
+			content.Sorting(content.SortByID, content.AscendingOrder),
+		)
+
+		articles, err := service.ArticleRepo().ForUser(user, opts...)
+		if err != nil {
+			fatal(w, log, "Error getting updated items: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, map[string]interface{}{"items": convertArticles(articles, processors)})
+	}
+}
+
+func markAllRead(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		var req readRequest
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := markRead(user, intParam(req.NewestItemID, r, "newestItemId"), service.ArticleRepo()); err != nil {
+			fatal(w, log, "Error marking items as read: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// itemState changes the state of a single item. Read state changes
+// address the item by its id, while starring uses the feed id and the
+// guid hash.
+func itemState(s state, value bool, service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		var opts []content.QueryOpt
+		var id int64
+		var err error
+
+		if s == starState {
+			var feedID int64
+			if feedID, err = urlParamID(r, "feedID"); err == nil {
+				opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(feedID)}))
+				id, err = urlParamID(r, "guidHash")
+			}
+		} else {
+			id, err = urlParamID(r, "itemID")
+		}
+
+		if err != nil {
+			writeError(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		opts = append(opts, content.IDs([]content.ArticleID{content.ArticleID(id)}))
+
+		if err := setState(s, value, user, service.ArticleRepo(), opts...); err != nil {
+			fatal(w, log, "Error changing item state: %+v", err)
+			return
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+// itemsState changes the state of multiple items. The items are given as
+// ids, or for starring in v1.2, as feed ids and guid hashes.
+func itemsState(s state, value bool, service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := compat.UserFromRequest(r)
+
+		var req struct {
+			Items   json.RawMessage `json:"items"`
+			ItemIDs []int64         `json:"itemIds"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ids, err := parseItems(req.Items, req.ItemIDs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if len(ids) > 0 {
+			if err := setState(s, value, user, service.ArticleRepo(), content.IDs(ids)); err != nil {
+				fatal(w, log, "Error changing items state: %+v", err)
+				return
+			}
+		}
+
+		compat.WriteJSON(w, struct{}{})
+	}
+}
+
+func parseItems(items json.RawMessage, itemIDs []int64) ([]content.ArticleID, error) {
+	ids := make([]content.ArticleID, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		ids = append(ids, content.ArticleID(id))
+	}
+
+	if len(items) == 0 {
+		return ids, nil
+	}
+
+	var plain []int64
+	if err := json.Unmarshal(items, &plain); err == nil {
+		for _, id := range plain {
+			ids = append(ids, content.ArticleID(id))
+		}
+
+		return ids, nil
+	}
+
+	var starred []starredItem
+	if err := json.Unmarshal(items, &starred); err != nil {
+		return nil, errors.Wrap(err, "parsing items")
+	}
+
+	for _, s := range starred {
+		id, err := strconv.ParseInt(s.GUIDHash, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing guid hash %s", s.GUIDHash)
+		}
+
+		ids = append(ids, content.ArticleID(id))
+	}
+
+	return ids, nil
+}
+
+func setState(s state, value bool, user content.User, repo repo.Article, opts ...content.QueryOpt) error {
+	if s == starState {
+		return errors.WithMessage(repo.Favor(value, user, opts...), "changing starred state")
+	}
+
+	return errors.WithMessage(repo.Read(value, user, opts...), "changing read state")
+}
+
+// typeOptions converts the type and id parameters to article query
+// options. A false result means that the query is known to be empty.
+func typeOptions(r *http.Request, user content.User, service repo.Service) ([]content.QueryOpt, bool, error) {
+	opts := []content.QueryOpt{content.Filters(content.GetUserFilters(user))}
+
+	id, _ := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+
+	typ, err := strconv.Atoi(r.Form.Get("type"))
+	if err != nil {
+		typ = allType
+	}
+
+	switch typ {
+	case feedType:
+		opts = append(opts, content.FeedIDs([]content.FeedID{content.FeedID(id)}))
+	case folderType:
+		// The root folder holds the feeds without tags.
+		if id == 0 {
+			return append(opts, content.UntaggedOnly), true, nil
+		}
+
+		ids, err := folderFeedIDs(content.TagID(id), user, service.TagRepo())
+		if err != nil {
+			if content.IsNoContent(err) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+
+		return append(opts, content.FeedIDs(ids)), len(ids) > 0, nil
+	case starredType:
+		opts = append(opts, content.FavoriteOnly)
+	}
+
+	return opts, true, nil
+}
+
+// modifiedTime converts the last modification time, which newer clients
+// send in microseconds instead of seconds.
+func modifiedTime(ts int64) time.Time {
+	if ts > 1e13 {
+		return time.Unix(0, ts*int64(time.Microsecond))
+	}
+
+	return time.Unix(ts, 0)
+}
+
+// itemModified returns the time the article was published, or the time the
+// user last changed its state, whichever is later.
+func itemModified(a content.Article) time.Time {
+	if a.StateChanged.Valid && a.StateChanged.Time.After(a.Date) {
+		return a.StateChanged.Time
+	}
+
+	return a.Date
+}
+
+func convertArticles(articles []content.Article, processors []processor.Article) []item {
+	items := make([]item, 0, len(articles))
+	if len(articles) == 0 {
+		return items
+	}
+
+	articles = processor.Articles(processors).Process(articles)
+
+	for _, a := range articles {
+		guid := a.Guid.String
+		if guid == "" {
+			guid = a.Link
+		}
+
+		i := item{
+			ID:           a.ID,
+			GUID:         guid,
+			GUIDHash:     strconv.FormatInt(int64(a.ID), 10),
+			URL:          a.Link,
+			Title:        a.Title,
+			Author:       a.Author,
+			PubDate:      a.Date.Unix(),
+			Body:         a.Description,
+			FeedID:       a.FeedID,
+			Unread:       !a.Read,
+			Starred:      a.Favorite,
+			LastModified: itemModified(a).Unix(),
+		}
+
+		if len(a.Enclosures) > 0 {
+			i.EnclosureLink = &a.Enclosures[0].URL
+			i.EnclosureMime = &a.Enclosures[0].Type
+		}
+
+		if a.ThumbnailLink != "" {
+			thumbnail := a.ThumbnailLink
+			i.MediaThumbnail = &thumbnail
+		}
+
+		items = append(items, i)
+	}
+
+	return items
+}
@@ -0,0 +1,1331 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/feed"
+)
+
+// openAPIDocument is the subset of the OpenAPI 3 specification used to
+// describe the api.
+type openAPIDocument struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       openAPIInfo            `json:"info"`
+	Servers    []openAPIServer        `json:"servers"`
+	Paths      map[string]openAPIPath `json:"paths"`
+	Components openAPIComponents      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+// openAPIPath maps the lowercase http methods to their operations.
+type openAPIPath map[string]*openAPIOperation
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas         openAPISchemas                   `json:"schemas"`
+	Responses       map[string]openAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// openAPISchema is a schema object. An empty schema matches any value.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+const (
+	bearerAuth      = "bearerAuth"
+	errorResponse   = "Error"
+	componentPrefix = "#/components/schemas/"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPISchemas holds the component schemas, generated from the json
+// encoding of the go types.
+type openAPISchemas map[string]*openAPISchema
+
+// of returns the schema of the json encoding of v. Named structs are added
+// as components, and referenced.
+func (s openAPISchemas) of(v interface{}) *openAPISchema {
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+func (s openAPISchemas) typeSchema(t reflect.Type) *openAPISchema {
+	if t == timeType {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte", Nullable: t.Kind() == reflect.Slice}
+		}
+
+		return &openAPISchema{Type: "array", Items: s.typeSchema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: s.typeSchema(t.Elem()), Nullable: true}
+	case reflect.Ptr:
+		schema := s.typeSchema(t.Elem())
+		if schema.Ref != "" {
+			return &openAPISchema{AllOf: []*openAPISchema{schema}, Nullable: true}
+		}
+
+		schema.Nullable = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+
+		name := componentName(t)
+		if _, ok := s[name]; !ok {
+			// Reserve the name first, for recursive types.
+			s[name] = &openAPISchema{}
+			*s[name] = *s.structSchema(t)
+		}
+
+		return &openAPISchema{Ref: componentPrefix + name}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// structSchema describes the fields of a struct, as encoded by
+// encoding/json. Fields without omitempty are required, since they are
+// always present.
+func (s openAPISchemas) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := s.structSchema(f.Type)
+			for k, v := range embedded.Properties {
+				schema.Properties[k] = v
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		omitEmpty := false
+		for _, p := range parts[1:] {
+			if p == "omitempty" {
+				omitEmpty = true
+			}
+		}
+
+		prop := s.typeSchema(f.Type)
+		if omitEmpty {
+			// Empty values are left out, instead of being null.
+			prop.Nullable = false
+			if len(prop.AllOf) == 1 {
+				prop = prop.AllOf[0]
+			}
+		} else {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = prop
+	}
+
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+// componentName names the component schemas after their types. Types
+// outside of the content and api packages are prefixed with their package.
+func componentName(t reflect.Type) string {
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i != -1 {
+		pkg = pkg[i+1:]
+	}
+
+	if pkg != "content" && pkg != "api" {
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	return name
+}
+
+func object(props ...property) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+
+	for _, p := range props {
+		schema.Properties[p.name] = p.schema
+		if !p.optional {
+			schema.Required = append(schema.Required, p.name)
+		}
+	}
+
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+func array(items *openAPISchema) *openAPISchema {
+	return &openAPISchema{Type: "array", Items: items}
+}
+
+func stringSchema() *openAPISchema {
+	return &openAPISchema{Type: "string"}
+}
+
+func integerSchema() *openAPISchema {
+	return &openAPISchema{Type: "integer", Format: "int64"}
+}
+
+func numberSchema() *openAPISchema {
+	return &openAPISchema{Type: "number", Format: "double"}
+}
+
+func booleanSchema() *openAPISchema {
+	return &openAPISchema{Type: "boolean"}
+}
+
+func enumSchema(values ...string) *openAPISchema {
+	return &openAPISchema{Type: "string", Enum: values}
+}
+
+// property is a property of an inline object schema.
+type property struct {
+	name     string
+	schema   *openAPISchema
+	optional bool
+}
+
+// value is a request value. It is a query parameter for GET and DELETE
+// requests, and a form field otherwise, though the handlers read both.
+type value struct {
+	name        string
+	description string
+	schema      *openAPISchema
+	required    bool
+}
+
+// flag is a value whose presence alone enables an option.
+func flag(name, description string) value {
+	return value{name: name, description: description + ". Only its presence is checked", schema: booleanSchema()}
+}
+
+// endpoint describes a single route, and is converted to an operation of
+// the document.
+type endpoint struct {
+	method  string
+	path    string
+	id      string
+	tag     string
+	summary string
+	public  bool
+	admin   bool
+	values  []value
+	headers []openAPIParameter
+	// response is the schema of the json response. Other kinds of responses
+	// are given with responses.
+	response  *openAPISchema
+	responses map[string]openAPIResponse
+}
+
+// openAPISpec describes the api as served by the router built from the
+// same features. The routes that depend on a feature, or on the hubbub
+// callback, are only described when they are available.
+func openAPISpec(features features, hubbub bool, version int) openAPIDocument {
+	s := openAPISchemas{}
+
+	endpoints := publicEndpoints(hubbub)
+	endpoints = append(endpoints, feedEndpoints(s)...)
+	endpoints = append(endpoints, tagEndpoints(s)...)
+	endpoints = append(endpoints, labelEndpoints(s)...)
+	endpoints = append(endpoints, annotationEndpoints(s)...)
+	endpoints = append(endpoints, articleEndpoints(s, features)...)
+	endpoints = append(endpoints, opmlEndpoints(s)...)
+	endpoints = append(endpoints, eventEndpoints()...)
+	endpoints = append(endpoints, userEndpoints(s)...)
+	endpoints = append(endpoints, webhookEndpoints(s)...)
+	endpoints = append(endpoints, ruleEndpoints(s)...)
+	endpoints = append(endpoints, outputEndpoints(s)...)
+
+	endpoints = append(endpoints, endpoint{
+		method: "GET", path: "/features", id: "getFeatures", tag: "features",
+		summary:  "Lists the optional features enabled on the server",
+		response: object(property{name: "features", schema: s.of(features)}),
+	})
+
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "readeef",
+			Description: "The readeef web api. Request values are read from both the query and the url encoded form.",
+			Version:     strconv.Itoa(version),
+		},
+		Servers: []openAPIServer{{URL: "/v2"}},
+		Paths:   map[string]openAPIPath{},
+		Components: openAPIComponents{
+			Schemas: s,
+			Responses: map[string]openAPIResponse{
+				errorResponse: {
+					Description: "The request has failed",
+					Content:     map[string]openAPIMediaType{"text/plain": {Schema: stringSchema()}},
+				},
+			},
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, e := range endpoints {
+		path, ok := doc.Paths[e.path]
+		if !ok {
+			path = openAPIPath{}
+			doc.Paths[e.path] = path
+		}
+
+		path[strings.ToLower(e.method)] = e.operation()
+	}
+
+	return doc
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+func (e endpoint) operation() *openAPIOperation {
+	op := &openAPIOperation{
+		OperationID: e.id,
+		Summary:     e.summary,
+		Tags:        []string{e.tag},
+		Responses:   map[string]openAPIResponse{"default": {Ref: "#/components/responses/" + errorResponse}},
+	}
+
+	if !e.public {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	if e.admin {
+		op.Description = "Requires an admin user."
+	}
+
+	for _, m := range pathParamPattern.FindAllStringSubmatch(e.path, -1) {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name: m[1], In: "path", Required: true, Schema: pathParamSchema(m[1]),
+		})
+	}
+
+	op.Parameters = append(op.Parameters, e.headers...)
+
+	if e.method == "GET" || e.method == "DELETE" {
+		for _, v := range e.values {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: v.name, In: "query", Description: v.description, Required: v.required, Schema: v.schema,
+			})
+		}
+	} else if len(e.values) > 0 {
+		form := object()
+		required := false
+		for _, v := range e.values {
+			schema := *v.schema
+			if v.description != "" {
+				schema.Description = v.description
+			}
+
+			form.Properties[v.name] = &schema
+			if v.required {
+				form.Required = append(form.Required, v.name)
+				required = true
+			}
+		}
+
+		op.RequestBody = &openAPIRequestBody{
+			Required: required,
+			Content:  map[string]openAPIMediaType{"application/x-www-form-urlencoded": {Schema: form}},
+		}
+	}
+
+	if e.response != nil {
+		op.Responses["200"] = openAPIResponse{
+			Description: "Success",
+			Content:     map[string]openAPIMediaType{"application/json": {Schema: e.response}},
+		}
+	}
+
+	for code, r := range e.responses {
+		op.Responses[code] = r
+	}
+
+	return op
+}
+
+// pathParamSchema matches the patterns of the route parameters.
+func pathParamSchema(name string) *openAPISchema {
+	switch name {
+	case "feedID", "tagID", "articleID", "webhookID", "annotationID", "ruleID":
+		return integerSchema()
+	case "token":
+		return &openAPISchema{Type: "string", Pattern: "^[0-9a-f]+$"}
+	case "format":
+		return enumSchema(outputFormats...)
+	case "key":
+		return enumSchema(settingKeys...)
+	default:
+		return stringSchema()
+	}
+}
+
+func successResponse(props ...property) *openAPISchema {
+	return object(append([]property{{name: "success", schema: booleanSchema()}}, props...)...)
+}
+
+func textResponse(description string) map[string]openAPIResponse {
+	return map[string]openAPIResponse{"200": {
+		Description: description,
+		Content:     map[string]openAPIMediaType{"text/plain": {Schema: stringSchema()}},
+	}}
+}
+
+func tokenResponse() map[string]openAPIResponse {
+	return map[string]openAPIResponse{"200": {
+		Description: "The new json web token",
+		Headers: map[string]openAPIHeader{
+			"Authorization": {Description: "The token, as a bearer authorization value", Schema: stringSchema()},
+		},
+		Content: map[string]openAPIMediaType{"text/plain": {Schema: stringSchema()}},
+	}}
+}
+
+func publicEndpoints(hubbub bool) []endpoint {
+	outputContent := map[string]openAPIMediaType{}
+	for _, format := range outputFormats {
+		outputContent[outputMIMETypes[format]] = openAPIMediaType{Schema: stringSchema()}
+	}
+
+	endpoints := []endpoint{
+		{
+			method: "GET", path: "/openapi.json", id: "getOpenAPI", tag: "api", public: true,
+			summary:  "Returns this document",
+			response: &openAPISchema{Type: "object"},
+		},
+		{
+			method: "POST", path: "/token", id: "createToken", tag: "auth", public: true,
+			summary: "Creates a token, using the user credentials",
+			values: []value{
+				{name: "user", schema: stringSchema(), required: true},
+				{name: "password", schema: stringSchema(), required: true},
+			},
+			responses: tokenResponse(),
+		},
+		{
+			method: "DELETE", path: "/token", id: "deleteToken", tag: "auth",
+			summary:   "Invalidates the token of the request",
+			responses: map[string]openAPIResponse{"200": {Description: "The token is invalidated"}},
+		},
+		{
+			method: "GET", path: "/out/{token}.{format}", id: "getOutputFeed", tag: "output", public: true,
+			summary:   "Serves a published output feed",
+			responses: map[string]openAPIResponse{"200": {Description: "The rendered feed", Content: outputContent}},
+		},
+	}
+
+	if hubbub {
+		values := []value{
+			{name: "hub.mode", schema: enumSchema("subscribe", "unsubscribe", "denied")},
+			{name: "hub.topic", schema: stringSchema()},
+			{name: "hub.challenge", schema: stringSchema()},
+			{name: "hub.lease_seconds", schema: integerSchema()},
+			{name: "hub.reason", schema: stringSchema()},
+		}
+
+		for _, method := range []string{"GET", "POST"} {
+			endpoints = append(endpoints, endpoint{
+				method: method, path: "/hubbub", id: strings.ToLower(method) + "Hubbub", tag: "hubbub", public: true,
+				summary:   "Receives the PubSubHubbub verifications and content notifications",
+				values:    values,
+				responses: textResponse("The verification challenge"),
+			})
+		}
+	}
+
+	return endpoints
+}
+
+func feedAuthValues() []value {
+	return []value{
+		{name: "username", schema: stringSchema()},
+		{name: "password", schema: stringSchema()},
+		{name: "token", description: "Sent as a bearer token", schema: stringSchema()},
+		{name: "userAgent", schema: stringSchema()},
+		{name: "cookieJar", schema: booleanSchema()},
+		{name: "header", description: "Given as 'Name: value'", schema: array(stringSchema())},
+		{name: "cookie", description: "Given as 'name=value'", schema: array(stringSchema())},
+	}
+}
+
+func scraperValues() []value {
+	return []value{
+		{name: "item", schema: stringSchema(), required: true},
+		{name: "title", schema: stringSchema(), required: true},
+		{name: "itemLink", schema: stringSchema()},
+		{name: "date", schema: stringSchema()},
+		{name: "content", schema: stringSchema()},
+	}
+}
+
+func feedEndpoints(s openAPISchemas) []endpoint {
+	feeds := s.of([]content.Feed{})
+	extract := successResponse(
+		property{name: "extractContent", schema: booleanSchema()},
+		property{name: "userExtractContent", schema: booleanSchema()},
+	)
+	retention := successResponse(
+		property{name: "retention", schema: s.of(content.Retention{})},
+		property{name: "userRetention", schema: s.of(content.Retention{})},
+	)
+	retentionValues := []value{
+		{name: "maxArticles", description: "The number of newest articles to keep", schema: integerSchema()},
+		{name: "maxAge", description: "A duration, such as 720h, after which articles are purged", schema: stringSchema()},
+	}
+	scraperFeed := append([]value{{name: "link", schema: stringSchema(), required: true}}, scraperValues()...)
+	scraperFeed = append(scraperFeed, feedAuthValues()...)
+
+	return []endpoint{
+		{
+			method: "GET", path: "/feed", id: "listFeeds", tag: "feed",
+			summary:  "Lists the user's feeds",
+			response: object(property{name: "feeds", schema: feeds}),
+		},
+		{
+			method: "POST", path: "/feed", id: "addFeed", tag: "feed",
+			summary: "Adds feeds to the user's subscriptions",
+			values: append([]value{
+				{name: "link", description: "A url fragment holds comma separated tags", schema: array(stringSchema()), required: true},
+			}, feedAuthValues()...),
+			response: successResponse(
+				property{name: "errors", schema: s.of([]addFeedError{})},
+				property{name: "feeds", schema: s.of(map[string]content.Feed{})},
+			),
+		},
+		{
+			method: "GET", path: "/feed/discover", id: "discoverFeeds", tag: "feed",
+			summary:  "Discovers the feeds of a url, to which the user is not subscribed",
+			values:   []value{{name: "query", schema: stringSchema(), required: true}},
+			response: object(property{name: "feeds", schema: feeds}),
+		},
+		{
+			method: "GET", path: "/feed/scheduler", id: "getSchedulerStats", tag: "feed", admin: true,
+			summary:  "Returns the feed scheduler statistics",
+			response: object(property{name: "stats", schema: s.of(feed.Stats{})}),
+		},
+		{
+			method: "POST", path: "/feed/scraper", id: "addScraperFeed", tag: "feed",
+			summary: "Adds a feed generated from an html page",
+			values:  scraperFeed,
+			response: successResponse(
+				property{name: "feed", schema: s.of(content.Feed{}), optional: true},
+				property{name: "error", schema: s.of(addFeedError{}), optional: true},
+			),
+		},
+		{
+			method: "POST", path: "/feed/scraper/preview", id: "previewScraper", tag: "feed",
+			summary: "Previews the feed generated from an html page",
+			values:  scraperFeed,
+			response: successResponse(
+				property{name: "feed", schema: s.of(content.Feed{}), optional: true},
+				property{name: "articles", schema: s.of([]content.Article{}), optional: true},
+				property{name: "error", schema: stringSchema(), optional: true},
+			),
+		},
+		{
+			method: "DELETE", path: "/feed/{feedID}", id: "deleteFeed", tag: "feed",
+			summary:  "Removes the feed from the user's subscriptions",
+			response: successResponse(),
+		},
+		{
+			method: "GET", path: "/feed/{feedID}/tags", id: "getFeedTags", tag: "feed",
+			summary:  "Lists the tags of the feed",
+			response: object(property{name: "tags", schema: array(stringSchema())}),
+		},
+		{
+			method: "PUT", path: "/feed/{feedID}/tags", id: "setFeedTags", tag: "feed",
+			summary: "Replaces the tags of the feed",
+			values: []value{
+				{name: "tag", description: "A tag value", schema: array(stringSchema())},
+				{name: "id", description: "An existing tag id", schema: array(integerSchema())},
+			},
+			response: successResponse(),
+		},
+		{
+			method: "POST", path: "/feed/{feedID}/extract", id: "enableFeedExtract", tag: "feed",
+			summary:  "Enables the content extraction of the feed's articles for the user",
+			response: extract,
+		},
+		{
+			method: "DELETE", path: "/feed/{feedID}/extract", id: "disableFeedExtract", tag: "feed",
+			summary:  "Disables the content extraction of the feed's articles for the user",
+			response: extract,
+		},
+		{
+			method: "POST", path: "/feed/{feedID}/extract/feed", id: "enableGlobalFeedExtract", tag: "feed", admin: true,
+			summary:  "Enables the content extraction of the feed's articles for all users",
+			response: extract,
+		},
+		{
+			method: "DELETE", path: "/feed/{feedID}/extract/feed", id: "disableGlobalFeedExtract", tag: "feed", admin: true,
+			summary:  "Disables the content extraction of the feed's articles for all users",
+			response: extract,
+		},
+		{
+			method: "PUT", path: "/feed/{feedID}/retention", id: "setFeedRetention", tag: "feed",
+			summary:  "Sets the article retention of the user's subscription to the feed",
+			values:   retentionValues,
+			response: retention,
+		},
+		{
+			method: "DELETE", path: "/feed/{feedID}/retention", id: "clearFeedRetention", tag: "feed",
+			summary:  "Clears the article retention of the user's subscription to the feed",
+			response: retention,
+		},
+		{
+			method: "PUT", path: "/feed/{feedID}/retention/feed", id: "setGlobalFeedRetention", tag: "feed", admin: true,
+			summary:  "Sets the article retention of the feed for all users",
+			values:   retentionValues,
+			response: retention,
+		},
+		{
+			method: "DELETE", path: "/feed/{feedID}/retention/feed", id: "clearGlobalFeedRetention", tag: "feed", admin: true,
+			summary:  "Clears the article retention of the feed for all users",
+			response: retention,
+		},
+		{
+			method: "POST", path: "/feed/{feedID}/refresh", id: "refreshFeed", tag: "feed",
+			summary: "Downloads the feed",
+			response: successResponse(
+				property{name: "newArticles", schema: integerSchema(), optional: true},
+				property{name: "error", schema: stringSchema(), optional: true},
+			),
+		},
+		{
+			method: "PUT", path: "/feed/{feedID}/scraper", id: "setFeedScraper", tag: "feed",
+			summary: "Changes the selectors of a scraper feed",
+			values:  scraperValues(),
+			response: successResponse(
+				property{name: "feed", schema: s.of(content.Feed{}), optional: true},
+				property{name: "error", schema: stringSchema(), optional: true},
+			),
+		},
+	}
+}
+
+func tagEndpoints(s openAPISchemas) []endpoint {
+	return []endpoint{
+		{
+			method: "GET", path: "/tag", id: "listTags", tag: "tag",
+			summary:  "Lists the user's tags",
+			response: object(property{name: "tags", schema: s.of([]content.Tag{})}),
+		},
+		{
+			method: "GET", path: "/tag/feedIDs", id: "getTagsFeedIDs", tag: "tag",
+			summary:  "Lists the user's tags, along with their feed ids",
+			response: object(property{name: "tagFeeds", schema: s.of([]tagsFeedIDs{})}),
+		},
+		{
+			method: "GET", path: "/tag/{tagID}/feedIDs", id: "getTagFeedIDs", tag: "tag",
+			summary:  "Lists the feed ids of the tag",
+			response: object(property{name: "feedIDs", schema: s.of([]content.FeedID{})}),
+		},
+	}
+}
+
+func labelEndpoints(s openAPISchemas) []endpoint {
+	label := s.of(content.Label{})
+	values := []value{
+		{name: "caption", schema: stringSchema()},
+		{name: "fgColor", description: "A hex color, such as #fff", schema: stringSchema()},
+		{name: "bgColor", description: "A hex color, such as #000", schema: stringSchema()},
+	}
+	articles := []value{{name: "id", description: "An article id", schema: array(integerSchema()), required: true}}
+
+	return []endpoint{
+		{
+			method: "GET", path: "/label", id: "listLabels", tag: "label",
+			summary:  "Lists the user's labels",
+			response: object(property{name: "labels", schema: s.of([]content.Label{})}),
+		},
+		{
+			method: "POST", path: "/label", id: "addLabel", tag: "label",
+			summary:  "Adds a label",
+			values:   values,
+			response: successResponse(property{name: "label", schema: label}),
+		},
+		{
+			method: "GET", path: "/label/{labelID}", id: "getLabel", tag: "label",
+			summary:  "Returns a label",
+			response: object(property{name: "label", schema: label}),
+		},
+		{
+			method: "PUT", path: "/label/{labelID}", id: "updateLabel", tag: "label",
+			summary:  "Renames or recolors a label. Missing values are left unchanged",
+			values:   values,
+			response: successResponse(property{name: "label", schema: label}),
+		},
+		{
+			method: "DELETE", path: "/label/{labelID}", id: "deleteLabel", tag: "label",
+			summary:  "Deletes a label",
+			response: successResponse(),
+		},
+		{
+			method: "POST", path: "/label/{labelID}/articles", id: "assignLabel", tag: "label",
+			summary:  "Labels the user's articles",
+			values:   articles,
+			response: successResponse(property{name: "ids", schema: s.of([]content.ArticleID{})}),
+		},
+		{
+			method: "DELETE", path: "/label/{labelID}/articles", id: "unassignLabel", tag: "label",
+			summary:  "Removes the label from the user's articles",
+			values:   articles,
+			response: successResponse(property{name: "ids", schema: s.of([]content.ArticleID{})}),
+		},
+	}
+}
+
+func annotationEndpoints(s openAPISchemas) []endpoint {
+	annotation := s.of(content.Annotation{})
+	values := []value{
+		{name: "note", schema: stringSchema()},
+		{name: "quote", description: "The highlighted text", schema: stringSchema()},
+		{name: "start", description: "The start offset of the highlighted range", schema: integerSchema()},
+		{name: "end", description: "The end offset of the highlighted range", schema: integerSchema()},
+	}
+	filter := []value{
+		{name: "feedID", schema: integerSchema()},
+		{name: "tagID", schema: integerSchema()},
+		{name: "afterTime", description: "Seconds since the epoch", schema: integerSchema()},
+		{name: "beforeTime", description: "Seconds since the epoch", schema: integerSchema()},
+	}
+
+	return []endpoint{
+		{
+			method: "GET", path: "/annotation", id: "listAnnotations", tag: "annotation",
+			summary:  "Lists the user's annotations, ordered by their creation time",
+			values:   filter,
+			response: object(property{name: "annotations", schema: s.of([]content.Annotation{})}),
+		},
+		{
+			method: "GET", path: "/annotation/export", id: "exportAnnotations", tag: "annotation",
+			summary: "Exports the user's annotations as markdown, grouped by article",
+			values:  filter,
+			responses: map[string]openAPIResponse{"200": {
+				Description: "The markdown document",
+				Content:     map[string]openAPIMediaType{"text/markdown": {Schema: stringSchema()}},
+			}},
+		},
+		{
+			method: "GET", path: "/article/{articleID}/annotations", id: "getArticleAnnotations", tag: "annotation",
+			summary:  "Lists the user's annotations of the article",
+			response: object(property{name: "annotations", schema: s.of([]content.Annotation{})}),
+		},
+		{
+			method: "POST", path: "/article/{articleID}/annotations", id: "addAnnotation", tag: "annotation",
+			summary:  "Adds a note or a highlight to the article",
+			values:   values,
+			response: successResponse(property{name: "annotation", schema: annotation}),
+		},
+		{
+			method: "PUT", path: "/article/{articleID}/annotations/{annotationID}", id: "updateAnnotation", tag: "annotation",
+			summary:  "Updates an annotation. Missing values are left unchanged",
+			values:   values,
+			response: successResponse(property{name: "annotation", schema: annotation}),
+		},
+		{
+			method: "DELETE", path: "/article/{articleID}/annotations/{annotationID}", id: "deleteAnnotation", tag: "annotation",
+			summary:  "Deletes an annotation",
+			response: successResponse(),
+		},
+	}
+}
+
+func articleQueryValues() []value {
+	return []value{
+		{name: "limit", description: "Capped by the configured limit", schema: integerSchema()},
+		{name: "afterID", schema: integerSchema()},
+		{name: "beforeID", schema: integerSchema()},
+		{name: "afterTime", description: "Seconds since the epoch", schema: integerSchema()},
+		{name: "beforeTime", description: "Seconds since the epoch", schema: integerSchema()},
+		{name: "afterScore", schema: integerSchema()},
+		{name: "beforeScore", schema: integerSchema()},
+		{name: "id", schema: array(integerSchema())},
+		flag("unreadOnly", "Only unread articles"),
+		flag("readOnly", "Only read articles"),
+		flag("unreadFirst", "Unread articles first"),
+		flag("olderFirst", "Older articles first"),
+	}
+}
+
+// articleSets are the article routes that list a set of the user's
+// articles, and their state changes.
+var articleSets = []struct {
+	path  string
+	id    string
+	name  string
+	state bool
+}{
+	{path: "/article", id: "", name: "the user's articles", state: true},
+	{path: "/article/favorite", id: "Favorite", name: "the favorite articles", state: true},
+	{path: "/article/queue", id: "Queued", name: "the queued articles, in their queue order", state: true},
+	{path: "/article/feed/{feedID}", id: "Feed", name: "the articles of a feed", state: true},
+	{path: "/article/tag/{tagID}", id: "Tag", name: "the articles of tagged feeds", state: true},
+	{path: "/article/label/{labelID}", id: "Label", name: "the labeled articles", state: true},
+	{path: "/article/popular", id: "Popular", name: "the popular articles"},
+	{path: "/article/popular/feed/{feedID}", id: "PopularFeed", name: "the popular articles of a feed"},
+	{path: "/article/popular/tag/{tagID}", id: "PopularTag", name: "the popular articles of tagged feeds"},
+}
+
+func articleEndpoints(s openAPISchemas, features features) []endpoint {
+	articles := object(property{name: "articles", schema: array(s.of(content.Article{}))})
+	ids := object(property{name: "ids", schema: array(s.of(content.ArticleID(0)))})
+
+	var endpoints []endpoint
+	for _, set := range articleSets {
+		endpoints = append(endpoints,
+			endpoint{
+				method: "GET", path: set.path, id: "get" + set.id + "Articles", tag: "article",
+				summary:  "Lists " + set.name,
+				values:   articleQueryValues(),
+				response: articles,
+			},
+			endpoint{
+				method: "GET", path: set.path + "/ids", id: "get" + set.id + "ArticleIDs", tag: "article",
+				summary:  "Lists the ids of " + set.name,
+				values:   articleQueryValues(),
+				response: ids,
+			},
+		)
+
+		if set.state {
+			endpoints = append(endpoints,
+				endpoint{
+					method: "POST", path: set.path + "/read", id: "read" + set.id + "Articles", tag: "article",
+					summary:  "Marks " + set.name + " as read",
+					values:   articleQueryValues(),
+					response: successResponse(),
+				},
+				endpoint{
+					method: "DELETE", path: set.path + "/read", id: "unread" + set.id + "Articles", tag: "article",
+					summary:  "Marks " + set.name + " as unread",
+					values:   articleQueryValues(),
+					response: successResponse(),
+				},
+			)
+		}
+	}
+
+	endpoints = append(endpoints,
+		endpoint{
+			method: "POST", path: "/article/favorite", id: "favorArticles", tag: "article",
+			summary:  "Marks the user's articles as favorite",
+			values:   articleQueryValues(),
+			response: successResponse(),
+		},
+		endpoint{
+			method: "DELETE", path: "/article/favorite", id: "unfavorArticles", tag: "article",
+			summary:  "Removes the user's articles from the favorites",
+			values:   articleQueryValues(),
+			response: successResponse(),
+		},
+		endpoint{
+			method: "POST", path: "/article/queue", id: "queueArticles", tag: "article",
+			summary:  "Adds the articles with the given ids to the end of the read-later queue",
+			values:   articleQueryValues(),
+			response: successResponse(),
+		},
+		endpoint{
+			method: "DELETE", path: "/article/queue", id: "dequeueArticles", tag: "article",
+			summary:  "Removes the user's articles from the read-later queue",
+			values:   articleQueryValues(),
+			response: successResponse(),
+		},
+		endpoint{
+			method: "PUT", path: "/article/queue/order", id: "reorderQueue", tag: "article",
+			summary: "Moves the queued articles to the front of the queue, in the given order",
+			values: []value{
+				{name: "id", description: "An article id", schema: array(integerSchema()), required: true},
+			},
+			response: successResponse(),
+		},
+		endpoint{
+			method: "GET", path: "/article/{articleID}", id: "getArticle", tag: "article",
+			summary: "Returns an article, along with the user's annotations of it",
+			response: object(
+				property{name: "article", schema: s.of(content.Article{})},
+				property{name: "annotations", schema: s.of([]content.Annotation{})},
+			),
+		},
+		endpoint{
+			method: "POST", path: "/article/{articleID}/read", id: "readArticle", tag: "article",
+			summary:  "Marks the article as read",
+			response: successResponse(property{name: "read", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "DELETE", path: "/article/{articleID}/read", id: "unreadArticle", tag: "article",
+			summary:  "Marks the article as unread",
+			response: successResponse(property{name: "read", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "POST", path: "/article/{articleID}/favorite", id: "favorArticle", tag: "article",
+			summary:  "Marks the article as favorite",
+			response: successResponse(property{name: "favorite", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "DELETE", path: "/article/{articleID}/favorite", id: "unfavorArticle", tag: "article",
+			summary:  "Removes the article from the favorites",
+			response: successResponse(property{name: "favorite", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "POST", path: "/article/{articleID}/queue", id: "queueArticle", tag: "article",
+			summary:  "Adds the article to the end of the read-later queue",
+			response: successResponse(property{name: "queued", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "DELETE", path: "/article/{articleID}/queue", id: "dequeueArticle", tag: "article",
+			summary:  "Removes the article from the read-later queue",
+			response: successResponse(property{name: "queued", schema: booleanSchema()}),
+		},
+		endpoint{
+			method: "GET", path: "/article/{articleID}/playback", id: "getPlayback", tag: "article",
+			summary:  "Returns the playback position of the article's media",
+			response: object(property{name: "playback", schema: s.of(content.Playback{})}),
+		},
+		endpoint{
+			method: "PUT", path: "/article/{articleID}/playback", id: "updatePlayback", tag: "article",
+			summary:  "Stores the playback position of the article's media",
+			values:   []value{{name: "position", description: "The position in seconds", schema: numberSchema(), required: true}},
+			response: successResponse(property{name: "playback", schema: s.of(content.Playback{})}),
+		},
+		endpoint{
+			method: "GET", path: "/article/{articleID}/labels", id: "getArticleLabels", tag: "article",
+			summary:  "Lists the user's labels of the article",
+			response: object(property{name: "labels", schema: s.of([]content.Label{})}),
+		},
+	)
+
+	if features.Search {
+		search := append([]value{{name: "query", schema: stringSchema(), required: true}}, articleQueryValues()...)
+
+		endpoints = append(endpoints,
+			endpoint{
+				method: "GET", path: "/article/search", id: "searchArticles", tag: "article",
+				summary: "Searches the user's articles", values: search, response: articles,
+			},
+			endpoint{
+				method: "GET", path: "/article/search/feed/{feedID}", id: "searchFeedArticles", tag: "article",
+				summary: "Searches the articles of a feed", values: search, response: articles,
+			},
+			endpoint{
+				method: "GET", path: "/article/search/tag/{tagID}", id: "searchTagArticles", tag: "article",
+				summary: "Searches the articles of tagged feeds", values: search, response: articles,
+			},
+		)
+	}
+
+	if features.Extractor {
+		endpoints = append(endpoints, endpoint{
+			method: "GET", path: "/article/{articleID}/format", id: "formatArticle", tag: "article",
+			summary: "Returns the extracted content of the article, along with its key points",
+			response: object(
+				property{name: "keyPoints", schema: &openAPISchema{Type: "array", Items: stringSchema(), Nullable: true}},
+				property{name: "content", schema: stringSchema()},
+				property{name: "topImage", schema: stringSchema()},
+			),
+		})
+	}
+
+	return endpoints
+}
+
+func opmlEndpoints(s openAPISchemas) []endpoint {
+	return []endpoint{
+		{
+			method: "GET", path: "/opml", id: "exportOPML", tag: "opml",
+			summary:  "Exports the user's feeds as OPML",
+			response: object(property{name: "opml", schema: stringSchema()}),
+		},
+		{
+			method: "POST", path: "/opml", id: "importOPML", tag: "opml",
+			summary: "Imports the feeds of an OPML document",
+			values: []value{
+				{name: "opml", schema: stringSchema(), required: true},
+				flag("dryRun", "Only discover the feeds, without adding them"),
+			},
+			response: object(
+				property{name: "feeds", schema: s.of([]content.Feed{})},
+				property{name: "skipped", schema: s.of([]string{})},
+			),
+		},
+	}
+}
+
+func eventEndpoints() []endpoint {
+	return []endpoint{
+		{
+			method: "GET", path: "/events", id: "getEvents", tag: "events",
+			summary: "Streams the user's events as server-sent events",
+			values: []value{
+				{name: "lastEventID", description: "Used when the Last-Event-ID header is missing", schema: integerSchema()},
+			},
+			headers: []openAPIParameter{{
+				Name: "Last-Event-ID", In: "header", Description: "Resumes the stream after the given event", Schema: integerSchema(),
+			}},
+			responses: map[string]openAPIResponse{"200": {
+				Description: "The event stream",
+				Content:     map[string]openAPIMediaType{"text/event-stream": {Schema: stringSchema()}},
+			}},
+		},
+		{
+			method: "GET", path: "/events/ws", id: "getEventSocket", tag: "events",
+			summary: "Streams the user's events over a websocket",
+			values: []value{
+				{name: "lastEventID", description: "Replays the events after the given one", schema: integerSchema()},
+				{name: "client", description: "Resumes after the last event acknowledged by the client", schema: stringSchema()},
+			},
+			responses: map[string]openAPIResponse{"101": {Description: "The websocket connection"}},
+		},
+	}
+}
+
+func settingValues() []value {
+	return []value{
+		{name: "value", schema: stringSchema()},
+		{name: "current", description: "The current password, required to change the password and api key", schema: stringSchema()},
+	}
+}
+
+func userEndpoints(s openAPISchemas) []endpoint {
+	setting := successResponse(property{name: "value", schema: stringSchema(), optional: true})
+
+	return []endpoint{
+		{
+			method: "GET", path: "/user/current", id: "getCurrentUser", tag: "user",
+			summary:  "Returns the current user",
+			response: object(property{name: "user", schema: s.of(content.User{})}),
+		},
+		{
+			method: "POST", path: "/user/token", id: "createUserToken", tag: "user",
+			summary:   "Creates a new token for the current user",
+			responses: tokenResponse(),
+		},
+		{
+			method: "GET", path: "/user/settings", id: "getSettingKeys", tag: "user",
+			summary:  "Lists the setting keys",
+			response: object(property{name: "keys", schema: array(enumSchema(settingKeys...))}),
+		},
+		{
+			method: "GET", path: "/user/settings/{key}", id: "getSettingValue", tag: "user",
+			summary:  "Returns a setting of the current user",
+			response: object(property{name: "value", schema: &openAPISchema{}}),
+		},
+		{
+			method: "PUT", path: "/user/settings/{key}", id: "setSettingValue", tag: "user",
+			summary:  "Changes a setting of the current user. A new api key is returned when rotated",
+			values:   settingValues(),
+			response: setting,
+		},
+		{
+			method: "GET", path: "/user", id: "listUsers", tag: "user", admin: true,
+			summary:  "Lists all users",
+			response: object(property{name: "users", schema: s.of([]content.User{})}),
+		},
+		{
+			method: "POST", path: "/user", id: "addUser", tag: "user", admin: true,
+			summary: "Adds a user",
+			values: []value{
+				{name: "login", schema: stringSchema(), required: true},
+				{name: "password", schema: stringSchema(), required: true},
+				{name: "firstName", schema: stringSchema()},
+				{name: "lastName", schema: stringSchema()},
+				{name: "email", schema: stringSchema()},
+				flag("admin", "The user is an admin"),
+				flag("active", "The user is active"),
+			},
+			response: successResponse(),
+		},
+		{
+			method: "DELETE", path: "/user/{name}", id: "deleteUser", tag: "user", admin: true,
+			summary:  "Deletes a user",
+			response: successResponse(),
+		},
+		{
+			method: "PUT", path: "/user/{name}/settings/{key}", id: "setUserSettingValue", tag: "user", admin: true,
+			summary:  "Changes a setting of a user",
+			values:   settingValues(),
+			response: setting,
+		},
+	}
+}
+
+func webhookEndpoints(s openAPISchemas) []endpoint {
+	webhook := s.of(webhookData{})
+	values := []value{
+		{name: "url", schema: stringSchema()},
+		{name: "active", schema: booleanSchema()},
+		{name: "event", description: "An empty value clears the filter", schema: array(enumSchema(eventable.Events...))},
+		{name: "feedID", description: "An empty value clears the filter", schema: array(integerSchema())},
+		{name: "tagID", description: "An empty value clears the filter", schema: array(integerSchema())},
+	}
+
+	return []endpoint{
+		{
+			method: "GET", path: "/webhook", id: "listWebhooks", tag: "webhook",
+			summary: "Lists the user's webhooks, and the events they may receive",
+			response: object(
+				property{name: "webhooks", schema: array(webhook)},
+				property{name: "events", schema: array(stringSchema())},
+			),
+		},
+		{
+			method: "POST", path: "/webhook", id: "addWebhook", tag: "webhook",
+			summary:  "Adds a webhook",
+			values:   values,
+			response: successResponse(property{name: "webhook", schema: webhook}),
+		},
+		{
+			method: "GET", path: "/webhook/{webhookID}", id: "getWebhook", tag: "webhook",
+			summary:  "Returns a webhook",
+			response: object(property{name: "webhook", schema: webhook}),
+		},
+		{
+			method: "PUT", path: "/webhook/{webhookID}", id: "updateWebhook", tag: "webhook",
+			summary:  "Changes a webhook. Missing values are left unchanged",
+			values:   values,
+			response: successResponse(property{name: "webhook", schema: webhook}),
+		},
+		{
+			method: "DELETE", path: "/webhook/{webhookID}", id: "deleteWebhook", tag: "webhook",
+			summary:  "Deletes a webhook",
+			response: successResponse(),
+		},
+		{
+			method: "GET", path: "/webhook/{webhookID}/deliveries", id: "getWebhookDeliveries", tag: "webhook",
+			summary:  "Lists the latest deliveries of a webhook",
+			values:   []value{{name: "limit", schema: integerSchema()}},
+			response: object(property{name: "deliveries", schema: s.of([]content.WebhookDelivery{})}),
+		},
+	}
+}
+
+func ruleEndpoints(s openAPISchemas) []endpoint {
+	rule := s.of(content.Rule{})
+	values := []value{
+		{name: "name", schema: stringSchema()},
+		{name: "active", schema: booleanSchema()},
+		{name: "any", description: "Matches articles satisfying any of the conditions, instead of all", schema: booleanSchema()},
+		{name: "feedID", description: "An empty value clears the list", schema: array(integerSchema())},
+		{name: "tagID", description: "An empty value clears the list", schema: array(integerSchema())},
+		{name: "condition", description: "A json encoded condition. An empty value clears the list", schema: array(stringSchema())},
+		{name: "read", schema: booleanSchema()},
+		{name: "favorite", schema: booleanSchema()},
+		{name: "labelID", description: "An empty value clears the list", schema: array(integerSchema())},
+		{name: "delete", description: "Hides the matching articles", schema: booleanSchema()},
+		{name: "postURL", description: "Receives the matching articles as json", schema: stringSchema()},
+	}
+	test := object(
+		property{name: "articles", schema: s.of([]content.Article{})},
+		property{name: "tested", schema: integerSchema()},
+	)
+
+	return []endpoint{
+		{
+			method: "GET", path: "/rules", id: "listRules", tag: "rule",
+			summary: "Lists the user's rules, and the article fields they may match",
+			response: object(
+				property{name: "rules", schema: array(rule)},
+				property{name: "fields", schema: array(enumSchema(ruleFields...))},
+			),
+		},
+		{
+			method: "POST", path: "/rules", id: "addRule", tag: "rule",
+			summary:  "Adds a rule, applied to the new articles of the user's feeds",
+			values:   values,
+			response: successResponse(property{name: "rule", schema: rule}),
+		},
+		{
+			method: "POST", path: "/rules/test", id: "testNewRule", tag: "rule",
+			summary:  "Returns the latest articles of the user that match an unsaved rule",
+			values:   append(values, value{name: "limit", schema: integerSchema()}),
+			response: test,
+		},
+		{
+			method: "GET", path: "/rules/{ruleID}", id: "getRule", tag: "rule",
+			summary:  "Returns a rule",
+			response: object(property{name: "rule", schema: rule}),
+		},
+		{
+			method: "PUT", path: "/rules/{ruleID}", id: "updateRule", tag: "rule",
+			summary:  "Changes a rule. Missing values are left unchanged",
+			values:   values,
+			response: successResponse(property{name: "rule", schema: rule}),
+		},
+		{
+			method: "DELETE", path: "/rules/{ruleID}", id: "deleteRule", tag: "rule",
+			summary:  "Deletes a rule",
+			response: successResponse(),
+		},
+		{
+			method: "GET", path: "/rules/{ruleID}/test", id: "testRule", tag: "rule",
+			summary:  "Returns the latest articles of the user that match a rule, without performing its actions",
+			values:   []value{{name: "limit", schema: integerSchema()}},
+			response: test,
+		},
+	}
+}
+
+func outputEndpoints(s openAPISchemas) []endpoint {
+	return []endpoint{
+		{
+			method: "GET", path: "/output", id: "listOutputFeeds", tag: "output",
+			summary: "Lists the user's output feeds, and their formats",
+			response: object(
+				property{name: "outputs", schema: s.of([]content.OutputFeed{})},
+				property{name: "formats", schema: array(enumSchema(outputFormats...))},
+			),
+		},
+		{
+			method: "POST", path: "/output", id: "addOutputFeed", tag: "output",
+			summary: "Publishes a set of the user's articles as an output feed",
+			values: []value{
+				{name: "kind", schema: enumSchema(
+					content.OutputKindFavorite, content.OutputKindTag, content.OutputKindFeed, content.OutputKindPopular,
+				), required: true},
+				{name: "tagID", schema: integerSchema()},
+				{name: "feedID", schema: integerSchema()},
+				{name: "title", schema: stringSchema()},
+			},
+			response: successResponse(property{name: "output", schema: s.of(content.OutputFeed{})}),
+		},
+		{
+			method: "DELETE", path: "/output/{token}", id: "deleteOutputFeed", tag: "output",
+			summary:  "Deletes an output feed",
+			response: successResponse(),
+		},
+	}
+}
+
+// openAPIRoutes serves the api specification.
+func openAPIRoutes(doc openAPIDocument, gzip, access mw) (routes, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return routes{}, errors.Wrap(err, "marshaling the api specification")
+	}
+
+	return routes{path: "/openapi.json", route: func(r chi.Router) {
+		r.Use(timeout(time.Second), gzip, access)
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(b)
+		})
+	}}, nil
+}
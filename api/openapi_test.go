@@ -0,0 +1,591 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/content/search"
+)
+
+// searchProvider only implements the search of a provider.
+type searchProvider struct {
+	search.Provider
+	searcher
+}
+
+func (p searchProvider) Search(query string, user content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+	return p.searcher.Search(query, user, opts...)
+}
+
+type contractMocks struct {
+	feed       *mock_repo.MockFeed
+	article    *mock_repo.MockArticle
+	tag        *mock_repo.MockTag
+	label      *mock_repo.MockLabel
+	annotation *mock_repo.MockAnnotation
+	user       *mock_repo.MockUser
+	extract    *mock_repo.MockExtract
+	webhook    *mock_repo.MockWebhook
+	rule       *mock_repo.MockRule
+	output     *mock_repo.MockOutputFeed
+	playback   *mock_repo.MockPlayback
+	searcher   *Mocksearcher
+}
+
+// contractMux creates the api router, with all optional routes enabled,
+// and the given user authenticated by its token. The returned function
+// stops the router and removes its storage.
+func contractMux(t *testing.T, ctrl *gomock.Controller, user content.User) (http.Handler, contractMocks, string, func()) {
+	m := contractMocks{
+		feed:       mock_repo.NewMockFeed(ctrl),
+		article:    mock_repo.NewMockArticle(ctrl),
+		tag:        mock_repo.NewMockTag(ctrl),
+		label:      mock_repo.NewMockLabel(ctrl),
+		annotation: mock_repo.NewMockAnnotation(ctrl),
+		user:       mock_repo.NewMockUser(ctrl),
+		extract:    mock_repo.NewMockExtract(ctrl),
+		webhook:    mock_repo.NewMockWebhook(ctrl),
+		rule:       mock_repo.NewMockRule(ctrl),
+		output:     mock_repo.NewMockOutputFeed(ctrl),
+		playback:   mock_repo.NewMockPlayback(ctrl),
+		searcher:   NewMocksearcher(ctrl),
+	}
+
+	service := mock_repo.NewMockService(ctrl)
+	service.EXPECT().FeedRepo().Return(m.feed).AnyTimes()
+	service.EXPECT().ArticleRepo().Return(m.article).AnyTimes()
+	service.EXPECT().TagRepo().Return(m.tag).AnyTimes()
+	service.EXPECT().LabelRepo().Return(m.label).AnyTimes()
+	service.EXPECT().AnnotationRepo().Return(m.annotation).AnyTimes()
+	service.EXPECT().UserRepo().Return(m.user).AnyTimes()
+	service.EXPECT().ExtractRepo().Return(m.extract).AnyTimes()
+	service.EXPECT().WebhookRepo().Return(m.webhook).AnyTimes()
+	service.EXPECT().RuleRepo().Return(m.rule).AnyTimes()
+	service.EXPECT().OutputFeedRepo().Return(m.output).AnyTimes()
+	service.EXPECT().PlaybackRepo().Return(m.playback).AnyTimes()
+	service.EXPECT().ThumbnailRepo().Return(mock_repo.NewMockThumbnail(ctrl)).AnyTimes()
+	service.EXPECT().ScoresRepo().Return(mock_repo.NewMockScores(ctrl)).AnyTimes()
+	service.EXPECT().SubscriptionRepo().Return(mock_repo.NewMockSubscription(ctrl)).AnyTimes()
+
+	m.user.EXPECT().Get(user.Login).Return(user, nil).AnyTimes()
+
+	dir, err := ioutil.TempDir("", "readeef-openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cleanup := func() {
+		cancel()
+		os.RemoveAll(dir)
+	}
+
+	cfg, err := config.Read("")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	cfg.Auth.Secret = string(secret)
+	cfg.Auth.TokenStoragePath = filepath.Join(dir, "tokens.db")
+	cfg.Hubbub.CallbackURL = "http://localhost"
+	cfg.API.Emulators = nil
+
+	ev := eventable.NewService(ctx, service, logger)
+	feedManager := readeef.NewFeedManager(ev.FeedRepo(), cfg, logger)
+
+	handler, err := Mux(ctx, ev, feedManager, searchProvider{searcher: m.searcher}, NewMockGenerator(ctrl),
+		nil, nil, cfg, logger, func(next http.Handler) http.Handler { return next })
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return handler, m, generateToken(string(user.Login), time.Now().Add(time.Hour)), cleanup
+}
+
+func fetchOpenAPIDocument(t *testing.T, handler http.Handler) openAPIDocument {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/v2/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("openapi.json code = %d", w.Code)
+	}
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding openapi.json: %v", err)
+	}
+
+	return doc
+}
+
+var routeParamPattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// documentedRoute converts a chi route to a path of the document.
+func documentedRoute(route string) string {
+	var segments []string
+	for _, s := range strings.Split(route, "/") {
+		if s != "" && s != "*" {
+			segments = append(segments, s)
+		}
+	}
+
+	path := "/" + strings.Join(segments, "/")
+
+	return strings.TrimPrefix(routeParamPattern.ReplaceAllString(path, "{$1}"), "/v2")
+}
+
+func Test_openAPISpec_routes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler, _, _, cleanup := contractMux(t, ctrl, content.User{Login: "user1"})
+	defer cleanup()
+
+	doc := fetchOpenAPIDocument(t, handler)
+
+	routed := map[string]bool{}
+	err := chi.Walk(handler.(chi.Routes), func(method, route string, h http.Handler, m ...func(http.Handler) http.Handler) error {
+		path := documentedRoute(route)
+		routed[method+" "+path] = true
+
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("route %s %s is not documented", method, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if !routed[strings.ToUpper(method)+" "+path] {
+				t.Errorf("documented operation %s %s is not routed", method, path)
+			}
+
+			if ids[op.OperationID] {
+				t.Errorf("duplicate operation id %s", op.OperationID)
+			}
+			ids[op.OperationID] = true
+
+			for _, r := range op.Responses {
+				for _, mt := range r.Content {
+					checkSchemaRefs(t, doc, mt.Schema)
+				}
+			}
+		}
+	}
+}
+
+func checkSchemaRefs(t *testing.T, doc openAPIDocument, schema *openAPISchema) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Ref != "" {
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]; !ok {
+			t.Errorf("unknown schema reference %s", schema.Ref)
+		}
+	}
+
+	checkSchemaRefs(t, doc, schema.Items)
+	checkSchemaRefs(t, doc, schema.AdditionalProperties)
+	for _, s := range schema.AllOf {
+		checkSchemaRefs(t, doc, s)
+	}
+	for _, s := range schema.Properties {
+		checkSchemaRefs(t, doc, s)
+	}
+}
+
+func Test_openAPISpec_contract(t *testing.T) {
+	date := time.Date(2020, 5, 10, 12, 0, 0, 0, time.UTC)
+	user := content.User{Login: "user1", FirstName: "User", Email: "user1@example.com", Admin: true, Active: true}
+	feed := content.Feed{ID: 1, Title: "Feed 1", Link: "http://example.com/feed", NextCheck: date}
+	scraperFeed := content.Feed{ID: 2, Title: "Feed 2", Link: "http://example.com/page", Scraper: &content.Scraper{Item: ".item", Title: "h2"}}
+	tag := content.Tag{ID: 1, Value: "tag1"}
+	label := content.Label{ID: 1, User: user.Login, Caption: "label1", FgColor: "#fff", BgColor: "#000"}
+	annotation := content.Annotation{
+		ID: 1, User: user.Login, ArticleID: 1, Note: "note", Quote: "quote", Start: 1, End: 6,
+		CreatedAt: time.Unix(1500000000, 0), UpdatedAt: time.Unix(1500000000, 0),
+	}
+	article := content.Article{
+		ID: 1, FeedID: 1, Title: "Article 1", Link: "http://example.com/1", Date: date,
+		Categories: content.Categories{"news"},
+		Enclosures: content.Enclosures{{URL: "http://example.com/1.mp3", Type: "audio/mpeg", Length: 100}},
+		Media:      &content.Media{Duration: 60},
+	}
+	webhook := content.Webhook{ID: 1, User: user.Login, URL: "http://example.com/hook", Active: true,
+		Filter: content.WebhookFilter{Events: []string{eventable.FeedUpdateEvent}}}
+	delivery := content.WebhookDelivery{ID: 1, WebhookID: 1, Event: eventable.FeedUpdateEvent, Status: content.DeliveryDelivered,
+		CreatedAt: date, UpdatedAt: date}
+	rule := content.Rule{ID: 1, User: user.Login, Name: "rule1", Active: true,
+		Match: content.RuleMatch{FeedIDs: []content.FeedID{1}, Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "article"},
+		}},
+		Actions: content.RuleActions{Read: true, LabelIDs: []content.LabelID{1}}}
+	output := content.OutputFeed{Token: "abc123", User: user.Login, Kind: content.OutputKindTag, TagID: 1, Title: "Tag 1", CreatedAt: date}
+
+	tests := []struct {
+		method string
+		target string
+		path   string
+		form   url.Values
+		setup  func(m contractMocks)
+	}{
+		{method: "GET", target: "/v2/openapi.json", path: "/openapi.json"},
+		{method: "GET", target: "/v2/features", path: "/features"},
+		{method: "GET", target: "/v2/feed", path: "/feed", setup: func(m contractMocks) {
+			m.feed.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{feed, scraperFeed}, nil)
+		}},
+		{method: "GET", target: "/v2/feed", path: "/feed", setup: func(m contractMocks) {
+			m.feed.EXPECT().ForUser(userMatcher{user}).Return(nil, nil)
+		}},
+		{method: "GET", target: "/v2/feed/1/tags", path: "/feed/{feedID}/tags", setup: func(m contractMocks) {
+			m.feed.EXPECT().Get(content.FeedID(1), userMatcher{user}).Return(feed, nil)
+			m.tag.EXPECT().ForFeed(feed, userMatcher{user}).Return([]content.Tag{tag}, nil)
+		}},
+		{method: "POST", target: "/v2/feed/1/extract", path: "/feed/{feedID}/extract", setup: func(m contractMocks) {
+			m.feed.EXPECT().Get(content.FeedID(1), userMatcher{user}).Return(feed, nil)
+			m.feed.EXPECT().SetUserExtract(feed, userMatcher{user}, true).Return(nil)
+		}},
+		{method: "GET", target: "/v2/tag", path: "/tag", setup: func(m contractMocks) {
+			m.tag.EXPECT().ForUser(userMatcher{user}).Return([]content.Tag{tag}, nil)
+		}},
+		{method: "GET", target: "/v2/tag/feedIDs", path: "/tag/feedIDs", setup: func(m contractMocks) {
+			m.tag.EXPECT().ForUser(userMatcher{user}).Return([]content.Tag{tag}, nil)
+			m.tag.EXPECT().FeedIDs(tag, userMatcher{user}).Return([]content.FeedID{1}, nil)
+		}},
+		{method: "GET", target: "/v2/tag/1/feedIDs", path: "/tag/{tagID}/feedIDs", setup: func(m contractMocks) {
+			m.tag.EXPECT().Get(content.TagID(1), userMatcher{user}).Return(tag, nil)
+			m.tag.EXPECT().FeedIDs(tag, userMatcher{user}).Return(nil, nil)
+		}},
+		{method: "GET", target: "/v2/label", path: "/label", setup: func(m contractMocks) {
+			m.label.EXPECT().ForUser(userMatcher{user}).Return([]content.Label{label}, nil)
+		}},
+		{method: "PUT", target: "/v2/label/1", path: "/label/{labelID}", form: url.Values{"caption": {"label2"}}, setup: func(m contractMocks) {
+			m.label.EXPECT().Get(content.LabelID(1), userMatcher{user}).Return(label, nil)
+			m.label.EXPECT().ForUser(userMatcher{user}).Return([]content.Label{label}, nil)
+			m.label.EXPECT().Update(gomock.Any()).Return(nil)
+		}},
+		{method: "POST", target: "/v2/label/1/articles", path: "/label/{labelID}/articles", form: url.Values{"id": {"1", "2"}}, setup: func(m contractMocks) {
+			m.label.EXPECT().Get(content.LabelID(1), userMatcher{user}).Return(label, nil)
+			m.article.EXPECT().IDs(userMatcher{user}, gomock.Any()).Return([]content.ArticleID{1}, nil)
+			m.label.EXPECT().Assign(label, []content.ArticleID{1}).Return(nil)
+		}},
+		{method: "GET", target: "/v2/article/label/1/ids", path: "/article/label/{labelID}/ids", setup: func(m contractMocks) {
+			m.label.EXPECT().Get(content.LabelID(1), userMatcher{user}).Return(label, nil)
+			m.article.EXPECT().IDs(userMatcher{user}, gomock.Any()).Return([]content.ArticleID{1}, nil)
+		}},
+		{method: "GET", target: "/v2/article?limit=10&unreadOnly", path: "/article", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+		}},
+		{method: "GET", target: "/v2/article/feed/1/ids", path: "/article/feed/{feedID}/ids", setup: func(m contractMocks) {
+			m.feed.EXPECT().Get(content.FeedID(1), userMatcher{user}).Return(feed, nil)
+			m.article.EXPECT().IDs(userMatcher{user}, gomock.Any()).Return([]content.ArticleID{1, 2}, nil)
+		}},
+		{method: "GET", target: "/v2/article/search?query=foo", path: "/article/search", setup: func(m contractMocks) {
+			m.searcher.EXPECT().Search("foo", userMatcher{user}, gomock.Any()).Return(nil, nil)
+		}},
+		{method: "GET", target: "/v2/article/1", path: "/article/{articleID}", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.annotation.EXPECT().ForArticles([]content.ArticleID{1}, userMatcher{user}).Return(
+				map[content.ArticleID][]content.Annotation{1: {annotation}}, nil)
+		}},
+		{method: "GET", target: "/v2/article/1/format", path: "/article/{articleID}/format", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.extract.EXPECT().Get(article).Return(content.Extract{ArticleID: 1, Title: "Article 1", Content: "<p>Some content.</p>"}, nil)
+		}},
+		{method: "DELETE", target: "/v2/article/1/favorite", path: "/article/{articleID}/favorite", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+		}},
+		{method: "GET", target: "/v2/article/1/playback", path: "/article/{articleID}/playback", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.playback.EXPECT().Get(article, userMatcher{user}).Return(content.Playback{}, content.ErrNoContent)
+		}},
+		{method: "PUT", target: "/v2/article/1/playback", path: "/article/{articleID}/playback", form: url.Values{"position": {"12.5"}}, setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.playback.EXPECT().Update(gomock.Any()).Return(nil)
+		}},
+		{method: "GET", target: "/v2/article/1/labels", path: "/article/{articleID}/labels", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.label.EXPECT().ForArticles([]content.ArticleID{1}, userMatcher{user}).Return(map[content.ArticleID][]content.Label{}, nil)
+		}},
+		{method: "GET", target: "/v2/article/1/annotations", path: "/article/{articleID}/annotations", setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.annotation.EXPECT().ForArticles([]content.ArticleID{1}, userMatcher{user}).Return(nil, nil)
+		}},
+		{method: "POST", target: "/v2/article/1/annotations", path: "/article/{articleID}/annotations", form: url.Values{"note": {"note"}}, setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.annotation.EXPECT().Create(gomock.Any()).Return(annotation, nil)
+		}},
+		{method: "PUT", target: "/v2/article/1/annotations/1", path: "/article/{articleID}/annotations/{annotationID}", form: url.Values{"note": {"changed"}}, setup: func(m contractMocks) {
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+			m.annotation.EXPECT().Get(content.AnnotationID(1), userMatcher{user}).Return(annotation, nil)
+			m.annotation.EXPECT().Update(gomock.Any()).Return(nil)
+		}},
+		{method: "GET", target: "/v2/annotation?feedID=1", path: "/annotation", setup: func(m contractMocks) {
+			m.annotation.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Annotation{annotation}, nil)
+		}},
+		{method: "GET", target: "/v2/annotation/export", path: "/annotation/export", setup: func(m contractMocks) {
+			m.annotation.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Annotation{annotation}, nil)
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any()).Return([]content.Article{article}, nil)
+		}},
+		{method: "GET", target: "/v2/opml", path: "/opml", setup: func(m contractMocks) {
+			m.feed.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{feed}, nil)
+			m.tag.EXPECT().ForFeed(feed, userMatcher{user}).Return([]content.Tag{tag}, nil)
+		}},
+		{method: "GET", target: "/v2/user/current", path: "/user/current"},
+		{method: "GET", target: "/v2/user/settings", path: "/user/settings"},
+		{method: "GET", target: "/v2/user/settings/email", path: "/user/settings/{key}"},
+		{method: "GET", target: "/v2/user", path: "/user", setup: func(m contractMocks) {
+			m.user.EXPECT().All().Return([]content.User{user, {Login: "user2"}}, nil)
+		}},
+		{method: "GET", target: "/v2/webhook", path: "/webhook", setup: func(m contractMocks) {
+			m.webhook.EXPECT().ForUser(userMatcher{user}).Return([]content.Webhook{webhook}, nil)
+		}},
+		{method: "GET", target: "/v2/webhook/1/deliveries", path: "/webhook/{webhookID}/deliveries", setup: func(m contractMocks) {
+			m.webhook.EXPECT().Get(content.WebhookID(1), userMatcher{user}).Return(webhook, nil)
+			m.webhook.EXPECT().Deliveries(webhook, defaultDeliveryHistory).Return([]content.WebhookDelivery{delivery}, nil)
+		}},
+		{method: "GET", target: "/v2/rules", path: "/rules", setup: func(m contractMocks) {
+			m.rule.EXPECT().ForUser(userMatcher{user}).Return([]content.Rule{rule}, nil)
+		}},
+		{method: "GET", target: "/v2/rules/1/test?limit=10", path: "/rules/{ruleID}/test", setup: func(m contractMocks) {
+			m.rule.EXPECT().Get(content.RuleID(1), userMatcher{user}).Return(rule, nil)
+			m.article.EXPECT().ForUser(userMatcher{user}, gomock.Any(), gomock.Any()).Return([]content.Article{article}, nil)
+		}},
+		{method: "GET", target: "/v2/output", path: "/output", setup: func(m contractMocks) {
+			m.output.EXPECT().ForUser(userMatcher{user}).Return([]content.OutputFeed{output}, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler, mocks, token, cleanup := contractMux(t, ctrl, user)
+			defer cleanup()
+
+			doc := fetchOpenAPIDocument(t, handler)
+
+			if tt.setup != nil {
+				tt.setup(mocks)
+			}
+
+			op, ok := doc.Paths[tt.path][strings.ToLower(tt.method)]
+			if !ok {
+				t.Fatalf("operation %s %s is not documented", tt.method, tt.path)
+			}
+
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			response, ok := op.Responses[fmt.Sprint(w.Code)]
+			if !ok {
+				t.Fatalf("undocumented response code %d: %s", w.Code, w.Body.String())
+			}
+
+			mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mt, ok := response.Content[mediaType]
+			if !ok {
+				t.Fatalf("undocumented content type %s", mediaType)
+			}
+
+			if mediaType != "application/json" {
+				return
+			}
+
+			var data interface{}
+			dec := json.NewDecoder(w.Body)
+			dec.UseNumber()
+			if err := dec.Decode(&data); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := validateSchema(doc, mt.Schema, data, "$"); err != nil {
+				t.Errorf("response does not match the specification: %v", err)
+			}
+		})
+	}
+}
+
+// validateSchema checks the decoded json value against the schema. Objects
+// with properties may not have undocumented ones.
+func validateSchema(doc openAPIDocument, schema *openAPISchema, value interface{}, path string) error {
+	if schema.Ref != "" {
+		ref, ok := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]
+		if !ok {
+			return fmt.Errorf("%s: unknown reference %s", path, schema.Ref)
+		}
+
+		return validateSchema(doc, ref, value, path)
+	}
+
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+
+		return fmt.Errorf("%s: unexpected null", path)
+	}
+
+	for _, s := range schema.AllOf {
+		if err := validateSchema(doc, s, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: %v is not a boolean", path, value)
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an integer", path, value)
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: %v is not an integer", path, value)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s: %v is not a number", path, value)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: %v is not a string", path, value)
+		}
+
+		if len(schema.Enum) > 0 {
+			found := false
+			for _, e := range schema.Enum {
+				found = found || e == s
+			}
+
+			if !found {
+				return fmt.Errorf("%s: %s is not one of %v", path, s, schema.Enum)
+			}
+		}
+
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %s is not a date-time", path, s)
+			}
+		}
+	case "array":
+		a, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %v is not an array", path, value)
+		}
+
+		for i := range a {
+			if err := validateSchema(doc, schema.Items, a[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		o, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %v is not an object", path, value)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := o[name]; !ok {
+				return fmt.Errorf("%s: missing property %s", path, name)
+			}
+		}
+
+		for name, v := range o {
+			s, ok := schema.Properties[name]
+			if !ok {
+				s = schema.AdditionalProperties
+			}
+
+			if s == nil {
+				if len(schema.Properties) > 0 {
+					return fmt.Errorf("%s: undocumented property %s", path, name)
+				}
+
+				continue
+			}
+
+			if err := validateSchema(doc, s, v, path+"."+name); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %s", path, schema.Type)
+	}
+
+	return nil
+}
+
+func Test_validateSchema(t *testing.T) {
+	s := openAPISchemas{}
+	doc := openAPIDocument{Components: openAPIComponents{Schemas: s}}
+	schema := object(property{name: "feeds", schema: s.of([]content.Feed{})})
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"feeds": [{"id": 1, "title": "", "description": "", "link": "", "updateError": "", "subscribeError": "", "nextCheck": "2020-05-10T12:00:00Z", "failureCount": 0, "dead": false, "extractContent": false, "userExtractContent": false, "retention": {}, "userRetention": {"maxArticles": 10}}]}`},
+		{name: "null list", data: `{"feeds": null}`},
+		{name: "missing property", data: `{}`, wantErr: true},
+		{name: "undocumented property", data: `{"feeds": [], "other": 1}`, wantErr: true},
+		{name: "wrong type", data: `{"feeds": [{"id": "1"}]}`, wantErr: true},
+		{name: "missing nested property", data: `{"feeds": [{"id": 1}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data interface{}
+			dec := json.NewDecoder(strings.NewReader(tt.data))
+			dec.UseNumber()
+			if err := dec.Decode(&data); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := validateSchema(doc, schema, data, "$"); (err != nil) != tt.wantErr {
+				t.Errorf("validateSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -50,7 +50,14 @@ func importOPML(
 				continue
 			}
 
-			discovered, err := feedManager.DiscoverFeeds(opmlFeed.URL)
+			auth := content.FeedAuth{
+				Username:  opmlFeed.Username,
+				Password:  opmlFeed.Password,
+				Token:     opmlFeed.Token,
+				UserAgent: opmlFeed.UserAgent,
+			}
+
+			discovered, err := feedManager.DiscoverFeeds(opmlFeed.URL, auth)
 			if err != nil {
 				skipped = append(skipped, opmlFeed.URL)
 				continue
@@ -65,7 +72,7 @@ func importOPML(
 					if dryRun {
 						feeds = append(feeds, f)
 					} else {
-						if feed, err := addFeedByURL(f.Link, user, repo, feedManager); err == nil {
+						if feed, err := addFeedByURL(f.Link, auth, user, repo, feedManager); err == nil {
 							feeds = append(feeds, feed)
 						} else {
 							fatal(w, log, "Error adding feed: %+v", err)
@@ -234,7 +234,7 @@ func Test_importOPML(t *testing.T) {
 									total--
 								}
 
-								feedManager.EXPECT().DiscoverFeeds(link).Return(tt.discovered[i], err)
+								feedManager.EXPECT().DiscoverFeeds(link, content.FeedAuth{}).Return(tt.discovered[i], err)
 							}
 						}
 
@@ -254,7 +254,7 @@ func Test_importOPML(t *testing.T) {
 											code = http.StatusInternalServerError
 										}
 									}
-									feedManager.EXPECT().AddFeedByLink(link).Return(tt.added[i][j], tt.addedErrs[i][j])
+									feedManager.EXPECT().AddFeedByLink(link, content.FeedAuth{}).Return(tt.added[i][j], tt.addedErrs[i][j])
 								}
 
 								if tt.addedErrs[i][j] == nil {
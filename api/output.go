@@ -0,0 +1,343 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/processor"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var outputFeedKey = contextKey("outputFeed")
+
+const outputFeedSize = 50
+
+func listOutputFeeds(repo repo.OutputFeed, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		outputs, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting output feeds: %+v", err)
+			return
+		}
+
+		args{"outputs": outputs, "formats": outputFormats}.WriteJSON(w)
+	}
+}
+
+func addOutputFeed(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		output, err := outputFeedFromForm(r, user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The tag and feed have to belong to the user.
+		if output.TagID != 0 {
+			if _, err := service.TagRepo().Get(output.TagID, user); err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown tag", http.StatusBadRequest)
+				} else {
+					fatal(w, log, "Error getting tag: %+v", err)
+				}
+				return
+			}
+		}
+
+		if output.FeedID != 0 {
+			if _, err := service.FeedRepo().Get(output.FeedID, user); err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, "Unknown feed", http.StatusBadRequest)
+				} else {
+					fatal(w, log, "Error getting feed: %+v", err)
+				}
+				return
+			}
+		}
+
+		output, err = service.OutputFeedRepo().Create(output)
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error creating output feed: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "output": output}.WriteJSON(w)
+	}
+}
+
+// deleteOutputFeed revokes the output feed token.
+func deleteOutputFeed(repo repo.OutputFeed, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		output, err := repo.Get(chi.URLParam(r, "token"))
+		if err == nil && output.User != user.Login {
+			err = content.ErrNoContent
+		}
+
+		if err == nil {
+			err = repo.Delete(output)
+		}
+
+		if err != nil {
+			if content.IsNoContent(err) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			} else {
+				fatal(w, log, "Error deleting output feed: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// serveOutputFeed renders the latest articles of the output feed in the
+// requested format.
+func serveOutputFeed(
+	service repo.Service,
+	processors []processor.Article,
+	articlesLimit int,
+	log log.Log,
+) http.HandlerFunc {
+	articleRepo := service.ArticleRepo()
+	tagRepo := service.TagRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		output, stop := outputFeedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		format := chi.URLParam(r, "format")
+		if _, ok := outputMIMETypes[format]; !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		limit := outputFeedSize
+		if articlesLimit > 0 && limit > articlesLimit {
+			limit = articlesLimit
+		}
+
+		o := []content.QueryOpt{
+			content.Paging(limit, 0),
+			content.Sorting(content.SortByDate, content.DescendingOrder),
+			content.Filters(content.GetUserFilters(user)),
+		}
+
+		repoType, subType := outputRepoTypes(output)
+		repoOpts, stop := repoTypeOptions(w, r, user, repoType, subType, time.Now().Add(-15*time.Minute), tagRepo, log)
+		if stop {
+			return
+		}
+
+		articles, err := articleRepo.ForUser(user, append(o, repoOpts...)...)
+		if err != nil {
+			fatal(w, log, "Error getting articles: %+v", err)
+			return
+		}
+
+		articles = processor.Articles(processors).Process(articles)
+
+		updated := output.CreatedAt
+		for _, a := range articles {
+			if a.Date.After(updated) {
+				updated = a.Date
+			}
+		}
+
+		b, err := renderOutput(outputChannel{
+			Title:    outputFeedTitle(w, r, output),
+			Link:     requestURL(r),
+			Updated:  updated,
+			Articles: articles,
+		}, format)
+		if err != nil {
+			fatal(w, log, "Error rendering output feed: %+v", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", outputMIMETypes[format]+"; charset=utf-8")
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+		w.Write(b)
+	}
+}
+
+// outputRepoTypes maps the output feed kind to the article repository
+// types used by getArticles.
+func outputRepoTypes(output content.OutputFeed) (articleRepoType, articleRepoType) {
+	switch output.Kind {
+	case content.OutputKindFavorite:
+		return favoriteRepoType, noRepoType
+	case content.OutputKindTag:
+		return tagRepoType, noRepoType
+	case content.OutputKindFeed:
+		return feedRepoType, noRepoType
+	case content.OutputKindPopular:
+		if output.TagID != 0 {
+			return popularRepoType, tagRepoType
+		} else if output.FeedID != 0 {
+			return popularRepoType, feedRepoType
+		}
+
+		return popularRepoType, userRepoType
+	}
+
+	return noRepoType, noRepoType
+}
+
+func outputFeedTitle(w http.ResponseWriter, r *http.Request, output content.OutputFeed) string {
+	if output.Title != "" {
+		return output.Title
+	}
+
+	var name string
+	if output.TagID != 0 {
+		if tag, stop := tagFromRequest(w, r); !stop {
+			name = string(tag.Value)
+		}
+	} else if output.FeedID != 0 {
+		if feed, stop := feedFromRequest(w, r); !stop {
+			name = feed.Title
+		}
+	}
+
+	switch output.Kind {
+	case content.OutputKindFavorite:
+		return "readeef: Favorites"
+	case content.OutputKindPopular:
+		if name != "" {
+			return "readeef: Popular in " + name
+		}
+
+		return "readeef: Popular"
+	}
+
+	return "readeef: " + name
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// outputFeedFromForm creates an output feed from the kind, tagID, feedID
+// and title form values.
+func outputFeedFromForm(r *http.Request, user content.User) (content.OutputFeed, error) {
+	output := content.OutputFeed{
+		User:  user.Login,
+		Kind:  r.Form.Get("kind"),
+		Title: r.Form.Get("title"),
+	}
+
+	if v := r.Form.Get("tagID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return output, fmt.Errorf("Invalid tag id: %s", v)
+		}
+
+		output.TagID = content.TagID(id)
+	}
+
+	if v := r.Form.Get("feedID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return output, fmt.Errorf("Invalid feed id: %s", v)
+		}
+
+		output.FeedID = content.FeedID(id)
+	}
+
+	if err := output.GenerateToken(); err != nil {
+		return output, err
+	}
+
+	return output, output.Validate()
+}
+
+// outputFeedContext resolves the output feed from its token, and adds it to
+// the request context, along with its user, and tag or feed. Since output
+// feeds are public, unknown tokens and inactive users are not found.
+func outputFeedContext(service repo.Service, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			output, err := service.OutputFeedRepo().Get(chi.URLParam(r, "token"))
+
+			var user content.User
+			if err == nil {
+				user, err = service.UserRepo().Get(output.User)
+			}
+
+			if err == nil && !user.Active {
+				err = content.ErrNoContent
+			}
+
+			ctx := context.WithValue(r.Context(), outputFeedKey, output)
+			ctx = context.WithValue(ctx, userKey, user)
+
+			if err == nil && output.TagID != 0 {
+				var tag content.Tag
+				tag, err = service.TagRepo().Get(output.TagID, user)
+				ctx = context.WithValue(ctx, tagKey, tag)
+			} else if err == nil && output.FeedID != 0 {
+				var feed content.Feed
+				feed, err = service.FeedRepo().Get(output.FeedID, user)
+				ctx = context.WithValue(ctx, feedKey, feed)
+			}
+
+			if err != nil {
+				if content.IsNoContent(err) || content.IsValidationError(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting output feed: %+v", err)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func outputFeedFromRequest(w http.ResponseWriter, r *http.Request) (output content.OutputFeed, stop bool) {
+	var ok bool
+	if output, ok = r.Context().Value(outputFeedKey).(content.OutputFeed); ok {
+		return output, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.OutputFeed{}, true
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// The formats in which output feeds are served.
+const (
+	rssOutputFormat  = "rss"
+	atomOutputFormat = "atom"
+	jsonOutputFormat = "json"
+)
+
+var outputFormats = []string{rssOutputFormat, atomOutputFormat, jsonOutputFormat}
+
+var outputMIMETypes = map[string]string{
+	rssOutputFormat:  "application/rss+xml",
+	atomOutputFormat: "application/atom+xml",
+	jsonOutputFormat: "application/feed+json",
+}
+
+// outputChannel holds the common data of a rendered output feed.
+type outputChannel struct {
+	Title    string
+	Link     string
+	Updated  time.Time
+	Articles []content.Article
+}
+
+type rssOutput struct {
+	XMLName xml.Name         `xml:"rss"`
+	Version string           `xml:"version,attr"`
+	Atom    string           `xml:"xmlns:atom,attr"`
+	Channel rssOutputChannel `xml:"channel"`
+}
+
+type rssOutputChannel struct {
+	Title         string          `xml:"title"`
+	Link          string          `xml:"link"`
+	Self          atomOutputLink  `xml:"atom:link"`
+	Description   string          `xml:"description"`
+	LastBuildDate string          `xml:"lastBuildDate"`
+	Generator     string          `xml:"generator"`
+	Items         []rssOutputItem `xml:"item"`
+}
+
+type rssOutputItem struct {
+	Title       string               `xml:"title"`
+	Link        string               `xml:"link,omitempty"`
+	Guid        rssOutputGuid        `xml:"guid"`
+	PubDate     string               `xml:"pubDate,omitempty"`
+	Author      string               `xml:"author,omitempty"`
+	Categories  []string             `xml:"category,omitempty"`
+	Enclosures  []rssOutputEnclosure `xml:"enclosure,omitempty"`
+	Description string               `xml:"description"`
+}
+
+type rssOutputGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutputEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atomOutput struct {
+	XMLName xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string            `xml:"id"`
+	Title   string            `xml:"title"`
+	Updated string            `xml:"updated"`
+	Links   []atomOutputLink  `xml:"link"`
+	Entries []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomOutputEntry struct {
+	ID         string               `xml:"id"`
+	Title      string               `xml:"title"`
+	Updated    string               `xml:"updated"`
+	Published  string               `xml:"published,omitempty"`
+	Author     *atomOutputPerson    `xml:"author,omitempty"`
+	Links      []atomOutputLink     `xml:"link"`
+	Categories []atomOutputCategory `xml:"category,omitempty"`
+	Content    atomOutputContent    `xml:"content"`
+}
+
+type atomOutputPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomOutputCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomOutputContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type jsonOutput struct {
+	Version string           `json:"version"`
+	Title   string           `json:"title"`
+	FeedURL string           `json:"feed_url"`
+	Items   []jsonOutputItem `json:"items"`
+}
+
+type jsonOutputItem struct {
+	ID            string                 `json:"id"`
+	URL           string                 `json:"url,omitempty"`
+	Title         string                 `json:"title"`
+	ContentHTML   string                 `json:"content_html"`
+	Image         string                 `json:"image,omitempty"`
+	DatePublished string                 `json:"date_published,omitempty"`
+	Authors       []jsonOutputAuthor     `json:"authors,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Attachments   []jsonOutputAttachment `json:"attachments,omitempty"`
+}
+
+type jsonOutputAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonOutputAttachment struct {
+	URL      string `json:"url"`
+	MIMEType string `json:"mime_type"`
+	Title    string `json:"title,omitempty"`
+	Size     int64  `json:"size_in_bytes,omitempty"`
+	Duration int64  `json:"duration_in_seconds,omitempty"`
+}
+
+// renderOutput encodes the channel in the given format.
+func renderOutput(c outputChannel, format string) ([]byte, error) {
+	switch format {
+	case rssOutputFormat:
+		return renderRSSOutput(c)
+	case atomOutputFormat:
+		return renderAtomOutput(c)
+	case jsonOutputFormat:
+		return renderJSONOutput(c)
+	}
+
+	return nil, fmt.Errorf("unknown output format %s", format)
+}
+
+func renderRSSOutput(c outputChannel) ([]byte, error) {
+	rss := rssOutput{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssOutputChannel{
+			Title:         c.Title,
+			Link:          c.Link,
+			Self:          atomOutputLink{Rel: "self", Href: c.Link, Type: outputMIMETypes[rssOutputFormat]},
+			Description:   c.Title,
+			LastBuildDate: c.Updated.UTC().Format(time.RFC1123Z),
+			Generator:     "readeef",
+			Items:         make([]rssOutputItem, len(c.Articles)),
+		},
+	}
+
+	for i, a := range c.Articles {
+		id, permaLink := outputArticleID(a)
+
+		item := rssOutputItem{
+			Title:       a.Title,
+			Link:        a.Link,
+			Guid:        rssOutputGuid{IsPermaLink: permaLink, Value: id},
+			Author:      a.Author,
+			Categories:  a.Categories,
+			Description: a.Description,
+		}
+
+		if !a.Date.IsZero() {
+			item.PubDate = a.Date.UTC().Format(time.RFC1123Z)
+		}
+
+		for _, e := range a.Enclosures {
+			item.Enclosures = append(item.Enclosures, rssOutputEnclosure{
+				URL: e.URL, Length: e.Length, Type: e.Type,
+			})
+		}
+
+		rss.Channel.Items[i] = item
+	}
+
+	return marshalXMLOutput(rss)
+}
+
+func renderAtomOutput(c outputChannel) ([]byte, error) {
+	atom := atomOutput{
+		ID:      c.Link,
+		Title:   c.Title,
+		Updated: c.Updated.UTC().Format(time.RFC3339),
+		Links: []atomOutputLink{
+			{Rel: "self", Href: c.Link, Type: outputMIMETypes[atomOutputFormat]},
+		},
+		Entries: make([]atomOutputEntry, len(c.Articles)),
+	}
+
+	for i, a := range c.Articles {
+		id, _ := outputArticleID(a)
+
+		date := a.Date
+		if date.IsZero() {
+			date = c.Updated
+		}
+
+		entry := atomOutputEntry{
+			ID:      id,
+			Title:   a.Title,
+			Updated: date.UTC().Format(time.RFC3339),
+			Content: atomOutputContent{Type: "html", Value: a.Description},
+		}
+
+		if !a.Date.IsZero() {
+			entry.Published = entry.Updated
+		}
+
+		if a.Author != "" {
+			entry.Author = &atomOutputPerson{Name: a.Author}
+		}
+
+		if a.Link != "" {
+			entry.Links = append(entry.Links, atomOutputLink{Rel: "alternate", Href: a.Link})
+		}
+
+		for _, e := range a.Enclosures {
+			entry.Links = append(entry.Links, atomOutputLink{
+				Rel: "enclosure", Href: e.URL, Type: e.Type, Length: e.Length,
+			})
+		}
+
+		for _, cat := range a.Categories {
+			entry.Categories = append(entry.Categories, atomOutputCategory{Term: cat})
+		}
+
+		atom.Entries[i] = entry
+	}
+
+	return marshalXMLOutput(atom)
+}
+
+func renderJSONOutput(c outputChannel) ([]byte, error) {
+	feed := jsonOutput{
+		Version: "https://jsonfeed.org/version/1.1",
+		Title:   c.Title,
+		FeedURL: c.Link,
+		Items:   make([]jsonOutputItem, len(c.Articles)),
+	}
+
+	for i, a := range c.Articles {
+		id, _ := outputArticleID(a)
+
+		item := jsonOutputItem{
+			ID:          id,
+			URL:         a.Link,
+			Title:       a.Title,
+			ContentHTML: a.Description,
+			Image:       a.ThumbnailLink,
+			Tags:        a.Categories,
+		}
+
+		if !a.Date.IsZero() {
+			item.DatePublished = a.Date.UTC().Format(time.RFC3339)
+		}
+
+		if a.Author != "" {
+			item.Authors = []jsonOutputAuthor{{Name: a.Author}}
+		}
+
+		for _, e := range a.Enclosures {
+			item.Attachments = append(item.Attachments, jsonOutputAttachment{
+				URL: e.URL, MIMEType: e.Type, Title: e.Title, Size: e.Length, Duration: e.Duration,
+			})
+		}
+
+		feed.Items[i] = item
+	}
+
+	return json.Marshal(feed)
+}
+
+func marshalXMLOutput(v interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+// outputArticleID returns a stable id for the article, and whether it is
+// its link.
+func outputArticleID(a content.Article) (string, bool) {
+	if a.Guid.Valid && a.Guid.String != "" {
+		return a.Guid.String, false
+	}
+
+	if a.Link != "" {
+		return a.Link, true
+	}
+
+	return "readeef:article:" + strconv.FormatInt(int64(a.ID), 10), false
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/parser"
+)
+
+func Test_serveOutputFeed(t *testing.T) {
+	token := "0123456789abcdef0123456789abcdef01234567"
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	articles := []content.Article{
+		{ID: 1, Title: "First", Link: "http://example.com/1", Description: "<p>first</p>", Date: date,
+			Guid: sql.NullString{String: "guid-1", Valid: true}},
+		{ID: 2, Title: "Second", Link: "http://example.com/2", Description: "second", Date: date.Add(-time.Hour),
+			Author: "author", Categories: content.Categories{"cat"},
+			Enclosures: content.Enclosures{{URL: "http://example.com/2.mp3", Type: "audio/mpeg", Length: 10}}},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		output   *content.OutputFeed
+		inactive bool
+		parse    func([]byte) (parser.Feed, error)
+		title    string
+		code     int
+	}{
+		{name: "unknown token", path: "/out/" + token + ".rss", code: 404},
+		{name: "inactive user", path: "/out/" + token + ".rss", output: &content.OutputFeed{
+			Token: token, User: "test", Kind: content.OutputKindFavorite,
+		}, inactive: true, code: 404},
+		{name: "unknown format", path: "/out/" + token + ".xml", output: &content.OutputFeed{
+			Token: token, User: "test", Kind: content.OutputKindFavorite,
+		}, code: 404},
+		{name: "favorite rss", path: "/out/" + token + ".rss", output: &content.OutputFeed{
+			Token: token, User: "test", Kind: content.OutputKindFavorite,
+		}, parse: parser.ParseRss2, title: "readeef: Favorites", code: 200},
+		{name: "feed atom", path: "/out/" + token + ".atom", output: &content.OutputFeed{
+			Token: token, User: "test", Kind: content.OutputKindFeed, FeedID: 3,
+		}, parse: parser.ParseAtom, title: "readeef: Feed 3", code: 200},
+		{name: "popular tag json", path: "/out/" + token + ".json", output: &content.OutputFeed{
+			Token: token, User: "test", Kind: content.OutputKindPopular, TagID: 4, Title: "Custom",
+		}, parse: parser.ParseJSONFeed, title: "Custom", code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			outputRepo := mock_repo.NewMockOutputFeed(ctrl)
+			userRepo := mock_repo.NewMockUser(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+
+			service.EXPECT().OutputFeedRepo().Return(outputRepo).AnyTimes()
+			service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+			service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+			service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+
+			user := content.User{Login: "test", Active: !tt.inactive}
+
+			if tt.output == nil {
+				outputRepo.EXPECT().Get(token).Return(content.OutputFeed{}, content.ErrNoContent)
+			} else {
+				outputRepo.EXPECT().Get(token).Return(*tt.output, nil)
+				userRepo.EXPECT().Get(content.Login("test")).Return(user, nil)
+
+				if !tt.inactive {
+					if tt.output.TagID != 0 {
+						tag := content.Tag{ID: tt.output.TagID, Value: "tag"}
+						tagRepo.EXPECT().Get(tt.output.TagID, user).Return(tag, nil)
+						tagRepo.EXPECT().FeedIDs(tag, user).Return([]content.FeedID{3}, nil).AnyTimes()
+					}
+
+					if tt.output.FeedID != 0 {
+						feedRepo.EXPECT().Get(tt.output.FeedID, user).Return(
+							content.Feed{ID: tt.output.FeedID, Title: "Feed 3"}, nil)
+					}
+				}
+			}
+
+			if tt.code == 200 {
+				articleRepo.EXPECT().ForUser(user, gomock.Any()).Return(articles, nil)
+			}
+
+			router := chi.NewRouter()
+			pass := func(next http.Handler) http.Handler { return next }
+			routes := outputFeedRoutes(service, nil, 100, logger, pass, pass)
+			router.Route(routes.path, routes.route)
+
+			r := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("serveOutputFeed() code = %v, want %v, body %s", w.Code, tt.code, w.Body)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			feed, err := tt.parse(w.Body.Bytes())
+			if err != nil {
+				t.Errorf("serveOutputFeed() parse error = %v, body %s", err, w.Body)
+				return
+			}
+
+			if feed.Title != tt.title {
+				t.Errorf("serveOutputFeed() title = %q, want %q", feed.Title, tt.title)
+			}
+
+			if len(feed.Articles) != len(articles) {
+				t.Errorf("serveOutputFeed() articles = %#v", feed.Articles)
+				return
+			}
+
+			for i, a := range feed.Articles {
+				if a.Title != articles[i].Title || a.Link != articles[i].Link || !a.Date.Equal(articles[i].Date) {
+					t.Errorf("serveOutputFeed() article = %#v, want %#v", a, articles[i])
+				}
+			}
+
+			if feed.Articles[0].Guid != "guid-1" {
+				t.Errorf("serveOutputFeed() guid = %q", feed.Articles[0].Guid)
+			}
+
+			if len(feed.Articles[1].Enclosures) != 1 {
+				t.Errorf("serveOutputFeed() enclosures = %#v", feed.Articles[1].Enclosures)
+			}
+		})
+	}
+}
+
+func Test_addOutputFeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		form      url.Values
+		unknownID bool
+		code      int
+	}{
+		{name: "no kind", form: url.Values{}, code: 400},
+		{name: "tag without id", form: url.Values{"kind": {"tag"}}, code: 400},
+		{name: "invalid feed id", form: url.Values{"kind": {"feed"}, "feedID": {"a"}}, code: 400},
+		{name: "unknown tag", form: url.Values{"kind": {"tag"}, "tagID": {"2"}}, unknownID: true, code: 400},
+		{name: "favorite", form: url.Values{"kind": {"favorite"}, "title": {"Mine"}}, code: 200},
+		{name: "popular feed", form: url.Values{"kind": {"popular"}, "feedID": {"3"}}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			outputRepo := mock_repo.NewMockOutputFeed(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+
+			service.EXPECT().OutputFeedRepo().Return(outputRepo).AnyTimes()
+			service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+
+			user := content.User{Login: "test"}
+
+			if tt.unknownID {
+				tagRepo.EXPECT().Get(content.TagID(2), user).Return(content.Tag{}, content.ErrNoContent)
+			}
+
+			if tt.code == 200 {
+				if tt.form.Get("feedID") != "" {
+					feedRepo.EXPECT().Get(content.FeedID(3), user).Return(content.Feed{ID: 3}, nil)
+				}
+
+				outputRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(output content.OutputFeed) (content.OutputFeed, error) {
+					if output.User != user.Login || output.Kind != tt.form.Get("kind") ||
+						output.Title != tt.form.Get("title") || len(output.Token) != 40 {
+						t.Errorf("addOutputFeed() output = %#v", output)
+					}
+
+					return output, nil
+				})
+			}
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			w := httptest.NewRecorder()
+
+			addOutputFeed(service, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("addOutputFeed() code = %v, want %v, body %s", w.Code, tt.code, w.Body)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success bool               `json:"success"`
+				Output  content.OutputFeed `json:"output"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !got.Success || got.Output.Token == "" {
+				t.Errorf("addOutputFeed() body = %s", w.Body)
+			}
+		})
+	}
+}
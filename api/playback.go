@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+func getPlayback(repo repo.Playback, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		playback, err := repo.Get(article, user)
+		if err != nil {
+			if !content.IsNoContent(err) {
+				fatal(w, log, "Error getting article playback: %+v", err)
+				return
+			}
+
+			playback = content.Playback{ArticleID: article.ID, User: user.Login}
+		}
+
+		args{"playback": playback}.WriteJSON(w)
+	}
+}
+
+func updatePlayback(repo repo.Playback, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		article, stop := articleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		position, err := strconv.ParseFloat(r.FormValue("position"), 64)
+		if err != nil || position < 0 {
+			http.Error(w, "Invalid position", http.StatusBadRequest)
+			return
+		}
+
+		playback := content.Playback{
+			ArticleID: article.ID,
+			User:      user.Login,
+			Position:  position,
+			UpdatedAt: time.Now(),
+		}
+
+		if err := repo.Update(playback); err != nil {
+			fatal(w, log, "Error updating article playback: %+v", err)
+			return
+		}
+
+		args{"success": true, "playback": playback}.WriteJSON(w)
+	}
+}
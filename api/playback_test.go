@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_getPlayback(t *testing.T) {
+	tests := []struct {
+		name      string
+		noUser    bool
+		noArticle bool
+		playback  content.Playback
+		getErr    error
+		position  float64
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "no article", noArticle: true, code: 400},
+		{name: "error", getErr: errors.New("err"), code: 500},
+		{name: "no playback", getErr: content.ErrNoContent, code: 200},
+		{name: "playback", playback: content.Playback{ArticleID: 4, User: "test", Position: 12.5}, position: 12.5, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			playbackRepo := mock_repo.NewMockPlayback(ctrl)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.noArticle {
+					break
+				}
+
+				article := content.Article{ID: 4, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), articleKey, article))
+
+				playbackRepo.EXPECT().Get(article, userMatcher{user}).Return(tt.playback, tt.getErr)
+			}
+
+			getPlayback(playbackRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("getPlayback() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Playback content.Playback `json:"playback"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("getPlayback() body = %s", w.Body)
+				return
+			}
+
+			if got.Playback.ArticleID != 4 || got.Playback.Position != tt.position {
+				t.Errorf("getPlayback() = %v, want position %v", got.Playback, tt.position)
+			}
+		})
+	}
+}
+
+func Test_updatePlayback(t *testing.T) {
+	tests := []struct {
+		name      string
+		noUser    bool
+		noArticle bool
+		position  string
+		updateErr error
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "no article", noArticle: true, code: 400},
+		{name: "no position", code: 400},
+		{name: "invalid position", position: "abc", code: 400},
+		{name: "negative position", position: "-1", code: 400},
+		{name: "update err", position: "30", updateErr: errors.New("err"), code: 500},
+		{name: "update", position: "30.5", code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			playbackRepo := mock_repo.NewMockPlayback(ctrl)
+
+			form := url.Values{}
+			if tt.position != "" {
+				form.Set("position", tt.position)
+			}
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.noArticle {
+					break
+				}
+
+				article := content.Article{ID: 4, Link: "http://example.com"}
+				r = r.WithContext(context.WithValue(r.Context(), articleKey, article))
+
+				if tt.code == 400 {
+					break
+				}
+
+				playbackRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(p content.Playback) error {
+					if p.ArticleID != article.ID || p.User != user.Login || p.UpdatedAt.IsZero() {
+						t.Errorf("updatePlayback() playback = %v", p)
+					}
+					return tt.updateErr
+				})
+			}
+
+			updatePlayback(playbackRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("updatePlayback() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success  bool             `json:"success"`
+				Playback content.Playback `json:"playback"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("updatePlayback() body = %s", w.Body)
+				return
+			}
+
+			if !got.Success || got.Playback.Position != 30.5 {
+				t.Errorf("updatePlayback() = %v", got)
+			}
+		})
+	}
+}
@@ -68,7 +68,9 @@ func (a args) WriteJSON(w http.ResponseWriter) {
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.Write(b)
 }
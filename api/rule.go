@@ -0,0 +1,354 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+var ruleKey = contextKey("rule")
+
+const (
+	defaultRuleTestArticles = 50
+	maxRuleTestArticles     = 500
+)
+
+var ruleFields = []string{
+	content.RuleFieldTitle, content.RuleFieldContent, content.RuleFieldAuthor,
+	content.RuleFieldLink, content.RuleFieldCategory,
+}
+
+func listRules(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		rules, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting rules: %+v", err)
+			return
+		}
+
+		args{"rules": rules, "fields": ruleFields}.WriteJSON(w)
+	}
+}
+
+func getRule(w http.ResponseWriter, r *http.Request) {
+	rule, stop := ruleFromRequest(w, r)
+	if stop {
+		return
+	}
+
+	args{"rule": rule}.WriteJSON(w)
+}
+
+func addRule(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		rule := content.Rule{User: user.Login, Active: true}
+		if err := ruleFromForm(r, &rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := rule.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rule, err := repo.Create(rule)
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error creating rule: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "rule": rule}.WriteJSON(w)
+	}
+}
+
+func updateRule(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, stop := ruleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := ruleFromForm(r, &rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := rule.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.Update(rule); err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error updating rule: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "rule": rule}.WriteJSON(w)
+	}
+}
+
+func deleteRule(repo repo.Rule, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rule, stop := ruleFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(rule); err != nil {
+			fatal(w, log, "Error deleting rule: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+// testRule returns the latest articles of the user that match the rule,
+// without performing any of its actions. The rule is either a stored one,
+// with the form values applied on top, or built from the form alone.
+func testRule(service repo.Service, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		rule, ok := r.Context().Value(ruleKey).(content.Rule)
+		if !ok {
+			rule = content.Rule{User: user.Login}
+		}
+
+		if err := ruleFromForm(r, &rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultRuleTestArticles
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 {
+			limit = l
+		}
+
+		if limit > maxRuleTestArticles {
+			limit = maxRuleTestArticles
+		}
+
+		articles, err := service.ArticleRepo().ForUser(
+			user,
+			content.Paging(limit, 0),
+			content.Sorting(content.SortByDate, content.DescendingOrder),
+		)
+		if err != nil {
+			fatal(w, log, "Error getting articles: %+v", err)
+			return
+		}
+
+		tagFeeds, err := ruleTagFeeds(rule, user, service.TagRepo())
+		if err != nil {
+			fatal(w, log, "Error getting rule tag feeds: %+v", err)
+			return
+		}
+
+		matching, err := rule.Matching(articles, tagFeeds)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		args{"articles": matching, "tested": len(articles)}.WriteJSON(w)
+	}
+}
+
+// ruleFromForm sets the rule name, state, match and actions from the
+// request form. Missing values are left unchanged, while empty ones clear
+// the corresponding list.
+func ruleFromForm(r *http.Request, rule *content.Rule) error {
+	if _, ok := r.Form["name"]; ok {
+		rule.Name = r.Form.Get("name")
+	}
+
+	flags := []struct {
+		name  string
+		value *bool
+	}{
+		{"active", &rule.Active},
+		{"any", &rule.Match.Any},
+		{"read", &rule.Actions.Read},
+		{"favorite", &rule.Actions.Favorite},
+		{"delete", &rule.Actions.Delete},
+	}
+
+	for _, f := range flags {
+		if v := r.Form.Get(f.name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("Invalid %s value: %s", f.name, v)
+			}
+
+			*f.value = b
+		}
+	}
+
+	if _, ok := r.Form["postURL"]; ok {
+		rule.Actions.PostURL = r.Form.Get("postURL")
+	}
+
+	if ids, ok, err := formIDs(r, "feedID"); err != nil {
+		return err
+	} else if ok {
+		rule.Match.FeedIDs = nil
+		for _, id := range ids {
+			rule.Match.FeedIDs = append(rule.Match.FeedIDs, content.FeedID(id))
+		}
+	}
+
+	if ids, ok, err := formIDs(r, "tagID"); err != nil {
+		return err
+	} else if ok {
+		rule.Match.TagIDs = nil
+		for _, id := range ids {
+			rule.Match.TagIDs = append(rule.Match.TagIDs, content.TagID(id))
+		}
+	}
+
+	if ids, ok, err := formIDs(r, "labelID"); err != nil {
+		return err
+	} else if ok {
+		rule.Actions.LabelIDs = nil
+		for _, id := range ids {
+			rule.Actions.LabelIDs = append(rule.Actions.LabelIDs, content.LabelID(id))
+		}
+	}
+
+	if conditions, ok := r.Form["condition"]; ok {
+		rule.Match.Conditions = nil
+		for _, v := range conditions {
+			if v == "" {
+				continue
+			}
+
+			var c content.RuleCondition
+			if err := json.Unmarshal([]byte(v), &c); err != nil {
+				return fmt.Errorf("Invalid condition: %s", v)
+			}
+
+			rule.Match.Conditions = append(rule.Match.Conditions, c)
+		}
+	}
+
+	return nil
+}
+
+// formIDs parses the repeated id values of the form field, skipping the
+// empty ones. The returned bool is false when the field is missing.
+func formIDs(r *http.Request, name string) ([]int64, bool, error) {
+	values, ok := r.Form[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	ids := []int64{}
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, true, fmt.Errorf("Invalid %s: %s", name, v)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, true, nil
+}
+
+func ruleTagFeeds(rule content.Rule, user content.User, tagRepo repo.Tag) ([]content.FeedID, error) {
+	var ids []content.FeedID
+
+	for _, id := range rule.Match.TagIDs {
+		tag, err := tagRepo.Get(id, user)
+		if err != nil {
+			if content.IsNoContent(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		feedIDs, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, feedIDs...)
+	}
+
+	return ids, nil
+}
+
+func ruleContext(repo repo.Rule, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			rule, err := repo.Get(content.RuleID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting rule: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ruleKey, rule)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func ruleFromRequest(w http.ResponseWriter, r *http.Request) (rule content.Rule, stop bool) {
+	var ok bool
+	if rule, ok = r.Context().Value(ruleKey).(content.Rule); ok {
+		return rule, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Rule{}, true
+}
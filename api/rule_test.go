@@ -0,0 +1,282 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_addRule(t *testing.T) {
+	condition := `{"field":"title","value":"go"}`
+
+	tests := []struct {
+		name      string
+		noUser    bool
+		form      url.Values
+		createErr error
+		want      content.Rule
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "no match", form: url.Values{"read": {"true"}}, code: 400},
+		{name: "no actions", form: url.Values{"condition": {condition}}, code: 400},
+		{name: "invalid condition", form: url.Values{"condition": {"title"}, "read": {"true"}}, code: 400},
+		{name: "unknown field", form: url.Values{"condition": {`{"field":"guid","value":"go"}`}, "read": {"true"}}, code: 400},
+		{name: "invalid feed id", form: url.Values{"feedID": {"a"}, "read": {"true"}}, code: 400},
+		{name: "invalid read", form: url.Values{"condition": {condition}, "read": {"maybe"}}, code: 400},
+		{name: "create err", form: url.Values{"condition": {condition}, "read": {"true"}}, createErr: errors.New("err"), code: 500},
+		{name: "create", form: url.Values{
+			"name":      {"rule"},
+			"condition": {condition, `{"field":"author","value":"^go","regex":true,"inverse":true}`},
+			"any":       {"true"},
+			"feedID":    {"1", "2"},
+			"tagID":     {"3"},
+			"read":      {"true"},
+			"labelID":   {"4"},
+			"delete":    {"true"},
+			"postURL":   {"https://example.com/rule"},
+		}, want: content.Rule{
+			User: "test", Name: "rule", Active: true,
+			Match: content.RuleMatch{
+				FeedIDs: []content.FeedID{1, 2}, TagIDs: []content.TagID{3}, Any: true,
+				Conditions: []content.RuleCondition{
+					{Field: content.RuleFieldTitle, Value: "go"},
+					{Field: content.RuleFieldAuthor, Value: "^go", Regex: true, Inverse: true},
+				},
+			},
+			Actions: content.RuleActions{
+				Read: true, LabelIDs: []content.LabelID{4}, Delete: true, PostURL: "https://example.com/rule",
+			},
+		}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if !tt.noUser {
+				r = r.WithContext(context.WithValue(r.Context(), userKey, content.User{Login: "test"}))
+
+				if tt.code != 400 {
+					ruleRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(rule content.Rule) (content.Rule, error) {
+						if tt.createErr == nil && !reflect.DeepEqual(rule, tt.want) {
+							t.Errorf("addRule() rule = %#v, want %#v", rule, tt.want)
+						}
+
+						rule.ID = 5
+						return rule, tt.createErr
+					})
+				}
+			}
+
+			addRule(ruleRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("addRule() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success bool         `json:"success"`
+				Rule    content.Rule `json:"rule"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("addRule() body = %s", w.Body)
+				return
+			}
+
+			if !got.Success || got.Rule.ID != 5 {
+				t.Errorf("addRule() = %s", w.Body)
+			}
+		})
+	}
+}
+
+func Test_updateRule(t *testing.T) {
+	rule := content.Rule{
+		ID: 2, User: "test", Name: "rule", Active: true,
+		Match:   content.RuleMatch{FeedIDs: []content.FeedID{1}},
+		Actions: content.RuleActions{Read: true, Favorite: true},
+	}
+
+	tests := []struct {
+		name      string
+		noRule    bool
+		form      url.Values
+		updateErr error
+		want      content.Rule
+		code      int
+	}{
+		{name: "no rule", noRule: true, code: 400},
+		{name: "invalid active", form: url.Values{"active": {"maybe"}}, code: 400},
+		{name: "clear match", form: url.Values{"feedID": {""}}, code: 400},
+		{name: "update err", form: url.Values{"active": {"false"}}, updateErr: errors.New("err"), code: 500},
+		{name: "deactivate", form: url.Values{"active": {"false"}}, want: content.Rule{
+			ID: 2, User: "test", Name: "rule",
+			Match:   content.RuleMatch{FeedIDs: []content.FeedID{1}},
+			Actions: content.RuleActions{Read: true, Favorite: true},
+		}, code: 200},
+		{name: "change actions", form: url.Values{"read": {"false"}, "labelID": {"3"}}, want: content.Rule{
+			ID: 2, User: "test", Name: "rule", Active: true,
+			Match:   content.RuleMatch{FeedIDs: []content.FeedID{1}},
+			Actions: content.RuleActions{Favorite: true, LabelIDs: []content.LabelID{3}},
+		}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ruleRepo := mock_repo.NewMockRule(ctrl)
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if !tt.noRule {
+				r = r.WithContext(context.WithValue(r.Context(), ruleKey, rule))
+
+				if tt.code != 400 {
+					ruleRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(rule content.Rule) error {
+						if tt.updateErr == nil && !reflect.DeepEqual(rule, tt.want) {
+							t.Errorf("updateRule() rule = %#v, want %#v", rule, tt.want)
+						}
+						return tt.updateErr
+					})
+				}
+			}
+
+			updateRule(ruleRepo, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("updateRule() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
+
+func Test_testRule(t *testing.T) {
+	articles := []content.Article{
+		{ID: 1, FeedID: 1, Title: "Go news"},
+		{ID: 2, FeedID: 2, Title: "Go tips"},
+		{ID: 3, FeedID: 3, Title: "Weather"},
+	}
+
+	tests := []struct {
+		name     string
+		rule     *content.Rule
+		form     url.Values
+		limit    int
+		tagFeeds []content.FeedID
+		want     []content.ArticleID
+		code     int
+	}{
+		{name: "invalid condition", form: url.Values{"condition": {`{"field":"title","value":"(","regex":true}`}}, code: 400},
+		{name: "unsaved", form: url.Values{"condition": {`{"field":"title","value":"go"}`}}, want: []content.ArticleID{1, 2}, code: 200},
+		{name: "unsaved with tag", form: url.Values{
+			"condition": {`{"field":"title","value":"go"}`}, "tagID": {"1"}, "limit": {"10"},
+		}, limit: 10, tagFeeds: []content.FeedID{2, 3}, want: []content.ArticleID{2}, code: 200},
+		{name: "stored", rule: &content.Rule{ID: 1, User: "test", Match: content.RuleMatch{
+			FeedIDs: []content.FeedID{1, 3},
+		}, Actions: content.RuleActions{Read: true}}, form: url.Values{"limit": {"1000"}}, limit: maxRuleTestArticles, want: []content.ArticleID{1, 3}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+			tagRepo := mock_repo.NewMockTag(ctrl)
+
+			service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+			service.EXPECT().TagRepo().Return(tagRepo).AnyTimes()
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			user := content.User{Login: "test"}
+			r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+			if tt.rule != nil {
+				r = r.WithContext(context.WithValue(r.Context(), ruleKey, *tt.rule))
+			}
+
+			limit := tt.limit
+			if limit == 0 {
+				limit = defaultRuleTestArticles
+			}
+
+			articleRepo.EXPECT().ForUser(user, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(user content.User, opts ...content.QueryOpt) ([]content.Article, error) {
+					o := content.QueryOptions{}
+					o.Apply(opts)
+
+					if o.Limit != limit || o.SortField != content.SortByDate || o.SortOrder != content.DescendingOrder {
+						t.Errorf("testRule() query options = %#v", o)
+					}
+
+					return articles, nil
+				},
+			)
+
+			if tt.tagFeeds != nil {
+				tag := content.Tag{ID: 1, Value: "tag"}
+				tagRepo.EXPECT().Get(content.TagID(1), user).Return(tag, nil)
+				tagRepo.EXPECT().FeedIDs(tag, user).Return(tt.tagFeeds, nil)
+			}
+
+			testRule(service, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("testRule() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Articles []content.Article `json:"articles"`
+				Tested   int               `json:"tested"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("testRule() body = %s", w.Body)
+				return
+			}
+
+			var ids []content.ArticleID
+			for _, a := range got.Articles {
+				ids = append(ids, a.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.want) || got.Tested != len(articles) {
+				t.Errorf("testRule() = %s, want %v", w.Body, tt.want)
+			}
+		})
+	}
+}
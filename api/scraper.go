@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type scraperManager interface {
+	PreviewScraper(link string, scraper content.Scraper, auth content.FeedAuth) (content.Feed, error)
+	AddScraperFeed(link string, scraper content.Scraper, auth content.FeedAuth) (content.Feed, error)
+	SetScraper(feed content.Feed, scraper content.Scraper) (content.Feed, error)
+}
+
+// scraperFromRequest reads the scraper selectors from the request form.
+func scraperFromRequest(r *http.Request) (content.Scraper, error) {
+	scraper := content.Scraper{
+		Item:    r.Form.Get("item"),
+		Title:   r.Form.Get("title"),
+		Link:    r.Form.Get("itemLink"),
+		Date:    r.Form.Get("date"),
+		Content: r.Form.Get("content"),
+	}
+
+	return scraper, scraper.Validate()
+}
+
+func previewScraper(manager scraperManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		link := r.Form.Get("link")
+		if u, err := url.Parse(link); err != nil || !u.IsAbs() {
+			http.Error(w, "Invalid link", http.StatusBadRequest)
+			return
+		}
+
+		scraper, err := scraperFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		auth, err := feedAuthFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		feed, err := manager.PreviewScraper(link, scraper, auth)
+		if err != nil {
+			log.Infof("Error previewing scraper for %s: %+v", link, err)
+			args{"success": false, "error": err.Error()}.WriteJSON(w)
+			return
+		}
+
+		args{"success": true, "feed": feed, "articles": feed.ParsedArticles()}.WriteJSON(w)
+	}
+}
+
+func addScraperFeed(repo repo.Feed, manager scraperManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		link := r.Form.Get("link")
+		u, err := url.Parse(link)
+		if err != nil || !u.IsAbs() {
+			http.Error(w, "Invalid link", http.StatusBadRequest)
+			return
+		}
+
+		scraper, err := scraperFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		auth, err := feedAuthFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		feed, err := manager.AddScraperFeed(link, scraper, auth)
+		if err != nil {
+			args{"success": false, "error": addFeedError{Link: link, Message: "adding feed to the database: " + err.Error()}}.WriteJSON(w)
+			return
+		}
+
+		if err = repo.AttachTo(feed, user); err != nil {
+			args{"success": false, "error": addFeedError{Link: link, Title: feed.Title, Message: fmt.Sprintf("adding feed to user %s: %s", user, err.Error())}}.WriteJSON(w)
+			return
+		}
+
+		if u.Fragment != "" {
+			tags := strings.Split(u.Fragment, ",")
+			t := make([]*content.Tag, len(tags))
+			for i := range tags {
+				t[i] = &content.Tag{Value: content.TagValue(tags[i])}
+			}
+
+			if err = repo.SetUserTags(feed, user, t); err != nil {
+				args{"success": false, "error": addFeedError{Link: link, Title: feed.Title, Message: "adding feed tags to the database: " + err.Error()}}.WriteJSON(w)
+				return
+			}
+		}
+
+		args{"success": true, "feed": feed}.WriteJSON(w)
+	}
+}
+
+func setFeedScraper(manager scraperManager, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		feed, stop := feedFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if feed.Scraper == nil {
+			http.Error(w, "Not a scraper feed", http.StatusBadRequest)
+			return
+		}
+
+		scraper, err := scraperFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		feed, err = manager.SetScraper(feed, scraper)
+		if err != nil {
+			log.Infof("Error setting the scraper of feed %s: %+v", feed, err)
+			args{"success": false, "error": err.Error()}.WriteJSON(w)
+			return
+		}
+
+		args{"success": true, "feed": feed}.WriteJSON(w)
+	}
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_previewScraper(t *testing.T) {
+	scraper := content.Scraper{Item: "article", Title: "h2"}
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		code       int
+		previewErr error
+	}{
+		{name: "no link", form: url.Values{"item": {"article"}, "title": {"h2"}}, code: http.StatusBadRequest},
+		{name: "no item", form: url.Values{"link": {"http://example.com"}, "title": {"h2"}}, code: http.StatusBadRequest},
+		{name: "preview err", form: url.Values{"link": {"http://example.com"}, "item": {"article"}, "title": {"h2"}}, code: http.StatusOK, previewErr: errors.New("no items")},
+		{name: "success", form: url.Values{"link": {"http://example.com"}, "item": {"article"}, "title": {"h2"}}, code: http.StatusOK},
+	}
+
+	type data struct {
+		Success bool         `json:"success"`
+		Feed    content.Feed `json:"feed"`
+		Error   string       `json:"error"`
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			manager := NewMockscraperManager(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			want := data{}
+			if tt.code == http.StatusOK {
+				feed := content.Feed{Title: "Example", Link: "http://example.com", Scraper: &scraper}
+				manager.EXPECT().PreviewScraper("http://example.com", scraper, content.FeedAuth{}).Return(feed, tt.previewErr)
+
+				if tt.previewErr == nil {
+					want.Success = true
+					want.Feed = feed
+				} else {
+					want.Error = tt.previewErr.Error()
+				}
+			}
+
+			previewScraper(manager, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("previewScraper() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got data
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("previewScraper() body = %s, error = %+v", w.Body, err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("previewScraper() got = %#v, want = %#v", got, want)
+			}
+		})
+	}
+}
+
+func Test_addScraperFeed(t *testing.T) {
+	scraper := content.Scraper{Item: "article", Title: "h2", Date: "time"}
+	form := url.Values{"link": {"http://example.com#tag1"}, "item": {"article"}, "title": {"h2"}, "date": {"time"}}
+
+	tests := []struct {
+		name      string
+		noUser    bool
+		addErr    error
+		attachErr error
+	}{
+		{name: "no user", noUser: true},
+		{name: "add err", addErr: errors.New("no items")},
+		{name: "attach err", attachErr: errors.New("attach")},
+		{name: "success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			manager := NewMockscraperManager(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			code := http.StatusOK
+			success := false
+			switch {
+			default:
+				if tt.noUser {
+					code = http.StatusBadRequest
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				feed := content.Feed{ID: 1, Link: "http://example.com", Scraper: &scraper}
+				manager.EXPECT().AddScraperFeed("http://example.com#tag1", scraper, content.FeedAuth{}).Return(feed, tt.addErr)
+				if tt.addErr != nil {
+					break
+				}
+
+				feedRepo.EXPECT().AttachTo(feed, userMatcher{user}).Return(tt.attachErr)
+				if tt.attachErr != nil {
+					break
+				}
+
+				feedRepo.EXPECT().SetUserTags(feed, userMatcher{user}, []*content.Tag{{Value: "tag1"}}).Return(nil)
+				success = true
+			}
+
+			addScraperFeed(feedRepo, manager).ServeHTTP(w, r)
+
+			if code != w.Code {
+				t.Errorf("addScraperFeed() code = %v, want %v", w.Code, code)
+				return
+			}
+
+			if code != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Success bool `json:"success"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Success != success {
+				t.Errorf("addScraperFeed() body = %s, want success %v", w.Body, success)
+			}
+		})
+	}
+}
+
+func Test_setFeedScraper(t *testing.T) {
+	scraper := content.Scraper{Item: "li", Title: "a"}
+
+	tests := []struct {
+		name       string
+		feed       content.Feed
+		noFeed     bool
+		code       int
+		setErr     error
+		wantResult bool
+	}{
+		{name: "no feed", noFeed: true, code: http.StatusBadRequest},
+		{name: "regular feed", feed: content.Feed{ID: 1, Link: "http://example.com"}, code: http.StatusBadRequest},
+		{name: "set err", feed: content.Feed{ID: 1, Link: "http://example.com", Scraper: &content.Scraper{Item: "div", Title: "h1"}}, code: http.StatusOK, setErr: errors.New("no items")},
+		{name: "success", feed: content.Feed{ID: 1, Link: "http://example.com", Scraper: &content.Scraper{Item: "div", Title: "h1"}}, code: http.StatusOK, wantResult: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			manager := NewMockscraperManager(ctrl)
+
+			form := url.Values{"item": {"li"}, "title": {"a"}}
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if !tt.noFeed {
+				r = r.WithContext(context.WithValue(r.Context(), feedKey, tt.feed))
+			}
+
+			if tt.code == http.StatusOK {
+				updated := tt.feed
+				updated.Scraper = &scraper
+				manager.EXPECT().SetScraper(tt.feed, scraper).Return(updated, tt.setErr)
+			}
+
+			setFeedScraper(manager, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("setFeedScraper() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Success bool         `json:"success"`
+				Feed    content.Feed `json:"feed"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Success != tt.wantResult {
+				t.Errorf("setFeedScraper() body = %s, want success %v", w.Body, tt.wantResult)
+				return
+			}
+
+			if tt.wantResult && !reflect.DeepEqual(got.Feed.Scraper, &scraper) {
+				t.Errorf("setFeedScraper() scraper = %v, want %v", got.Feed.Scraper, scraper)
+			}
+		})
+	}
+}
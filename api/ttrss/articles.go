@@ -18,6 +18,7 @@ type headline struct {
 	Id        content.ArticleID `json:"id"`
 	Unread    bool              `json:"unread"`
 	Marked    bool              `json:"marked"`
+	Published bool              `json:"published"`
 	Updated   int64             `json:"updated"`
 	IsUpdated bool              `json:"is_updated"`
 	Title     string            `json:"title"`
@@ -27,11 +28,17 @@ type headline struct {
 	Excerpt   string            `json:"excerpt,omitempty"`
 	Content   string            `json:"content,omitempty"`
 	FeedTitle string            `json:"feed_title"`
+	Note      string            `json:"note,omitempty"`
 
-	Tags   []string `json:"tags,omitempty"`
-	Labels []string `json:"labels,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Labels      []headlineLabel `json:"labels"`
+	Attachments []attachment    `json:"attachments,omitempty"`
 }
 
+// headlineLabel holds the label feed id, caption, and foreground and
+// background colors.
+type headlineLabel [4]interface{}
+
 type headlinesHeader struct {
 	Id      content.FeedID    `json:"id"`
 	FirstId content.ArticleID `json:"first_id"`
@@ -46,13 +53,36 @@ type article struct {
 	Link      string `json:"link"`
 	Unread    bool   `json:"unread"`
 	Marked    bool   `json:"marked"`
+	Published bool   `json:"published"`
 	Author    string `json:"author"`
 	Updated   int64  `json:"updated"`
 	Content   string `json:"content,omitempty"`
 	FeedId    string `json:"feed_id"`
 	FeedTitle string `json:"feed_title"`
+	Note      string `json:"note,omitempty"`
+
+	Labels      []headlineLabel `json:"labels"`
+	Attachments []attachment    `json:"attachments"`
+}
 
-	Labels []string `json:"labels,omitempty"`
+// articleState holds the user state of articles, which isn't part of the
+// articles themselves.
+type articleState struct {
+	feedTitles map[content.FeedID]string
+	published  map[content.ArticleID]bool
+	labels     map[content.ArticleID][]content.Label
+	notes      map[content.ArticleID]string
+}
+
+type attachment struct {
+	Id          string `json:"id"`
+	ContentUrl  string `json:"content_url"`
+	ContentType string `json:"content_type"`
+	PostId      string `json:"post_id"`
+	Title       string `json:"title"`
+	Duration    string `json:"duration"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
 }
 
 func registerArticleActions(searchProvider search.Provider, processors []processor.Article) {
@@ -60,6 +90,7 @@ func registerArticleActions(searchProvider search.Provider, processors []process
 		return getHeadlines(req, user, service, searchProvider, processors)
 	}
 	actions["updateArticle"] = updateArticle
+	actions["setArticleNote"] = setArticleNote
 	actions["getArticle"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
 		return getArticle(req, user, service, processors)
 	}
@@ -72,7 +103,7 @@ func getHeadlines(
 	searchProvider search.Provider,
 	processors []processor.Article,
 ) (interface{}, error) {
-	if req.FeedId == 0 {
+	if req.FeedId == 0 && !req.IsCat {
 		return nil, errors.WithStack(newErr("no feed id", "INCORRECT_USAGE"))
 	}
 
@@ -81,84 +112,45 @@ func getHeadlines(
 		limit = 200
 	}
 
-	var feedTitle string
-	var firstID content.ArticleID
-	opts := []content.QueryOpt{
-		content.Paging(limit, req.Skip), content.UnreadFirst,
-		content.Filters(content.GetUserFilters(user)),
-	}
-
-	switch req.OrderBy {
-	case "date_reverse":
-		opts = append(opts, content.Sorting(content.SortByDate, content.AscendingOrder))
-	default:
-		opts = append(opts, content.Sorting(content.SortByDate, content.DescendingOrder))
-	}
-
-	var feedGenerator func() ([]content.Feed, error)
-
-	if req.SinceId > 0 {
-		opts = append(opts, content.IDRange(0, req.SinceId))
-	}
-
-	if req.IsCat {
-		if req.FeedId == CAT_UNCATEGORIZED {
-			opts = append(opts, content.UntaggedOnly)
-
-			feedTitle = "Uncategorized"
-		} else if req.FeedId > 0 {
-			tag, err := service.TagRepo().Get(content.TagID(req.FeedId), user)
-			if err != nil {
-				return nil, errors.WithMessage(err, "getting tag for user")
-			}
+	feedID, isCat := req.FeedId, req.IsCat
+	if req.Search != "" {
+		switch req.SearchMode {
+		case "all_feeds":
+			feedID, isCat = ALL_ID, false
+		case "this_cat":
+			if !isCat && feedID > 0 {
+				catID, err := feedCategory(feedID, user, service)
+				if err != nil {
+					return nil, err
+				}
 
-			feedGenerator = func() ([]content.Feed, error) {
-				return service.FeedRepo().ForTag(tag, user)
+				feedID, isCat = content.FeedID(catID), true
 			}
-
-			feedTitle = string(tag.Value)
 		}
-	} else {
-		if req.FeedId == FAVORITE_ID {
-			opts = append(opts, content.FavoriteOnly)
-			feedTitle = "Starred articles"
-		} else if req.FeedId == FRESH_ID {
-			opts = append(opts, content.TimeRange(time.Now().Add(FRESH_DURATION), time.Time{}))
-			feedTitle = "Fresh articles"
-		} else if req.FeedId == ALL_ID {
-			feedTitle = "All articles"
-		} else if req.FeedId > 0 {
-			feed, err := service.FeedRepo().Get(req.FeedId, user)
-			if err != nil {
-				return nil, errors.WithMessage(err, "getting user feed")
-			}
-
-			feedGenerator = func() ([]content.Feed, error) {
-				return []content.Feed{feed}, nil
-			}
+	}
 
-			feedTitle = feed.Title
-		}
+	q, err := queryFeed(feedID, isCat, user, service)
+	if err != nil {
+		return nil, err
 	}
 
-	if feedGenerator != nil {
-		feeds, err := feedGenerator()
-		if err != nil {
-			return nil, errors.WithMessage(err, "getting feeds")
-		}
+	q.opts = append(q.opts, content.Paging(limit, req.Skip), content.UnreadFirst)
 
-		ids := make([]content.FeedID, len(feeds))
-		for i := range feeds {
-			ids[i] = feeds[i].ID
-		}
+	switch req.OrderBy {
+	case "date_reverse":
+		q.opts = append(q.opts, content.Sorting(content.SortByDate, content.AscendingOrder))
+	default:
+		q.opts = append(q.opts, content.Sorting(content.SortByDate, content.DescendingOrder))
+	}
 
-		opts = append(opts, content.FeedIDs(ids))
+	if req.SinceId > 0 {
+		q.opts = append(q.opts, content.IDRange(req.SinceId, 0))
 	}
 
-	var articleGenerator func() ([]content.Article, error)
+	var articleGenerator func(opts []content.QueryOpt) ([]content.Article, error)
 	if req.Search != "" {
 		if searchProvider != nil {
-			articleGenerator = func() ([]content.Article, error) {
+			articleGenerator = func(opts []content.QueryOpt) ([]content.Article, error) {
 				return searchProvider.Search(req.Search, user, opts...)
 			}
 		}
@@ -166,27 +158,38 @@ func getHeadlines(
 		var skip bool
 
 		switch req.ViewMode {
-		case "all_articles":
+		case "", "all_articles":
 		case "adaptive":
 		case "unread":
-			opts = append(opts, content.UnreadOnly)
+			q.opts = append(q.opts, content.UnreadOnly)
 		case "marked":
-			opts = append(opts, content.FavoriteOnly)
+			q.opts = append(q.opts, content.FavoriteOnly)
+		case "published":
+			ids, err := service.ArticleRepo().PublishedIDs(user)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting published article ids")
+			}
+
+			q.restrict(ids)
 		default:
 			skip = true
 		}
 
 		if !skip {
-			articleGenerator = func() ([]content.Article, error) {
+			articleGenerator = func(opts []content.QueryOpt) ([]content.Article, error) {
 				return service.ArticleRepo().ForUser(user, opts...)
 			}
 		}
 	}
 
 	if articleGenerator != nil {
-		articles, err := articleGenerator()
-		if err != nil {
-			return nil, errors.WithMessage(err, "gettting articles")
+		var articles []content.Article
+		var firstID content.ArticleID
+
+		if !q.empty() {
+			if articles, err = articleGenerator(q.options(time.Time{})); err != nil {
+				return nil, errors.WithMessage(err, "gettting articles")
+			}
 		}
 
 		if len(articles) > 0 {
@@ -195,7 +198,12 @@ func getHeadlines(
 			firstID = articles[0].ID
 		}
 
-		headlines := headlinesFromArticles(articles, feedTitle, req.ShowContent, req.ShowExcerpt)
+		state, err := loadArticleState(articles, user, service)
+		if err != nil {
+			return nil, err
+		}
+
+		headlines := headlinesFromArticles(articles, state, req.ShowContent, req.ShowExcerpt, req.IncludeAttachments)
 		if req.IncludeHeader {
 			header := headlinesHeader{Id: req.FeedId, FirstId: firstID, IsCat: req.IsCat}
 			hContent := headlinesHeaderContent{}
@@ -213,11 +221,16 @@ func getHeadlines(
 }
 
 func updateArticle(req request, user content.User, service repo.Service) (interface{}, error) {
-	if req.Field != 0 && req.Field != 2 {
+	if req.Field < 0 || req.Field > 3 {
 		return nil, errors.Errorf("Unknown field %d", req.Field)
 	}
 
-	articles, err := service.ArticleRepo().ForUser(user,
+	if len(req.ArticleIds) == 0 {
+		return nil, errors.WithStack(newErr("no article ids", "INCORRECT_USAGE"))
+	}
+
+	articleRepo := service.ArticleRepo()
+	articles, err := articleRepo.ForUser(user,
 		content.IDs(req.ArticleIds),
 		content.Filters(content.GetUserFilters(user)),
 	)
@@ -225,93 +238,101 @@ func updateArticle(req request, user content.User, service repo.Service) (interf
 		return nil, errors.Wrap(err, "getting usr articles")
 	}
 
-	var read, unread, favor, unfavor []content.ArticleID
+	if req.Field == 3 {
+		if err := setArticleNotes(articles, req.Data, user, service.AnnotationRepo()); err != nil {
+			return nil, err
+		}
+
+		return genericContent{Status: "OK", Updated: int64(len(articles))}, nil
+	}
+
+	published := map[content.ArticleID]bool{}
+	if req.Field == 1 {
+		ids, err := articleRepo.PublishedIDs(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting published article ids")
+		}
+
+		for _, id := range ids {
+			published[id] = true
+		}
+	}
+
+	// The articles whose state has to be turned on or off, where the
+	// state is starred, published or unread, depending on the field.
+	var on, off []content.ArticleID
 
 	for _, a := range articles {
+		var current bool
 		switch req.Field {
 		case 0:
-			switch req.Mode {
-			case 0:
-				if a.Favorite {
-					unfavor = append(unfavor, a.ID)
-				}
-			case 1:
-				if !a.Favorite {
-					favor = append(favor, a.ID)
-				}
-			case 2:
-				if a.Favorite {
-					unfavor = append(unfavor, a.ID)
-				} else {
-					favor = append(favor, a.ID)
-				}
-			}
+			current = a.Favorite
+		case 1:
+			current = published[a.ID]
 		case 2:
-			switch req.Mode {
-			case 0:
-				if !a.Read {
-					read = append(read, a.ID)
-				}
-			case 1:
-				if a.Read {
-					unread = append(unread, a.ID)
-				}
-			case 2:
-				if a.Read {
-					unread = append(unread, a.ID)
-				} else {
-					read = append(read, a.ID)
-				}
-			}
+			current = !a.Read
 		}
-	}
 
-	var updateCount int
-	if len(read) > 0 {
-		if err = service.ArticleRepo().Read(true, user,
-			content.IDs(read),
-			content.Filters(content.GetUserFilters(user)),
-		); err != nil {
-			return nil, errors.WithMessage(err, "marking articles as read")
+		state := req.Mode == 1
+		if req.Mode == 2 {
+			state = !current
 		}
 
-		updateCount += len(read)
-	}
-
-	if len(unread) > 0 {
-		if err = service.ArticleRepo().Read(false, user,
-			content.IDs(unread),
-			content.Filters(content.GetUserFilters(user)),
-		); err != nil {
-			return nil, errors.WithMessage(err, "marking articles as unread")
+		if state == current {
+			continue
 		}
 
-		updateCount += len(unread)
+		if state {
+			on = append(on, a.ID)
+		} else {
+			off = append(off, a.ID)
+		}
 	}
 
-	if len(favor) > 0 {
-		if err = service.ArticleRepo().Favor(true, user,
-			content.IDs(favor),
+	for _, update := range []struct {
+		state bool
+		ids   []content.ArticleID
+	}{{true, on}, {false, off}} {
+		if len(update.ids) == 0 {
+			continue
+		}
+
+		opts := []content.QueryOpt{
+			content.IDs(update.ids),
 			content.Filters(content.GetUserFilters(user)),
-		); err != nil {
-			return nil, errors.WithMessage(err, "marking articles as favorite")
 		}
 
-		updateCount += len(favor)
-	}
+		switch req.Field {
+		case 0:
+			err = errors.WithMessage(articleRepo.Favor(update.state, user, opts...), "changing favorite state")
+		case 1:
+			err = errors.WithMessage(articleRepo.Publish(update.state, user, update.ids), "changing published state")
+		case 2:
+			err = errors.WithMessage(articleRepo.Read(!update.state, user, opts...), "changing read state")
+		}
 
-	if len(unfavor) > 0 {
-		if err = service.ArticleRepo().Favor(false, user,
-			content.IDs(unfavor),
-			content.Filters(content.GetUserFilters(user)),
-		); err != nil {
-			return nil, errors.WithMessage(err, "marking articles as not favorite")
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		updateCount += len(unfavor)
+	return genericContent{Status: "OK", Updated: int64(len(on) + len(off))}, nil
+}
+
+// setArticleNote sets the note of one or more articles, same as updating
+// the note field with updateArticle.
+func setArticleNote(req request, user content.User, service repo.Service) (interface{}, error) {
+	if len(req.ArticleIds) == 0 {
+		req.ArticleIds = req.ArticleId
+	}
+
+	if req.Note != "" {
+		req.Data = req.Note
 	}
 
-	return genericContent{Status: "OK", Updated: int64(updateCount)}, nil
+	req.Field = 3
+
+	return updateArticle(req, user, service)
 }
 
 func getArticle(
@@ -320,41 +341,49 @@ func getArticle(
 	service repo.Service,
 	processors []processor.Article,
 ) (interface{}, error) {
+	ids := req.ArticleId
+	if len(ids) == 0 {
+		ids = req.ArticleIds
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.WithStack(newErr("no article ids", "INCORRECT_USAGE"))
+	}
+
 	articles, err := service.ArticleRepo().ForUser(user,
-		content.IDs(req.ArticleIds),
+		content.IDs(ids),
 		content.Filters(content.GetUserFilters(user)),
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting user articles")
 	}
 
-	feedTitles := map[content.FeedID]string{}
-
-	for _, a := range articles {
-		if _, ok := feedTitles[a.FeedID]; !ok {
-			f, err := service.FeedRepo().Get(a.FeedID, content.User{})
-			if err != nil {
-				return nil, errors.Wrapf(err, "getting feed by id %d", a.FeedID)
-			}
+	articles = processor.Articles(processors).Process(articles)
 
-			feedTitles[a.FeedID] = f.Title
-		}
+	state, err := loadArticleState(articles, user, service)
+	if err != nil {
+		return nil, err
 	}
 
 	cContent := articlesContent{}
 
 	for _, a := range articles {
-		title := feedTitles[a.FeedID]
 		h := article{
 			Id:        strconv.FormatInt(int64(a.ID), 10),
 			Unread:    !a.Read,
 			Marked:    a.Favorite,
+			Published: state.published[a.ID],
 			Updated:   a.Date.Unix(),
 			Title:     a.Title,
 			Link:      a.Link,
+			Author:    a.Author,
 			FeedId:    strconv.FormatInt(int64(a.FeedID), 10),
-			FeedTitle: title,
+			FeedTitle: state.feedTitles[a.FeedID],
 			Content:   a.Description,
+			Note:      state.notes[a.ID],
+
+			Labels:      headlineLabels(state.labels[a.ID]),
+			Attachments: attachmentsFromArticle(a),
 		}
 
 		cContent = append(cContent, h)
@@ -363,20 +392,27 @@ func getArticle(
 	return cContent, nil
 }
 
-func headlinesFromArticles(articles []content.Article, feedTitle string, content, excerpt bool) headlinesContent {
+func headlinesFromArticles(articles []content.Article, state articleState, content, excerpt, attachments bool) headlinesContent {
 	c := headlinesContent{}
 	for _, a := range articles {
-		title := feedTitle
 		h := headline{
 			Id:        a.ID,
 			Unread:    !a.Read,
 			Marked:    a.Favorite,
+			Published: state.published[a.ID],
 			Updated:   a.Date.Unix(),
 			IsUpdated: !a.Read,
 			Title:     a.Title,
 			Link:      a.Link,
+			Author:    a.Author,
 			FeedId:    strconv.FormatInt(int64(a.FeedID), 10),
-			FeedTitle: title,
+			FeedTitle: state.feedTitles[a.FeedID],
+			Note:      state.notes[a.ID],
+			Labels:    headlineLabels(state.labels[a.ID]),
+		}
+
+		if attachments {
+			h.Attachments = attachmentsFromArticle(a)
 		}
 
 		if content {
@@ -397,3 +433,157 @@ func headlinesFromArticles(articles []content.Article, feedTitle string, content
 
 	return c
 }
+
+func loadArticleState(articles []content.Article, user content.User, service repo.Service) (articleState, error) {
+	state := articleState{
+		feedTitles: map[content.FeedID]string{},
+		published:  map[content.ArticleID]bool{},
+	}
+
+	if len(articles) == 0 {
+		return state, nil
+	}
+
+	feeds, err := service.FeedRepo().ForUser(user)
+	if err != nil {
+		return state, errors.WithMessage(err, "getting user feeds")
+	}
+
+	for _, f := range feeds {
+		state.feedTitles[f.ID] = f.Title
+	}
+
+	published, err := service.ArticleRepo().PublishedIDs(user)
+	if err != nil {
+		return state, errors.WithMessage(err, "getting published article ids")
+	}
+
+	for _, id := range published {
+		state.published[id] = true
+	}
+
+	ids := make([]content.ArticleID, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	if state.labels, err = service.LabelRepo().ForArticles(ids, user); err != nil {
+		return state, errors.WithMessage(err, "getting article labels")
+	}
+
+	annotations, err := service.AnnotationRepo().ForArticles(ids, user)
+	if err != nil {
+		return state, errors.WithMessage(err, "getting article annotations")
+	}
+
+	state.notes = map[content.ArticleID]string{}
+	for id, a := range annotations {
+		if note, ok := articleNote(a); ok {
+			state.notes[id] = note.Note
+		}
+	}
+
+	return state, nil
+}
+
+// setArticleNotes stores the note as the plain, non-highlight annotation of
+// each article, removing it when the note is empty.
+func setArticleNotes(articles []content.Article, note string, user content.User, annotationRepo repo.Annotation) error {
+	ids := make([]content.ArticleID, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	annotations, err := annotationRepo.ForArticles(ids, user)
+	if err != nil {
+		return errors.WithMessage(err, "getting article annotations")
+	}
+
+	for _, id := range ids {
+		existing, ok := articleNote(annotations[id])
+		switch {
+		case !ok && note == "":
+		case !ok:
+			if _, err := annotationRepo.Create(content.Annotation{User: user.Login, ArticleID: id, Note: note}); err != nil {
+				return errors.WithMessage(err, "creating article note")
+			}
+		case note == "":
+			if err := annotationRepo.Delete(existing); err != nil {
+				return errors.WithMessage(err, "deleting article note")
+			}
+		case existing.Note != note:
+			existing.Note = note
+			existing.UpdatedAt = time.Now().UTC()
+			if err := annotationRepo.Update(existing); err != nil {
+				return errors.WithMessage(err, "updating article note")
+			}
+		}
+	}
+
+	return nil
+}
+
+// articleNote returns the first annotation of an article that is a plain
+// note rather than a highlight.
+func articleNote(annotations []content.Annotation) (content.Annotation, bool) {
+	for _, a := range annotations {
+		if !a.IsHighlight() {
+			return a, true
+		}
+	}
+
+	return content.Annotation{}, false
+}
+
+func headlineLabels(labels []content.Label) []headlineLabel {
+	res := make([]headlineLabel, len(labels))
+	for i, l := range labels {
+		res[i] = headlineLabel{labelFeedID(l.ID), l.Caption, l.FgColor, l.BgColor}
+	}
+
+	return res
+}
+
+// feedCategory returns the category of a feed, which is its first tag.
+func feedCategory(id content.FeedID, user content.User, service repo.Service) (content.TagID, error) {
+	feed, err := service.FeedRepo().Get(id, user)
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting user feed")
+	}
+
+	tags, err := service.TagRepo().ForFeed(feed, user)
+	if err != nil {
+		return 0, errors.WithMessage(err, "getting feed tags")
+	}
+
+	if len(tags) == 0 {
+		return CAT_UNCATEGORIZED, nil
+	}
+
+	return tags[0].ID, nil
+}
+
+func attachmentsFromArticle(a content.Article) []attachment {
+	attachments := make([]attachment, 0, len(a.Enclosures))
+	postId := strconv.FormatInt(int64(a.ID), 10)
+
+	for i, e := range a.Enclosures {
+		at := attachment{
+			// Enclosures have no ids of their own, derive one from the
+			// article id and their position.
+			Id:          strconv.FormatInt(int64(a.ID)*1000+int64(i), 10),
+			ContentUrl:  e.URL,
+			ContentType: e.Type,
+			PostId:      postId,
+			Title:       e.Title,
+		}
+
+		if e.Duration > 0 {
+			at.Duration = strconv.FormatInt(e.Duration, 10)
+		}
+
+		attachments = append(attachments, at)
+	}
+
+	return attachments
+}
@@ -34,6 +34,24 @@ func convertRequest(in map[string]interface{}) request {
 			req.PrefName = parseString(v)
 		case "feed_url":
 			req.FeedUrl = parseString(v)
+		case "login":
+			req.Login = parseString(v)
+		case "search_mode":
+			req.SearchMode = parseString(v)
+		case "caption":
+			req.Caption = parseString(v)
+		case "fg_color":
+			req.FgColor = parseString(v)
+		case "bg_color":
+			req.BgColor = parseString(v)
+		case "note":
+			req.Note = parseString(v)
+		case "title":
+			req.Title = parseString(v)
+		case "url":
+			req.Url = parseString(v)
+		case "content":
+			req.Content = parseString(v)
 		case "unread_only":
 			req.UnreadOnly = parseBool(v)
 		case "include_empty":
@@ -50,6 +68,8 @@ func convertRequest(in map[string]interface{}) request {
 			req.HasSandbox = parseBool(v)
 		case "include_header":
 			req.IncludeHeader = parseBool(v)
+		case "assign":
+			req.Assign = parseBool(v)
 		case "seq":
 			req.Seq = parseInt(v)
 		case "limit":
@@ -59,13 +79,18 @@ func convertRequest(in map[string]interface{}) request {
 		case "skip":
 			req.Skip = parseInt(v)
 		case "mode":
+			// catchupFeed uses named modes, while updateArticle uses
+			// numeric ones.
 			req.Mode = parseInt(v)
+			req.CatchupMode = parseString(v)
 		case "field":
 			req.Field = parseInt(v)
 		case "cat_id":
 			req.CatId = content.TagID(parseInt64(v))
 		case "feed_id":
 			req.FeedId = content.FeedID(parseInt64(v))
+		case "label_id":
+			req.LabelId = content.FeedID(parseInt64(v))
 		case "since_id":
 			req.SinceId = content.ArticleID(parseInt64(v))
 		case "article_ids":
@@ -132,6 +157,12 @@ func parseArticleIds(vv interface{}) (ids []content.ArticleID) {
 		for _, p := range v {
 			ids = append(ids, content.ArticleID(int64(p)))
 		}
+	case []interface{}:
+		for _, p := range v {
+			if i := parseInt64(p); i > 0 {
+				ids = append(ids, content.ArticleID(i))
+			}
+		}
 	case float64:
 		ids = append(ids, content.ArticleID(int64(v)))
 	}
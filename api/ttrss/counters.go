@@ -2,6 +2,7 @@ package ttrss
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,63 +17,30 @@ type counter struct {
 	Counter    int64       `json:"counter"`
 	AuxCounter int64       `json:"auxcounter,omitempty"`
 	Kind       string      `json:"kind,omitempty"`
+
+	Description string `json:"description,omitempty"`
 }
 
 func getUnread(req request, user content.User, service repo.Service) (interface{}, error) {
 	opts := []content.QueryOpt{
-		content.Filters(content.GetUserFilters(user)),
+		content.UnreadOnly, content.Filters(content.GetUserFilters(user)),
 	}
 
-	var feedGenerator func() ([]content.Feed, error)
-
-	if req.IsCat {
-		if req.FeedId > 0 {
-			tag, err := service.TagRepo().Get(content.TagID(req.FeedId), user)
-			if err != nil {
-				return nil, errors.WithMessage(err, "getting tag for user")
-			}
-
-			feedGenerator = func() ([]content.Feed, error) {
-				return service.FeedRepo().ForTag(tag, user)
-			}
-		} else if req.FeedId == CAT_UNCATEGORIZED {
-			opts = append(opts, content.UntaggedOnly)
-		} else if req.FeedId == CAT_SPECIAL {
-			opts = append(opts, content.FavoriteOnly)
-		}
-	} else {
-		switch req.FeedId {
-		case FAVORITE_ID:
-			opts = append(opts, content.FavoriteOnly)
-		case FRESH_ID:
-			opts = append(opts, content.TimeRange(time.Now().Add(FRESH_DURATION), time.Time{}))
-		default:
-			if req.FeedId > 0 {
-				feed, err := service.FeedRepo().Get(req.FeedId, user)
-				if err != nil {
-					return nil, errors.WithMessage(err, "getting user feed")
-				}
-
-				feedGenerator = func() ([]content.Feed, error) {
-					return []content.Feed{feed}, nil
-				}
-			}
-
-		}
-	}
-
-	if feedGenerator != nil {
-		feeds, err := feedGenerator()
+	// Without a feed, the global unread count is returned.
+	if req.FeedId != 0 || req.IsCat {
+		q, err := queryFeed(req.FeedId, req.IsCat, user, service)
 		if err != nil {
-			return nil, errors.WithMessage(err, "getting feeds")
+			return nil, err
 		}
 
-		ids := make([]content.FeedID, len(feeds))
-		for i := range feeds {
-			ids[i] = feeds[i].ID
+		if q.empty() {
+			return genericContent{Unread: "0"}, nil
 		}
 
-		opts = append(opts, content.FeedIDs(ids))
+		opts = append(q.options(time.Time{}), content.UnreadOnly)
+		if req.IsCat && req.FeedId == CAT_SPECIAL {
+			opts = append(opts, content.FavoriteOnly)
+		}
 	}
 
 	count, err := service.ArticleRepo().Count(user, opts...)
@@ -127,7 +95,25 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 			Counter:    unreadFavCount,
 			AuxCounter: favCount})
 
-	cContent = append(cContent, counter{Id: PUBLISHED_ID})
+	published, err := articleRepo.PublishedIDs(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting published article ids")
+	}
+
+	unreadPublishedCount, err := countArticles(articleRepo, user, published, content.UnreadOnly)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting published unread count")
+	}
+
+	publishedCount, err := countArticles(articleRepo, user, published)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting published count")
+	}
+
+	cContent = append(cContent,
+		counter{Id: PUBLISHED_ID,
+			Counter:    unreadPublishedCount,
+			AuxCounter: publishedCount})
 
 	freshTime := time.Now().Add(FRESH_DURATION)
 	freshCount, err := articleRepo.Count(user, content.UnreadOnly,
@@ -142,32 +128,60 @@ func getCounters(req request, user content.User, service repo.Service) (interfac
 			Counter:    freshCount,
 			AuxCounter: 0})
 
-	userCount, err := articleRepo.Count(user,
-		content.Filters(content.GetUserFilters(user)),
-	)
-	if err != nil {
-		return nil, errors.WithMessage(err, "getting user count")
-	}
 	cContent = append(cContent,
 		counter{Id: ALL_ID,
-			Counter:    userCount,
+			Counter:    unreadCount,
 			AuxCounter: 0})
 
-	for _, f := range feeds {
-		feedCount, err := articleRepo.Count(user,
-			content.FeedIDs([]content.FeedID{f.ID}),
-			content.Filters(content.GetUserFilters(user)),
-		)
-		if err != nil {
-			return nil, errors.WithMessage(err, "getting feed count")
+	if strings.Contains(req.OutputMode, "f") {
+		for _, f := range feeds {
+			feedCount, err := articleRepo.Count(user, content.UnreadOnly,
+				content.FeedIDs([]content.FeedID{f.ID}),
+				content.Filters(content.GetUserFilters(user)),
+			)
+			if err != nil {
+				return nil, errors.WithMessage(err, "getting feed unread count")
+			}
+			cContent = append(cContent,
+				counter{Id: int64(f.ID), Counter: feedCount},
+			)
+
 		}
-		cContent = append(cContent,
-			counter{Id: int64(f.ID), Counter: feedCount},
-		)
+	}
 
+	labels, err := userLabels(user, service)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(req.OutputMode, "l") {
+		for _, l := range labels {
+			cContent = append(cContent,
+				counter{
+					Id:          int64(labelFeedID(l.ID)),
+					Counter:     l.unread,
+					AuxCounter:  l.total,
+					Description: l.Caption,
+				},
+			)
+		}
+	}
+
+	if !strings.Contains(req.OutputMode, "c") {
+		return cContent, nil
+	}
+
+	labeled, err := labeledArticleIDs(user, service)
+	if err != nil {
+		return nil, err
+	}
+
+	labelsCount, err := countArticles(articleRepo, user, labeled, content.UnreadOnly)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting labeled unread count")
 	}
 
-	cContent = append(cContent, counter{Id: CAT_LABELS, Counter: 0, Kind: "cat"})
+	cContent = append(cContent, counter{Id: CAT_LABELS, Counter: labelsCount, Kind: "cat"})
 
 	tagRepo := service.TagRepo()
 	tags, err := tagRepo.ForUser(user)
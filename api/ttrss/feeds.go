@@ -2,10 +2,13 @@ package ttrss
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo"
 )
@@ -93,6 +96,43 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 				CatId:  FAVORITE_ID,
 			})
 		}
+
+		published, err := articleRepo.PublishedIDs(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting published article ids")
+		}
+
+		unreadPublished, err := countArticles(articleRepo, user, published, content.UnreadOnly)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting unread published count")
+		}
+
+		if unreadPublished > 0 || !req.UnreadOnly {
+			fContent = append(fContent, feed{
+				Id:     PUBLISHED_ID,
+				Title:  specialTitle(PUBLISHED_ID),
+				Unread: unreadPublished,
+				CatId:  FAVORITE_ID,
+			})
+		}
+	}
+
+	if req.CatId == CAT_ALL || req.CatId == CAT_LABELS {
+		labels, err := userLabels(user, service)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range labels {
+			if l.unread > 0 || !req.UnreadOnly {
+				fContent = append(fContent, feed{
+					Id:     labelFeedID(l.ID),
+					Title:  l.Caption,
+					Unread: l.unread,
+					CatId:  CAT_LABELS,
+				})
+			}
+		}
 	}
 
 	var feeds []content.Feed
@@ -171,55 +211,55 @@ func getFeeds(req request, user content.User, service repo.Service) (interface{}
 	return fContent, nil
 }
 
-func updateFeed(req request, user content.User, service repo.Service) (interface{}, error) {
-	return genericContent{Status: "OK"}, nil
+func registerFeedActions(feedManager *readeef.FeedManager) {
+	actions["updateFeed"] = func(req request, user content.User, service repo.Service) (interface{}, error) {
+		return updateFeed(req, user, feedManager, service)
+	}
 }
 
-func catchupFeed(req request, user content.User, service repo.Service) (interface{}, error) {
-	o := []content.QueryOpt{
-		content.TimeRange(time.Time{}, time.Now()),
-		content.Filters(content.GetUserFilters(user)),
+func updateFeed(
+	req request,
+	user content.User,
+	feedManager *readeef.FeedManager,
+	service repo.Service,
+) (interface{}, error) {
+	feed, err := service.FeedRepo().Get(req.FeedId, user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return nil, errors.WithStack(newErr("no feed", "FEED_NOT_FOUND"))
+		}
+		return nil, errors.WithMessage(err, "getting user feed")
 	}
 
-	var feedGenerator func() ([]content.Feed, error)
-
-	if req.IsCat {
-		tagID := content.TagID(req.FeedId)
+	if _, err := feedManager.RefreshFeed(feed); err != nil {
+		return nil, errors.WithMessage(err, "refreshing feed")
+	}
 
-		if tagID == CAT_UNCATEGORIZED {
-			o = append(o, content.UntaggedOnly)
-		} else {
-			tag, err := service.TagRepo().Get(tagID, user)
-			if err != nil {
-				return nil, errors.WithMessage(err, "getting tag for user")
-			}
+	return genericContent{Status: "OK"}, nil
+}
 
-			feedGenerator = func() ([]content.Feed, error) {
-				return service.FeedRepo().ForTag(tag, user)
-			}
-		}
-	} else {
-		feedGenerator = func() ([]content.Feed, error) {
-			feed, err := service.FeedRepo().Get(req.FeedId, user)
-			return []content.Feed{feed}, err
-		}
+func catchupFeed(req request, user content.User, service repo.Service) (interface{}, error) {
+	q, err := queryFeed(req.FeedId, req.IsCat, user, service)
+	if err != nil {
+		return nil, err
 	}
 
-	if feedGenerator != nil {
-		feeds, err := feedGenerator()
-		if err != nil {
-			return nil, errors.WithMessage(err, "getting feeds")
-		}
-
-		ids := make([]content.FeedID, len(feeds))
-		for i := range feeds {
-			ids[i] = feeds[i].ID
-		}
+	if q.empty() {
+		return genericContent{Status: "OK"}, nil
+	}
 
-		o = append(o, content.FeedIDs(ids))
+	// Only the articles older than the given period are caught up.
+	before := time.Now()
+	switch req.CatchupMode {
+	case "1day":
+		before = before.AddDate(0, 0, -1)
+	case "1week":
+		before = before.AddDate(0, 0, -7)
+	case "2week":
+		before = before.AddDate(0, 0, -14)
 	}
 
-	if err := service.ArticleRepo().Read(true, user, o...); err != nil {
+	if err := service.ArticleRepo().Read(true, user, q.options(before)...); err != nil {
 		return nil, errors.WithMessage(err, "setting read state")
 	}
 
@@ -229,19 +269,28 @@ func catchupFeed(req request, user content.User, service repo.Service) (interfac
 func getFeedTree(req request, user content.User, service repo.Service) (interface{}, error) {
 	items := []category{}
 
-	special, err := createSpecialCategory(service.ArticleRepo(), user)
+	special, err := createSpecialCategory(service, user)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting special categories")
 	}
 	items = append(items, special)
 
+	labels, err := createLabelsCategory(service, user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting labels category")
+	}
+
+	if len(labels.Items) > 0 || req.IncludeEmpty {
+		items = append(items, labels)
+	}
+
 	feeds, err := service.FeedRepo().ForUser(user)
 	if err != nil {
 		return nil, errors.WithMessage(err, "getting user feeds")
 	}
 
 	uncat := category{Id: "CAT:0", Items: []category{}, BareId: 0, Name: "Uncategorized", Type: "category"}
-	tagCategories := map[content.Tag]category{}
+	tagCategories := map[content.TagID]category{}
 
 	for _, f := range feeds {
 		tags, err := service.TagRepo().ForFeed(f, user)
@@ -249,17 +298,15 @@ func getFeedTree(req request, user content.User, service repo.Service) (interfac
 			return nil, errors.WithMessage(err, "getting feed tags")
 		}
 
-		item, err := feedListCategoryFeed(service.ArticleRepo(), user, f, f.ID)
+		item, err := feedListCategoryFeed(service, user, f, f.ID)
 		if err != nil {
 			return nil, err
 		}
 
 		if len(tags) > 0 {
 			for _, t := range tags {
-				var c category
-				if cached, ok := tagCategories[t]; ok {
-					c = cached
-				} else {
+				c, ok := tagCategories[t.ID]
+				if !ok {
 					c = category{
 						Id:     "CAT:" + strconv.FormatInt(int64(t.ID), 10),
 						BareId: content.FeedID(t.ID),
@@ -270,23 +317,31 @@ func getFeedTree(req request, user content.User, service repo.Service) (interfac
 				}
 
 				c.Items = append(c.Items, item)
-				tagCategories[t] = c
+				tagCategories[t.ID] = c
 			}
 		} else {
 			uncat.Items = append(uncat.Items, item)
 		}
 	}
 
-	categories := []category{uncat}
+	categories := make([]category, 0, len(tagCategories))
 	for _, c := range tagCategories {
 		categories = append(categories, c)
 	}
 
+	sort.Slice(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].Name) < strings.ToLower(categories[j].Name)
+	})
+
+	if len(uncat.Items) > 0 || req.IncludeEmpty {
+		categories = append(categories, uncat)
+	}
+
 	for _, c := range categories {
 		if len(c.Items) == 1 {
 			c.Param = "(1 feed)"
 		} else {
-			c.Param = fmt.Sprintf("(%d feed)", len(c.Items))
+			c.Param = fmt.Sprintf("(%d feeds)", len(c.Items))
 		}
 		items = append(items, c)
 	}
@@ -317,12 +372,13 @@ func specialTitle(id content.FeedID) (t string) {
 }
 
 func feedListCategoryFeed(
-	repo repo.Article,
+	service repo.Service,
 	user content.User,
 	feed content.Feed,
 	id content.FeedID,
 ) (category, error) {
 	c := category{BareId: id, Id: "FEED:" + strconv.FormatInt(int64(id), 10), Type: "feed"}
+	repo := service.ArticleRepo()
 
 	var err error
 	if feed.ID > 0 {
@@ -353,6 +409,11 @@ func feedListCategoryFeed(
 			c.Unread, err = repo.Count(user, content.UnreadOnly,
 				content.Filters(content.GetUserFilters(user)),
 			)
+		case PUBLISHED_ID:
+			var ids []content.ArticleID
+			if ids, err = repo.PublishedIDs(user); err == nil {
+				c.Unread, err = countArticles(repo, user, ids, content.UnreadOnly)
+			}
 		}
 
 		if err != nil {
@@ -363,14 +424,14 @@ func feedListCategoryFeed(
 	return c, nil
 }
 
-func createSpecialCategory(repo repo.Article, user content.User) (category, error) {
+func createSpecialCategory(service repo.Service, user content.User) (category, error) {
 	ids := [...]content.FeedID{ALL_ID, FRESH_ID, FAVORITE_ID, PUBLISHED_ID, ARCHIVED_ID, RECENTLY_READ_ID}
 
 	special := category{Id: "CAT:-1", Items: make([]category, len(ids)), Name: "Special", Type: "category", BareId: -1}
 
 	var err error
 	for i, id := range ids {
-		special.Items[i], err = feedListCategoryFeed(repo, user, content.Feed{}, id)
+		special.Items[i], err = feedListCategoryFeed(service, user, content.Feed{}, id)
 
 		if err != nil {
 			return category{}, err
@@ -380,9 +441,188 @@ func createSpecialCategory(repo repo.Article, user content.User) (category, erro
 	return special, nil
 }
 
+func createLabelsCategory(service repo.Service, user content.User) (category, error) {
+	labels, err := userLabels(user, service)
+	if err != nil {
+		return category{}, err
+	}
+
+	c := category{Id: "CAT:-2", Items: make([]category, len(labels)), Name: "Labels", Type: "category", BareId: CAT_LABELS}
+
+	for i, l := range labels {
+		id := labelFeedID(l.ID)
+		c.Items[i] = category{
+			Id:     "FEED:" + strconv.FormatInt(int64(id), 10),
+			BareId: id,
+			Name:   l.Caption,
+			Type:   "feed",
+			Unread: l.unread,
+		}
+	}
+
+	return c, nil
+}
+
+// feedQuery describes the articles of a feed, which can be a regular
+// feed, a category, or one of the virtual feeds.
+type feedQuery struct {
+	opts  []content.QueryOpt
+	title string
+	after time.Time
+
+	// The query is limited to the ids, if restricted.
+	ids        []content.ArticleID
+	restricted bool
+}
+
+func (q *feedQuery) restrict(ids []content.ArticleID) {
+	if q.restricted {
+		set := map[content.ArticleID]struct{}{}
+		for _, id := range q.ids {
+			set[id] = struct{}{}
+		}
+
+		common := []content.ArticleID{}
+		for _, id := range ids {
+			if _, ok := set[id]; ok {
+				common = append(common, id)
+			}
+		}
+
+		ids = common
+	}
+
+	q.ids, q.restricted = ids, true
+}
+
+// empty reports whether the query is known to match no articles.
+func (q feedQuery) empty() bool {
+	return q.restricted && len(q.ids) == 0
+}
+
+// options returns the query options for articles older than the given
+// time, which can be zero.
+func (q feedQuery) options(before time.Time) []content.QueryOpt {
+	opts := append([]content.QueryOpt{}, q.opts...)
+	if q.restricted {
+		opts = append(opts, content.IDs(q.ids))
+	}
+
+	if !q.after.IsZero() || !before.IsZero() {
+		opts = append(opts, content.TimeRange(q.after, before))
+	}
+
+	return opts
+}
+
+func queryFeed(id content.FeedID, isCat bool, user content.User, service repo.Service) (feedQuery, error) {
+	q := feedQuery{opts: []content.QueryOpt{content.Filters(content.GetUserFilters(user))}}
+
+	var feedGenerator func() ([]content.Feed, error)
+
+	if isCat {
+		switch {
+		case id == CAT_UNCATEGORIZED:
+			q.opts = append(q.opts, content.UntaggedOnly)
+			q.title = "Uncategorized"
+		case id == CAT_LABELS:
+			ids, err := labeledArticleIDs(user, service)
+			if err != nil {
+				return q, err
+			}
+
+			q.restrict(ids)
+			q.title = "Labels"
+		case id > 0:
+			tag, err := service.TagRepo().Get(content.TagID(id), user)
+			if err != nil {
+				return q, errors.WithMessage(err, "getting tag for user")
+			}
+
+			feedGenerator = func() ([]content.Feed, error) {
+				return service.FeedRepo().ForTag(tag, user)
+			}
+
+			q.title = string(tag.Value)
+		}
+	} else {
+		switch {
+		case id == FAVORITE_ID:
+			q.opts = append(q.opts, content.FavoriteOnly)
+		case id == FRESH_ID:
+			q.after = time.Now().Add(FRESH_DURATION)
+		case id == ALL_ID:
+		case id == PUBLISHED_ID:
+			ids, err := service.ArticleRepo().PublishedIDs(user)
+			if err != nil {
+				return q, errors.WithMessage(err, "getting published article ids")
+			}
+
+			q.restrict(ids)
+		case isLabelFeed(id):
+			label, err := service.LabelRepo().Get(feedLabelID(id), user)
+			if err != nil {
+				return q, errors.WithMessage(err, "getting label for user")
+			}
+
+			q.opts = append(q.opts, content.LabelIDs([]content.LabelID{label.ID}))
+			q.title = label.Caption
+		case id > 0:
+			feed, err := service.FeedRepo().Get(id, user)
+			if err != nil {
+				return q, errors.WithMessage(err, "getting user feed")
+			}
+
+			feedGenerator = func() ([]content.Feed, error) {
+				return []content.Feed{feed}, nil
+			}
+
+			q.title = feed.Title
+		default:
+			// Readeef doesn't keep archived or recently read articles.
+			q.restrict(nil)
+		}
+
+		if q.title == "" {
+			q.title = specialTitle(id)
+		}
+	}
+
+	if feedGenerator != nil {
+		feeds, err := feedGenerator()
+		if err != nil {
+			return q, errors.WithMessage(err, "getting feeds")
+		}
+
+		ids := make([]content.FeedID, len(feeds))
+		for i := range feeds {
+			ids[i] = feeds[i].ID
+		}
+
+		if len(ids) == 0 {
+			q.restrict(nil)
+		}
+
+		q.opts = append(q.opts, content.FeedIDs(ids))
+	}
+
+	return q, nil
+}
+
+// countArticles counts the user articles with the given ids, since a
+// query without ids is not limited at all.
+func countArticles(repo repo.Article, user content.User, ids []content.ArticleID, opts ...content.QueryOpt) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	opts = append(opts, content.IDs(ids), content.Filters(content.GetUserFilters(user)))
+
+	return repo.Count(user, opts...)
+}
+
 func init() {
 	actions["getFeeds"] = getFeeds
-	actions["updateFeed"] = updateFeed
 	actions["catchupFeed"] = catchupFeed
 	actions["getFeedTree"] = getFeedTree
 }
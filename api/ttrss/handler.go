@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/api/compat"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/processor"
 	"github.com/urandom/readeef/content/repo"
@@ -20,36 +21,49 @@ import (
 )
 
 type request struct {
-	Op            string              `json:"op"`
-	Sid           string              `json:"sid"`
-	Seq           int                 `json:"seq"`
-	User          string              `json:"user"`
-	Password      string              `json:"password"`
-	OutputMode    string              `json:"output_mode"`
-	UnreadOnly    bool                `json:"unread_only"`
-	IncludeEmpty  bool                `json:"include_empty"`
-	Limit         int                 `json:"limit"`
-	Offset        int                 `json:"offset"`
-	CatId         content.TagID       `json:"cat_id"`
-	FeedId        content.FeedID      `json:"feed_id"`
-	Skip          int                 `json:"skip"`
-	IsCat         bool                `json:"is_cat"`
-	ShowContent   bool                `json:"show_content"`
-	ShowExcerpt   bool                `json:"show_excerpt"`
-	ViewMode      string              `json:"view_mode"`
-	SinceId       content.ArticleID   `json:"since_id"`
-	Sanitize      bool                `json:"sanitize"`
-	HasSandbox    bool                `json:"has_sandbox"`
-	IncludeHeader bool                `json:"include_header"`
-	OrderBy       string              `json:"order_by"`
-	Search        string              `json:"search"`
-	ArticleIds    []content.ArticleID `json:"article_ids"`
-	Mode          int                 `json:"mode"`
-	Field         int                 `json:"field"`
-	Data          string              `json:"data"`
-	ArticleId     []content.ArticleID `json:"article_id"`
-	PrefName      string              `json:"pref_name"`
-	FeedUrl       string              `json:"feed_url"`
+	Op                 string              `json:"op"`
+	Sid                string              `json:"sid"`
+	Seq                int                 `json:"seq"`
+	User               string              `json:"user"`
+	Password           string              `json:"password"`
+	OutputMode         string              `json:"output_mode"`
+	UnreadOnly         bool                `json:"unread_only"`
+	IncludeEmpty       bool                `json:"include_empty"`
+	Limit              int                 `json:"limit"`
+	Offset             int                 `json:"offset"`
+	CatId              content.TagID       `json:"cat_id"`
+	FeedId             content.FeedID      `json:"feed_id"`
+	Skip               int                 `json:"skip"`
+	IsCat              bool                `json:"is_cat"`
+	ShowContent        bool                `json:"show_content"`
+	ShowExcerpt        bool                `json:"show_excerpt"`
+	IncludeAttachments bool                `json:"include_attachments"`
+	ViewMode           string              `json:"view_mode"`
+	SinceId            content.ArticleID   `json:"since_id"`
+	Sanitize           bool                `json:"sanitize"`
+	HasSandbox         bool                `json:"has_sandbox"`
+	IncludeHeader      bool                `json:"include_header"`
+	OrderBy            string              `json:"order_by"`
+	Search             string              `json:"search"`
+	ArticleIds         []content.ArticleID `json:"article_ids"`
+	Mode               int                 `json:"mode"`
+	CatchupMode        string              `json:"-"`
+	SearchMode         string              `json:"search_mode"`
+	Field              int                 `json:"field"`
+	Data               string              `json:"data"`
+	ArticleId          []content.ArticleID `json:"article_id"`
+	PrefName           string              `json:"pref_name"`
+	FeedUrl            string              `json:"feed_url"`
+	Login              string              `json:"login"`
+	LabelId            content.FeedID      `json:"label_id"`
+	Assign             bool                `json:"assign"`
+	Caption            string              `json:"caption"`
+	FgColor            string              `json:"fg_color"`
+	BgColor            string              `json:"bg_color"`
+	Note               string              `json:"note"`
+	Title              string              `json:"title"`
+	Url                string              `json:"url"`
+	Content            string              `json:"content"`
 }
 
 type response struct {
@@ -68,7 +82,7 @@ const (
 	API_STATUS_OK  = 0
 	API_STATUS_ERR = 1
 	API_VERSION    = "1.8.0"
-	API_LEVEL      = 12
+	API_LEVEL      = 15
 
 	ARCHIVED_ID      = 0
 	FAVORITE_ID      = -1
@@ -84,6 +98,9 @@ const (
 	CAT_LABELS             = -2
 	CAT_ALL_EXCEPT_VIRTUAL = -3 // i.e: labels
 	CAT_ALL                = -4
+
+	// Labels are exposed as virtual feeds, with ids below the base index.
+	LABEL_BASE_INDEX = -1024
 )
 
 var (
@@ -102,10 +119,11 @@ func Handler(
 ) http.HandlerFunc {
 	sessionManager := newSession(ctx)
 
-	processors = filterProcessors(processors)
+	processors = compat.FilterProcessors(processors)
 
 	registerAuthActions(sessionManager, secret)
 	registerArticleActions(searchProvider, processors)
+	registerFeedActions(feedManager)
 	registerSettingActions(feedManager, update)
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -166,6 +184,20 @@ func Handler(
 	}
 }
 
+// labelFeedID converts a label id to the id of its virtual feed.
+func labelFeedID(id content.LabelID) content.FeedID {
+	return content.FeedID(LABEL_BASE_INDEX - 1 - id)
+}
+
+// feedLabelID converts a virtual feed id back to the label id.
+func feedLabelID(id content.FeedID) content.LabelID {
+	return content.LabelID(LABEL_BASE_INDEX - 1 - id)
+}
+
+func isLabelFeed(id content.FeedID) bool {
+	return id < LABEL_BASE_INDEX
+}
+
 func FakeWebHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusMovedPermanently)
 }
@@ -207,20 +239,6 @@ func writeJson(w http.ResponseWriter, req request, resp response, data interface
 	}
 }
 
-func filterProcessors(input []processor.Article) []processor.Article {
-	processors := make([]processor.Article, 0, len(input))
-
-	for i := range input {
-		if _, ok := input[i].(processor.ProxyHTTP); ok {
-			continue
-		}
-
-		processors = append(processors, input[i])
-	}
-
-	return processors
-}
-
 type err struct {
 	message string
 	kind    string
@@ -243,7 +261,7 @@ func errorKind(err error) string {
 		Kind() string
 	}
 
-	if v, ok := err.(kinder); ok {
+	if v, ok := errors.Cause(err).(kinder); ok {
 		return v.Kind()
 	}
 
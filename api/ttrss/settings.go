@@ -1,6 +1,7 @@
 package ttrss
 
 import (
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -45,13 +46,59 @@ func getPref(
 		return genericContent{Value: true}, nil
 	case "FRESH_ARTICLE_MAX_AGE":
 		return genericContent{Value: (-1 * FRESH_DURATION).Hours()}, nil
+	case "ENABLE_API_ACCESS", "PURGE_UNREAD_ARTICLES", "CDM_EXPANDED":
+		return genericContent{Value: true}, nil
+	case "ALLOW_DUPLICATE_POSTS", "AUTO_ASSIGN_LABELS", "COMBINED_DISPLAY_MODE",
+		"REVERSE_HEADLINES", "STRIP_IMAGES", "SORT_HEADLINES_BY_FEED_DATE",
+		"ON_CATCHUP_SHOW_NEXT_FEED", "VFEED_GROUP_BY_FEED":
+		return genericContent{Value: false}, nil
+	case "PURGE_OLD_DAYS":
+		return genericContent{Value: 0}, nil
+	case "USER_LANGUAGE", "USER_TIMEZONE", "USER_STYLESHEET":
+		return genericContent{Value: ""}, nil
+	case "_DEFAULT_VIEW_MODE":
+		return genericContent{Value: "adaptive"}, nil
+	case "_DEFAULT_VIEW_LIMIT":
+		return genericContent{Value: 30}, nil
+	case "_DEFAULT_VIEW_ORDER_BY":
+		return genericContent{Value: "default"}, nil
 	default:
 		return unknown(req, user, service)
 	}
 }
 
+// shareToPublished publishes the user article with the given url. Unlike
+// tt-rss, readeef can't create articles outside of feeds, so only articles
+// from subscribed feeds can be shared.
 func shareToPublished(req request, user content.User, service repo.Service) (interface{}, error) {
-	return nil, errors.WithStack(newErr("unsupported operation", "Publishing failed"))
+	if req.Url == "" {
+		return nil, errors.WithStack(newErr("no url", "INCORRECT_USAGE"))
+	}
+
+	// The user filters exclude the matching articles, so an inverse url
+	// filter limits the query to the ones containing the url.
+	filters := append(content.GetUserFilters(user), content.Filter{
+		URLTerm: strings.ToLower(req.Url), InverseURL: true,
+	})
+
+	articles, err := service.ArticleRepo().ForUser(user, content.Filters(filters))
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting articles by url")
+	}
+
+	for _, a := range articles {
+		if a.Link != req.Url {
+			continue
+		}
+
+		if err := service.ArticleRepo().Publish(true, user, []content.ArticleID{a.ID}); err != nil {
+			return nil, errors.WithMessage(err, "publishing article")
+		}
+
+		return genericContent{Status: "OK"}, nil
+	}
+
+	return nil, errors.WithStack(newErr("article not found in the subscribed feeds", "Publishing failed"))
 }
 
 func subscribeToFeed(
@@ -63,7 +110,10 @@ func subscribeToFeed(
 	repo := service.FeedRepo()
 	feed, err := repo.FindByLink(req.FeedUrl)
 	if content.IsNoContent(err) {
-		feed, err = feedManager.AddFeedByLink(req.FeedUrl)
+		// The feed credentials share the password parameter with the
+		// login operation.
+		auth := content.FeedAuth{Username: req.Login, Password: req.Password}
+		feed, err = feedManager.AddFeedByLink(req.FeedUrl, auth)
 		if err != nil {
 			return nil, errors.WithStack(newErr(err.Error(), "INCORRECT_USAGE"))
 		}
@@ -9,6 +9,7 @@ import (
 )
 
 type categoriesContent []cat
+type labelsContent []label
 
 type cat struct {
 	Id      string `json:"id"`
@@ -17,6 +18,14 @@ type cat struct {
 	OrderId int64  `json:"order_id"`
 }
 
+type label struct {
+	Id      content.FeedID `json:"id"`
+	Caption string         `json:"caption"`
+	FgColor string         `json:"fg_color"`
+	BgColor string         `json:"bg_color"`
+	Checked bool           `json:"checked"`
+}
+
 func getCategories(req request, user content.User, service repo.Service) (interface{}, error) {
 	articleRepo := service.ArticleRepo()
 	tagRepo := service.TagRepo()
@@ -62,6 +71,29 @@ func getCategories(req request, user content.User, service repo.Service) (interf
 		)
 	}
 
+	labels, err := service.LabelRepo().ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	if len(labels) > 0 {
+		labeled, err := labeledArticleIDs(user, service)
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := countArticles(articleRepo, user, labeled, content.UnreadOnly)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting unread labeled count")
+		}
+
+		if count > 0 || !req.UnreadOnly {
+			cContent = append(cContent,
+				cat{Id: strconv.FormatInt(CAT_LABELS, 10), Title: "Labels", Unread: count},
+			)
+		}
+	}
+
 	count, err = articleRepo.Count(user,
 		content.UnreadOnly, content.FavoriteOnly,
 		content.Filters(content.GetUserFilters(user)),
@@ -80,15 +112,209 @@ func getCategories(req request, user content.User, service repo.Service) (interf
 }
 
 func getLabels(req request, user content.User, service repo.Service) (interface{}, error) {
-	return []interface{}{}, nil
+	labelRepo := service.LabelRepo()
+
+	labels, err := labelRepo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	checked := map[content.LabelID]bool{}
+	if len(req.ArticleId) > 0 {
+		articleLabels, err := labelRepo.ForArticles(req.ArticleId[:1], user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting article labels")
+		}
+
+		for _, l := range articleLabels[req.ArticleId[0]] {
+			checked[l.ID] = true
+		}
+	}
+
+	lContent := labelsContent{}
+	for _, l := range labels {
+		lContent = append(lContent, label{
+			Id:      labelFeedID(l.ID),
+			Caption: l.Caption,
+			FgColor: l.FgColor,
+			BgColor: l.BgColor,
+			Checked: checked[l.ID],
+		})
+	}
+
+	return lContent, nil
 }
 
 func setArticleLabel(req request, user content.User, service repo.Service) (interface{}, error) {
-	return genericContent{Status: "OK", Updated: 0}, nil
+	label, err := service.LabelRepo().Get(feedLabelID(req.LabelId), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return nil, errors.WithStack(newErr("no label", "INCORRECT_USAGE"))
+		}
+		return nil, errors.WithMessage(err, "getting user label")
+	}
+
+	if len(req.ArticleIds) == 0 {
+		return genericContent{Status: "OK"}, nil
+	}
+
+	// Only the user's own articles can be labeled.
+	ids, err := service.ArticleRepo().IDs(user, content.IDs(req.ArticleIds))
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user article ids")
+	}
+
+	if req.Assign {
+		err = service.LabelRepo().Assign(label, ids)
+	} else {
+		err = service.LabelRepo().Unassign(label, ids)
+	}
+
+	if err != nil {
+		return nil, errors.WithMessage(err, "changing article labels")
+	}
+
+	return genericContent{Status: "OK", Updated: int64(len(ids))}, nil
+}
+
+// addLabel is a readeef extension, since the tt-rss api offers no way of
+// creating labels.
+func addLabel(req request, user content.User, service repo.Service) (interface{}, error) {
+	if req.Caption == "" {
+		return nil, errors.WithStack(newErr("no caption", "INCORRECT_USAGE"))
+	}
+
+	label, err := service.LabelRepo().Create(content.Label{
+		User: user.Login, Caption: req.Caption, FgColor: req.FgColor, BgColor: req.BgColor,
+	})
+	if err != nil {
+		if content.IsValidationError(err) {
+			return nil, errors.WithStack(newErr(err.Error(), "INCORRECT_USAGE"))
+		}
+		return nil, errors.WithMessage(err, "creating label")
+	}
+
+	return genericContent{Status: "OK", Value: labelFeedID(label.ID)}, nil
+}
+
+// updateLabel is a readeef extension, renaming or recoloring the given
+// label. Empty fields keep their current values.
+func updateLabel(req request, user content.User, service repo.Service) (interface{}, error) {
+	label, err := service.LabelRepo().Get(feedLabelID(req.LabelId), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return nil, errors.WithStack(newErr("no label", "INCORRECT_USAGE"))
+		}
+		return nil, errors.WithMessage(err, "getting user label")
+	}
+
+	if req.Caption != "" {
+		label.Caption = req.Caption
+	}
+	if req.FgColor != "" {
+		label.FgColor = req.FgColor
+	}
+	if req.BgColor != "" {
+		label.BgColor = req.BgColor
+	}
+
+	if err := service.LabelRepo().Update(label); err != nil {
+		if content.IsValidationError(err) {
+			return nil, errors.WithStack(newErr(err.Error(), "INCORRECT_USAGE"))
+		}
+		return nil, errors.WithMessage(err, "updating label")
+	}
+
+	return genericContent{Status: "OK"}, nil
+}
+
+// removeLabel is a readeef extension, deleting the given label.
+func removeLabel(req request, user content.User, service repo.Service) (interface{}, error) {
+	label, err := service.LabelRepo().Get(feedLabelID(req.LabelId), user)
+	if err != nil {
+		if content.IsNoContent(err) {
+			return nil, errors.WithStack(newErr("no label", "INCORRECT_USAGE"))
+		}
+		return nil, errors.WithMessage(err, "getting user label")
+	}
+
+	if err := service.LabelRepo().Delete(label); err != nil {
+		return nil, errors.WithMessage(err, "deleting label")
+	}
+
+	return genericContent{Status: "OK"}, nil
+}
+
+// userLabel is a user label, along with its article counts.
+type userLabel struct {
+	content.Label
+
+	unread int64
+	total  int64
+}
+
+func userLabels(user content.User, service repo.Service) ([]userLabel, error) {
+	labelRepo := service.LabelRepo()
+
+	labels, err := labelRepo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	articleRepo := service.ArticleRepo()
+	filters := content.Filters(content.GetUserFilters(user))
+
+	res := make([]userLabel, len(labels))
+	for i, l := range labels {
+		opt := content.LabelIDs([]content.LabelID{l.ID})
+
+		res[i].Label = l
+		if res[i].unread, err = articleRepo.Count(user, opt, filters, content.UnreadOnly); err != nil {
+			return nil, errors.WithMessage(err, "getting label unread count")
+		}
+
+		if res[i].total, err = articleRepo.Count(user, opt, filters); err != nil {
+			return nil, errors.WithMessage(err, "getting label count")
+		}
+	}
+
+	return res, nil
+}
+
+// labeledArticleIDs returns the ids of all articles with at least one
+// user label.
+func labeledArticleIDs(user content.User, service repo.Service) ([]content.ArticleID, error) {
+	labelRepo := service.LabelRepo()
+
+	labels, err := labelRepo.ForUser(user)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting user labels")
+	}
+
+	set := map[content.ArticleID]struct{}{}
+	ids := []content.ArticleID{}
+	for _, l := range labels {
+		labelIDs, err := labelRepo.ArticleIDs(l)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting label article ids")
+		}
+
+		for _, id := range labelIDs {
+			if _, ok := set[id]; !ok {
+				set[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
 }
 
 func init() {
 	actions["getCategories"] = getCategories
 	actions["getLabels"] = getLabels
 	actions["setArticleLabel"] = setArticleLabel
+	actions["addLabel"] = addLabel
+	actions["updateLabel"] = updateLabel
+	actions["removeLabel"] = removeLabel
 }
@@ -18,14 +18,18 @@ const (
 	profileSetting   = "profile"
 	activeSetting    = "is-active"
 	passwordSetting  = "password"
+	apiKeySetting    = "api-key"
 )
 
+var settingKeys = []string{
+	firstNameSetting, lastNameSetting,
+	emailSetting, profileSetting,
+	activeSetting, passwordSetting,
+	apiKeySetting,
+}
+
 func getSettingKeys(w http.ResponseWriter, r *http.Request) {
-	args{"keys": []string{
-		firstNameSetting, lastNameSetting,
-		emailSetting, profileSetting,
-		activeSetting, passwordSetting,
-	}}.WriteJSON(w)
+	args{"keys": settingKeys}.WriteJSON(w)
 }
 
 func getSettingValue(w http.ResponseWriter, r *http.Request) {
@@ -73,6 +77,7 @@ func setSettingValue(repo repo.User, secret []byte, log log.Log) http.HandlerFun
 		value := r.Form.Get("value")
 
 		var err error
+		var apiKey string
 		switch chi.URLParam(r, "key") {
 		case firstNameSetting:
 			user.FirstName = value
@@ -99,6 +104,16 @@ func setSettingValue(repo repo.User, secret []byte, log log.Log) http.HandlerFun
 				http.Error(w, "Not authorized", http.StatusBadRequest)
 				return
 			}
+		case apiKeySetting:
+			// The value is ignored, a new random key is generated and
+			// returned instead.
+			var auth bool
+			if auth, err = user.Authenticate(r.Form.Get("current"), secret); auth {
+				apiKey, err = user.RotateAPIKey()
+			} else {
+				http.Error(w, "Not authorized", http.StatusBadRequest)
+				return
+			}
 		default:
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
@@ -108,7 +123,9 @@ func setSettingValue(repo repo.User, secret []byte, log log.Log) http.HandlerFun
 			err = repo.Update(user)
 		}
 
-		if err == nil {
+		if err == nil && apiKey != "" {
+			args{"success": true, "value": apiKey}.WriteJSON(w)
+		} else if err == nil {
 			args{"success": true}.WriteJSON(w)
 		} else {
 			fatal(w, log, "Error setting user setting: %+v", err)
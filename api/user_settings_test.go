@@ -27,6 +27,7 @@ func Test_getSettingKeys(t *testing.T) {
 			"profile",
 			"is-active",
 			"password",
+			"api-key",
 		}},
 	}
 
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+var webhookKey = contextKey("webhook")
+
+const (
+	defaultDeliveryHistory = 50
+	maxDeliveryHistory     = 500
+)
+
+// webhookData adds the signing key, with which the receivers can verify
+// the payload signatures.
+type webhookData struct {
+	content.Webhook
+	SigningKey string `json:"signingKey"`
+}
+
+func listWebhooks(repo repo.Webhook, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		webhooks, err := repo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting webhooks: %+v", err)
+			return
+		}
+
+		data := make([]webhookData, len(webhooks))
+		for i := range webhooks {
+			data[i] = webhookData{webhooks[i], webhooks[i].SigningKey(secret)}
+		}
+
+		args{"webhooks": data, "events": eventable.Events}.WriteJSON(w)
+	}
+}
+
+func getWebhook(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, stop := webhookFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		args{"webhook": webhookData{webhook, webhook.SigningKey(secret)}}.WriteJSON(w)
+	}
+}
+
+func addWebhook(repo repo.Webhook, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		webhook := content.Webhook{User: user.Login, Active: true}
+		if err := webhookFromForm(r, &webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		webhook, err := repo.Create(webhook)
+		if err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error creating webhook: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "webhook": webhookData{webhook, webhook.SigningKey(secret)}}.WriteJSON(w)
+	}
+}
+
+func updateWebhook(repo repo.Webhook, secret []byte, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, stop := webhookFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := webhookFromForm(r, &webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.Update(webhook); err != nil {
+			if content.IsValidationError(err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				fatal(w, log, "Error updating webhook: %+v", err)
+			}
+			return
+		}
+
+		args{"success": true, "webhook": webhookData{webhook, webhook.SigningKey(secret)}}.WriteJSON(w)
+	}
+}
+
+func deleteWebhook(repo repo.Webhook, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, stop := webhookFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		if err := repo.Delete(webhook); err != nil {
+			fatal(w, log, "Error deleting webhook: %+v", err)
+			return
+		}
+
+		args{"success": true}.WriteJSON(w)
+	}
+}
+
+func getWebhookDeliveries(repo repo.Webhook, log log.Log) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, stop := webhookFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		limit := defaultDeliveryHistory
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 {
+			limit = l
+		}
+
+		if limit > maxDeliveryHistory {
+			limit = maxDeliveryHistory
+		}
+
+		deliveries, err := repo.Deliveries(webhook, limit)
+		if err != nil {
+			fatal(w, log, "Error getting webhook deliveries: %+v", err)
+			return
+		}
+
+		args{"deliveries": deliveries}.WriteJSON(w)
+	}
+}
+
+// webhookFromForm sets the webhook url, state and filter from the request
+// form. Missing values are left unchanged, while empty ones clear the
+// corresponding filter.
+func webhookFromForm(r *http.Request, webhook *content.Webhook) error {
+	if _, ok := r.Form["url"]; ok {
+		webhook.URL = r.Form.Get("url")
+	}
+
+	if v := r.Form.Get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("Invalid active value: %s", v)
+		}
+
+		webhook.Active = active
+	}
+
+	if events, ok := r.Form["event"]; ok {
+		webhook.Filter.Events = nil
+		for _, e := range events {
+			if e == "" {
+				continue
+			}
+
+			if !isEvent(e) {
+				return fmt.Errorf("Unknown event: %s", e)
+			}
+
+			webhook.Filter.Events = append(webhook.Filter.Events, e)
+		}
+	}
+
+	if ids, ok := r.Form["feedID"]; ok {
+		webhook.Filter.FeedIDs = nil
+		for _, v := range ids {
+			if v == "" {
+				continue
+			}
+
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid feed id: %s", v)
+			}
+
+			webhook.Filter.FeedIDs = append(webhook.Filter.FeedIDs, content.FeedID(id))
+		}
+	}
+
+	if ids, ok := r.Form["tagID"]; ok {
+		webhook.Filter.TagIDs = nil
+		for _, v := range ids {
+			if v == "" {
+				continue
+			}
+
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid tag id: %s", v)
+			}
+
+			webhook.Filter.TagIDs = append(webhook.Filter.TagIDs, content.TagID(id))
+		}
+	}
+
+	return webhook.Validate()
+}
+
+func isEvent(name string) bool {
+	for _, e := range eventable.Events {
+		if e == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func webhookContext(repo repo.Webhook, log log.Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, stop := userFromRequest(w, r)
+			if stop {
+				return
+			}
+
+			id, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			webhook, err := repo.Get(content.WebhookID(id), user)
+			if err != nil {
+				if content.IsNoContent(err) {
+					http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				} else {
+					fatal(w, log, "Error getting webhook: %+v", err)
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), webhookKey, webhook)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func webhookFromRequest(w http.ResponseWriter, r *http.Request) (webhook content.Webhook, stop bool) {
+	var ok bool
+	if webhook, ok = r.Context().Value(webhookKey).(content.Webhook); ok {
+		return webhook, false
+	}
+
+	http.Error(w, "Bad Request", http.StatusBadRequest)
+	return content.Webhook{}, true
+}
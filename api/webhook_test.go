@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func Test_addWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		noUser    bool
+		form      url.Values
+		createErr error
+		filter    content.WebhookFilter
+		code      int
+	}{
+		{name: "no user", noUser: true, code: 400},
+		{name: "no url", form: url.Values{}, code: 400},
+		{name: "invalid url", form: url.Values{"url": {"example.com"}}, code: 400},
+		{name: "unknown event", form: url.Values{"url": {"http://example.com"}, "event": {"unknown"}}, code: 400},
+		{name: "invalid feed id", form: url.Values{"url": {"http://example.com"}, "feedID": {"a"}}, code: 400},
+		{name: "create err", form: url.Values{"url": {"http://example.com"}}, createErr: errors.New("err"), code: 500},
+		{name: "create", form: url.Values{"url": {"http://example.com"}}, code: 200},
+		{name: "create with filter", form: url.Values{
+			"url":    {"http://example.com"},
+			"event":  {"feed-update", "feed-delete"},
+			"feedID": {"1", "2"},
+			"tagID":  {"3"},
+		}, filter: content.WebhookFilter{
+			Events:  []string{"feed-update", "feed-delete"},
+			FeedIDs: []content.FeedID{1, 2},
+			TagIDs:  []content.TagID{3},
+		}, code: 200},
+	}
+
+	secret := []byte("secret")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			webhookRepo := mock_repo.NewMockWebhook(ctrl)
+
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			switch {
+			default:
+				if tt.noUser {
+					break
+				}
+
+				user := content.User{Login: "test"}
+				r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+
+				if tt.code == 400 {
+					break
+				}
+
+				webhookRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(webhook content.Webhook) (content.Webhook, error) {
+					if webhook.User != user.Login || !webhook.Active || webhook.URL != tt.form.Get("url") {
+						t.Errorf("addWebhook() webhook = %#v", webhook)
+					}
+
+					webhook.ID = 5
+					return webhook, tt.createErr
+				})
+			}
+
+			addWebhook(webhookRepo, secret, logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("addWebhook() code = %v, want %v", w.Code, tt.code)
+				return
+			}
+
+			if w.Code != 200 {
+				return
+			}
+
+			var got struct {
+				Success bool `json:"success"`
+				Webhook struct {
+					ID         content.WebhookID     `json:"id"`
+					Filter     content.WebhookFilter `json:"filter"`
+					SigningKey string                `json:"signingKey"`
+				} `json:"webhook"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Errorf("addWebhook() body = %s", w.Body)
+				return
+			}
+
+			if !got.Success || got.Webhook.ID != 5 || got.Webhook.SigningKey == "" {
+				t.Errorf("addWebhook() = %s", w.Body)
+			}
+
+			if len(got.Webhook.Filter.Events) != len(tt.filter.Events) ||
+				len(got.Webhook.Filter.FeedIDs) != len(tt.filter.FeedIDs) ||
+				len(got.Webhook.Filter.TagIDs) != len(tt.filter.TagIDs) {
+				t.Errorf("addWebhook() filter = %#v, want %#v", got.Webhook.Filter, tt.filter)
+			}
+		})
+	}
+}
+
+func Test_updateWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		noWebhook bool
+		form      url.Values
+		updateErr error
+		want      content.Webhook
+		code      int
+	}{
+		{name: "no webhook", noWebhook: true, code: 400},
+		{name: "invalid active", form: url.Values{"active": {"maybe"}}, code: 400},
+		{name: "update err", form: url.Values{"active": {"false"}}, updateErr: errors.New("err"), code: 500},
+		{name: "deactivate", form: url.Values{"active": {"false"}}, want: content.Webhook{
+			ID: 2, User: "test", URL: "http://example.com", Filter: content.WebhookFilter{Events: []string{"feed-update"}},
+		}, code: 200},
+		{name: "clear events", form: url.Values{"event": {""}}, want: content.Webhook{
+			ID: 2, User: "test", URL: "http://example.com", Active: true,
+		}, code: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			webhookRepo := mock_repo.NewMockWebhook(ctrl)
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+			w := httptest.NewRecorder()
+
+			if !tt.noWebhook {
+				webhook := content.Webhook{
+					ID: 2, User: "test", URL: "http://example.com", Active: true,
+					Filter: content.WebhookFilter{Events: []string{"feed-update"}},
+				}
+				r = r.WithContext(context.WithValue(r.Context(), webhookKey, webhook))
+
+				if tt.code != 400 {
+					webhookRepo.EXPECT().Update(gomock.Any()).DoAndReturn(func(webhook content.Webhook) error {
+						if tt.updateErr == nil && (webhook.Active != tt.want.Active || len(webhook.Filter.Events) != len(tt.want.Filter.Events)) {
+							t.Errorf("updateWebhook() webhook = %#v, want %#v", webhook, tt.want)
+						}
+						return tt.updateErr
+					})
+				}
+			}
+
+			updateWebhook(webhookRepo, []byte("secret"), logger).ServeHTTP(w, r)
+
+			if tt.code != w.Code {
+				t.Errorf("updateWebhook() code = %v, want %v", w.Code, tt.code)
+			}
+		})
+	}
+}
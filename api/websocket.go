@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/api/token"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+	"golang.org/x/net/websocket"
+)
+
+// wsMessage is a message, sent by the websocket clients. Subscriptions
+// narrow down the sent events to the given event types, feeds and tags,
+// while unsubscriptions exclude them.
+type wsMessage struct {
+	Type    string           `json:"type"`
+	ID      int64            `json:"id,omitempty"`
+	Events  []string         `json:"events,omitempty"`
+	FeedIDs []content.FeedID `json:"feedIDs,omitempty"`
+	TagIDs  []content.TagID  `json:"tagIDs,omitempty"`
+}
+
+type wsSubscription struct {
+	Events       map[string]bool         `json:"events"`
+	FeedIDs      map[content.FeedID]bool `json:"feedIDs"`
+	TagIDs       map[content.TagID]bool  `json:"tagIDs"`
+	tagFeeds     map[content.TagID][]content.FeedID
+	excludedTags map[content.TagID][]content.FeedID
+}
+
+type wsConn struct {
+	ws        *websocket.Conn
+	user      content.User
+	client    string
+	events    *eventLog
+	tagRepo   repo.Tag
+	validator func() bool
+	log       log.Log
+
+	lastID       int64
+	subscription wsSubscription
+}
+
+// eventWebSocket streams the user's events over a websocket. Each event has
+// an id, and clients can resume from the last one they saw, either with the
+// lastEventID query parameter, or by acknowledging the events under a
+// client name. If the missed events are no longer available, a
+// resync-required event is sent instead.
+func eventWebSocket(
+	ctx context.Context,
+	service eventable.Service,
+	storage token.Storage,
+	events *eventLog,
+	log log.Log,
+) http.HandlerFunc {
+	feedRepo := service.FeedRepo()
+	tagRepo := service.TagRepo()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, stop := userFromRequest(w, r)
+		if stop {
+			return
+		}
+
+		lastID := int64(-1)
+		if v := r.Form.Get("lastEventID"); v != "" {
+			var err error
+			if lastID, err = strconv.ParseInt(v, 10, 64); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		feeds, err := feedRepo.ForUser(user)
+		if err != nil {
+			fatal(w, log, "Error getting user feeds: %+v", err)
+			return
+		}
+
+		feedSet := feedSet{}
+		for i := range feeds {
+			feedSet[feeds[i].ID] = struct{}{}
+		}
+		feeds = nil
+
+		client := r.Form.Get("client")
+		if lastID == -1 && client != "" {
+			if id, ok := events.acked(user.Login, client); ok {
+				lastID = id
+			}
+		}
+
+		notify, current, cancel := events.register(user.Login, feedSet)
+		defer cancel()
+
+		validator := connectionValidator(storage, r)
+
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			c := wsConn{
+				ws: ws, user: user, client: client, events: events,
+				tagRepo: tagRepo, validator: validator, log: log,
+				lastID: current, subscription: newWSSubscription(),
+			}
+
+			if err := c.serve(ctx, lastID, notify); err != nil {
+				log.Debugf("Websocket connection closed: %v", err)
+			}
+		}}.ServeHTTP(w, r)
+	}
+}
+
+func (c *wsConn) serve(ctx context.Context, lastID int64, notify <-chan struct{}) error {
+	if err := c.send(event{Type: "connection-established", Data: args{"lastEventID": c.lastID}}); err != nil {
+		return err
+	}
+
+	if lastID >= 0 {
+		if err := c.resume(lastID); err != nil {
+			return err
+		}
+	}
+
+	messages := make(chan wsMessage)
+	closed := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			var msg wsMessage
+			if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+				closed <- err
+				return
+			}
+
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-closed:
+			return err
+		case <-ping.C:
+			if !c.validator() {
+				return errors.New("invalid token")
+			}
+
+			err = c.send(event{Type: "ping"})
+		case <-notify:
+			if !c.validator() {
+				return errors.New("invalid token")
+			}
+
+			err = c.sendEvents()
+		case msg := <-messages:
+			err = c.handle(msg)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// resume sends the events after the given id, or a resync-required event,
+// if some of them are missing.
+func (c *wsConn) resume(id int64) error {
+	events, complete := c.events.since(c.user.Login, id)
+	if !complete {
+		return c.send(event{Type: "resync-required"})
+	}
+
+	return c.write(events)
+}
+
+func (c *wsConn) sendEvents() error {
+	events, complete := c.events.since(c.user.Login, c.lastID)
+	if !complete {
+		// The connection fell too far behind.
+		if len(events) > 0 {
+			c.lastID = events[len(events)-1].ID
+		}
+		return c.send(event{Type: "resync-required"})
+	}
+
+	return c.write(events)
+}
+
+func (c *wsConn) write(events []loggedEvent) error {
+	for _, e := range events {
+		c.lastID = e.ID
+
+		if !c.subscription.matches(e) {
+			continue
+		}
+
+		if err := websocket.JSON.Send(c.ws, e); err != nil {
+			return errors.Wrapf(err, "sending event %d", e.ID)
+		}
+	}
+
+	return nil
+}
+
+func (c *wsConn) send(e event) error {
+	if err := websocket.JSON.Send(c.ws, e); err != nil {
+		return errors.Wrapf(err, "sending %s message", e.Type)
+	}
+
+	return nil
+}
+
+func (c *wsConn) handle(msg wsMessage) error {
+	switch msg.Type {
+	case "ping":
+		return c.send(event{Type: "pong"})
+	case "ack":
+		c.events.ack(c.user.Login, c.client, msg.ID)
+		return nil
+	case "subscribe", "unsubscribe":
+		if err := c.subscription.update(msg, c.user, c.tagRepo); err != nil {
+			if content.IsNoContent(errors.Cause(err)) {
+				return c.send(event{Type: "error", Data: "unknown tag"})
+			}
+
+			c.log.Printf("Error updating websocket subscription: %+v", err)
+			return c.send(event{Type: "error", Data: "error updating subscription"})
+		}
+
+		return c.send(event{Type: "subscription", Data: c.subscription})
+	}
+
+	return c.send(event{Type: "error", Data: "unknown message type " + msg.Type})
+}
+
+func newWSSubscription() wsSubscription {
+	return wsSubscription{
+		Events:       map[string]bool{},
+		FeedIDs:      map[content.FeedID]bool{},
+		TagIDs:       map[content.TagID]bool{},
+		tagFeeds:     map[content.TagID][]content.FeedID{},
+		excludedTags: map[content.TagID][]content.FeedID{},
+	}
+}
+
+// update subscribes or unsubscribes the message's event types, feeds and
+// tags. The map values hold whether the events are included or excluded.
+func (s *wsSubscription) update(msg wsMessage, user content.User, tagRepo repo.Tag) error {
+	include := msg.Type == "subscribe"
+
+	for _, e := range msg.Events {
+		s.Events[e] = include
+	}
+
+	for _, id := range msg.FeedIDs {
+		s.FeedIDs[id] = include
+	}
+
+	for _, id := range msg.TagIDs {
+		tag, err := tagRepo.Get(id, user)
+		if err != nil {
+			return errors.WithMessage(err, "getting tag")
+		}
+
+		ids, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			return errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		s.TagIDs[id] = include
+		if include {
+			delete(s.excludedTags, id)
+			s.tagFeeds[id] = ids
+		} else {
+			delete(s.tagFeeds, id)
+			s.excludedTags[id] = ids
+		}
+	}
+
+	return nil
+}
+
+// matches checks whether the event is sent to the client. Events that
+// aren't about a feed are only filtered by type. Explicitly listed feeds
+// take precedence over the feeds of the listed tags.
+func (s wsSubscription) matches(e loggedEvent) bool {
+	if included, ok := s.Events[e.Type]; ok && !included {
+		return false
+	} else if !ok && hasInclusions(s.Events) {
+		return false
+	}
+
+	if e.FeedID == 0 {
+		return true
+	}
+
+	feeds := map[content.FeedID]bool{}
+	for _, ids := range s.tagFeeds {
+		for _, id := range ids {
+			feeds[id] = true
+		}
+	}
+
+	for _, ids := range s.excludedTags {
+		for _, id := range ids {
+			feeds[id] = false
+		}
+	}
+
+	for id, include := range s.FeedIDs {
+		feeds[id] = include
+	}
+
+	if included, ok := feeds[e.FeedID]; ok {
+		return included
+	}
+
+	for _, included := range feeds {
+		if included {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasInclusions(set map[string]bool) bool {
+	for _, included := range set {
+		if included {
+			return true
+		}
+	}
+
+	return false
+}
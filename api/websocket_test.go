@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/handler/auth"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"golang.org/x/net/websocket"
+)
+
+type wsTestEvent struct {
+	ID   int64           `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func Test_eventWebSocket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := mock_repo.NewMockService(ctrl)
+	feedRepo := mock_repo.NewMockFeed(ctrl)
+	articleRepo := mock_repo.NewMockArticle(ctrl)
+	storage := NewMockStorage(ctrl)
+
+	service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+	service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+	service.EXPECT().TagRepo().Return(mock_repo.NewMockTag(ctrl)).AnyTimes()
+	service.EXPECT().AnnotationRepo().Return(mock_repo.NewMockAnnotation(ctrl)).AnyTimes()
+
+	ev := eventable.NewService(ctx, service, logger)
+	events := newEventLog(eventLogSize, 0)
+	go events.listen(ctx, ev)
+
+	user := content.User{Login: "test"}
+	feedRepo.EXPECT().ForUser(userMatcher{user}).Return([]content.Feed{{ID: 1}}, nil).AnyTimes()
+	feedRepo.EXPECT().Update(gomock.Any()).Return([]content.Article{{ID: 1}}, nil).AnyTimes()
+	articleRepo.EXPECT().Read(true, userMatcher{user}).Return(nil).AnyTimes()
+	articleRepo.EXPECT().IDs(userMatcher{user}, gomock.Any()).Return(nil, nil).AnyTimes()
+	storage.EXPECT().Exists("token").Return(false, nil).AnyTimes()
+
+	handler := eventWebSocket(ctx, ev, storage, events, logger)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		r = r.WithContext(context.WithValue(r.Context(), userKey, user))
+		r = r.WithContext(context.WithValue(r.Context(), auth.TokenKey, "token"))
+		r = r.WithContext(context.WithValue(r.Context(), auth.ClaimsKey, claims{true}))
+
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	dial := func(query string) *websocket.Conn {
+		ws, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/?"+query, "", "http://localhost")
+		if err != nil {
+			t.Fatalf("websocket.Dial() error = %v", err)
+		}
+
+		if e := receive(t, ws); e.Type != "connection-established" {
+			t.Fatalf("eventWebSocket() first event = %#v", e)
+		}
+
+		return ws
+	}
+
+	ws := dial("client=phone")
+
+	ev.FeedRepo().Update(&content.Feed{ID: 1})
+	if e := receive(t, ws); e.Type != eventable.FeedUpdateEvent || e.ID != 1 {
+		t.Errorf("eventWebSocket() event = %#v, want feed update 1", e)
+	}
+
+	send(t, ws, wsMessage{Type: "subscribe", Events: []string{eventable.ArticleStateEvent}})
+	if e := receive(t, ws); e.Type != "subscription" {
+		t.Errorf("eventWebSocket() subscribe reply = %#v", e)
+	}
+
+	ev.FeedRepo().Update(&content.Feed{ID: 1})
+	ev.ArticleRepo().Read(true, user)
+	if e := receive(t, ws); e.Type != eventable.ArticleStateEvent || e.ID != 3 {
+		t.Errorf("eventWebSocket() event = %#v, want the article state event 3", e)
+	}
+
+	send(t, ws, wsMessage{Type: "ack", ID: 3})
+	send(t, ws, wsMessage{Type: "ping"})
+	if e := receive(t, ws); e.Type != "pong" {
+		t.Errorf("eventWebSocket() ping reply = %#v", e)
+	}
+	ws.Close()
+
+	// Missed while disconnected.
+	ev.FeedRepo().Update(&content.Feed{ID: 1})
+	time.Sleep(50 * time.Millisecond)
+
+	ws = dial("client=phone")
+	if e := receive(t, ws); e.Type != eventable.FeedUpdateEvent || e.ID != 4 {
+		t.Errorf("eventWebSocket() resumed event = %#v, want feed update 4", e)
+	}
+	ws.Close()
+
+	ws = dial("lastEventID=2")
+	for _, id := range []int64{3, 4} {
+		if e := receive(t, ws); e.ID != id {
+			t.Errorf("eventWebSocket() resumed event = %#v, want %d", e, id)
+		}
+	}
+	ws.Close()
+
+	ws = dial("lastEventID=100")
+	if e := receive(t, ws); e.Type != "resync-required" {
+		t.Errorf("eventWebSocket() event = %#v, want resync-required", e)
+	}
+	ws.Close()
+}
+
+func receive(t *testing.T, ws *websocket.Conn) wsTestEvent {
+	var e wsTestEvent
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := websocket.JSON.Receive(ws, &e); err != nil {
+		t.Fatalf("websocket receive error = %v", err)
+	}
+
+	return e
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg wsMessage) {
+	if err := websocket.JSON.Send(ws, msg); err != nil {
+		t.Fatalf("websocket send error = %v", err)
+	}
+}
+
+func Test_wsSubscription_matches(t *testing.T) {
+	tests := []struct {
+		name  string
+		msgs  []wsMessage
+		tags  map[content.TagID][]content.FeedID
+		event loggedEvent
+		want  bool
+	}{
+		{name: "no subscription", event: loggedEvent{Type: "feed-update", FeedID: 1}, want: true},
+		{name: "event", msgs: []wsMessage{{Type: "subscribe", Events: []string{"feed-update"}}},
+			event: loggedEvent{Type: "feed-update", FeedID: 1}, want: true},
+		{name: "other event", msgs: []wsMessage{{Type: "subscribe", Events: []string{"feed-update"}}},
+			event: loggedEvent{Type: "article-state-change"}},
+		{name: "unsubscribed event", msgs: []wsMessage{{Type: "unsubscribe", Events: []string{"feed-update"}}},
+			event: loggedEvent{Type: "feed-update", FeedID: 1}},
+		{name: "other unsubscribed event", msgs: []wsMessage{{Type: "unsubscribe", Events: []string{"feed-update"}}},
+			event: loggedEvent{Type: "article-state-change"}, want: true},
+		{name: "feed", msgs: []wsMessage{{Type: "subscribe", FeedIDs: []content.FeedID{1}}},
+			event: loggedEvent{Type: "feed-update", FeedID: 1}, want: true},
+		{name: "other feed", msgs: []wsMessage{{Type: "subscribe", FeedIDs: []content.FeedID{1}}},
+			event: loggedEvent{Type: "feed-update", FeedID: 2}},
+		{name: "feed filter, no feed", msgs: []wsMessage{{Type: "subscribe", FeedIDs: []content.FeedID{1}}},
+			event: loggedEvent{Type: "article-state-change"}, want: true},
+		{name: "resubscribed feed", msgs: []wsMessage{
+			{Type: "subscribe", FeedIDs: []content.FeedID{1}},
+			{Type: "unsubscribe", FeedIDs: []content.FeedID{1}},
+		}, event: loggedEvent{Type: "feed-update", FeedID: 1}},
+		{name: "tag", msgs: []wsMessage{{Type: "subscribe", TagIDs: []content.TagID{1}}},
+			tags: map[content.TagID][]content.FeedID{1: {2, 3}}, event: loggedEvent{Type: "feed-update", FeedID: 3}, want: true},
+		{name: "other tag", msgs: []wsMessage{{Type: "subscribe", TagIDs: []content.TagID{1}}},
+			tags: map[content.TagID][]content.FeedID{1: {2, 3}}, event: loggedEvent{Type: "feed-update", FeedID: 4}},
+		{name: "unsubscribed tag, subscribed feed", msgs: []wsMessage{
+			{Type: "unsubscribe", TagIDs: []content.TagID{1}},
+			{Type: "subscribe", FeedIDs: []content.FeedID{3}},
+		}, tags: map[content.TagID][]content.FeedID{1: {2, 3}}, event: loggedEvent{Type: "feed-update", FeedID: 3}, want: true},
+		{name: "unsubscribed tag", msgs: []wsMessage{{Type: "unsubscribe", TagIDs: []content.TagID{1}}},
+			tags: map[content.TagID][]content.FeedID{1: {2, 3}}, event: loggedEvent{Type: "feed-update", FeedID: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagRepo := mock_repo.NewMockTag(ctrl)
+			user := content.User{Login: "test"}
+
+			for id, feedIDs := range tt.tags {
+				tag := content.Tag{ID: id, Value: "tag"}
+				tagRepo.EXPECT().Get(id, user).Return(tag, nil).AnyTimes()
+				tagRepo.EXPECT().FeedIDs(tag, user).Return(feedIDs, nil).AnyTimes()
+			}
+
+			s := newWSSubscription()
+			for _, msg := range tt.msgs {
+				if err := s.update(msg, user, tagRepo); err != nil {
+					t.Fatalf("wsSubscription.update() error = %v", err)
+				}
+			}
+
+			if got := s.matches(tt.event); got != tt.want {
+				t.Errorf("wsSubscription.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/sql"
+	"github.com/urandom/readeef/log"
+)
+
+var (
+	feedCommands = map[string]func([]string, repo.Service, config.Config, log.Log) error{}
+)
+
+func runFeed(config config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command")
+	}
+
+	log := initLog(config.Log)
+	service, err := sql.NewService(config.DB.Driver, config.DB.Connect, log)
+	if err != nil {
+		return errors.WithMessage(err, "creating content service")
+	}
+
+	if f, ok := feedCommands[args[0]]; ok {
+		return f(args[1:], service, config, log)
+	}
+
+	return errors.Errorf("unknown command %s", args[0])
+}
+
+func feedRefresh(args []string, service repo.Service, config config.Config, log log.Log) error {
+	if len(args) != 1 {
+		return errors.New("invalid number of arguments")
+	}
+
+	feed, err := findFeed(args[0], service.FeedRepo())
+	if err != nil {
+		return err
+	}
+
+	feedManager := readeef.NewFeedManager(service.FeedRepo(), config, log)
+
+	processors, err := initFeedProcessors(config.FeedParser.Processors, config.FeedParser.ProxyHTTPURLTemplate, log)
+	if err != nil {
+		return errors.WithMessage(err, "initializing parser processors")
+	}
+
+	for _, p := range processors {
+		feedManager.AddFeedProcessor(p)
+	}
+
+	count, err := feedManager.RefreshFeed(feed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d new articles\n", feed, count)
+
+	return nil
+}
+
+func feedExtract(args []string, service repo.Service, config config.Config, log log.Log) error {
+	if len(args) != 2 {
+		return errors.New("invalid number of arguments")
+	}
+
+	feed, err := findFeed(args[0], service.FeedRepo())
+	if err != nil {
+		return err
+	}
+
+	var extract bool
+	switch args[1] {
+	case "on":
+		extract = true
+	case "off":
+	default:
+		return errors.Errorf("unknown extract value %s", args[1])
+	}
+
+	if err := service.FeedRepo().SetExtract(feed, extract); err != nil {
+		return errors.WithMessage(err, "setting feed content extraction")
+	}
+
+	fmt.Printf("%s: content extraction %s\n", feed, args[1])
+
+	return nil
+}
+
+func feedAuth(args []string, service repo.Service, config config.Config, log log.Log) error {
+	if len(args) == 0 {
+		return errors.New("invalid number of arguments")
+	}
+
+	var auth content.FeedAuth
+	var headers, cookies stringList
+
+	flags := flag.NewFlagSet("feed auth", flag.ContinueOnError)
+	flags.StringVar(&auth.Username, "user", "", "basic auth username")
+	flags.StringVar(&auth.Password, "password", "", "basic auth password")
+	flags.StringVar(&auth.Token, "token", "", "bearer token")
+	flags.StringVar(&auth.UserAgent, "user-agent", "", "user agent")
+	flags.BoolVar(&auth.CookieJar, "cookie-jar", false, "keep the cookies set by the server")
+	flags.Var(&headers, "header", "extra header as 'Name: value', can be repeated")
+	flags.Var(&cookies, "cookie", "cookie as 'name=value', can be repeated")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	feed, err := findFeed(args[0], service.FeedRepo())
+	if err != nil {
+		return err
+	}
+
+	if auth.Headers, err = headers.split(":"); err != nil {
+		return errors.WithMessage(err, "parsing headers")
+	}
+
+	if auth.Cookies, err = cookies.split("="); err != nil {
+		return errors.WithMessage(err, "parsing cookies")
+	}
+
+	if feed.Credentials, err = auth.Encrypt([]byte(config.Auth.Secret)); err != nil {
+		return errors.WithMessage(err, "encrypting feed credentials")
+	}
+
+	if err := service.FeedRepo().SetCredentials(feed); err != nil {
+		return errors.WithMessage(err, "setting feed credentials")
+	}
+
+	if auth.Empty() {
+		fmt.Printf("%s: credentials cleared\n", feed)
+	} else {
+		fmt.Printf("%s: credentials set\n", feed)
+	}
+
+	return nil
+}
+
+func feedRetention(args []string, service repo.Service, config config.Config, log log.Log) error {
+	if len(args) == 0 {
+		return errors.New("invalid number of arguments")
+	}
+
+	var retention content.Retention
+
+	flags := flag.NewFlagSet("feed retention", flag.ContinueOnError)
+	flags.IntVar(&retention.MaxArticles, "max-articles", 0, "number of newest articles to keep")
+	flags.DurationVar(&retention.MaxAge, "max-age", 0, "age after which articles are purged")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	feed, err := findFeed(args[0], service.FeedRepo())
+	if err != nil {
+		return err
+	}
+
+	if err := service.FeedRepo().SetRetention(feed, retention); err != nil {
+		return errors.WithMessage(err, "setting feed retention")
+	}
+
+	if retention.Empty() {
+		fmt.Printf("%s: retention cleared\n", feed)
+	} else {
+		fmt.Printf("%s: retention set\n", feed)
+	}
+
+	return nil
+}
+
+// stringList collects the values of a repeated flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func (l stringList) split(sep string) (map[string]string, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string]string, len(l))
+	for _, v := range l {
+		parts := strings.SplitN(v, sep, 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf("invalid value %s", v)
+		}
+
+		m[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return m, nil
+}
+
+// findFeed looks up a feed either by its id or its link.
+func findFeed(idOrLink string, repo repo.Feed) (content.Feed, error) {
+	id, err := strconv.ParseInt(idOrLink, 10, 64)
+	if err != nil {
+		feed, err := repo.FindByLink(idOrLink)
+		if err != nil {
+			return content.Feed{}, errors.WithMessage(err, "getting feed by link")
+		}
+
+		return feed, nil
+	}
+
+	feeds, err := repo.All()
+	if err != nil {
+		return content.Feed{}, errors.WithMessage(err, "getting all feeds")
+	}
+
+	for _, feed := range feeds {
+		if feed.ID == content.FeedID(id) {
+			return feed, nil
+		}
+	}
+
+	return content.Feed{}, errors.Errorf("feed %d not found", id)
+}
+
+func init() {
+	flags := flag.NewFlagSet("feed", flag.ExitOnError)
+
+	commands = append(commands, Command{
+		Name:  "feed",
+		Desc:  "manage feeds",
+		Flags: flags,
+		Run:   runFeed,
+	})
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of feed:\n\n")
+		fmt.Fprintf(os.Stderr, "\tfeed [arguments] command\n\n")
+		fmt.Fprintf(os.Stderr, `Available commands:
+
+	refresh ID|LINK 		download the feed right away and store
+					any new articles
+	extract ID|LINK on|off		extract the full content of every new
+					article of the feed
+	auth ID|LINK [flags]		set the credentials used to download the
+					feed, clearing them when no flags are
+					given: -user, -password, -token,
+					-header, -cookie, -user-agent,
+					-cookie-jar
+	retention ID|LINK [flags]	set the article retention of the feed,
+					falling back to the global one when no
+					flags are given: -max-articles, -max-age
+
+`)
+	}
+
+	feedCommands["refresh"] = feedRefresh
+	feedCommands["extract"] = feedExtract
+	feedCommands["auth"] = feedAuth
+	feedCommands["retention"] = feedRetention
+}
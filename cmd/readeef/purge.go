@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql"
+	"github.com/urandom/readeef/content/retention"
+)
+
+var (
+	purgeDryRun  bool
+	purgeVerbose bool
+)
+
+func runPurge(config config.Config, args []string) error {
+	if purgeVerbose {
+		config.Log.Level = "debug"
+	}
+
+	log := initLog(config.Log)
+	service, err := sql.NewService(config.DB.Driver, config.DB.Connect, log)
+	if err != nil {
+		return errors.WithMessage(err, "creating content service")
+	}
+
+	global := globalRetention(config.Content)
+
+	if purgeDryRun {
+		expired, err := retention.Find(service, global, log)
+		if err != nil {
+			return errors.WithMessage(err, "finding expired articles")
+		}
+
+		count := 0
+		for _, e := range expired {
+			fmt.Printf("%s: %d articles\n", e.Feed, len(e.Articles))
+
+			if purgeVerbose {
+				for _, a := range e.Articles {
+					fmt.Printf("\t%d %s %s\n", a.ID, a.Date.Format("2006-01-02"), a.Title)
+				}
+			}
+
+			count += len(e.Articles)
+		}
+
+		fmt.Printf("%d articles would be deleted\n", count)
+
+		return nil
+	}
+
+	searchProvider := initSearchProvider(config.Content, service, log)
+
+	count, err := retention.Purge(service, global, searchProvider, log)
+	if err != nil {
+		return errors.WithMessage(err, "purging expired articles")
+	}
+
+	fmt.Printf("%d articles deleted\n", count)
+
+	return nil
+}
+
+func globalRetention(config config.Content) content.Retention {
+	return content.Retention{
+		MaxArticles: config.Retention.MaxArticles,
+		MaxAge:      config.Converted.RetentionMaxAge,
+	}
+}
+
+func init() {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	flags.BoolVar(&purgeDryRun, "dry-run", false, "only report the articles that would be deleted")
+	flags.BoolVar(&purgeVerbose, "verbose", false, "verbose output")
+
+	commands = append(commands, Command{
+		Name:  "purge",
+		Desc:  "delete the articles outside of the retention of their feeds",
+		Flags: flags,
+		Run:   runPurge,
+	})
+}
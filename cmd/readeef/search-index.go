@@ -35,6 +35,10 @@ func runSearchIndex(config config.Config, args []string) error {
 		return errors.WithMessage(err, "indexing all feeds")
 	}
 
+	if err := search.ReindexAnnotations(searchProvider, service.AnnotationRepo()); err != nil {
+		return errors.WithMessage(err, "indexing all annotations")
+	}
+
 	return nil
 }
 
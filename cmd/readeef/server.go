@@ -32,6 +32,7 @@ import (
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/content/repo/logging"
 	"github.com/urandom/readeef/content/repo/sql"
+	"github.com/urandom/readeef/content/retention"
 	"github.com/urandom/readeef/content/search"
 	"github.com/urandom/readeef/content/thumbnail"
 	"github.com/urandom/readeef/log"
@@ -127,7 +128,7 @@ func runServer(cfg config.Config, args []string) error {
 		return errors.WithMessage(err, "initializing content extract generator")
 	}
 
-	articleProcessors, err := initArticleProcessors(cfg.Content.Article.Processors, cfg.Content.Article.ProxyHTTPURLTemplate, logger)
+	articleProcessors, err := initArticleProcessors(cfg.Content.Article.Processors, cfg.Content.Article.ProxyHTTPURLTemplate, service.ExtractRepo(), logger)
 	if err != nil {
 		return errors.WithMessage(err, "initializing article processors")
 	}
@@ -139,7 +140,9 @@ func runServer(cfg config.Config, args []string) error {
 
 	initPopularityScore(ctx, service, cfg.Popularity, logger)
 
-	initFeedMonitors(ctx, cfg.FeedManager, service, searchProvider, thumbnailer, logger)
+	initFeedMonitors(ctx, cfg, service, searchProvider, thumbnailer, extractor, logger)
+
+	go retention.Schedule(ctx, service, globalRetention(cfg.Content), searchProvider, cfg.Content.Converted.RetentionPurgeInterval, logger)
 
 	hubbub, err := initHubbub(cfg, service, feedManager, logger)
 	if err != nil {
@@ -257,7 +260,7 @@ func initAdminUser(repo repo.User, secret []byte) error {
 	return nil
 }
 
-func initArticleProcessors(names []string, proxyTemplate string, log log.Log) ([]processor.Article, error) {
+func initArticleProcessors(names []string, proxyTemplate string, extractRepo repo.Extract, log log.Log) ([]processor.Article, error) {
 	var processors []processor.Article
 
 	for _, p := range names {
@@ -278,6 +281,8 @@ func initArticleProcessors(names []string, proxyTemplate string, log log.Log) ([
 			processors = append(processors, processor.NewInsertThumbnailTarget(log))
 		case "unescape":
 			processors = append(processors, processor.NewUnescape(log))
+		case "extract-content":
+			processors = append(processors, processor.NewExtractContent(extractRepo, log))
 		}
 	}
 
@@ -350,6 +355,10 @@ func initSearchProvider(config config.Content, service repo.Service, log log.Log
 				if err := search.Reindex(searchProvider, service.ArticleRepo()); err != nil {
 					log.Printf("Error reindexing all articles: %+v", err)
 				}
+
+				if err := search.ReindexAnnotations(searchProvider, service.AnnotationRepo()); err != nil {
+					log.Printf("Error reindexing all annotations: %+v", err)
+				}
 			}()
 		}
 	}
@@ -404,16 +413,18 @@ func initPopularityScore(ctx context.Context, service repo.Service, config confi
 
 func initFeedMonitors(
 	ctx context.Context,
-	config config.FeedManager,
+	config config.Config,
 	service eventable.Service,
 	searchProvider search.Provider,
 	thumbnailer thumbnail.Generator,
+	extractor extract.Generator,
 	log log.Log,
 ) {
 	go monitor.Unread(ctx, service, log)
 	go monitor.UserFilters(service, log)
+	go monitor.Webhooks(ctx, service, []byte(config.Auth.Secret), log)
 
-	for _, m := range config.Monitors {
+	for _, m := range config.FeedManager.Monitors {
 		switch m {
 		case "index":
 			if searchProvider != nil {
@@ -423,6 +434,10 @@ func initFeedMonitors(
 			if thumbnailer != nil {
 				go monitor.Thumbnailer(service, thumbnailer, log)
 			}
+		case "extractor":
+			if extractor != nil {
+				go monitor.Extractor(service, extract.RateLimit(extractor, config.Content.Converted.ExtractPerHostInterval), log)
+			}
 		}
 	}
 }
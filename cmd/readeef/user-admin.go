@@ -169,6 +169,31 @@ func userAdminSet(args []string, service repo.Service, config config.Config, log
 	return nil
 }
 
+func userAdminRotateAPIKey(args []string, service repo.Service, config config.Config, log log.Log) error {
+	if len(args) != 1 {
+		return errors.New("invalid number of arguments")
+	}
+
+	repo := service.UserRepo()
+	u, err := repo.Get(content.Login(args[0]))
+	if err != nil {
+		return errors.WithMessage(err, "getting user")
+	}
+
+	key, err := u.RotateAPIKey()
+	if err != nil {
+		return errors.Wrapf(err, "rotating %s api key", u)
+	}
+
+	if err = repo.Update(u); err != nil {
+		return errors.WithMessage(err, "updating user")
+	}
+
+	fmt.Println(key)
+
+	return nil
+}
+
 func userAdminList(args []string, service repo.Service, config config.Config, log log.Log) error {
 	users, err := service.UserRepo().All()
 	if err != nil {
@@ -232,13 +257,16 @@ func init() {
 		- hashtype 		the password hash type
 		- salt 			the salt
 		- hash 			the password hash
-		- md5api 		the password-based key
-					used by the fever api emulation
+		- md5api 		the api key hash used by the
+					fever api emulation
 		- admin 		whether the user is an admin
 		- active 		whether the user is active
 		- profile 		the json profile data
 	set LOGIN PROPERTY VALUE 	sets a new value to a given property
-		- instead of a salt/hashtype/hash and md5api, a password property is used
+		- instead of a salt/hashtype/hash, a password property is used
+	rotate-api-key LOGIN 		generates and prints a new api key, used
+					instead of the password by fever clients,
+					and as the miniflux api token
 	list 				lists all users
 	list-detailed 			lists all users, including some properties
 
@@ -249,6 +277,7 @@ func init() {
 	userAdminCommands["remove"] = userAdminRemove
 	userAdminCommands["get"] = userAdminGet
 	userAdminCommands["set"] = userAdminSet
+	userAdminCommands["rotate-api-key"] = userAdminRotateAPIKey
 	userAdminCommands["list"] = userAdminList
 	userAdminCommands["list-detailed"] = userAdminListDetailed
 }
@@ -30,9 +30,12 @@ var DefaultCfg = `
 	formatter = "text" # text, json
 	access-file = ""   # stdout or a filename
 [api]
-	emulators = []     # ["tt-rss", "fever"]
+	emulators = []     # ["tt-rss", "fever", "greader", "nextcloud", "miniflux"]
 [api.limits]
 	articles-per-query = 200
+[api.events]
+	log-size = 200     # recent events kept per user, for resuming event streams
+	storage-path = ""  # persists the event logs, e.g. "./storage/events.db"
 [db]
 	driver = "sqlite3"
 	connect = "file:./storage/content.sqlite3?cache=shared&mode=rwc&_busy_timeout=50000000&_foreign_keys=1&_journal=wal"
@@ -41,7 +44,14 @@ var DefaultCfg = `
 	token-storage-path = "./storage/token.db"
 [feed-manager]
 	update-interval = "30m"
-	monitors = ["index", "thumbnailer"]
+	min-update-interval = "10m"
+	max-update-interval = "24h"
+	dead-after = 20    # consecutive failures before a feed is marked as dead
+	fetch-workers = 10
+	fetch-workers-per-host = 2
+	startup-jitter = "5m"
+	# user-agent = "readeef" # sent with feed downloads, unless a feed sets its own
+	monitors = ["index", "thumbnailer", "extractor"]
 [timeout]
 	connect = "1s"
 	read-write = "2s"
@@ -55,14 +65,20 @@ var DefaultCfg = `
 	proxy-http-url-template = "/proxy?url={{ . }}"
 [content.extract]
 	generator = "goose" # readability
+	per-host-interval = "2s"
 [content.search]
 	provider = "bleve"
 	batch-size = 100
 	bleve-path = "./storage/search.bleve"
 	elastic-url = "http://localhost:9200"
 [content.article]
-	processors = ["insert-thumbnail-target"]
+	processors = ["insert-thumbnail-target"] # add "extract-content" first to show extracted content
 	proxy-http-url-template = "/proxy?url={{ . }}"
+[content.retention]
+	max-articles = 0   # newest articles kept per feed, 0 keeps all
+	max-age = ""       # e.g. "2160h", older articles are purged
+	purge-interval = "24h"
+	# articles still present in the source feed are fetched again after a purge
 [content.thumbnail]
 	store = true
 [ui]
@@ -41,6 +41,10 @@ type API struct {
 	Limits    struct {
 		ArticlesPerQuery int `toml:"articles-per-query"`
 	} `toml:"limits"`
+	Events struct {
+		LogSize     int    `toml:"log-size"`
+		StoragePath string `toml:"storage-path"`
+	} `toml:"events"`
 }
 
 type Timeout struct {
@@ -99,12 +103,26 @@ type FeedParser struct {
 }
 
 type FeedManager struct {
-	UpdateInterval string `toml:"update-interval"`
+	UpdateInterval    string `toml:"update-interval"`
+	MinUpdateInterval string `toml:"min-update-interval"`
+	MaxUpdateInterval string `toml:"max-update-interval"`
+	DeadAfter         int    `toml:"dead-after"`
+
+	FetchWorkers        int    `toml:"fetch-workers"`
+	FetchWorkersPerHost int    `toml:"fetch-workers-per-host"`
+	StartupJitter       string `toml:"startup-jitter"`
+
+	// UserAgent is sent with every feed download, unless the feed sets its
+	// own.
+	UserAgent string `toml:"user-agent"`
 
 	Monitors []string `toml:"monitors"`
 
 	Converted struct {
-		UpdateInterval time.Duration
+		UpdateInterval    time.Duration
+		MinUpdateInterval time.Duration
+		MaxUpdateInterval time.Duration
+		StartupJitter     time.Duration
 	} `toml:"-"`
 }
 
@@ -117,6 +135,9 @@ type Content struct {
 	Extract struct {
 		Generator      string `toml:"generator"`
 		ReadabilityKey string `toml:"readability-key"`
+		// PerHostInterval is the minimum time between two background
+		// extracts from the same host.
+		PerHostInterval string `toml:"per-host-interval"`
 	} `toml:"extract"`
 
 	Search struct {
@@ -131,8 +152,24 @@ type Content struct {
 		ProxyHTTPURLTemplate string   `toml:"proxy-http-url-template"`
 	} `toml:"article"`
 
+	// Retention is the default article retention of every feed. Feeds and
+	// user subscriptions may override it.
+	Retention struct {
+		MaxArticles int    `toml:"max-articles"`
+		MaxAge      string `toml:"max-age"`
+		// PurgeInterval is the time between two purges of the expired
+		// articles.
+		PurgeInterval string `toml:"purge-interval"`
+	} `toml:"retention"`
+
 	// deprecated
 	ThumbnailGenerator string `toml:"thumbnail-generator"`
+
+	Converted struct {
+		ExtractPerHostInterval time.Duration
+		RetentionMaxAge        time.Duration
+		RetentionPurgeInterval time.Duration
+	} `toml:"-"`
 }
 
 type UI struct {
@@ -188,6 +225,24 @@ func (c *FeedManager) Convert() {
 	} else {
 		c.Converted.UpdateInterval = 30 * time.Minute
 	}
+
+	if d, err := time.ParseDuration(c.MinUpdateInterval); err == nil {
+		c.Converted.MinUpdateInterval = d
+	} else {
+		c.Converted.MinUpdateInterval = 10 * time.Minute
+	}
+
+	if d, err := time.ParseDuration(c.MaxUpdateInterval); err == nil {
+		c.Converted.MaxUpdateInterval = d
+	} else {
+		c.Converted.MaxUpdateInterval = 24 * time.Hour
+	}
+
+	if d, err := time.ParseDuration(c.StartupJitter); err == nil {
+		c.Converted.StartupJitter = d
+	} else {
+		c.Converted.StartupJitter = 5 * time.Minute
+	}
 }
 
 func (c *Content) Convert() {
@@ -198,4 +253,20 @@ func (c *Content) Convert() {
 			c.Thumbnail.Generator = "description"
 		}
 	}
+
+	if d, err := time.ParseDuration(c.Extract.PerHostInterval); err == nil {
+		c.Converted.ExtractPerHostInterval = d
+	} else {
+		c.Converted.ExtractPerHostInterval = 2 * time.Second
+	}
+
+	if d, err := time.ParseDuration(c.Retention.MaxAge); err == nil {
+		c.Converted.RetentionMaxAge = d
+	}
+
+	if d, err := time.ParseDuration(c.Retention.PurgeInterval); err == nil {
+		c.Converted.RetentionPurgeInterval = d
+	} else {
+		c.Converted.RetentionPurgeInterval = 24 * time.Hour
+	}
 }
@@ -0,0 +1,78 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type AnnotationID int64
+
+// Annotation is a user's note on an article, or a highlight of a part of its
+// text. Highlights hold the quoted text, and optionally its character range
+// within the article description, and may carry a note of their own.
+type Annotation struct {
+	ID        AnnotationID `json:"id"`
+	User      Login        `db:"user_login" json:"-"`
+	ArticleID ArticleID    `db:"article_id" json:"articleID"`
+	Note      string       `json:"note"`
+	Quote     string       `json:"quote,omitempty"`
+	Start     int          `db:"range_start" json:"start,omitempty"`
+	End       int          `db:"range_end" json:"end,omitempty"`
+	CreatedAt time.Time    `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time    `db:"updated_at" json:"updatedAt"`
+}
+
+// AnnotationFilter limits the user's annotations to the ones of articles
+// from the given feeds, created within the time range. Empty values do not
+// limit the annotations.
+type AnnotationFilter struct {
+	FeedIDs []FeedID
+	After   time.Time
+	Before  time.Time
+}
+
+// IsHighlight reports whether the annotation marks a part of the article.
+func (a Annotation) IsHighlight() bool {
+	return a.Quote != "" || a.End > 0
+}
+
+func (a Annotation) Validate() error {
+	if a.User == "" {
+		return NewValidationError(errors.New("Annotation has no user"))
+	}
+
+	if a.ArticleID == 0 {
+		return NewValidationError(errors.New("Annotation has no article"))
+	}
+
+	if a.Note == "" && !a.IsHighlight() {
+		return NewValidationError(errors.New("Annotation has neither a note nor a highlight"))
+	}
+
+	if (a.Start != 0 || a.End != 0) && (a.Start < 0 || a.End <= a.Start) {
+		return NewValidationError(fmt.Errorf("Annotation has an invalid range %d-%d", a.Start, a.End))
+	}
+
+	return nil
+}
+
+func (a Annotation) String() string {
+	return fmt.Sprintf("%s:%d: article %d", a.User, a.ID, a.ArticleID)
+}
+
+func (id *AnnotationID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (AnnotationID)", src, src)
+	}
+
+	*id = AnnotationID(asInt)
+
+	return nil
+}
+
+func (id AnnotationID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
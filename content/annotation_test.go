@@ -0,0 +1,52 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestAnnotation_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation content.Annotation
+		wantErr    bool
+	}{
+		{"note", content.Annotation{User: "test", ArticleID: 1, Note: "note"}, false},
+		{"quote", content.Annotation{User: "test", ArticleID: 1, Quote: "quote"}, false},
+		{"range", content.Annotation{User: "test", ArticleID: 1, Start: 0, End: 10}, false},
+		{"quoted range with note", content.Annotation{User: "test", ArticleID: 1, Note: "note", Quote: "quote", Start: 5, End: 10}, false},
+		{"no user", content.Annotation{ArticleID: 1, Note: "note"}, true},
+		{"no article", content.Annotation{User: "test", Note: "note"}, true},
+		{"empty", content.Annotation{User: "test", ArticleID: 1}, true},
+		{"negative start", content.Annotation{User: "test", ArticleID: 1, Quote: "quote", Start: -1, End: 10}, true},
+		{"reversed range", content.Annotation{User: "test", ArticleID: 1, Quote: "quote", Start: 10, End: 5}, true},
+		{"start only", content.Annotation{User: "test", ArticleID: 1, Quote: "quote", Start: 10}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.annotation.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Annotation.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnnotation_IsHighlight(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation content.Annotation
+		want       bool
+	}{
+		{"note", content.Annotation{Note: "note"}, false},
+		{"quote", content.Annotation{Quote: "quote"}, true},
+		{"range", content.Annotation{Start: 1, End: 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.annotation.IsHighlight(); got != tt.want {
+				t.Errorf("Annotation.IsHighlight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
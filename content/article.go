@@ -3,6 +3,7 @@ package content
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -21,12 +22,23 @@ type Article struct {
 	Link        string    `json:"link"`
 	Date        time.Time `json:"date"`
 
+	Author     string     `json:"author,omitempty"`
+	Categories Categories `json:"categories,omitempty"`
+	Enclosures Enclosures `json:"enclosures,omitempty"`
+	Media      *Media     `json:"media,omitempty"`
+
 	Read          bool   `json:"read"`
 	Favorite      bool   `json:"favorite"`
+	Queued        bool   `json:"queued"`
 	Score         int64  `json:"score,omitempty"`
 	Thumbnail     string `json:"thumbnail,omitempty"`
 	ThumbnailLink string `db:"thumbnail_link" json:"thumbnailLink,omitempty"`
 
+	// StateChanged is the last time the user changed the read, favorite or
+	// queue state of the article. It is only loaded by ModifiedSince
+	// queries.
+	StateChanged sql.NullTime `db:"state_changed" json:"-"`
+
 	IsNew bool `json:"-"`
 
 	Hit struct {
@@ -34,6 +46,31 @@ type Article struct {
 	} `json:"hits"`
 }
 
+// Enclosure is a media file attached to an article.
+type Enclosure struct {
+	URL    string `json:"url"`
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Length int64  `json:"length,omitempty"`
+	// Duration is the media duration in seconds.
+	Duration int64 `json:"duration,omitempty"`
+}
+
+type Enclosures []Enclosure
+
+// Media holds the podcast metadata of an article, as provided by the itunes
+// and media rss extensions.
+type Media struct {
+	Image string `json:"image,omitempty"`
+	// Duration is the episode duration in seconds.
+	Duration int64 `json:"duration,omitempty"`
+	Episode  int   `json:"episode,omitempty"`
+	Season   int   `json:"season,omitempty"`
+	Explicit bool  `json:"explicit,omitempty"`
+}
+
+type Categories []string
+
 type ArticleExtract struct {
 	ArticleID ArticleID
 	Title     string
@@ -48,6 +85,9 @@ const (
 	DefaultSort sortingField = iota
 	SortByID
 	SortByDate
+	// SortByQueue sorts by the position of the articles in the user's
+	// read-later queue.
+	SortByQueue
 )
 
 const (
@@ -68,6 +108,7 @@ type QueryOptions struct {
 	UnreadOnly      bool
 	UnreadFirst     bool
 	FavoriteOnly    bool
+	QueuedOnly      bool
 	UntaggedOnly    bool
 	IncludeScores   bool
 	HighScoredFirst bool
@@ -77,8 +118,11 @@ type QueryOptions struct {
 	AfterDate       time.Time
 	BeforeScore     int64
 	AfterScore      int64
+	ModifiedSince   time.Time
+	InitialState    bool
 	IDs             []ArticleID
 	FeedIDs         []FeedID
+	LabelIDs        []LabelID
 	Filters         []Filter
 
 	SortField sortingField
@@ -129,6 +173,14 @@ func FeedIDs(ids []FeedID) QueryOpt {
 	}}
 }
 
+// LabelIDs limits the query to the articles with any of the specified
+// labels.
+func LabelIDs(ids []LabelID) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.LabelIDs = ids
+	}}
+}
+
 // TimeRange sets the minimum and maximum times of returned articles.
 func TimeRange(after, before time.Time) QueryOpt {
 	return QueryOpt{func(o *QueryOptions) {
@@ -137,6 +189,14 @@ func TimeRange(after, before time.Time) QueryOpt {
 	}}
 }
 
+// ModifiedSince limits the query to articles that were published, or whose
+// state was changed by the user, after the given time.
+func ModifiedSince(t time.Time) QueryOpt {
+	return QueryOpt{func(o *QueryOptions) {
+		o.ModifiedSince = t
+	}}
+}
+
 // ScoreRange sets the minimum and maximum scores of returned articles.
 func ScoreRange(after, before int64) QueryOpt {
 	return QueryOpt{func(o *QueryOptions) {
@@ -181,6 +241,11 @@ var (
 		o.FavoriteOnly = true
 	}}
 
+	// QueuedOnly sets the query for articles in the read-later queue.
+	QueuedOnly = QueryOpt{func(o *QueryOptions) {
+		o.QueuedOnly = true
+	}}
+
 	// UntaggedOnly sets the query for untagged articles.
 	UntaggedOnly = QueryOpt{func(o *QueryOptions) {
 		o.UntaggedOnly = true
@@ -191,6 +256,12 @@ var (
 		o.IncludeScores = true
 	}}
 
+	// InitialState sets the state of new articles, without recording it as
+	// a change made by the user.
+	InitialState = QueryOpt{func(o *QueryOptions) {
+		o.InitialState = true
+	}}
+
 	// HighScoredFirst sets the query to return articles with high scores first.
 	HighScoredFirst = QueryOpt{func(o *QueryOptions) {
 		o.HighScoredFirst = true
@@ -243,6 +314,72 @@ func (id ArticleID) Value() (driver.Value, error) {
 	return int64(id), nil
 }
 
+func (val *Enclosures) Scan(src interface{}) error {
+	return scanJSON(src, val, "Enclosures")
+}
+
+func (val Enclosures) Value() (driver.Value, error) {
+	return valueJSON(len(val), val)
+}
+
+func (val *Media) Scan(src interface{}) error {
+	return scanJSON(src, val, "Media")
+}
+
+// Value stores an empty media as NULL, so that it is scanned back as a nil
+// pointer.
+func (val Media) Value() (driver.Value, error) {
+	if val == (Media{}) {
+		return nil, nil
+	}
+
+	return valueJSON(1, val)
+}
+
+func (val *Categories) Scan(src interface{}) error {
+	return scanJSON(src, val, "Categories")
+}
+
+func (val Categories) Value() (driver.Value, error) {
+	return valueJSON(len(val), val)
+}
+
+// scanJSON decodes a json encoded text column into val.
+func scanJSON(src interface{}, val interface{}, name string) error {
+	var data []byte
+	switch t := src.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type string (%s)", src, src, name)
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, val)
+}
+
+// valueJSON encodes val for a json text column, using an empty string for
+// empty values.
+func valueJSON(length int, val interface{}) (driver.Value, error) {
+	if length == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
 func GetUserFilters(u User) []Filter {
 	if filters, ok := u.ProfileData["filters"].([]Filter); ok {
 		return filters
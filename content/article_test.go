@@ -1,6 +1,7 @@
 package content_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/urandom/readeef/content"
@@ -37,3 +38,39 @@ func TestArticle_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestEnclosures_ScanValue(t *testing.T) {
+	tests := []struct {
+		name string
+		val  content.Enclosures
+	}{
+		{"empty", nil},
+		{"single", content.Enclosures{{URL: "http://example.com/1.mp3", Type: "audio/mpeg", Length: 10, Duration: 60}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.val.Value()
+			if err != nil {
+				t.Fatalf("Enclosures.Value() error = %v", err)
+			}
+
+			var got content.Enclosures
+			if err := got.Scan(v); err != nil {
+				t.Fatalf("Enclosures.Scan() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.val) {
+				t.Errorf("Enclosures.Scan() = %#v, want %#v", got, tt.val)
+			}
+		})
+	}
+
+	var got content.Categories
+	if err := got.Scan([]byte(`["a","b"]`)); err != nil || !reflect.DeepEqual(got, content.Categories{"a", "b"}) {
+		t.Errorf("Categories.Scan() = %#v, error = %v", got, err)
+	}
+
+	if err := got.Scan(42); err == nil {
+		t.Errorf("Categories.Scan() expected an error")
+	}
+}
@@ -0,0 +1,57 @@
+package extract
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+type rateLimited struct {
+	generator Generator
+	interval  time.Duration
+
+	mu   *sync.Mutex
+	next map[string]time.Time
+}
+
+// RateLimit wraps a generator so that consecutive extracts from the same
+// host are at least interval apart. Callers wait for their turn.
+func RateLimit(g Generator, interval time.Duration) Generator {
+	if interval <= 0 {
+		return g
+	}
+
+	return rateLimited{generator: g, interval: interval, mu: &sync.Mutex{}, next: map[string]time.Time{}}
+}
+
+func (g rateLimited) Generate(link string) (content.Extract, error) {
+	if wait := g.reserve(link); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return g.generator.Generate(link)
+}
+
+// reserve books the next slot for the link's host, returning how long the
+// caller has to wait for it.
+func (g rateLimited) reserve(link string) time.Duration {
+	var host string
+	if u, err := url.Parse(link); err == nil {
+		host = u.Host
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	slot := g.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+
+	g.next[host] = slot.Add(g.interval)
+
+	return slot.Sub(now)
+}
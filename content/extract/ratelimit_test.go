@@ -0,0 +1,41 @@
+package extract
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+type generatorFunc func(link string) (content.Extract, error)
+
+func (f generatorFunc) Generate(link string) (content.Extract, error) {
+	return f(link)
+}
+
+func TestRateLimit(t *testing.T) {
+	interval := time.Hour
+	g := RateLimit(generatorFunc(func(link string) (content.Extract, error) {
+		return content.Extract{}, nil
+	}), interval).(rateLimited)
+
+	tests := []struct {
+		name string
+		link string
+		want time.Duration
+	}{
+		{"first", "http://example.com/1", 0},
+		{"same host", "http://example.com/2", interval},
+		{"other host", "http://sugr.org/1", 0},
+		{"same host again", "http://example.com/3", 2 * interval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.reserve(tt.link)
+			if got > tt.want || got < tt.want-time.Minute {
+				t.Errorf("rateLimited.reserve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
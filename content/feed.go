@@ -22,10 +22,35 @@ type Feed struct {
 	HubLink        string          `db:"hub_link" json:"-"`
 	UpdateError    string          `db:"update_error" json:"updateError"`
 	SubscribeError string          `db:"subscribe_error" json:"subscribeError"`
+	ETag           string          `db:"etag" json:"-"`
+	LastModified   string          `db:"last_modified" json:"-"`
+	NextCheck      time.Time       `db:"next_check" json:"nextCheck"`
+	FailureCount   int             `db:"failure_count" json:"failureCount"`
+	Dead           bool            `json:"dead"`
 	TTL            time.Duration   `json:"-"`
 	SkipHours      map[int]bool    `json:"-"`
 	SkipDays       map[string]bool `json:"-"`
 
+	// ExtractContent makes the feed manager extract the full content of
+	// every new article of the feed.
+	ExtractContent bool `db:"extract_content" json:"extractContent"`
+	// UserExtractContent is the same option, set on the subscription of a
+	// user. Feeds fetched without a user have it set when any of the
+	// subscribers has enabled it.
+	UserExtractContent bool `db:"user_extract_content" json:"userExtractContent"`
+
+	// Retention overrides the global article retention of the feed.
+	Retention Retention `db:"retention" json:"retention"`
+	// UserRetention is set on the subscription of a user, and overrides
+	// the feed retention for that user. It is only loaded for the feeds of
+	// a user.
+	UserRetention Retention `db:"user_retention" json:"userRetention"`
+
+	// Credentials holds the encrypted FeedAuth used to download the feed.
+	Credentials string `db:"credentials" json:"-"`
+	// Scraper is set for feeds generated from an html page.
+	Scraper *Scraper `json:"scraper,omitempty"`
+
 	parsedArticles []Article
 }
 
@@ -60,9 +85,31 @@ func (f *Feed) Refresh(pf parser.Feed) {
 			Description: pf.Articles[i].Description,
 			Link:        pf.Articles[i].Link,
 			Date:        pf.Articles[i].Date,
+			Author:      pf.Articles[i].Author,
+			Categories:  Categories(pf.Articles[i].Categories),
 		}
 		a.FeedID = f.ID
 
+		for _, e := range pf.Articles[i].Enclosures {
+			a.Enclosures = append(a.Enclosures, Enclosure{
+				URL:      e.URL,
+				Type:     e.Type,
+				Title:    e.Title,
+				Length:   e.Length,
+				Duration: int64(e.Duration / time.Second),
+			})
+		}
+
+		if media := (Media{
+			Image:    pf.Articles[i].Thumbnail,
+			Duration: int64(pf.Articles[i].Duration / time.Second),
+			Episode:  pf.Articles[i].Episode,
+			Season:   pf.Articles[i].Season,
+			Explicit: pf.Articles[i].Explicit,
+		}); media != (Media{}) {
+			a.Media = &media
+		}
+
 		if pf.Articles[i].Guid != "" {
 			a.Guid.Valid = true
 			a.Guid.String = pf.Articles[i].Guid
@@ -72,6 +119,12 @@ func (f *Feed) Refresh(pf parser.Feed) {
 	}
 }
 
+// ShouldExtract reports whether the content of new articles should be
+// extracted, either because of the feed or the subscription option.
+func (f Feed) ShouldExtract() bool {
+	return f.ExtractContent || f.UserExtractContent
+}
+
 func (f Feed) ParsedArticles() (a []Article) {
 	return f.parsedArticles
 }
@@ -0,0 +1,116 @@
+package content
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// FeedAuth holds the credentials and request customizations used when
+// downloading a feed.
+type FeedAuth struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is sent as a bearer token in the Authorization header.
+	Token string `json:"token,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+	// CookieJar keeps the cookies set by the server between downloads.
+	CookieJar bool   `json:"cookieJar,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// Empty reports whether there is nothing to customize.
+func (a FeedAuth) Empty() bool {
+	return a.Username == "" && a.Password == "" && a.Token == "" &&
+		len(a.Headers) == 0 && len(a.Cookies) == 0 && !a.CookieJar && a.UserAgent == ""
+}
+
+// Equal reports whether both hold the same customizations.
+func (a FeedAuth) Equal(b FeedAuth) bool {
+	ja, erra := json.Marshal(a)
+	jb, errb := json.Marshal(b)
+
+	return erra == nil && errb == nil && string(ja) == string(jb)
+}
+
+// Encrypt serializes the credentials, encrypting them with a key derived
+// from the secret. Empty credentials produce an empty string.
+func (a FeedAuth) Encrypt(secret []byte) (string, error) {
+	if a.Empty() {
+		return "", nil
+	}
+
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", errors.Wrap(err, "marshaling feed credentials")
+	}
+
+	gcm, err := feedAuthCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "generating nonce")
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, b, nil)), nil
+}
+
+// DecryptFeedAuth reverses FeedAuth.Encrypt.
+func DecryptFeedAuth(data string, secret []byte) (FeedAuth, error) {
+	var auth FeedAuth
+	if data == "" {
+		return auth, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return auth, errors.Wrap(err, "decoding feed credentials")
+	}
+
+	gcm, err := feedAuthCipher(secret)
+	if err != nil {
+		return auth, err
+	}
+
+	if len(b) < gcm.NonceSize() {
+		return auth, errors.New("feed credentials too short")
+	}
+
+	b, err = gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return auth, errors.Wrap(err, "decrypting feed credentials")
+	}
+
+	if err := json.Unmarshal(b, &auth); err != nil {
+		return auth, errors.Wrap(err, "unmarshaling feed credentials")
+	}
+
+	return auth, nil
+}
+
+func feedAuthCipher(secret []byte) (cipher.AEAD, error) {
+	key := sha256.Sum256(secret)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gcm cipher")
+	}
+
+	return gcm, nil
+}
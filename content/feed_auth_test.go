@@ -0,0 +1,73 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeedAuth_Encrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    FeedAuth
+		secret  string
+		decrypt string
+		wantErr bool
+	}{
+		{name: "empty", auth: FeedAuth{}, secret: "secret", decrypt: "secret"},
+		{name: "basic", auth: FeedAuth{Username: "user", Password: "pass"}, secret: "secret", decrypt: "secret"},
+		{name: "full", auth: FeedAuth{
+			Token:     "token",
+			Headers:   map[string]string{"X-Api-Key": "key"},
+			Cookies:   map[string]string{"session": "abc"},
+			CookieJar: true,
+			UserAgent: "readeef",
+		}, secret: "secret", decrypt: "secret"},
+		{name: "wrong secret", auth: FeedAuth{Token: "token"}, secret: "secret", decrypt: "other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.auth.Encrypt([]byte(tt.secret))
+			if err != nil {
+				t.Errorf("FeedAuth.Encrypt() error = %v", err)
+				return
+			}
+
+			if tt.auth.Empty() != (data == "") {
+				t.Errorf("FeedAuth.Encrypt() = %q for empty %v", data, tt.auth.Empty())
+				return
+			}
+
+			got, err := DecryptFeedAuth(data, []byte(tt.decrypt))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecryptFeedAuth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.auth) {
+				t.Errorf("DecryptFeedAuth() = %v, want %v", got, tt.auth)
+			}
+		})
+	}
+}
+
+func TestFeedAuth_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b FeedAuth
+		want bool
+	}{
+		{name: "empty", want: true},
+		{name: "empty maps", a: FeedAuth{Token: "token", Headers: map[string]string{}}, b: FeedAuth{Token: "token"}, want: true},
+		{name: "same", a: FeedAuth{Username: "user", Cookies: map[string]string{"a": "b"}}, b: FeedAuth{Username: "user", Cookies: map[string]string{"a": "b"}}, want: true},
+		{name: "different", a: FeedAuth{Username: "user", Password: "a"}, b: FeedAuth{Username: "user", Password: "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("FeedAuth.Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
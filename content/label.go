@@ -0,0 +1,59 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+type LabelID int64
+
+// colorPattern matches the hex colors of the labels.
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Label is a user defined marker, attached to individual articles.
+type Label struct {
+	ID      LabelID `json:"id"`
+	User    Login   `db:"user_login" json:"-"`
+	Caption string  `json:"caption"`
+	FgColor string  `db:"fg_color" json:"fgColor"`
+	BgColor string  `db:"bg_color" json:"bgColor"`
+}
+
+func (l Label) Validate() error {
+	if l.Caption == "" {
+		return NewValidationError(errors.New("Label has no caption"))
+	}
+
+	if l.User == "" {
+		return NewValidationError(errors.New("Label has no user"))
+	}
+
+	for _, c := range []string{l.FgColor, l.BgColor} {
+		if c != "" && !colorPattern.MatchString(c) {
+			return NewValidationError(fmt.Errorf("Label has an invalid color %s", c))
+		}
+	}
+
+	return nil
+}
+
+func (l Label) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.User, l.ID, l.Caption)
+}
+
+func (id *LabelID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (LabelID)", src, src)
+	}
+
+	*id = LabelID(asInt)
+
+	return nil
+}
+
+func (id LabelID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
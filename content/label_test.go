@@ -0,0 +1,30 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestLabel_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		label   content.Label
+		wantErr bool
+	}{
+		{"valid", content.Label{User: "user", Caption: "label"}, false},
+		{"colors", content.Label{User: "user", Caption: "label", FgColor: "#fff", BgColor: "#00AAff"}, false},
+		{"no caption", content.Label{User: "user"}, true},
+		{"no user", content.Label{Caption: "label"}, true},
+		{"named color", content.Label{User: "user", Caption: "label", FgColor: "red"}, true},
+		{"short color", content.Label{User: "user", Caption: "label", BgColor: "#ff"}, true},
+		{"no hash", content.Label{User: "user", Caption: "label", BgColor: "ffffff"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.label.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Label.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
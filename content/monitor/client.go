@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// publicClient returns a client for the user supplied urls, which refuses to
+// connect to non-public addresses. The check is done on the resolved
+// address, so that host names pointing to internal hosts, and redirects to
+// them, are rejected as well.
+func publicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !content.IsPublicIP(ip) {
+				return fmt.Errorf("refusing to connect to non-public address %s", address)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"github.com/urandom/readeef/content/extract"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+// Extractor stores the extracted content of the new articles of feeds that
+// have content extraction enabled, either for the feed or for any of its
+// subscriptions.
+func Extractor(service eventable.Service, generator extract.Generator, log log.Log) {
+	for event := range service.Listener() {
+		switch data := event.Data.(type) {
+		case eventable.FeedUpdateData:
+			if len(data.NewArticles) > 0 {
+				go processExtractorEvent(service, data, generator, log)
+			}
+		}
+	}
+}
+
+func processExtractorEvent(service eventable.Service, data eventable.FeedUpdateData, generator extract.Generator, log log.Log) {
+	// The options may have changed since the feed was scheduled.
+	feed, err := service.FeedRepo().FindByLink(data.Feed.Link)
+	if err != nil {
+		log.Printf("Error getting feed %s: %+v", data.Feed, err)
+		return
+	}
+
+	if !feed.ShouldExtract() {
+		return
+	}
+
+	log.Infof("Extracting content of %d articles of feed %s", len(data.NewArticles), feed)
+
+	repo := service.ExtractRepo()
+	for _, a := range data.NewArticles {
+		if _, err := extract.Get(a, repo, generator, nil); err != nil {
+			log.Printf("Error extracting content of article %s: %+v", a, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/content/repo/eventable"
 	"github.com/urandom/readeef/content/search"
 	"github.com/urandom/readeef/log"
@@ -13,6 +14,8 @@ func Index(service eventable.Service, provider search.Provider, log log.Log) {
 			go processIndexUpdateEvent(data, provider, log)
 		case eventable.FeedDeleteData:
 			go processIndexDeleteEvent(data, provider, log)
+		case eventable.AnnotationData:
+			go processIndexAnnotationEvent(data, provider, log)
 		}
 	}
 }
@@ -32,3 +35,16 @@ func processIndexDeleteEvent(data eventable.FeedDeleteData, provider search.Prov
 		log.Printf("Error removing feed %s from search index: %+v", data.Feed, err)
 	}
 }
+
+func processIndexAnnotationEvent(data eventable.AnnotationData, provider search.Provider, log log.Log) {
+	op := search.BatchAdd
+	if data.Deleted {
+		op = search.BatchDelete
+	}
+
+	log.Infof("Updating annotation search index for %s", data.Annotation)
+
+	if err := provider.BatchIndexAnnotations([]content.Annotation{data.Annotation}, op); err != nil {
+		log.Printf("Error updating annotation %s in search index: %+v", data.Annotation, err)
+	}
+}
@@ -0,0 +1,236 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+const ruleMatchEvent = "rule-match"
+
+// rulePayload is the json body that is posted to the url of a rule.
+type rulePayload struct {
+	Event     string            `json:"event"`
+	RuleID    content.RuleID    `json:"ruleID"`
+	Timestamp time.Time         `json:"timestamp"`
+	Data      webhookFeedUpdate `json:"data"`
+}
+
+// applyRules performs the actions of the active user rules on the matching
+// new articles of the feed. Only the rules of the feed's subscribers are
+// evaluated. The actions go through the non-eventable repos, so the state
+// events of the changed articles are returned for the caller to dispatch.
+func applyRules(
+	ctx context.Context,
+	service repo.Service,
+	feed content.Feed,
+	articles []content.Article,
+	users []content.User,
+	client *http.Client,
+	log log.Log,
+) []eventable.Event {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var events []eventable.Event
+
+	for _, user := range users {
+		rules, err := service.RuleRepo().ForUser(user)
+		if err != nil {
+			log.Printf("Error getting rules of user %s: %+v", user, err)
+			continue
+		}
+
+		if !hasActiveRule(rules) {
+			continue
+		}
+
+		if subscribed, err := hasFeed(user, feed.ID, service.FeedRepo()); err != nil {
+			log.Printf("Error getting feeds of user %s: %+v", user, err)
+			continue
+		} else if !subscribed {
+			continue
+		}
+
+		for _, rule := range rules {
+			if !rule.Active {
+				continue
+			}
+
+			feeds, err := tagFeeds(rule.Match.TagIDs, user, service.TagRepo())
+			if err != nil {
+				log.Printf("Error getting tag feeds of rule %s: %+v", rule, err)
+				continue
+			}
+
+			matching, err := rule.Matching(articles, feeds)
+			if err != nil {
+				log.Printf("Error matching articles of rule %s: %+v", rule, err)
+				continue
+			}
+
+			if len(matching) == 0 {
+				continue
+			}
+
+			log.Infof("Rule %s matched %d new articles of feed %s", rule, len(matching), feed)
+
+			ruleEvents, err := performRuleActions(ctx, rule, user, feed, matching, service, client, log)
+			if err != nil {
+				log.Printf("Error performing the actions of rule %s: %+v", rule, err)
+			}
+
+			events = append(events, ruleEvents...)
+		}
+	}
+
+	return events
+}
+
+func hasActiveRule(rules []content.Rule) bool {
+	for _, r := range rules {
+		if r.Active {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasFeed(user content.User, id content.FeedID, feedRepo repo.Feed) (bool, error) {
+	feeds, err := feedRepo.ForUser(user)
+	if err != nil {
+		return false, err
+	}
+
+	for _, f := range feeds {
+		if f.ID == id {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func performRuleActions(
+	ctx context.Context,
+	rule content.Rule,
+	user content.User,
+	feed content.Feed,
+	articles []content.Article,
+	service repo.Service,
+	client *http.Client,
+	log log.Log,
+) ([]eventable.Event, error) {
+	ids := make([]content.ArticleID, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	articleRepo := service.ArticleRepo()
+	actions := rule.Actions
+
+	var events []eventable.Event
+
+	if actions.Read {
+		if err := articleRepo.Read(true, user, content.IDs(ids)); err != nil {
+			return events, errors.WithMessage(err, "marking articles as read")
+		}
+
+		events = append(events, eventable.ReadStateEvent(user, true, content.IDs(ids)))
+	}
+
+	if actions.Favorite {
+		if err := articleRepo.Favor(true, user, content.IDs(ids)); err != nil {
+			return events, errors.WithMessage(err, "marking articles as favorite")
+		}
+
+		events = append(events, eventable.FavorStateEvent(user, true, content.IDs(ids)))
+	}
+
+	for _, id := range actions.LabelIDs {
+		label, err := service.LabelRepo().Get(id, user)
+		if err != nil {
+			if content.IsNoContent(err) {
+				continue
+			}
+
+			return events, errors.WithMessage(err, fmt.Sprintf("getting label %d", id))
+		}
+
+		if err := service.LabelRepo().Assign(label, ids); err != nil {
+			return events, errors.WithMessage(err, fmt.Sprintf("assigning label %s", label))
+		}
+	}
+
+	if actions.Delete {
+		if err := articleRepo.Hide(user, ids); err != nil {
+			return events, errors.WithMessage(err, "hiding articles")
+		}
+	}
+
+	if actions.PostURL != "" {
+		go func() {
+			if err := postRuleMatch(ctx, rule, feed, articles, client); err != nil {
+				log.Printf("Error posting the matches of rule %s: %+v", rule, err)
+			}
+		}()
+	}
+
+	return events, nil
+}
+
+func postRuleMatch(
+	ctx context.Context,
+	rule content.Rule,
+	feed content.Feed,
+	articles []content.Article,
+	client *http.Client,
+) error {
+	payload, err := json.Marshal(rulePayload{
+		Event:     ruleMatchEvent,
+		RuleID:    rule.ID,
+		Timestamp: time.Now().UTC(),
+		Data:      feedUpdatePayload(feed, articles),
+	})
+	if err != nil {
+		return errors.Wrap(err, "encoding payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", rule.Actions.PostURL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "readeef")
+	req.Header.Set("X-Readeef-Event", ruleMatchEvent)
+	req.Header.Set("X-Readeef-Rule", strconv.FormatInt(int64(rule.ID), 10))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "posting payload")
+	}
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -9,23 +9,42 @@ import (
 	"github.com/urandom/readeef/log"
 )
 
+// Unread marks the new articles of the updated feeds as unread for every
+// user, and then applies the user rules to them, so that the rule actions
+// are not overwritten by the initial unread state.
 func Unread(ctx context.Context, service eventable.Service, log log.Log) {
 	// Grab the non-eventable article repo. We don't want to notify on the
 	// initial unread mark.
 	articleRepo := service.Service.ArticleRepo()
 
 	go func() {
-		articleRepo.RemoveStaleUnreadRecords()
+		removeStale := func() {
+			if err := articleRepo.RemoveStaleUnreadRecords(); err != nil {
+				log.Printf("Error removing stale unread records: %+v", err)
+			}
+
+			if err := articleRepo.RemoveStaleStateChanges(); err != nil {
+				log.Printf("Error removing stale article state changes: %+v", err)
+			}
+		}
+
+		removeStale()
 
 		ticker := time.NewTicker(24 * time.Hour)
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			articleRepo.RemoveStaleUnreadRecords()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				removeStale()
+			}
 		}
 	}()
 
+	client := publicClient(webhookTimeout)
+
 	userRepo := service.UserRepo()
 	for event := range service.Listener() {
 		switch data := event.Data.(type) {
@@ -47,10 +66,23 @@ func Unread(ctx context.Context, service eventable.Service, log log.Log) {
 				if err := articleRepo.Read(
 					false, user, content.IDs(ids),
 					content.Filters(content.GetUserFilters(user)),
+					content.InitialState,
 				); err != nil {
 					log.Printf("Error marking new articles as unread: %+v", err)
 				}
 			}
+
+			// The rules use the non-eventable repos as well. Dispatching from
+			// within the listener could block the bus, as it waits for this
+			// very listener to consume its events, so the state events of the
+			// rule actions are dispatched separately.
+			if events := applyRules(ctx, service.Service, data.Feed, data.NewArticles, users, client, log); len(events) > 0 {
+				go func() {
+					for _, e := range events {
+						service.Dispatch(e)
+					}
+				}()
+			}
 		}
 	}
 }
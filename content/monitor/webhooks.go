@@ -0,0 +1,484 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/repo/eventable"
+	"github.com/urandom/readeef/log"
+)
+
+const (
+	webhookTimeout      = 15 * time.Second
+	webhookPollInterval = time.Minute
+	webhookBatchSize    = 50
+	webhookCacheTTL     = time.Minute
+	// A failed delivery is retried after 1, 2, 4, ... minutes, for a total
+	// of a little over 2 hours, before it is given up.
+	webhookMaxAttempts = 8
+	webhookRetryDelay  = time.Minute
+	webhookHistory     = 30 * 24 * time.Hour
+)
+
+// webhookPayload is the json body that is posted to the webhook urls.
+type webhookPayload struct {
+	Event     string            `json:"event"`
+	WebhookID content.WebhookID `json:"webhookID"`
+	Timestamp time.Time         `json:"timestamp"`
+	Data      interface{}       `json:"data"`
+}
+
+type webhookFeed struct {
+	ID       content.FeedID `json:"id"`
+	Title    string         `json:"title"`
+	Link     string         `json:"link"`
+	SiteLink string         `json:"siteLink"`
+}
+
+type webhookArticle struct {
+	ID     content.ArticleID `json:"id"`
+	Title  string            `json:"title"`
+	Link   string            `json:"link"`
+	Author string            `json:"author,omitempty"`
+	Date   time.Time         `json:"date"`
+}
+
+type webhookFeedUpdate struct {
+	Feed     webhookFeed      `json:"feed"`
+	Articles []webhookArticle `json:"articles"`
+}
+
+type webhookDeliverer struct {
+	repo   repo.Webhook
+	client *http.Client
+	secret []byte
+	log    log.Log
+	wake   chan struct{}
+}
+
+// webhookCache holds the active webhooks, along with the feeds of their
+// users and tags, so that matching an event doesn't require querying the
+// database. Subscription changes are not dispatched as events, so the
+// cache is reloaded once it is older than webhookCacheTTL, as well as when
+// the tags of a feed change.
+type webhookCache struct {
+	webhooks  []content.Webhook
+	userFeeds map[content.Login]map[content.FeedID]struct{}
+	tagFeeds  map[content.WebhookID][]content.FeedID
+	loaded    time.Time
+}
+
+// webhookEvents buffers the service events, so that the bus listener never
+// waits for the database.
+type webhookEvents struct {
+	mu     sync.Mutex
+	events []eventable.Event
+	wake   chan struct{}
+}
+
+// Webhooks queues the service events for the matching user webhooks, and
+// delivers the queued payloads. Failed deliveries are retried with an
+// exponential backoff.
+func Webhooks(ctx context.Context, service eventable.Service, secret []byte, log log.Log) {
+	d := webhookDeliverer{
+		repo:   service.WebhookRepo(),
+		client: publicClient(webhookTimeout),
+		secret: secret,
+		log:    log,
+		wake:   make(chan struct{}, 1),
+	}
+
+	go d.loop(ctx)
+
+	events := &webhookEvents{wake: make(chan struct{}, 1)}
+	go d.queueEvents(ctx, events, service.Service)
+
+	for event := range service.Listener() {
+		events.push(event)
+	}
+}
+
+func (e *webhookEvents) push(event eventable.Event) {
+	e.mu.Lock()
+	e.events = append(e.events, event)
+	e.mu.Unlock()
+
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (e *webhookEvents) drain() []eventable.Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	events := e.events
+	e.events = nil
+
+	return events
+}
+
+// queueEvents enqueues the deliveries of the buffered events.
+func (d webhookDeliverer) queueEvents(ctx context.Context, events *webhookEvents, service repo.Service) {
+	cache := webhookCache{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events.wake:
+		}
+
+		for _, event := range events.drain() {
+			if event.Name == eventable.FeedSetTagsEvent {
+				cache.loaded = time.Time{}
+			}
+
+			if time.Since(cache.loaded) > webhookCacheTTL {
+				// A failed reload keeps the previous webhooks, and is
+				// retried with the next event.
+				if err := cache.load(service); err != nil {
+					d.log.Printf("Error loading webhooks: %+v", err)
+				}
+			}
+
+			if queueWebhookEvent(event, cache, d.repo, d.log) > 0 {
+				d.notify()
+			}
+		}
+	}
+}
+
+func (c *webhookCache) load(service repo.Service) error {
+	all, err := service.WebhookRepo().All()
+	if err != nil {
+		return errors.WithMessage(err, "getting webhooks")
+	}
+
+	webhooks := []content.Webhook{}
+	userFeeds := map[content.Login]map[content.FeedID]struct{}{}
+	webhookFeeds := map[content.WebhookID][]content.FeedID{}
+
+	for _, w := range all {
+		if !w.Active {
+			continue
+		}
+
+		user := content.User{Login: w.User}
+		if _, ok := userFeeds[w.User]; !ok {
+			feeds, err := service.FeedRepo().ForUser(user)
+			if err != nil {
+				return errors.WithMessage(err, fmt.Sprintf("getting feeds of user %s", user))
+			}
+
+			ids := map[content.FeedID]struct{}{}
+			for _, f := range feeds {
+				ids[f.ID] = struct{}{}
+			}
+			userFeeds[w.User] = ids
+		}
+
+		ids, err := tagFeeds(w.Filter.TagIDs, user, service.TagRepo())
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("getting tag feeds of webhook %s", w))
+		}
+
+		webhooks = append(webhooks, w)
+		webhookFeeds[w.ID] = ids
+	}
+
+	*c = webhookCache{
+		webhooks: webhooks, userFeeds: userFeeds, tagFeeds: webhookFeeds,
+		loaded: time.Now(),
+	}
+
+	return nil
+}
+
+// queueWebhookEvent enqueues a delivery of the event for every matching
+// cached webhook, and returns the number of queued deliveries.
+func queueWebhookEvent(event eventable.Event, cache webhookCache, repo repo.Webhook, log log.Log) int {
+	login, feedID := webhookEventScope(event)
+
+	queued := 0
+	for _, w := range cache.webhooks {
+		if login != "" && w.User != login {
+			continue
+		}
+
+		switch event.Data.(type) {
+		case eventable.FeedUpdateData:
+			// New articles are only sent to the feed's subscribers.
+			if _, ok := cache.userFeeds[w.User][feedID]; !ok {
+				continue
+			}
+		case eventable.FeedDeleteData:
+			// Deleted feeds no longer have subscribers, and are only sent
+			// to the webhooks that explicitly follow them.
+			if !containsFeed(w.Filter.FeedIDs, feedID) {
+				continue
+			}
+		}
+
+		if !w.Matches(event.Name, feedID, cache.tagFeeds[w.ID]) {
+			continue
+		}
+
+		payload, err := json.Marshal(webhookPayload{
+			Event:     event.Name,
+			WebhookID: w.ID,
+			Timestamp: time.Now().UTC(),
+			Data:      webhookEventData(event),
+		})
+		if err != nil {
+			log.Printf("Error encoding webhook payload for event %s: %+v", event.Name, err)
+			continue
+		}
+
+		if _, err := repo.Enqueue(content.WebhookDelivery{
+			WebhookID: w.ID, Event: event.Name, Payload: string(payload),
+		}); err != nil {
+			log.Printf("Error enqueuing delivery for webhook %s: %+v", w, err)
+			continue
+		}
+
+		queued++
+	}
+
+	return queued
+}
+
+// webhookEventScope returns the user and feed that the event concerns.
+// Either can be empty.
+func webhookEventScope(event eventable.Event) (content.Login, content.FeedID) {
+	switch data := event.Data.(type) {
+	case eventable.ArticleStateData:
+		// State changes are matched against a feed filter, only when they
+		// are limited to a single feed.
+		if ids, ok := data.Options["feedIDs"].([]content.FeedID); ok && len(ids) == 1 {
+			return data.User, ids[0]
+		}
+
+		return data.User, 0
+	case eventable.FeedSetTagsData:
+		return data.User.Login, data.Feed.ID
+	case eventable.FeedData:
+		return "", data.FeedID()
+	case eventable.UserData:
+		return data.UserLogin(), 0
+	}
+
+	return "", 0
+}
+
+func webhookEventData(event eventable.Event) interface{} {
+	data, ok := event.Data.(eventable.FeedUpdateData)
+	if !ok {
+		return event.Data
+	}
+
+	return feedUpdatePayload(data.Feed, data.NewArticles)
+}
+
+func feedUpdatePayload(feed content.Feed, articles []content.Article) webhookFeedUpdate {
+	update := webhookFeedUpdate{
+		Feed: webhookFeed{
+			ID: feed.ID, Title: feed.Title,
+			Link: feed.Link, SiteLink: feed.SiteLink,
+		},
+		Articles: make([]webhookArticle, len(articles)),
+	}
+
+	for i, a := range articles {
+		update.Articles[i] = webhookArticle{
+			ID: a.ID, Title: a.Title, Link: a.Link, Author: a.Author, Date: a.Date,
+		}
+	}
+
+	return update
+}
+
+// tagFeeds returns the ids of the feeds of the given user tags. Missing tags
+// are skipped.
+func tagFeeds(tagIDs []content.TagID, user content.User, tagRepo repo.Tag) ([]content.FeedID, error) {
+	var ids []content.FeedID
+
+	for _, id := range tagIDs {
+		tag, err := tagRepo.Get(id, user)
+		if err != nil {
+			if content.IsNoContent(err) {
+				continue
+			}
+
+			return nil, errors.WithMessage(err, "getting tag")
+		}
+
+		feedIDs, err := tagRepo.FeedIDs(tag, user)
+		if err != nil {
+			return nil, errors.WithMessage(err, "getting tag feed ids")
+		}
+
+		ids = append(ids, feedIDs...)
+	}
+
+	return ids, nil
+}
+
+func containsFeed(ids []content.FeedID, id content.FeedID) bool {
+	for i := range ids {
+		if ids[i] == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d webhookDeliverer) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d webhookDeliverer) loop(ctx context.Context) {
+	poll := time.NewTicker(webhookPollInterval)
+	defer poll.Stop()
+
+	prune := time.NewTicker(24 * time.Hour)
+	defer prune.Stop()
+
+	d.deliverPending(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-prune.C:
+			if err := d.repo.DeleteStaleDeliveries(time.Now().Add(-webhookHistory)); err != nil {
+				d.log.Printf("Error deleting stale webhook deliveries: %+v", err)
+			}
+			continue
+		case <-d.wake:
+		case <-poll.C:
+		}
+
+		d.deliverPending(ctx)
+	}
+}
+
+func (d webhookDeliverer) deliverPending(ctx context.Context) {
+	webhooks := map[content.WebhookID]content.Webhook{}
+
+	for ctx.Err() == nil {
+		deliveries, err := d.repo.Pending(time.Now(), webhookBatchSize)
+		if err != nil {
+			d.log.Printf("Error getting pending webhook deliveries: %+v", err)
+			return
+		}
+
+		if len(deliveries) == 0 {
+			return
+		}
+
+		if len(webhooks) == 0 {
+			all, err := d.repo.All()
+			if err != nil {
+				d.log.Printf("Error getting webhooks: %+v", err)
+				return
+			}
+
+			for _, w := range all {
+				webhooks[w.ID] = w
+			}
+		}
+
+		for _, delivery := range deliveries {
+			if w, ok := webhooks[delivery.WebhookID]; ok && w.Active {
+				delivery = d.deliver(ctx, w, delivery)
+			} else {
+				delivery.Status = content.DeliveryFailed
+				delivery.Error = "webhook is inactive"
+			}
+
+			if err := d.repo.UpdateDelivery(delivery); err != nil {
+				d.log.Printf("Error updating webhook delivery %s: %+v", delivery, err)
+				return
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// deliver posts the payload to the webhook url, and returns the delivery
+// with its updated state.
+func (d webhookDeliverer) deliver(ctx context.Context, w content.Webhook, delivery content.WebhookDelivery) content.WebhookDelivery {
+	d.log.Infof("Delivering %s to %s", delivery, w)
+
+	delivery.Attempts++
+	delivery.ResponseCode = 0
+
+	err := d.post(ctx, w, &delivery)
+	if err == nil {
+		delivery.Status = content.DeliveryDelivered
+		delivery.Error = ""
+
+		return delivery
+	}
+
+	d.log.Infof("Error delivering %s: %v", delivery, err)
+	delivery.Error = err.Error()
+
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = content.DeliveryFailed
+	} else {
+		delivery.NextAttempt = time.Now().Add(webhookRetryDelay << uint(delivery.Attempts-1))
+	}
+
+	return delivery
+}
+
+func (d webhookDeliverer) post(ctx context.Context, w content.Webhook, delivery *content.WebhookDelivery) error {
+	payload := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "readeef")
+	req.Header.Set("X-Readeef-Event", delivery.Event)
+	req.Header.Set("X-Readeef-Delivery", strconv.FormatInt(int64(delivery.ID), 10))
+	req.Header.Set("X-Readeef-Signature", "sha256="+w.Sign(payload, d.secret))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "posting payload")
+	}
+	defer resp.Body.Close()
+
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	delivery.ResponseCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package content
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The kinds of articles that an output feed can publish.
+const (
+	OutputKindFavorite = "favorite"
+	OutputKindTag      = "tag"
+	OutputKindFeed     = "feed"
+	OutputKindPopular  = "popular"
+)
+
+// OutputFeed publishes a set of a user's articles as a feed, served at a
+// url that contains its unguessable token. Popular output feeds may be
+// narrowed down to a tag or a feed.
+type OutputFeed struct {
+	Token     string    `json:"token"`
+	User      Login     `db:"user_login" json:"-"`
+	Kind      string    `json:"kind"`
+	TagID     TagID     `db:"tag_id" json:"tagID,omitempty"`
+	FeedID    FeedID    `db:"feed_id" json:"feedID,omitempty"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// GenerateToken assigns a new random token to the output feed.
+func (o *OutputFeed) GenerateToken() error {
+	token := make([]byte, 20)
+	if _, err := rand.Read(token); err != nil {
+		return errors.Wrap(err, "generating output feed token")
+	}
+
+	o.Token = hex.EncodeToString(token)
+
+	return nil
+}
+
+func (o OutputFeed) Validate() error {
+	if o.Token == "" {
+		return NewValidationError(errors.New("Output feed has no token"))
+	}
+
+	if o.User == "" {
+		return NewValidationError(errors.New("Output feed has no user"))
+	}
+
+	switch o.Kind {
+	case OutputKindFavorite:
+		if o.TagID != 0 || o.FeedID != 0 {
+			return NewValidationError(errors.New("Favorite output feeds cannot have a tag or feed"))
+		}
+	case OutputKindTag:
+		if o.TagID == 0 || o.FeedID != 0 {
+			return NewValidationError(errors.New("Tag output feeds require only a tag"))
+		}
+	case OutputKindFeed:
+		if o.FeedID == 0 || o.TagID != 0 {
+			return NewValidationError(errors.New("Feed output feeds require only a feed"))
+		}
+	case OutputKindPopular:
+		if o.TagID != 0 && o.FeedID != 0 {
+			return NewValidationError(errors.New("Popular output feeds can have either a tag or a feed"))
+		}
+	default:
+		return NewValidationError(errors.Errorf("Unknown output feed kind %q", o.Kind))
+	}
+
+	return nil
+}
+
+func (o OutputFeed) String() string {
+	return fmt.Sprintf("%s:%s: %s (tag %d, feed %d)", o.User, o.Kind, o.Title, o.TagID, o.FeedID)
+}
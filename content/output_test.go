@@ -0,0 +1,49 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestOutputFeed_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  content.OutputFeed
+		wantErr bool
+	}{
+		{"favorite", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindFavorite}, false},
+		{"no token", content.OutputFeed{User: "test", Kind: content.OutputKindFavorite}, true},
+		{"no user", content.OutputFeed{Token: "t", Kind: content.OutputKindFavorite}, true},
+		{"unknown kind", content.OutputFeed{Token: "t", User: "test", Kind: "other"}, true},
+		{"favorite with tag", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindFavorite, TagID: 1}, true},
+		{"tag", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindTag, TagID: 1}, false},
+		{"tag without id", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindTag}, true},
+		{"feed", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindFeed, FeedID: 1}, false},
+		{"feed with tag", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindFeed, FeedID: 1, TagID: 1}, true},
+		{"popular", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindPopular}, false},
+		{"popular feed", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindPopular, FeedID: 1}, false},
+		{"popular tag and feed", content.OutputFeed{Token: "t", User: "test", Kind: content.OutputKindPopular, FeedID: 1, TagID: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.output.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("OutputFeed.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputFeed_GenerateToken(t *testing.T) {
+	var o1, o2 content.OutputFeed
+	if err := o1.GenerateToken(); err != nil {
+		t.Fatalf("OutputFeed.GenerateToken() error = %v", err)
+	}
+	if err := o2.GenerateToken(); err != nil {
+		t.Fatalf("OutputFeed.GenerateToken() error = %v", err)
+	}
+
+	if len(o1.Token) != 40 || o1.Token == o2.Token {
+		t.Errorf("OutputFeed.GenerateToken() = %s, %s", o1.Token, o2.Token)
+	}
+}
@@ -0,0 +1,36 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Playback is the position a user has reached in an article's media.
+type Playback struct {
+	ArticleID ArticleID `db:"article_id" json:"articleID"`
+	User      Login     `db:"user_login" json:"-"`
+	// Position is the playback position in seconds.
+	Position  float64   `json:"position"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+func (p Playback) Validate() error {
+	if p.ArticleID == 0 {
+		return NewValidationError(errors.New("Playback has no article id"))
+	}
+
+	if p.User == "" {
+		return NewValidationError(errors.New("Playback has no user"))
+	}
+
+	if p.Position < 0 {
+		return NewValidationError(errors.New("Playback position is negative"))
+	}
+
+	return nil
+}
+
+func (p Playback) String() string {
+	return fmt.Sprintf("%s:%d: %.1fs", p.User, p.ArticleID, p.Position)
+}
@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+// ExtractContent replaces the article descriptions with their stored
+// extracted content, when one exists.
+type ExtractContent struct {
+	repo repo.Extract
+	log  log.Log
+}
+
+func NewExtractContent(repo repo.Extract, l log.Log) ExtractContent {
+	return ExtractContent{repo: repo, log: l}
+}
+
+func (p ExtractContent) ProcessArticles(articles []content.Article) []content.Article {
+	if len(articles) == 0 {
+		return articles
+	}
+
+	p.log.Infof("Replacing descriptions with extracted content of feed '%d'\n", articles[0].FeedID)
+
+	for i := range articles {
+		// Extract contents are processed as id-less articles.
+		if articles[i].ID == 0 {
+			continue
+		}
+
+		extract, err := p.repo.Get(articles[i])
+		if err != nil {
+			if !content.IsNoContent(err) {
+				p.log.Infof("Error getting extract of article %s: %+v\n", articles[i], err)
+			}
+
+			continue
+		}
+
+		if strings.TrimSpace(extract.Content) != "" {
+			articles[i].Description = extract.Content
+		}
+	}
+
+	return articles
+}
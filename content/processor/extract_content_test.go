@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+)
+
+func TestExtractContent_ProcessArticles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repo.NewMockExtract(ctrl)
+
+	articles := []content.Article{
+		{ID: 1, Description: "summary 1"},
+		{ID: 2, Description: "summary 2"},
+		{ID: 3, Description: "summary 3"},
+		{ID: 4, Description: "summary 4"},
+		{Description: "extract"},
+	}
+
+	repo.EXPECT().Get(articles[0]).Return(content.Extract{ArticleID: 1, Content: "<p>full 1</p>"}, nil)
+	repo.EXPECT().Get(articles[1]).Return(content.Extract{}, content.ErrNoContent)
+	repo.EXPECT().Get(articles[2]).Return(content.Extract{}, errors.New("err"))
+	repo.EXPECT().Get(articles[3]).Return(content.Extract{ArticleID: 4, Content: " "}, nil)
+
+	want := []content.Article{
+		{ID: 1, Description: "<p>full 1</p>"},
+		{ID: 2, Description: "summary 2"},
+		{ID: 3, Description: "summary 3"},
+		{ID: 4, Description: "summary 4"},
+		{Description: "extract"},
+	}
+
+	input := append([]content.Article{}, articles...)
+	if got := NewExtractContent(repo, logger).ProcessArticles(input); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractContent.ProcessArticles() = %v, want %v", got, want)
+	}
+}
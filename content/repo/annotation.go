@@ -0,0 +1,15 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Annotation allows fetching and manipulating content.Annotation objects
+type Annotation interface {
+	Get(content.AnnotationID, content.User) (content.Annotation, error)
+	ForUser(content.User, content.AnnotationFilter) ([]content.Annotation, error)
+	ForArticles([]content.ArticleID, content.User) (map[content.ArticleID][]content.Annotation, error)
+	All() ([]content.Annotation, error)
+
+	Create(content.Annotation) (content.Annotation, error)
+	Update(content.Annotation) error
+	Delete(content.Annotation) error
+}
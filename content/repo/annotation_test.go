@@ -0,0 +1,215 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_annotationRepo_Create(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	tests := []struct {
+		name       string
+		annotation content.Annotation
+		wantErr    bool
+	}{
+		{"note", content.Annotation{User: user1, ArticleID: articles[0].ID, Note: "note"}, false},
+		{"highlight", content.Annotation{User: user1, ArticleID: articles[0].ID, Quote: "quote", Start: 2, End: 7, Note: "why"}, false},
+		{"other user", content.Annotation{User: user2, ArticleID: articles[0].ID, Note: "note"}, false},
+		{"unknown article", content.Annotation{User: user1, ArticleID: 1000000, Note: "note"}, true},
+		{"empty", content.Annotation{User: user1, ArticleID: articles[0].ID}, true},
+		{"no user", content.Annotation{ArticleID: articles[0].ID, Note: "note"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.AnnotationRepo()
+			got, err := r.Create(tt.annotation)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("annotationRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.ID == 0 || got.CreatedAt.IsZero() || !got.UpdatedAt.Equal(got.CreatedAt) {
+				t.Errorf("annotationRepo.Create() = %#v", got)
+				return
+			}
+
+			fetched, err := r.Get(got.ID, content.User{Login: tt.annotation.User})
+			if err != nil {
+				t.Errorf("annotationRepo.Create() post fetch error = %v", err)
+				return
+			}
+
+			if fetched.Note != got.Note || fetched.Quote != got.Quote || fetched.Start != got.Start ||
+				fetched.End != got.End || !fetched.CreatedAt.Equal(got.CreatedAt) {
+				t.Errorf("annotationRepo.Create() post fetch = %#v, want %#v", fetched, got)
+			}
+
+			if _, err := r.Get(got.ID, content.User{Login: "user3"}); !content.IsNoContent(err) {
+				t.Errorf("annotationRepo.Create() got annotation of another user, error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_annotationRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.AnnotationRepo()
+	user := content.User{Login: user1}
+
+	annotation, err := r.Create(content.Annotation{User: user1, ArticleID: articles[1].ID, Note: "update"})
+	if err != nil {
+		t.Fatalf("annotationRepo.Create() error = %v", err)
+	}
+
+	updatedAt := annotation.CreatedAt.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		annotation content.Annotation
+		noContent  bool
+		wantErr    bool
+	}{
+		{"note", content.Annotation{ID: annotation.ID, User: user1, ArticleID: articles[1].ID, Note: "updated", UpdatedAt: updatedAt}, false, false},
+		{"highlight", content.Annotation{ID: annotation.ID, User: user1, ArticleID: articles[1].ID, Quote: "quote", Start: 1, End: 6, UpdatedAt: updatedAt}, false, false},
+		{"empty", content.Annotation{ID: annotation.ID, User: user1, ArticleID: articles[1].ID}, false, true},
+		{"other user", content.Annotation{ID: annotation.ID, User: user2, ArticleID: articles[1].ID, Note: "other"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Update(tt.annotation)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("annotationRepo.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.noContent != content.IsNoContent(err) {
+				t.Errorf("annotationRepo.Update() error = %v, noContent %v", err, tt.noContent)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			fetched, err := r.Get(annotation.ID, user)
+			if err != nil {
+				t.Errorf("annotationRepo.Update() post fetch error = %v", err)
+				return
+			}
+
+			if fetched.Note != tt.annotation.Note || fetched.Quote != tt.annotation.Quote ||
+				fetched.Start != tt.annotation.Start || fetched.End != tt.annotation.End ||
+				!fetched.UpdatedAt.Equal(updatedAt) || !fetched.CreatedAt.Equal(annotation.CreatedAt) {
+				t.Errorf("annotationRepo.Update() post fetch = %#v, want %#v", fetched, tt.annotation)
+			}
+		})
+	}
+}
+
+func Test_annotationRepo_ForUser(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.AnnotationRepo()
+	user := content.User{Login: "annotator"}
+	if err := service.UserRepo().Update(user); err != nil {
+		t.Fatalf("userRepo.Update() error = %v", err)
+	}
+	defer service.UserRepo().Delete(user)
+
+	base := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	first, err := r.Create(content.Annotation{User: user.Login, ArticleID: articles[0].ID, Note: "first", CreatedAt: base})
+	if err != nil {
+		t.Fatalf("annotationRepo.Create() error = %v", err)
+	}
+
+	second, err := r.Create(content.Annotation{User: user.Login, ArticleID: articles[len(articles)-1].ID, Quote: "second", CreatedAt: base.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("annotationRepo.Create() error = %v", err)
+	}
+
+	if articles[0].FeedID == articles[len(articles)-1].FeedID {
+		t.Fatalf("expected the first and last articles to be from different feeds")
+	}
+
+	tests := []struct {
+		name   string
+		filter content.AnnotationFilter
+		want   []content.AnnotationID
+	}{
+		{"all", content.AnnotationFilter{}, []content.AnnotationID{first.ID, second.ID}},
+		{"feed", content.AnnotationFilter{FeedIDs: []content.FeedID{articles[0].FeedID}}, []content.AnnotationID{first.ID}},
+		{"after", content.AnnotationFilter{After: base.Add(time.Hour)}, []content.AnnotationID{second.ID}},
+		{"before", content.AnnotationFilter{Before: base.Add(time.Hour)}, []content.AnnotationID{first.ID}},
+		{"empty range", content.AnnotationFilter{After: base.Add(time.Hour), Before: base.Add(2 * time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.ForUser(user, tt.filter)
+			if err != nil {
+				t.Errorf("annotationRepo.ForUser() error = %v", err)
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("annotationRepo.ForUser() = %v, want %v", got, tt.want)
+				return
+			}
+
+			for i := range got {
+				if got[i].ID != tt.want[i] {
+					t.Errorf("annotationRepo.ForUser() = %v, want %v", got, tt.want)
+					return
+				}
+			}
+		})
+	}
+
+	byArticle, err := r.ForArticles([]content.ArticleID{articles[0].ID, articles[1].ID}, user)
+	if err != nil {
+		t.Fatalf("annotationRepo.ForArticles() error = %v", err)
+	}
+
+	if a := byArticle[articles[0].ID]; len(a) != 1 || a[0].ID != first.ID {
+		t.Errorf("annotationRepo.ForArticles() = %v, want %v", a, first)
+	}
+
+	if a := byArticle[articles[1].ID]; len(a) != 0 {
+		t.Errorf("annotationRepo.ForArticles() unannotated article = %v", a)
+	}
+
+	if err := r.Delete(first); err != nil {
+		t.Fatalf("annotationRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(first.ID, user); !content.IsNoContent(err) {
+		t.Errorf("annotationRepo.Delete() annotation still exists, error = %v", err)
+	}
+
+	all, err := r.All()
+	if err != nil {
+		t.Fatalf("annotationRepo.All() error = %v", err)
+	}
+
+	found := false
+	for _, a := range all {
+		if a.ID == first.ID {
+			t.Errorf("annotationRepo.All() deleted annotation %s still listed", a)
+		}
+		found = found || a.ID == second.ID
+	}
+
+	if !found {
+		t.Errorf("annotationRepo.All() missing annotation %s", second)
+	}
+}
@@ -1,6 +1,10 @@
 package repo
 
-import "github.com/urandom/readeef/content"
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
 
 // Article allows fetching and manipulating content.Article objects
 type Article interface {
@@ -13,6 +17,19 @@ type Article interface {
 
 	Read(bool, content.User, ...content.QueryOpt) error
 	Favor(bool, content.User, ...content.QueryOpt) error
+	Queue(bool, content.User, ...content.QueryOpt) error
+	ReorderQueue(content.User, []content.ArticleID) error
+
+	Publish(bool, content.User, []content.ArticleID) error
+	PublishedIDs(content.User) ([]content.ArticleID, error)
+
+	Hide(content.User, []content.ArticleID) error
+
+	RecentlyReadIDs(content.User, time.Time) ([]content.ArticleID, error)
 
 	RemoveStaleUnreadRecords() error
+	RemoveStaleStateChanges() error
+
+	Expired(content.Feed, content.Retention) ([]content.Article, error)
+	Delete([]content.Article) error
 }
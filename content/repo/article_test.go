@@ -1,6 +1,7 @@
 package repo_test
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/parser"
 )
 
 var (
@@ -485,3 +487,427 @@ func Test_articleRepo_Favor(t *testing.T) {
 		})
 	}
 }
+
+func Test_articleRepo_ModifiedSince(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	all, err := r.ForUser(user, content.UnreadOnly, content.Sorting(content.SortByID, content.AscendingOrder))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+	if len(all) < 2 {
+		t.Fatalf("articleRepo.ForUser() want at least 2 unread articles, got %d", len(all))
+	}
+
+	if err := r.Read(true, user, content.IDs([]content.ArticleID{all[1].ID})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+	defer r.Read(false, user, content.IDs([]content.ArticleID{all[1].ID}))
+
+	since := time.Now()
+	ids := []content.ArticleID{all[0].ID, all[1].ID}
+
+	// The second article is already read, and its state doesn't change.
+	if err := r.Read(true, user, content.IDs(ids)); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+	defer r.Read(false, user, content.IDs(ids[:1]))
+
+	modified, err := r.ForUser(user, content.ModifiedSince(since))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(modified) != 1 || modified[0].ID != all[0].ID {
+		t.Fatalf("articleRepo.ForUser() modified = %v, want %v", modified, all[:1])
+	}
+
+	if !modified[0].StateChanged.Valid || modified[0].StateChanged.Time.Before(since.Add(-time.Second)) {
+		t.Errorf("articleRepo.ForUser() state changed = %v, want after %v", modified[0].StateChanged, since)
+	}
+
+	// The initial state of new articles is not a change made by the user.
+	since = time.Now()
+	if err := r.Read(false, user, content.IDs(ids[:1]), content.InitialState); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	if modified, err = r.ForUser(user, content.ModifiedSince(since)); err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(modified) != 0 {
+		t.Errorf("articleRepo.ForUser() modified = %v, want none", modified)
+	}
+
+	if err := r.RemoveStaleStateChanges(); err != nil {
+		t.Errorf("articleRepo.RemoveStaleStateChanges() error = %v", err)
+	}
+}
+
+func Test_articleRepo_Queue(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+	queueOrder := content.Sorting(content.SortByQueue, content.AscendingOrder)
+
+	all, err := r.ForUser(user, content.Sorting(content.SortByID, content.AscendingOrder))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(all) < 3 {
+		t.Fatalf("articleRepo.ForUser() expected at least 3 articles, got %d", len(all))
+	}
+
+	a, b, c := all[0], all[1], all[2]
+
+	queue := func(want ...content.Article) {
+		t.Helper()
+
+		articles, err := r.ForUser(user, content.QueuedOnly, queueOrder)
+		if err != nil {
+			t.Fatalf("articleRepo.ForUser() error = %v", err)
+		}
+
+		ids, err := r.IDs(user, content.QueuedOnly, queueOrder)
+		if err != nil {
+			t.Fatalf("articleRepo.IDs() error = %v", err)
+		}
+
+		count, err := r.Count(user, content.QueuedOnly)
+		if err != nil {
+			t.Fatalf("articleRepo.Count() error = %v", err)
+		}
+
+		if len(articles) != len(want) || len(ids) != len(want) || count != int64(len(want)) {
+			t.Fatalf("articleRepo queue = %v, ids %v, count %d, want %v", articles, ids, count, want)
+		}
+
+		for i := range want {
+			if articles[i].ID != want[i].ID || !articles[i].Queued || ids[i] != want[i].ID {
+				t.Fatalf("articleRepo queue = %v, ids %v, want %v", articles, ids, want)
+			}
+		}
+	}
+
+	if err := r.Queue(true, content.User{}); err == nil {
+		t.Errorf("articleRepo.Queue() expected an invalid user error")
+	}
+
+	if err := r.Queue(true, user, content.IDs([]content.ArticleID{c.ID})); err != nil {
+		t.Fatalf("articleRepo.Queue() error = %v", err)
+	}
+
+	// New entries go to the end of the queue, and queued ones stay put.
+	if err := r.Queue(true, user, content.IDs([]content.ArticleID{a.ID, b.ID, c.ID})); err != nil {
+		t.Fatalf("articleRepo.Queue() error = %v", err)
+	}
+	queue(c, a, b)
+
+	if err := r.ReorderQueue(user, []content.ArticleID{b.ID, 1000000}); err != nil {
+		t.Fatalf("articleRepo.ReorderQueue() error = %v", err)
+	}
+	queue(b, c, a)
+
+	// Read articles leave the queue.
+	if err := r.Read(true, user, content.IDs([]content.ArticleID{c.ID})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+	queue(b, a)
+
+	if !c.Read {
+		if err := r.Read(false, user, content.IDs([]content.ArticleID{c.ID})); err != nil {
+			t.Fatalf("articleRepo.Read() error = %v", err)
+		}
+	}
+
+	if err := r.Queue(false, user, content.QueuedOnly); err != nil {
+		t.Fatalf("articleRepo.Queue() error = %v", err)
+	}
+	queue()
+}
+
+func Test_articleRepo_ExpiredDelete(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	feed := content.Feed{Link: "http://sugr.org/retention/articles"}
+	feed.Refresh(parser.Feed{Title: "retention", Articles: []parser.Article{
+		{Title: "Retention 1", Link: "http://sugr.org/retention/a/1", Date: time.Now()},
+		{Title: "Retention 2", Link: "http://sugr.org/retention/a/2", Date: time.Now().Add(-1 * time.Hour)},
+		{Title: "Retention 3", Link: "http://sugr.org/retention/a/3", Date: time.Now().Add(-2 * time.Hour)},
+		{Title: "Retention 4", Link: "http://sugr.org/retention/a/4", Date: time.Now().Add(-30 * time.Hour)},
+		{Title: "Retention 5", Link: "http://sugr.org/retention/a/5", Date: time.Now().Add(-50 * time.Hour)},
+	}})
+	createFeed(&feed, user)
+	defer service.FeedRepo().Delete(feed)
+
+	all, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	byTitle := map[string]content.Article{}
+	for _, a := range all {
+		byTitle[a.Title] = a
+	}
+
+	// Favored articles are never expired.
+	if err := r.Favor(true, user, content.IDs([]content.ArticleID{byTitle["Retention 4"].ID})); err != nil {
+		t.Fatalf("articleRepo.Favor() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		retention content.Retention
+		want      []string
+	}{
+		{"empty", content.Retention{}, nil},
+		{"max articles", content.Retention{MaxArticles: 2}, []string{"Retention 5", "Retention 3"}},
+		{"max age", content.Retention{MaxAge: 24 * time.Hour}, []string{"Retention 5"}},
+		{"both", content.Retention{MaxArticles: 4, MaxAge: 40 * time.Hour}, []string{"Retention 5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Expired(feed, tt.retention)
+			if err != nil {
+				t.Fatalf("articleRepo.Expired() error = %v", err)
+			}
+
+			var titles []string
+			for _, a := range got {
+				titles = append(titles, a.Title)
+			}
+
+			if !reflect.DeepEqual(titles, tt.want) {
+				t.Errorf("articleRepo.Expired() = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+
+	expired, err := r.Expired(feed, content.Retention{MaxArticles: 2})
+	if err != nil {
+		t.Fatalf("articleRepo.Expired() error = %v", err)
+	}
+
+	if err := r.Delete(expired); err != nil {
+		t.Fatalf("articleRepo.Delete() error = %v", err)
+	}
+
+	remaining, err := r.All(content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	if len(remaining) != 3 {
+		t.Errorf("articleRepo.Delete() remaining = %d, want 3", len(remaining))
+	}
+
+	for _, a := range remaining {
+		if a.Title == "Retention 3" || a.Title == "Retention 5" {
+			t.Errorf("articleRepo.Delete() article %s not deleted", a.Title)
+		}
+	}
+
+	// The purged articles are still in the feed, and are not created again.
+	created, err := service.FeedRepo().Update(&feed)
+	if err != nil {
+		t.Fatalf("feedRepo.Update() error = %v", err)
+	}
+
+	if len(created) != 0 {
+		t.Errorf("feedRepo.Update() created purged articles %v", created)
+	}
+
+	if remaining, err = r.All(content.FeedIDs([]content.FeedID{feed.ID})); err != nil {
+		t.Fatalf("articleRepo.All() error = %v", err)
+	}
+
+	if len(remaining) != 3 {
+		t.Errorf("feedRepo.Update() remaining = %d, want 3", len(remaining))
+	}
+}
+
+func Test_articleRepo_Publish(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+	ids := []content.ArticleID{articles[0].ID, articles[2].ID}
+
+	if err := r.Publish(true, content.User{}, ids); err == nil {
+		t.Errorf("articleRepo.Publish() expected error for an invalid user")
+	}
+
+	if err := r.Publish(true, user, ids); err != nil {
+		t.Fatalf("articleRepo.Publish() error = %v", err)
+	}
+
+	// Publishing twice keeps the article published.
+	if err := r.Publish(true, user, ids[:1]); err != nil {
+		t.Fatalf("articleRepo.Publish() repeated error = %v", err)
+	}
+
+	got, err := r.PublishedIDs(user)
+	if err != nil {
+		t.Fatalf("articleRepo.PublishedIDs() error = %v", err)
+	}
+
+	if len(got) != len(ids) {
+		t.Errorf("articleRepo.PublishedIDs() = %v, want %v", got, ids)
+	}
+
+	if got, err := r.PublishedIDs(content.User{Login: user2}); err != nil || len(got) != 0 {
+		t.Errorf("articleRepo.PublishedIDs() other user = %v, error = %v", got, err)
+	}
+
+	if err := r.Publish(false, user, ids); err != nil {
+		t.Fatalf("articleRepo.Publish() error = %v", err)
+	}
+
+	if got, err := r.PublishedIDs(user); err != nil || len(got) != 0 {
+		t.Errorf("articleRepo.PublishedIDs() after unpublishing = %v, error = %v", got, err)
+	}
+}
+
+func Test_articleRepo_Hide(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	feed := content.Feed{Link: "http://sugr.org/hidden/articles"}
+	feed.Refresh(parser.Feed{Title: "hidden", Articles: []parser.Article{
+		{Title: "Hidden 1", Link: "http://sugr.org/hidden/a/1", Date: time.Now()},
+		{Title: "Hidden 2", Link: "http://sugr.org/hidden/a/2", Date: time.Now()},
+	}})
+	createFeed(&feed, user)
+	defer service.FeedRepo().Delete(feed)
+
+	all, err := r.ForUser(user, content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("articleRepo.ForUser() = %v, want 2 articles", all)
+	}
+
+	if err := r.Read(false, user, content.FeedIDs([]content.FeedID{feed.ID})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	if err := r.Hide(content.User{}, []content.ArticleID{all[0].ID}); err == nil {
+		t.Errorf("articleRepo.Hide() expected error for an invalid user")
+	}
+
+	if err := r.Hide(user, []content.ArticleID{all[0].ID}); err != nil {
+		t.Fatalf("articleRepo.Hide() error = %v", err)
+	}
+
+	// Hiding twice keeps the article hidden.
+	if err := r.Hide(user, []content.ArticleID{all[0].ID}); err != nil {
+		t.Fatalf("articleRepo.Hide() repeated error = %v", err)
+	}
+
+	got, err := r.ForUser(user, content.FeedIDs([]content.FeedID{feed.ID}))
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].ID != all[1].ID {
+		t.Errorf("articleRepo.ForUser() after hiding = %v, want %v", got, all[1:])
+	}
+
+	if count, err := r.Count(user, content.FeedIDs([]content.FeedID{feed.ID}), content.UnreadOnly); err != nil || count != 1 {
+		t.Errorf("articleRepo.Count() unread after hiding = %d, error = %v", count, err)
+	}
+
+	// Hidden articles are not marked unread again.
+	if err := r.Read(false, user, content.FeedIDs([]content.FeedID{feed.ID})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	if ids, err := r.IDs(user, content.FeedIDs([]content.FeedID{feed.ID}), content.UnreadOnly); err != nil || len(ids) != 1 {
+		t.Errorf("articleRepo.IDs() unread after hiding = %v, error = %v", ids, err)
+	}
+}
+
+func Test_articleRepo_RecentlyReadIDs(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.ArticleRepo()
+	user := content.User{Login: user1}
+	ids := content.IDs([]content.ArticleID{articles[3].ID, articles[4].ID})
+	since := time.Now().Add(-time.Hour)
+
+	contains := func(got []content.ArticleID, id content.ArticleID) bool {
+		for _, g := range got {
+			if g == id {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if err := r.Read(false, user, ids); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	if err := r.Read(true, user, ids); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	got, err := r.RecentlyReadIDs(user, since)
+	if err != nil {
+		t.Fatalf("articleRepo.RecentlyReadIDs() error = %v", err)
+	}
+
+	if !contains(got, articles[3].ID) || !contains(got, articles[4].ID) {
+		t.Errorf("articleRepo.RecentlyReadIDs() = %v, missing read articles", got)
+	}
+
+	// Reading already read articles doesn't fail.
+	if err := r.Read(true, user, ids); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	if got, err := r.RecentlyReadIDs(content.User{Login: user2}, since); err != nil || contains(got, articles[3].ID) {
+		t.Errorf("articleRepo.RecentlyReadIDs() other user = %v, error = %v", got, err)
+	}
+
+	if got, err := r.RecentlyReadIDs(user, time.Now().Add(time.Hour)); err != nil || len(got) != 0 {
+		t.Errorf("articleRepo.RecentlyReadIDs() future = %v, error = %v", got, err)
+	}
+
+	if err := r.Read(false, user, content.IDs([]content.ArticleID{articles[3].ID})); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+
+	got, err = r.RecentlyReadIDs(user, since)
+	if err != nil {
+		t.Fatalf("articleRepo.RecentlyReadIDs() error = %v", err)
+	}
+
+	if contains(got, articles[3].ID) || !contains(got, articles[4].ID) {
+		t.Errorf("articleRepo.RecentlyReadIDs() = %v after marking as unread", got)
+	}
+
+	if err := r.Read(false, user, ids); err != nil {
+		t.Fatalf("articleRepo.Read() error = %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package eventable
+
+import (
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+const (
+	AnnotationChangeEvent = "annotation-change"
+)
+
+type AnnotationData struct {
+	User       content.Login      `json:"user"`
+	Annotation content.Annotation `json:"annotation"`
+	Deleted    bool               `json:"deleted"`
+}
+
+func (e AnnotationData) UserLogin() content.Login {
+	return e.User
+}
+
+type annotationRepo struct {
+	repo.Annotation
+	eventBus bus
+	log      log.Log
+}
+
+func (r annotationRepo) Create(annotation content.Annotation) (content.Annotation, error) {
+	annotation, err := r.Annotation.Create(annotation)
+
+	if err == nil {
+		r.dispatch(annotation, false)
+	}
+
+	return annotation, err
+}
+
+func (r annotationRepo) Update(annotation content.Annotation) error {
+	err := r.Annotation.Update(annotation)
+
+	if err == nil {
+		r.dispatch(annotation, false)
+	}
+
+	return err
+}
+
+func (r annotationRepo) Delete(annotation content.Annotation) error {
+	err := r.Annotation.Delete(annotation)
+
+	if err == nil {
+		r.dispatch(annotation, true)
+	}
+
+	return err
+}
+
+func (r annotationRepo) dispatch(annotation content.Annotation, deleted bool) {
+	r.log.Debugf("Dispatching annotation change event")
+
+	r.eventBus.Dispatch(
+		AnnotationChangeEvent,
+		AnnotationData{annotation.User, annotation, deleted},
+	)
+
+	r.log.Debugf("Dispatch of annotation change event end")
+}
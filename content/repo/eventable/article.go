@@ -8,6 +8,7 @@ import (
 
 const (
 	ArticleStateEvent = "article-state-change"
+	ArticleQueueEvent = "article-queue-change"
 
 	read  = "read"
 	favor = "favor"
@@ -24,6 +25,38 @@ func (e ArticleStateData) UserLogin() content.Login {
 	return e.User
 }
 
+// ArticleQueueData describes a change of the user's read-later queue.
+// Order holds the moved articles when the queue is reordered.
+type ArticleQueueData struct {
+	User    content.Login          `json:"user"`
+	Queued  bool                   `json:"queued"`
+	Options map[string]interface{} `json:"options,omitempty"`
+	Order   []content.ArticleID    `json:"order,omitempty"`
+}
+
+func (e ArticleQueueData) UserLogin() content.Login {
+	return e.User
+}
+
+// ReadStateEvent returns the state event of articles, whose read state was
+// changed through the non-eventable repo.
+func ReadStateEvent(user content.User, state bool, opts ...content.QueryOpt) Event {
+	return articleStateEvent(user, read, state, opts)
+}
+
+// FavorStateEvent returns the state event of articles, whose favorite state
+// was changed through the non-eventable repo.
+func FavorStateEvent(user content.User, state bool, opts ...content.QueryOpt) Event {
+	return articleStateEvent(user, favor, state, opts)
+}
+
+func articleStateEvent(user content.User, name string, state bool, opts []content.QueryOpt) Event {
+	o := content.QueryOptions{}
+	o.Apply(opts)
+
+	return Event{ArticleStateEvent, ArticleStateData{user.Login, name, state, convertOptions(o)}}
+}
+
 type articleRepo struct {
 	repo.Article
 	eventBus bus
@@ -31,6 +64,16 @@ type articleRepo struct {
 }
 
 func (r articleRepo) Read(state bool, user content.User, opts ...content.QueryOpt) error {
+	// Read articles leave the queue, so the queued ones among them are
+	// reported as dequeued.
+	var dequeued []content.ArticleID
+	if state {
+		var err error
+		if dequeued, err = r.Article.IDs(user, append(opts, content.QueuedOnly)...); err != nil {
+			r.log.Printf("Error getting queued article ids: %+v", err)
+		}
+	}
+
 	err := r.Article.Read(state, user, opts...)
 
 	if err == nil {
@@ -44,6 +87,13 @@ func (r articleRepo) Read(state bool, user content.User, opts ...content.QueryOp
 			ArticleStateData{user.Login, read, state, convertOptions(o)},
 		)
 
+		if len(dequeued) > 0 {
+			r.eventBus.Dispatch(
+				ArticleQueueEvent,
+				ArticleQueueData{User: user.Login, Queued: false, Options: convertOptions(content.QueryOptions{IDs: dequeued})},
+			)
+		}
+
 		r.log.Debugf("Dispatch of article read state event end")
 	}
 
@@ -70,6 +120,43 @@ func (r articleRepo) Favor(state bool, user content.User, opts ...content.QueryO
 	return err
 }
 
+func (r articleRepo) Queue(state bool, user content.User, opts ...content.QueryOpt) error {
+	err := r.Article.Queue(state, user, opts...)
+
+	if err == nil {
+		r.log.Debugf("Dispatching article queue event")
+
+		o := content.QueryOptions{}
+		o.Apply(opts)
+
+		r.eventBus.Dispatch(
+			ArticleQueueEvent,
+			ArticleQueueData{User: user.Login, Queued: state, Options: convertOptions(o)},
+		)
+
+		r.log.Debugf("Dispatch of article queue event end")
+	}
+
+	return err
+}
+
+func (r articleRepo) ReorderQueue(user content.User, ids []content.ArticleID) error {
+	err := r.Article.ReorderQueue(user, ids)
+
+	if err == nil {
+		r.log.Debugf("Dispatching article queue reorder event")
+
+		r.eventBus.Dispatch(
+			ArticleQueueEvent,
+			ArticleQueueData{User: user.Login, Queued: true, Order: ids},
+		)
+
+		r.log.Debugf("Dispatch of article queue reorder event end")
+	}
+
+	return err
+}
+
 func convertOptions(o content.QueryOptions) map[string]interface{} {
 	data := map[string]interface{}{}
 
@@ -85,6 +172,10 @@ func convertOptions(o content.QueryOptions) map[string]interface{} {
 		data["favoriteOnly"] = true
 	}
 
+	if o.QueuedOnly {
+		data["queuedOnly"] = true
+	}
+
 	if o.UntaggedOnly {
 		data["untaggedOnly"] = true
 	}
@@ -7,12 +7,17 @@ import (
 	"github.com/urandom/readeef/log"
 )
 
+// Events holds the names of all the events that are dispatched by the
+// service.
+var Events = []string{FeedUpdateEvent, FeedDeleteEvent, FeedSetTagsEvent, ArticleStateEvent, ArticleQueueEvent, AnnotationChangeEvent}
+
 type Service struct {
 	repo.Service
 	eventBus bus
 
-	article articleRepo
-	feed    feedRepo
+	article    articleRepo
+	feed       feedRepo
+	annotation annotationRepo
 }
 
 func NewService(ctx context.Context, s repo.Service, log log.Log) Service {
@@ -22,6 +27,7 @@ func NewService(ctx context.Context, s repo.Service, log log.Log) Service {
 		s, bus,
 		articleRepo{s.ArticleRepo(), bus, log},
 		feedRepo{s.FeedRepo(), bus, log},
+		annotationRepo{s.AnnotationRepo(), bus, log},
 	}
 }
 
@@ -29,6 +35,13 @@ func (s Service) Listener() Stream {
 	return s.eventBus.Listener()
 }
 
+// Dispatch sends the event to all listeners. It is meant for changes made
+// through the non-eventable repos, and should not be called from a
+// listener, since the bus may wait for that listener to drain its events.
+func (s Service) Dispatch(event Event) {
+	s.eventBus.Dispatch(event.Name, event.Data)
+}
+
 func (s Service) ArticleRepo() repo.Article {
 	return s.article
 }
@@ -36,3 +49,7 @@ func (s Service) ArticleRepo() repo.Article {
 func (s Service) FeedRepo() repo.Feed {
 	return s.feed
 }
+
+func (s Service) AnnotationRepo() repo.Annotation {
+	return s.annotation
+}
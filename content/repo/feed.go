@@ -15,6 +15,7 @@ type Feed interface {
 	Unsubscribed() ([]content.Feed, error)
 
 	Update(*content.Feed) ([]content.Article, error)
+	UpdateSchedule(content.Feed) error
 	Delete(content.Feed) error
 
 	Users(content.Feed) ([]content.User, error)
@@ -22,4 +23,13 @@ type Feed interface {
 	DetachFrom(content.Feed, content.User) error
 
 	SetUserTags(content.Feed, content.User, []*content.Tag) error
+
+	SetExtract(content.Feed, bool) error
+	SetUserExtract(content.Feed, content.User, bool) error
+
+	SetRetention(content.Feed, content.Retention) error
+	SetUserRetention(content.Feed, content.User, content.Retention) error
+
+	SetCredentials(content.Feed) error
+	SetScraper(content.Feed) error
 }
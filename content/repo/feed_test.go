@@ -238,10 +238,14 @@ func Test_feedRepo_UpdateDelete(t *testing.T) {
 	}{
 		{"test1", content.Feed{Title: "title 3", Link: "http://sugr.org/3"}, parser.Feed{Title: "title 3"}, []content.Article{}, false},
 		{"test2", content.Feed{Title: "title 4", Link: "http://sugr.org/4"}, parser.Feed{Title: "title 4", Articles: []parser.Article{
-			{Title: "Article 100", Link: "http://sugr.org/3/article/100"},
+			{Title: "Article 100", Link: "http://sugr.org/3/article/100", Author: "John Doe", Categories: []string{"tech"}, Enclosures: []parser.Enclosure{
+				{URL: "http://sugr.org/3/article/100.mp3", Type: "audio/mpeg", Length: 100, Duration: time.Minute},
+			}, Thumbnail: "http://sugr.org/3/article/100.jpg", Duration: time.Minute, Episode: 3, Explicit: true},
 			{Title: "Article 200", Link: "http://sugr.org/3/article/200"},
 		}}, []content.Article{
-			{Title: "Article 100", Link: "http://sugr.org/3/article/100"},
+			{Title: "Article 100", Link: "http://sugr.org/3/article/100", Author: "John Doe", Categories: content.Categories{"tech"}, Enclosures: content.Enclosures{
+				{URL: "http://sugr.org/3/article/100.mp3", Type: "audio/mpeg", Length: 100, Duration: 60},
+			}, Media: &content.Media{Image: "http://sugr.org/3/article/100.jpg", Duration: 60, Episode: 3, Explicit: true}},
 			{Title: "Article 200", Link: "http://sugr.org/3/article/200"},
 		}, false},
 	}
@@ -284,6 +288,20 @@ func Test_feedRepo_UpdateDelete(t *testing.T) {
 					t.Errorf("feedRepo.Update() = %v, want %v", got[i], tt.want[i])
 					return
 				}
+
+				stored, err := service.ArticleRepo().All(content.IDs([]content.ArticleID{got[i].ID}))
+				if err != nil || len(stored) != 1 {
+					t.Errorf("articleRepo.All() = %v, error %v", stored, err)
+					return
+				}
+
+				if stored[0].Author != tt.want[i].Author ||
+					!reflect.DeepEqual(stored[0].Categories, tt.want[i].Categories) ||
+					!reflect.DeepEqual(stored[0].Enclosures, tt.want[i].Enclosures) ||
+					!reflect.DeepEqual(stored[0].Media, tt.want[i].Media) {
+					t.Errorf("feedRepo.Update() stored = %#v, want %#v", stored[0], tt.want[i])
+					return
+				}
 			}
 
 			if err := r.Delete(tt.feed); err != nil {
@@ -509,3 +527,221 @@ func setupFeed() {
 		}
 	})
 }
+
+func Test_feedRepo_SetExtract(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedRepo()
+	u1 := content.User{Login: user1}
+	u2 := content.User{Login: user2}
+
+	feed := content.Feed{Link: "http://sugr.org/extract"}
+	createFeed(&feed, u1, u2)
+	defer r.Delete(feed)
+
+	tests := []struct {
+		name     string
+		feed     bool
+		user     content.User
+		value    bool
+		wantFeed bool
+		wantU1   bool
+		wantU2   bool
+		wantAny  bool
+	}{
+		{"user 1 enable", false, u1, true, false, true, false, true},
+		{"user 2 enable", false, u2, true, false, true, true, true},
+		{"user 1 disable", false, u1, false, false, false, true, true},
+		{"user 2 disable", false, u2, false, false, false, false, false},
+		{"feed enable", true, content.User{}, true, true, false, false, false},
+		{"feed disable", true, content.User{}, false, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.feed {
+				err = r.SetExtract(feed, tt.value)
+			} else {
+				err = r.SetUserExtract(feed, tt.user, tt.value)
+			}
+			if err != nil {
+				t.Errorf("feedRepo.SetExtract() error = %v", err)
+				return
+			}
+
+			for user, want := range map[content.Login]bool{user1: tt.wantU1, user2: tt.wantU2} {
+				got, err := r.Get(feed.ID, content.User{Login: user})
+				if err != nil {
+					t.Errorf("feedRepo.Get() error = %v", err)
+					return
+				}
+
+				if got.ExtractContent != tt.wantFeed || got.UserExtractContent != want {
+					t.Errorf("feedRepo.Get() for %s = %v/%v, want %v/%v", user, got.ExtractContent, got.UserExtractContent, tt.wantFeed, want)
+				}
+			}
+
+			got, err := r.FindByLink(feed.Link)
+			if err != nil {
+				t.Errorf("feedRepo.FindByLink() error = %v", err)
+				return
+			}
+
+			if got.ExtractContent != tt.wantFeed || got.UserExtractContent != tt.wantAny {
+				t.Errorf("feedRepo.FindByLink() = %v/%v, want %v/%v", got.ExtractContent, got.UserExtractContent, tt.wantFeed, tt.wantAny)
+			}
+		})
+	}
+
+	if err := r.SetUserExtract(feed1, u2, true); err == nil {
+		t.Errorf("feedRepo.SetUserExtract() expected error for an unsubscribed user")
+	}
+}
+
+func Test_feedRepo_SetRetention(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedRepo()
+	u1 := content.User{Login: user1}
+	u2 := content.User{Login: user2}
+
+	feed := content.Feed{Link: "http://sugr.org/retention"}
+	createFeed(&feed, u1, u2)
+	defer r.Delete(feed)
+
+	feedRetention := content.Retention{MaxArticles: 100}
+	userRetention := content.Retention{MaxAge: 48 * time.Hour}
+
+	tests := []struct {
+		name      string
+		feed      bool
+		user      content.User
+		retention content.Retention
+		wantFeed  content.Retention
+		wantU1    content.Retention
+		wantU2    content.Retention
+		wantErr   bool
+	}{
+		{"invalid", true, content.User{}, content.Retention{MaxArticles: -1}, content.Retention{}, content.Retention{}, content.Retention{}, true},
+		{"feed set", true, content.User{}, feedRetention, feedRetention, content.Retention{}, content.Retention{}, false},
+		{"user 1 set", false, u1, userRetention, feedRetention, userRetention, content.Retention{}, false},
+		{"user 1 clear", false, u1, content.Retention{}, feedRetention, content.Retention{}, content.Retention{}, false},
+		{"feed clear", true, content.User{}, content.Retention{}, content.Retention{}, content.Retention{}, content.Retention{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.feed {
+				err = r.SetRetention(feed, tt.retention)
+			} else {
+				err = r.SetUserRetention(feed, tt.user, tt.retention)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("feedRepo.SetRetention() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			for user, want := range map[content.Login]content.Retention{user1: tt.wantU1, user2: tt.wantU2} {
+				got, err := r.Get(feed.ID, content.User{Login: user})
+				if err != nil {
+					t.Errorf("feedRepo.Get() error = %v", err)
+					return
+				}
+
+				if got.Retention != tt.wantFeed || got.UserRetention != want {
+					t.Errorf("feedRepo.Get() for %s = %v/%v, want %v/%v", user, got.Retention, got.UserRetention, tt.wantFeed, want)
+				}
+			}
+
+			got, err := r.FindByLink(feed.Link)
+			if err != nil {
+				t.Errorf("feedRepo.FindByLink() error = %v", err)
+				return
+			}
+
+			if got.Retention != tt.wantFeed {
+				t.Errorf("feedRepo.FindByLink() = %v, want %v", got.Retention, tt.wantFeed)
+			}
+		})
+	}
+
+	if err := r.SetUserRetention(feed1, u2, userRetention); err == nil {
+		t.Errorf("feedRepo.SetUserRetention() expected error for an unsubscribed user")
+	}
+}
+
+func Test_feedRepo_SetCredentials(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedRepo()
+	u1 := content.User{Login: user1}
+
+	feed := content.Feed{Link: "http://sugr.org/credentials", Credentials: "initial"}
+	createFeed(&feed, u1)
+	defer r.Delete(feed)
+
+	got, err := r.FindByLink(feed.Link)
+	if err != nil {
+		t.Fatalf("feedRepo.FindByLink() error = %v", err)
+	}
+
+	if got.Credentials != "initial" {
+		t.Errorf("feedRepo.Update() credentials = %q, want %q", got.Credentials, "initial")
+	}
+
+	for _, value := range []string{"changed", ""} {
+		feed.Credentials = value
+		if err := r.SetCredentials(feed); err != nil {
+			t.Fatalf("feedRepo.SetCredentials() error = %v", err)
+		}
+
+		// A regular update keeps the stored credentials.
+		got.Credentials = "stale"
+		if _, err := r.Update(&got); err != nil {
+			t.Fatalf("feedRepo.Update() error = %v", err)
+		}
+
+		got, err = r.Get(feed.ID, u1)
+		if err != nil {
+			t.Fatalf("feedRepo.Get() error = %v", err)
+		}
+
+		if got.Credentials != value {
+			t.Errorf("feedRepo.Get() credentials = %q, want %q", got.Credentials, value)
+		}
+	}
+}
+
+func Test_feedRepo_SetScraper(t *testing.T) {
+	skipTest(t)
+	setupFeed()
+
+	r := service.FeedRepo()
+	u1 := content.User{Login: user1}
+
+	scraper := content.Scraper{Item: "div.post", Title: "h2"}
+	feed := content.Feed{Link: "http://sugr.org/scraper", Scraper: &scraper}
+	createFeed(&feed, u1)
+	defer r.Delete(feed)
+
+	for _, want := range []*content.Scraper{&scraper, {Item: "li", Title: "a", Date: "time"}, nil} {
+		if want != &scraper {
+			feed.Scraper = want
+			if err := r.SetScraper(feed); err != nil {
+				t.Fatalf("feedRepo.SetScraper() error = %v", err)
+			}
+		}
+
+		got, err := r.Get(feed.ID, u1)
+		if err != nil {
+			t.Fatalf("feedRepo.Get() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got.Scraper, want) {
+			t.Errorf("feedRepo.Get() scraper = %v, want %v", got.Scraper, want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Label allows fetching and manipulating content.Label objects
+type Label interface {
+	Get(content.LabelID, content.User) (content.Label, error)
+	ForUser(content.User) ([]content.Label, error)
+	ForArticles([]content.ArticleID, content.User) (map[content.ArticleID][]content.Label, error)
+
+	ArticleIDs(content.Label) ([]content.ArticleID, error)
+	FeedIDs(content.Label) ([]content.FeedID, error)
+
+	Create(content.Label) (content.Label, error)
+	Update(content.Label) error
+	Delete(content.Label) error
+
+	Assign(content.Label, []content.ArticleID) error
+	Unassign(content.Label, []content.ArticleID) error
+}
@@ -0,0 +1,315 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_labelRepo_Create(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	tests := []struct {
+		name    string
+		label   content.Label
+		wantErr bool
+	}{
+		{"valid", content.Label{User: user1, Caption: "create 1", FgColor: "#000", BgColor: "#fff"}, false},
+		{"other user", content.Label{User: user2, Caption: "create 1"}, false},
+		{"duplicate caption", content.Label{User: user1, Caption: "create 1"}, true},
+		{"no caption", content.Label{User: user1}, true},
+		{"no user", content.Label{Caption: "create 2"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.LabelRepo()
+			got, err := r.Create(tt.label)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("labelRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.ID == 0 {
+				t.Errorf("labelRepo.Create() label has no id")
+				return
+			}
+
+			fetched, err := r.Get(got.ID, content.User{Login: tt.label.User})
+			if err != nil {
+				t.Errorf("labelRepo.Create() post fetch error = %v", err)
+				return
+			}
+
+			if fetched != got {
+				t.Errorf("labelRepo.Create() post fetch = %v, want %v", fetched, got)
+			}
+
+			if _, err := r.Get(got.ID, content.User{Login: "user3"}); !content.IsNoContent(err) {
+				t.Errorf("labelRepo.Create() got label of another user, error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_labelRepo_Assign(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.LabelRepo()
+	user := content.User{Login: user1}
+
+	label, err := r.Create(content.Label{User: user1, Caption: "assign"})
+	if err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	ids := []content.ArticleID{articles[0].ID, articles[1].ID, articles[3].ID}
+	if err := r.Assign(label, ids); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	// Assigning an already labeled article is not an error.
+	if err := r.Assign(label, ids[:1]); err != nil {
+		t.Fatalf("labelRepo.Assign() repeated error = %v", err)
+	}
+
+	got, err := r.ArticleIDs(label)
+	if err != nil {
+		t.Fatalf("labelRepo.ArticleIDs() error = %v", err)
+	}
+
+	if len(got) != len(ids) {
+		t.Errorf("labelRepo.ArticleIDs() = %v, want %v", got, ids)
+	}
+
+	feedIDs, err := r.FeedIDs(label)
+	if err != nil {
+		t.Fatalf("labelRepo.FeedIDs() error = %v", err)
+	}
+
+	if len(feedIDs) != 1 || feedIDs[0] != feed1.ID {
+		t.Errorf("labelRepo.FeedIDs() = %v, want %v", feedIDs, []content.FeedID{feed1.ID})
+	}
+
+	byArticle, err := r.ForArticles([]content.ArticleID{articles[0].ID, articles[2].ID}, user)
+	if err != nil {
+		t.Fatalf("labelRepo.ForArticles() error = %v", err)
+	}
+
+	if l := byArticle[articles[0].ID]; len(l) != 1 || l[0] != label {
+		t.Errorf("labelRepo.ForArticles() = %v, want %v", l, label)
+	}
+
+	if l := byArticle[articles[2].ID]; len(l) != 0 {
+		t.Errorf("labelRepo.ForArticles() unlabeled article = %v", l)
+	}
+
+	if err := r.Unassign(label, ids[1:]); err != nil {
+		t.Fatalf("labelRepo.Unassign() error = %v", err)
+	}
+
+	got, err = r.ArticleIDs(label)
+	if err != nil {
+		t.Fatalf("labelRepo.ArticleIDs() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0] != ids[0] {
+		t.Errorf("labelRepo.ArticleIDs() = %v, want %v", got, ids[:1])
+	}
+
+	if err := r.Assign(content.Label{User: user1, Caption: "no id"}, ids); err == nil {
+		t.Errorf("labelRepo.Assign() expected error for a label without an id")
+	}
+}
+
+func Test_labelRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.LabelRepo()
+
+	label, err := r.Create(content.Label{User: user1, Caption: "update 1"})
+	if err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	if _, err := r.Create(content.Label{User: user1, Caption: "update 2"}); err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		label   content.Label
+		noLabel bool
+		wantErr bool
+	}{
+		{"rename", content.Label{ID: label.ID, User: user1, Caption: "update 3"}, false, false},
+		{"colors", content.Label{ID: label.ID, User: user1, Caption: "update 3", FgColor: "#123456", BgColor: "#abc"}, false, false},
+		{"duplicate caption", content.Label{ID: label.ID, User: user1, Caption: "update 2"}, false, true},
+		{"invalid color", content.Label{ID: label.ID, User: user1, Caption: "update 3", FgColor: "red"}, false, true},
+		{"other user", content.Label{ID: label.ID, User: user2, Caption: "update 4"}, true, true},
+		{"unknown", content.Label{ID: label.ID + 1000, User: user1, Caption: "update 4"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Update(tt.label)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("labelRepo.Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.noLabel != content.IsNoContent(err) {
+				t.Errorf("labelRepo.Update() error = %v, noLabel %v", err, tt.noLabel)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			fetched, err := r.Get(label.ID, content.User{Login: user1})
+			if err != nil {
+				t.Errorf("labelRepo.Update() post fetch error = %v", err)
+				return
+			}
+
+			if fetched != tt.label {
+				t.Errorf("labelRepo.Update() post fetch = %v, want %v", fetched, tt.label)
+			}
+		})
+	}
+}
+
+func Test_labelRepo_Delete(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.LabelRepo()
+	user := content.User{Login: user2}
+
+	label, err := r.Create(content.Label{User: user2, Caption: "delete"})
+	if err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	if err := r.Assign(label, []content.ArticleID{articles[4].ID}); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	if err := r.Delete(label); err != nil {
+		t.Fatalf("labelRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(label.ID, user); !content.IsNoContent(err) {
+		t.Errorf("labelRepo.Delete() label still exists, error = %v", err)
+	}
+
+	labels, err := r.ForUser(user)
+	if err != nil {
+		t.Fatalf("labelRepo.ForUser() error = %v", err)
+	}
+
+	for _, l := range labels {
+		if l.ID == label.ID {
+			t.Errorf("labelRepo.Delete() label %s still listed", l)
+		}
+	}
+
+	byArticle, err := r.ForArticles([]content.ArticleID{articles[4].ID}, user)
+	if err != nil {
+		t.Fatalf("labelRepo.ForArticles() error = %v", err)
+	}
+
+	for _, l := range byArticle[articles[4].ID] {
+		if l.ID == label.ID {
+			t.Errorf("labelRepo.Delete() label %s still assigned", l)
+		}
+	}
+}
+
+func Test_articleRepo_labelIDs(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	r := service.LabelRepo()
+	ar := service.ArticleRepo()
+	user := content.User{Login: user1}
+
+	all, err := ar.ForUser(user)
+	if err != nil {
+		t.Fatalf("articleRepo.ForUser() error = %v", err)
+	}
+
+	if len(all) < 3 {
+		t.Fatalf("articleRepo.ForUser() = %d articles, want at least 3", len(all))
+	}
+
+	first, err := r.Create(content.Label{User: user1, Caption: "filter 1"})
+	if err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	second, err := r.Create(content.Label{User: user1, Caption: "filter 2"})
+	if err != nil {
+		t.Fatalf("labelRepo.Create() error = %v", err)
+	}
+
+	if err := r.Assign(first, []content.ArticleID{all[0].ID, all[1].ID}); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	if err := r.Assign(second, []content.ArticleID{all[1].ID, all[2].ID}); err != nil {
+		t.Fatalf("labelRepo.Assign() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ids  []content.LabelID
+		want []content.ArticleID
+	}{
+		{"first", []content.LabelID{first.ID}, []content.ArticleID{all[0].ID, all[1].ID}},
+		{"second", []content.LabelID{second.ID}, []content.ArticleID{all[1].ID, all[2].ID}},
+		{"both", []content.LabelID{first.ID, second.ID}, []content.ArticleID{all[0].ID, all[1].ID, all[2].ID}},
+		{"unknown", []content.LabelID{second.ID + 1000}, []content.ArticleID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ar.ForUser(user, content.LabelIDs(tt.ids))
+			if err != nil {
+				t.Errorf("articleRepo.ForUser() error = %v", err)
+				return
+			}
+
+			want := map[content.ArticleID]bool{}
+			for _, id := range tt.want {
+				want[id] = true
+			}
+
+			if len(got) != len(want) {
+				t.Errorf("articleRepo.ForUser() = %d articles, want %v", len(got), tt.want)
+				return
+			}
+
+			for _, a := range got {
+				if !want[a.ID] {
+					t.Errorf("articleRepo.ForUser() unexpected article %d", a.ID)
+				}
+			}
+
+			count, err := ar.Count(user, content.LabelIDs(tt.ids))
+			if err != nil {
+				t.Errorf("articleRepo.Count() error = %v", err)
+				return
+			}
+
+			if count != int64(len(want)) {
+				t.Errorf("articleRepo.Count() = %d, want %d", count, len(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type annotationRepo struct {
+	repo.Annotation
+
+	log log.Log
+}
+
+func (r annotationRepo) Get(id content.AnnotationID, user content.User) (content.Annotation, error) {
+	start := time.Now()
+
+	annotation, err := r.Annotation.Get(id, user)
+
+	r.log.Infof("repo.Annotation.Get took %s", time.Now().Sub(start))
+
+	return annotation, err
+}
+
+func (r annotationRepo) ForUser(user content.User, filter content.AnnotationFilter) ([]content.Annotation, error) {
+	start := time.Now()
+
+	annotations, err := r.Annotation.ForUser(user, filter)
+
+	r.log.Infof("repo.Annotation.ForUser took %s", time.Now().Sub(start))
+
+	return annotations, err
+}
+
+func (r annotationRepo) ForArticles(ids []content.ArticleID, user content.User) (map[content.ArticleID][]content.Annotation, error) {
+	start := time.Now()
+
+	annotations, err := r.Annotation.ForArticles(ids, user)
+
+	r.log.Infof("repo.Annotation.ForArticles took %s", time.Now().Sub(start))
+
+	return annotations, err
+}
+
+func (r annotationRepo) All() ([]content.Annotation, error) {
+	start := time.Now()
+
+	annotations, err := r.Annotation.All()
+
+	r.log.Infof("repo.Annotation.All took %s", time.Now().Sub(start))
+
+	return annotations, err
+}
+
+func (r annotationRepo) Create(annotation content.Annotation) (content.Annotation, error) {
+	start := time.Now()
+
+	annotation, err := r.Annotation.Create(annotation)
+
+	r.log.Infof("repo.Annotation.Create took %s", time.Now().Sub(start))
+
+	return annotation, err
+}
+
+func (r annotationRepo) Update(annotation content.Annotation) error {
+	start := time.Now()
+
+	err := r.Annotation.Update(annotation)
+
+	r.log.Infof("repo.Annotation.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r annotationRepo) Delete(annotation content.Annotation) error {
+	start := time.Now()
+
+	err := r.Annotation.Delete(annotation)
+
+	r.log.Infof("repo.Annotation.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
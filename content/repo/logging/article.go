@@ -74,6 +74,26 @@ func (r articleRepo) Favor(state bool, user content.User, opts ...content.QueryO
 	return err
 }
 
+func (r articleRepo) Queue(state bool, user content.User, opts ...content.QueryOpt) error {
+	start := time.Now()
+
+	err := r.Article.Queue(state, user, opts...)
+
+	r.log.Infof("repo.Article.Queue took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) ReorderQueue(user content.User, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Article.ReorderQueue(user, ids)
+
+	r.log.Infof("repo.Article.ReorderQueue took %s", time.Now().Sub(start))
+
+	return err
+}
+
 func (r articleRepo) RemoveStaleUnreadRecords() error {
 	start := time.Now()
 
@@ -83,3 +103,73 @@ func (r articleRepo) RemoveStaleUnreadRecords() error {
 
 	return err
 }
+
+func (r articleRepo) RemoveStaleStateChanges() error {
+	start := time.Now()
+
+	err := r.Article.RemoveStaleStateChanges()
+
+	r.log.Infof("repo.Article.RemoveStaleStateChanges took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) Expired(feed content.Feed, retention content.Retention) ([]content.Article, error) {
+	start := time.Now()
+
+	articles, err := r.Article.Expired(feed, retention)
+
+	r.log.Infof("repo.Article.Expired took %s", time.Now().Sub(start))
+
+	return articles, err
+}
+
+func (r articleRepo) Delete(articles []content.Article) error {
+	start := time.Now()
+
+	err := r.Article.Delete(articles)
+
+	r.log.Infof("repo.Article.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) Publish(state bool, user content.User, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Article.Publish(state, user, ids)
+
+	r.log.Infof("repo.Article.Publish took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) PublishedIDs(user content.User) ([]content.ArticleID, error) {
+	start := time.Now()
+
+	ids, err := r.Article.PublishedIDs(user)
+
+	r.log.Infof("repo.Article.PublishedIDs took %s", time.Now().Sub(start))
+
+	return ids, err
+}
+
+func (r articleRepo) Hide(user content.User, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Article.Hide(user, ids)
+
+	r.log.Infof("repo.Article.Hide took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r articleRepo) RecentlyReadIDs(user content.User, since time.Time) ([]content.ArticleID, error) {
+	start := time.Now()
+
+	ids, err := r.Article.RecentlyReadIDs(user, since)
+
+	r.log.Infof("repo.Article.RecentlyReadIDs took %s", time.Now().Sub(start))
+
+	return ids, err
+}
@@ -94,6 +94,16 @@ func (r feedRepo) Update(feed *content.Feed) ([]content.Article, error) {
 	return articles, err
 }
 
+func (r feedRepo) UpdateSchedule(feed content.Feed) error {
+	start := time.Now()
+
+	err := r.Feed.UpdateSchedule(feed)
+
+	r.log.Infof("repo.Feed.UpdateSchedule took %s", time.Now().Sub(start))
+
+	return err
+}
+
 func (r feedRepo) Delete(feed content.Feed) error {
 	start := time.Now()
 
@@ -143,3 +153,63 @@ func (r feedRepo) SetUserTags(feed content.Feed, user content.User, tags []*cont
 
 	return err
 }
+
+func (r feedRepo) SetExtract(feed content.Feed, extract bool) error {
+	start := time.Now()
+
+	err := r.Feed.SetExtract(feed, extract)
+
+	r.log.Infof("repo.Feed.SetExtract took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r feedRepo) SetUserExtract(feed content.Feed, user content.User, extract bool) error {
+	start := time.Now()
+
+	err := r.Feed.SetUserExtract(feed, user, extract)
+
+	r.log.Infof("repo.Feed.SetUserExtract took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r feedRepo) SetRetention(feed content.Feed, retention content.Retention) error {
+	start := time.Now()
+
+	err := r.Feed.SetRetention(feed, retention)
+
+	r.log.Infof("repo.Feed.SetRetention took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r feedRepo) SetUserRetention(feed content.Feed, user content.User, retention content.Retention) error {
+	start := time.Now()
+
+	err := r.Feed.SetUserRetention(feed, user, retention)
+
+	r.log.Infof("repo.Feed.SetUserRetention took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r feedRepo) SetCredentials(feed content.Feed) error {
+	start := time.Now()
+
+	err := r.Feed.SetCredentials(feed)
+
+	r.log.Infof("repo.Feed.SetCredentials took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r feedRepo) SetScraper(feed content.Feed) error {
+	start := time.Now()
+
+	err := r.Feed.SetScraper(feed)
+
+	r.log.Infof("repo.Feed.SetScraper took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type labelRepo struct {
+	repo.Label
+
+	log log.Log
+}
+
+func (r labelRepo) Get(id content.LabelID, user content.User) (content.Label, error) {
+	start := time.Now()
+
+	label, err := r.Label.Get(id, user)
+
+	r.log.Infof("repo.Label.Get took %s", time.Now().Sub(start))
+
+	return label, err
+}
+
+func (r labelRepo) ForUser(user content.User) ([]content.Label, error) {
+	start := time.Now()
+
+	labels, err := r.Label.ForUser(user)
+
+	r.log.Infof("repo.Label.ForUser took %s", time.Now().Sub(start))
+
+	return labels, err
+}
+
+func (r labelRepo) ForArticles(ids []content.ArticleID, user content.User) (map[content.ArticleID][]content.Label, error) {
+	start := time.Now()
+
+	labels, err := r.Label.ForArticles(ids, user)
+
+	r.log.Infof("repo.Label.ForArticles took %s", time.Now().Sub(start))
+
+	return labels, err
+}
+
+func (r labelRepo) ArticleIDs(label content.Label) ([]content.ArticleID, error) {
+	start := time.Now()
+
+	ids, err := r.Label.ArticleIDs(label)
+
+	r.log.Infof("repo.Label.ArticleIDs took %s", time.Now().Sub(start))
+
+	return ids, err
+}
+
+func (r labelRepo) FeedIDs(label content.Label) ([]content.FeedID, error) {
+	start := time.Now()
+
+	ids, err := r.Label.FeedIDs(label)
+
+	r.log.Infof("repo.Label.FeedIDs took %s", time.Now().Sub(start))
+
+	return ids, err
+}
+
+func (r labelRepo) Create(label content.Label) (content.Label, error) {
+	start := time.Now()
+
+	label, err := r.Label.Create(label)
+
+	r.log.Infof("repo.Label.Create took %s", time.Now().Sub(start))
+
+	return label, err
+}
+
+func (r labelRepo) Update(label content.Label) error {
+	start := time.Now()
+
+	err := r.Label.Update(label)
+
+	r.log.Infof("repo.Label.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Delete(label content.Label) error {
+	start := time.Now()
+
+	err := r.Label.Delete(label)
+
+	r.log.Infof("repo.Label.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Assign(label content.Label, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Label.Assign(label, ids)
+
+	r.log.Infof("repo.Label.Assign took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r labelRepo) Unassign(label content.Label, ids []content.ArticleID) error {
+	start := time.Now()
+
+	err := r.Label.Unassign(label, ids)
+
+	r.log.Infof("repo.Label.Unassign took %s", time.Now().Sub(start))
+
+	return err
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type outputFeedRepo struct {
+	repo.OutputFeed
+
+	log log.Log
+}
+
+func (r outputFeedRepo) Get(token string) (content.OutputFeed, error) {
+	start := time.Now()
+
+	output, err := r.OutputFeed.Get(token)
+
+	r.log.Infof("repo.OutputFeed.Get took %s", time.Now().Sub(start))
+
+	return output, err
+}
+
+func (r outputFeedRepo) ForUser(user content.User) ([]content.OutputFeed, error) {
+	start := time.Now()
+
+	outputs, err := r.OutputFeed.ForUser(user)
+
+	r.log.Infof("repo.OutputFeed.ForUser took %s", time.Now().Sub(start))
+
+	return outputs, err
+}
+
+func (r outputFeedRepo) Create(output content.OutputFeed) (content.OutputFeed, error) {
+	start := time.Now()
+
+	output, err := r.OutputFeed.Create(output)
+
+	r.log.Infof("repo.OutputFeed.Create took %s", time.Now().Sub(start))
+
+	return output, err
+}
+
+func (r outputFeedRepo) Delete(output content.OutputFeed) error {
+	start := time.Now()
+
+	err := r.OutputFeed.Delete(output)
+
+	r.log.Infof("repo.OutputFeed.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
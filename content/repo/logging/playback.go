@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type playbackRepo struct {
+	repo.Playback
+
+	log log.Log
+}
+
+func (r playbackRepo) Get(article content.Article, user content.User) (content.Playback, error) {
+	start := time.Now()
+
+	playback, err := r.Playback.Get(article, user)
+
+	r.log.Infof("repo.Playback.Get took %s", time.Now().Sub(start))
+
+	return playback, err
+}
+
+func (r playbackRepo) Update(playback content.Playback) error {
+	start := time.Now()
+
+	err := r.Playback.Update(playback)
+
+	r.log.Infof("repo.Playback.Update took %s", time.Now().Sub(start))
+
+	return err
+}
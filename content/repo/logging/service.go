@@ -8,30 +8,46 @@ import (
 type Service struct {
 	repo.Service
 
+	annotation   annotationRepo
 	article      articleRepo
 	extract      extractRepo
 	feed         feedRepo
+	label        labelRepo
+	outputFeed   outputFeedRepo
+	playback     playbackRepo
+	rule         ruleRepo
 	scores       scoresRepo
 	subscription subscriptionRepo
 	tag          tagRepo
 	thumbnail    thumbnailRepo
 	user         userRepo
+	webhook      webhookRepo
 }
 
 func NewService(s repo.Service, log log.Log) Service {
 	return Service{
 		s,
+		annotationRepo{s.AnnotationRepo(), log},
 		articleRepo{s.ArticleRepo(), log},
 		extractRepo{s.ExtractRepo(), log},
 		feedRepo{s.FeedRepo(), log},
+		labelRepo{s.LabelRepo(), log},
+		outputFeedRepo{s.OutputFeedRepo(), log},
+		playbackRepo{s.PlaybackRepo(), log},
+		ruleRepo{s.RuleRepo(), log},
 		scoresRepo{s.ScoresRepo(), log},
 		subscriptionRepo{s.SubscriptionRepo(), log},
 		tagRepo{s.TagRepo(), log},
 		thumbnailRepo{s.ThumbnailRepo(), log},
 		userRepo{s.UserRepo(), log},
+		webhookRepo{s.WebhookRepo(), log},
 	}
 }
 
+func (s Service) AnnotationRepo() repo.Annotation {
+	return s.annotation
+}
+
 func (s Service) ArticleRepo() repo.Article {
 	return s.article
 }
@@ -44,6 +60,22 @@ func (s Service) FeedRepo() repo.Feed {
 	return s.feed
 }
 
+func (s Service) LabelRepo() repo.Label {
+	return s.label
+}
+
+func (s Service) OutputFeedRepo() repo.OutputFeed {
+	return s.outputFeed
+}
+
+func (s Service) PlaybackRepo() repo.Playback {
+	return s.playback
+}
+
+func (s Service) RuleRepo() repo.Rule {
+	return s.rule
+}
+
 func (s Service) ScoresRepo() repo.Scores {
 	return s.scores
 }
@@ -63,3 +95,7 @@ func (s Service) ThumbnailRepo() repo.Thumbnail {
 func (s Service) UserRepo() repo.User {
 	return s.user
 }
+
+func (s Service) WebhookRepo() repo.Webhook {
+	return s.webhook
+}
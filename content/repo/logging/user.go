@@ -63,3 +63,13 @@ func (r userRepo) FindByMD5(data []byte) (content.User, error) {
 
 	return user, err
 }
+
+func (r userRepo) FindByAPIToken(data []byte) (content.User, error) {
+	start := time.Now()
+
+	user, err := r.User.FindByAPIToken(data)
+
+	r.log.Infof("repo.User.FindByAPIToken took %s", time.Now().Sub(start))
+
+	return user, err
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/log"
+)
+
+type webhookRepo struct {
+	repo.Webhook
+
+	log log.Log
+}
+
+func (r webhookRepo) Get(id content.WebhookID, user content.User) (content.Webhook, error) {
+	start := time.Now()
+
+	webhook, err := r.Webhook.Get(id, user)
+
+	r.log.Infof("repo.Webhook.Get took %s", time.Now().Sub(start))
+
+	return webhook, err
+}
+
+func (r webhookRepo) ForUser(user content.User) ([]content.Webhook, error) {
+	start := time.Now()
+
+	webhooks, err := r.Webhook.ForUser(user)
+
+	r.log.Infof("repo.Webhook.ForUser took %s", time.Now().Sub(start))
+
+	return webhooks, err
+}
+
+func (r webhookRepo) All() ([]content.Webhook, error) {
+	start := time.Now()
+
+	webhooks, err := r.Webhook.All()
+
+	r.log.Infof("repo.Webhook.All took %s", time.Now().Sub(start))
+
+	return webhooks, err
+}
+
+func (r webhookRepo) Create(webhook content.Webhook) (content.Webhook, error) {
+	start := time.Now()
+
+	webhook, err := r.Webhook.Create(webhook)
+
+	r.log.Infof("repo.Webhook.Create took %s", time.Now().Sub(start))
+
+	return webhook, err
+}
+
+func (r webhookRepo) Update(webhook content.Webhook) error {
+	start := time.Now()
+
+	err := r.Webhook.Update(webhook)
+
+	r.log.Infof("repo.Webhook.Update took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r webhookRepo) Delete(webhook content.Webhook) error {
+	start := time.Now()
+
+	err := r.Webhook.Delete(webhook)
+
+	r.log.Infof("repo.Webhook.Delete took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r webhookRepo) Enqueue(delivery content.WebhookDelivery) (content.WebhookDelivery, error) {
+	start := time.Now()
+
+	delivery, err := r.Webhook.Enqueue(delivery)
+
+	r.log.Infof("repo.Webhook.Enqueue took %s", time.Now().Sub(start))
+
+	return delivery, err
+}
+
+func (r webhookRepo) Pending(before time.Time, limit int) ([]content.WebhookDelivery, error) {
+	start := time.Now()
+
+	deliveries, err := r.Webhook.Pending(before, limit)
+
+	r.log.Infof("repo.Webhook.Pending took %s", time.Now().Sub(start))
+
+	return deliveries, err
+}
+
+func (r webhookRepo) UpdateDelivery(delivery content.WebhookDelivery) error {
+	start := time.Now()
+
+	err := r.Webhook.UpdateDelivery(delivery)
+
+	r.log.Infof("repo.Webhook.UpdateDelivery took %s", time.Now().Sub(start))
+
+	return err
+}
+
+func (r webhookRepo) Deliveries(webhook content.Webhook, limit int) ([]content.WebhookDelivery, error) {
+	start := time.Now()
+
+	deliveries, err := r.Webhook.Deliveries(webhook, limit)
+
+	r.log.Infof("repo.Webhook.Deliveries took %s", time.Now().Sub(start))
+
+	return deliveries, err
+}
+
+func (r webhookRepo) DeleteStaleDeliveries(before time.Time) error {
+	start := time.Now()
+
+	err := r.Webhook.DeleteStaleDeliveries(before)
+
+	r.log.Infof("repo.Webhook.DeleteStaleDeliveries took %s", time.Now().Sub(start))
+
+	return err
+}
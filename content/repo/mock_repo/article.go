@@ -8,6 +8,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	content "github.com/urandom/readeef/content"
 	reflect "reflect"
+	time "time"
 )
 
 // MockArticle is a mock of Article interface
@@ -68,6 +69,31 @@ func (mr *MockArticleMockRecorder) Count(arg0 interface{}, arg1 ...interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockArticle)(nil).Count), varargs...)
 }
 
+// Delete mocks base method
+func (m *MockArticle) Delete(arg0 []content.Article) error {
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockArticleMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticle)(nil).Delete), arg0)
+}
+
+// Expired mocks base method
+func (m *MockArticle) Expired(arg0 content.Feed, arg1 content.Retention) ([]content.Article, error) {
+	ret := m.ctrl.Call(m, "Expired", arg0, arg1)
+	ret0, _ := ret[0].([]content.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expired indicates an expected call of Expired
+func (mr *MockArticleMockRecorder) Expired(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expired", reflect.TypeOf((*MockArticle)(nil).Expired), arg0, arg1)
+}
+
 // Favor mocks base method
 func (m *MockArticle) Favor(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
 	varargs := []interface{}{arg0, arg1}
@@ -103,6 +129,18 @@ func (mr *MockArticleMockRecorder) ForUser(arg0 interface{}, arg1 ...interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForUser", reflect.TypeOf((*MockArticle)(nil).ForUser), varargs...)
 }
 
+// Hide mocks base method
+func (m *MockArticle) Hide(arg0 content.User, arg1 []content.ArticleID) error {
+	ret := m.ctrl.Call(m, "Hide", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Hide indicates an expected call of Hide
+func (mr *MockArticleMockRecorder) Hide(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hide", reflect.TypeOf((*MockArticle)(nil).Hide), arg0, arg1)
+}
+
 // IDs mocks base method
 func (m *MockArticle) IDs(arg0 content.User, arg1 ...content.QueryOpt) ([]content.ArticleID, error) {
 	varargs := []interface{}{arg0}
@@ -121,6 +159,48 @@ func (mr *MockArticleMockRecorder) IDs(arg0 interface{}, arg1 ...interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDs", reflect.TypeOf((*MockArticle)(nil).IDs), varargs...)
 }
 
+// Publish mocks base method
+func (m *MockArticle) Publish(arg0 bool, arg1 content.User, arg2 []content.ArticleID) error {
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish
+func (mr *MockArticleMockRecorder) Publish(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockArticle)(nil).Publish), arg0, arg1, arg2)
+}
+
+// PublishedIDs mocks base method
+func (m *MockArticle) PublishedIDs(arg0 content.User) ([]content.ArticleID, error) {
+	ret := m.ctrl.Call(m, "PublishedIDs", arg0)
+	ret0, _ := ret[0].([]content.ArticleID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishedIDs indicates an expected call of PublishedIDs
+func (mr *MockArticleMockRecorder) PublishedIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishedIDs", reflect.TypeOf((*MockArticle)(nil).PublishedIDs), arg0)
+}
+
+// Queue mocks base method
+func (m *MockArticle) Queue(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Queue", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Queue indicates an expected call of Queue
+func (mr *MockArticleMockRecorder) Queue(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockArticle)(nil).Queue), varargs...)
+}
+
 // Read mocks base method
 func (m *MockArticle) Read(arg0 bool, arg1 content.User, arg2 ...content.QueryOpt) error {
 	varargs := []interface{}{arg0, arg1}
@@ -138,6 +218,31 @@ func (mr *MockArticleMockRecorder) Read(arg0, arg1 interface{}, arg2 ...interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockArticle)(nil).Read), varargs...)
 }
 
+// RecentlyReadIDs mocks base method
+func (m *MockArticle) RecentlyReadIDs(arg0 content.User, arg1 time.Time) ([]content.ArticleID, error) {
+	ret := m.ctrl.Call(m, "RecentlyReadIDs", arg0, arg1)
+	ret0, _ := ret[0].([]content.ArticleID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecentlyReadIDs indicates an expected call of RecentlyReadIDs
+func (mr *MockArticleMockRecorder) RecentlyReadIDs(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecentlyReadIDs", reflect.TypeOf((*MockArticle)(nil).RecentlyReadIDs), arg0, arg1)
+}
+
+// RemoveStaleStateChanges mocks base method
+func (m *MockArticle) RemoveStaleStateChanges() error {
+	ret := m.ctrl.Call(m, "RemoveStaleStateChanges")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveStaleStateChanges indicates an expected call of RemoveStaleStateChanges
+func (mr *MockArticleMockRecorder) RemoveStaleStateChanges() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveStaleStateChanges", reflect.TypeOf((*MockArticle)(nil).RemoveStaleStateChanges))
+}
+
 // RemoveStaleUnreadRecords mocks base method
 func (m *MockArticle) RemoveStaleUnreadRecords() error {
 	ret := m.ctrl.Call(m, "RemoveStaleUnreadRecords")
@@ -149,3 +254,15 @@ func (m *MockArticle) RemoveStaleUnreadRecords() error {
 func (mr *MockArticleMockRecorder) RemoveStaleUnreadRecords() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveStaleUnreadRecords", reflect.TypeOf((*MockArticle)(nil).RemoveStaleUnreadRecords))
 }
+
+// ReorderQueue mocks base method
+func (m *MockArticle) ReorderQueue(arg0 content.User, arg1 []content.ArticleID) error {
+	ret := m.ctrl.Call(m, "ReorderQueue", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderQueue indicates an expected call of ReorderQueue
+func (mr *MockArticleMockRecorder) ReorderQueue(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderQueue", reflect.TypeOf((*MockArticle)(nil).ReorderQueue), arg0, arg1)
+}
@@ -147,6 +147,78 @@ func (mr *MockFeedMockRecorder) IDs() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IDs", reflect.TypeOf((*MockFeed)(nil).IDs))
 }
 
+// SetCredentials mocks base method
+func (m *MockFeed) SetCredentials(arg0 content.Feed) error {
+	ret := m.ctrl.Call(m, "SetCredentials", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCredentials indicates an expected call of SetCredentials
+func (mr *MockFeedMockRecorder) SetCredentials(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCredentials", reflect.TypeOf((*MockFeed)(nil).SetCredentials), arg0)
+}
+
+// SetExtract mocks base method
+func (m *MockFeed) SetExtract(arg0 content.Feed, arg1 bool) error {
+	ret := m.ctrl.Call(m, "SetExtract", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetExtract indicates an expected call of SetExtract
+func (mr *MockFeedMockRecorder) SetExtract(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExtract", reflect.TypeOf((*MockFeed)(nil).SetExtract), arg0, arg1)
+}
+
+// SetRetention mocks base method
+func (m *MockFeed) SetRetention(arg0 content.Feed, arg1 content.Retention) error {
+	ret := m.ctrl.Call(m, "SetRetention", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRetention indicates an expected call of SetRetention
+func (mr *MockFeedMockRecorder) SetRetention(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRetention", reflect.TypeOf((*MockFeed)(nil).SetRetention), arg0, arg1)
+}
+
+// SetScraper mocks base method
+func (m *MockFeed) SetScraper(arg0 content.Feed) error {
+	ret := m.ctrl.Call(m, "SetScraper", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetScraper indicates an expected call of SetScraper
+func (mr *MockFeedMockRecorder) SetScraper(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetScraper", reflect.TypeOf((*MockFeed)(nil).SetScraper), arg0)
+}
+
+// SetUserExtract mocks base method
+func (m *MockFeed) SetUserExtract(arg0 content.Feed, arg1 content.User, arg2 bool) error {
+	ret := m.ctrl.Call(m, "SetUserExtract", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserExtract indicates an expected call of SetUserExtract
+func (mr *MockFeedMockRecorder) SetUserExtract(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserExtract", reflect.TypeOf((*MockFeed)(nil).SetUserExtract), arg0, arg1, arg2)
+}
+
+// SetUserRetention mocks base method
+func (m *MockFeed) SetUserRetention(arg0 content.Feed, arg1 content.User, arg2 content.Retention) error {
+	ret := m.ctrl.Call(m, "SetUserRetention", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRetention indicates an expected call of SetUserRetention
+func (mr *MockFeedMockRecorder) SetUserRetention(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRetention", reflect.TypeOf((*MockFeed)(nil).SetUserRetention), arg0, arg1, arg2)
+}
+
 // SetUserTags mocks base method
 func (m *MockFeed) SetUserTags(arg0 content.Feed, arg1 content.User, arg2 []*content.Tag) error {
 	ret := m.ctrl.Call(m, "SetUserTags", arg0, arg1, arg2)
@@ -185,6 +257,18 @@ func (mr *MockFeedMockRecorder) Update(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFeed)(nil).Update), arg0)
 }
 
+// UpdateSchedule mocks base method
+func (m *MockFeed) UpdateSchedule(arg0 content.Feed) error {
+	ret := m.ctrl.Call(m, "UpdateSchedule", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule
+func (mr *MockFeedMockRecorder) UpdateSchedule(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockFeed)(nil).UpdateSchedule), arg0)
+}
+
 // Users mocks base method
 func (m *MockFeed) Users(arg0 content.Feed) ([]content.User, error) {
 	ret := m.ctrl.Call(m, "Users", arg0)
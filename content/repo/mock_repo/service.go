@@ -33,6 +33,18 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AnnotationRepo mocks base method
+func (m *MockService) AnnotationRepo() repo.Annotation {
+	ret := m.ctrl.Call(m, "AnnotationRepo")
+	ret0, _ := ret[0].(repo.Annotation)
+	return ret0
+}
+
+// AnnotationRepo indicates an expected call of AnnotationRepo
+func (mr *MockServiceMockRecorder) AnnotationRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotationRepo", reflect.TypeOf((*MockService)(nil).AnnotationRepo))
+}
+
 // ArticleRepo mocks base method
 func (m *MockService) ArticleRepo() repo.Article {
 	ret := m.ctrl.Call(m, "ArticleRepo")
@@ -69,6 +81,54 @@ func (mr *MockServiceMockRecorder) FeedRepo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedRepo", reflect.TypeOf((*MockService)(nil).FeedRepo))
 }
 
+// LabelRepo mocks base method
+func (m *MockService) LabelRepo() repo.Label {
+	ret := m.ctrl.Call(m, "LabelRepo")
+	ret0, _ := ret[0].(repo.Label)
+	return ret0
+}
+
+// LabelRepo indicates an expected call of LabelRepo
+func (mr *MockServiceMockRecorder) LabelRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LabelRepo", reflect.TypeOf((*MockService)(nil).LabelRepo))
+}
+
+// OutputFeedRepo mocks base method
+func (m *MockService) OutputFeedRepo() repo.OutputFeed {
+	ret := m.ctrl.Call(m, "OutputFeedRepo")
+	ret0, _ := ret[0].(repo.OutputFeed)
+	return ret0
+}
+
+// OutputFeedRepo indicates an expected call of OutputFeedRepo
+func (mr *MockServiceMockRecorder) OutputFeedRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OutputFeedRepo", reflect.TypeOf((*MockService)(nil).OutputFeedRepo))
+}
+
+// PlaybackRepo mocks base method
+func (m *MockService) PlaybackRepo() repo.Playback {
+	ret := m.ctrl.Call(m, "PlaybackRepo")
+	ret0, _ := ret[0].(repo.Playback)
+	return ret0
+}
+
+// PlaybackRepo indicates an expected call of PlaybackRepo
+func (mr *MockServiceMockRecorder) PlaybackRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaybackRepo", reflect.TypeOf((*MockService)(nil).PlaybackRepo))
+}
+
+// RuleRepo mocks base method
+func (m *MockService) RuleRepo() repo.Rule {
+	ret := m.ctrl.Call(m, "RuleRepo")
+	ret0, _ := ret[0].(repo.Rule)
+	return ret0
+}
+
+// RuleRepo indicates an expected call of RuleRepo
+func (mr *MockServiceMockRecorder) RuleRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RuleRepo", reflect.TypeOf((*MockService)(nil).RuleRepo))
+}
+
 // ScoresRepo mocks base method
 func (m *MockService) ScoresRepo() repo.Scores {
 	ret := m.ctrl.Call(m, "ScoresRepo")
@@ -128,3 +188,15 @@ func (m *MockService) UserRepo() repo.User {
 func (mr *MockServiceMockRecorder) UserRepo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRepo", reflect.TypeOf((*MockService)(nil).UserRepo))
 }
+
+// WebhookRepo mocks base method
+func (m *MockService) WebhookRepo() repo.Webhook {
+	ret := m.ctrl.Call(m, "WebhookRepo")
+	ret0, _ := ret[0].(repo.Webhook)
+	return ret0
+}
+
+// WebhookRepo indicates an expected call of WebhookRepo
+func (mr *MockServiceMockRecorder) WebhookRepo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookRepo", reflect.TypeOf((*MockService)(nil).WebhookRepo))
+}
@@ -58,6 +58,19 @@ func (mr *MockUserMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUser)(nil).Delete), arg0)
 }
 
+// FindByAPIToken mocks base method
+func (m *MockUser) FindByAPIToken(arg0 []byte) (content.User, error) {
+	ret := m.ctrl.Call(m, "FindByAPIToken", arg0)
+	ret0, _ := ret[0].(content.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByAPIToken indicates an expected call of FindByAPIToken
+func (mr *MockUserMockRecorder) FindByAPIToken(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByAPIToken", reflect.TypeOf((*MockUser)(nil).FindByAPIToken), arg0)
+}
+
 // FindByMD5 mocks base method
 func (m *MockUser) FindByMD5(arg0 []byte) (content.User, error) {
 	ret := m.ctrl.Call(m, "FindByMD5", arg0)
@@ -0,0 +1,12 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// OutputFeed allows fetching and manipulating content.OutputFeed objects.
+type OutputFeed interface {
+	Get(string) (content.OutputFeed, error)
+	ForUser(content.User) ([]content.OutputFeed, error)
+
+	Create(content.OutputFeed) (content.OutputFeed, error)
+	Delete(content.OutputFeed) error
+}
@@ -0,0 +1,99 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_outputFeedRepo_Create(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	tests := []struct {
+		name    string
+		output  content.OutputFeed
+		wantErr bool
+	}{
+		{"favorite", content.OutputFeed{User: user1, Kind: content.OutputKindFavorite, Title: "Favorites"}, false},
+		{"popular tag", content.OutputFeed{User: user1, Kind: content.OutputKindPopular, TagID: 1}, false},
+		{"feed", content.OutputFeed{User: user1, Kind: content.OutputKindFeed, FeedID: 1}, false},
+		{"feed without id", content.OutputFeed{User: user1, Kind: content.OutputKindFeed}, true},
+		{"unknown kind", content.OutputFeed{User: user1, Kind: "other"}, true},
+		{"no user", content.OutputFeed{Kind: content.OutputKindFavorite}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.OutputFeedRepo()
+			got, err := r.Create(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("outputFeedRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if len(got.Token) != 40 || got.CreatedAt.IsZero() {
+				t.Errorf("outputFeedRepo.Create() = %#v", got)
+				return
+			}
+
+			fetched, err := r.Get(got.Token)
+			if err != nil {
+				t.Errorf("outputFeedRepo.Create() post fetch error = %v", err)
+				return
+			}
+
+			if fetched.String() != got.String() || fetched.Token != got.Token {
+				t.Errorf("outputFeedRepo.Create() post fetch = %#v, want %#v", fetched, got)
+			}
+		})
+	}
+}
+
+func Test_outputFeedRepo_Delete(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.OutputFeedRepo()
+
+	output, err := r.Create(content.OutputFeed{User: user2, Kind: content.OutputKindFavorite})
+	if err != nil {
+		t.Fatalf("outputFeedRepo.Create() error = %v", err)
+	}
+
+	outputs, err := r.ForUser(content.User{Login: user2})
+	if err != nil {
+		t.Fatalf("outputFeedRepo.ForUser() error = %v", err)
+	}
+
+	found := false
+	for _, o := range outputs {
+		if o.Token == output.Token {
+			found = true
+		}
+		if o.User != user2 {
+			t.Errorf("outputFeedRepo.ForUser() got output feed of %s", o.User)
+		}
+	}
+
+	if !found {
+		t.Errorf("outputFeedRepo.ForUser() = %v, missing %s", outputs, output.Token)
+	}
+
+	other := output
+	other.User = user1
+	if err := r.Delete(other); !content.IsNoContent(err) {
+		t.Errorf("outputFeedRepo.Delete() deleted output feed of another user, error = %v", err)
+	}
+
+	if err := r.Delete(output); err != nil {
+		t.Fatalf("outputFeedRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(output.Token); !content.IsNoContent(err) {
+		t.Errorf("outputFeedRepo.Delete() output feed still exists, error = %v", err)
+	}
+}
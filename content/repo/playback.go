@@ -0,0 +1,9 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Playback allows fetching and manipulating content.Playback objects
+type Playback interface {
+	Get(content.Article, content.User) (content.Playback, error)
+	Update(content.Playback) error
+}
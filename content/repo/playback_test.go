@@ -0,0 +1,67 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+)
+
+func Test_playbackRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	updated := time.Date(2018, time.March, 4, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		article  content.Article
+		user     content.User
+		playback content.Playback
+		wantErr  bool
+	}{
+		{"valid", articles[0], content.User{Login: user1}, content.Playback{ArticleID: articles[0].ID, User: user1, Position: 42.5, UpdatedAt: updated}, false},
+		{"overwrite", articles[0], content.User{Login: user1}, content.Playback{ArticleID: articles[0].ID, User: user1, Position: 90, UpdatedAt: updated.Add(time.Minute)}, false},
+		{"other user", articles[0], content.User{Login: user2}, content.Playback{ArticleID: articles[0].ID, User: user2, Position: 10, UpdatedAt: updated}, false},
+		{"no article", content.Article{}, content.User{Login: user1}, content.Playback{User: user1, Position: 10}, true},
+		{"no user", articles[0], content.User{}, content.Playback{ArticleID: articles[0].ID, Position: 10}, true},
+		{"negative position", articles[0], content.User{Login: user1}, content.Playback{ArticleID: articles[0].ID, User: user1, Position: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.PlaybackRepo()
+			if err := r.Update(tt.playback); (err != nil) != tt.wantErr {
+				t.Errorf("playbackRepo.Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got, err := r.Get(tt.article, tt.user)
+			if err != nil {
+				t.Errorf("playbackRepo.Update() post fetch error = %v", err)
+				return
+			}
+
+			if got.Position != tt.playback.Position || !got.UpdatedAt.Equal(tt.playback.UpdatedAt) {
+				t.Errorf("playbackRepo.Update() post fetch = %v, want %v", got, tt.playback)
+			}
+		})
+	}
+}
+
+func Test_playbackRepo_Get(t *testing.T) {
+	skipTest(t)
+	setupArticle()
+
+	_, err := service.PlaybackRepo().Get(articles[7], content.User{Login: user1})
+	if errors.Cause(err) != content.ErrNoContent {
+		t.Errorf("playbackRepo.Get() error = %v, wanted no content", err)
+	}
+
+	if _, err := service.PlaybackRepo().Get(content.Article{}, content.User{Login: user1}); err == nil {
+		t.Errorf("playbackRepo.Get() expected validation error")
+	}
+}
@@ -0,0 +1,13 @@
+package repo
+
+import "github.com/urandom/readeef/content"
+
+// Rule allows fetching and manipulating content.Rule objects
+type Rule interface {
+	Get(content.RuleID, content.User) (content.Rule, error)
+	ForUser(content.User) ([]content.Rule, error)
+
+	Create(content.Rule) (content.Rule, error)
+	Update(content.Rule) error
+	Delete(content.Rule) error
+}
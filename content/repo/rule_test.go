@@ -0,0 +1,124 @@
+package repo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_ruleRepo_Create(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	title := content.RuleMatch{Conditions: []content.RuleCondition{{Field: content.RuleFieldTitle, Value: "go"}}}
+
+	tests := []struct {
+		name    string
+		rule    content.Rule
+		wantErr bool
+	}{
+		{"valid", content.Rule{User: user1, Name: "read", Match: title, Actions: content.RuleActions{Read: true}, Active: true}, false},
+		{"full", content.Rule{User: user1, Name: "full", Match: content.RuleMatch{
+			FeedIDs: []content.FeedID{1, 2}, TagIDs: []content.TagID{3}, Any: true,
+			Conditions: []content.RuleCondition{
+				{Field: content.RuleFieldAuthor, Value: "^gopher$", Regex: true},
+				{Field: content.RuleFieldCategory, Value: "news", Inverse: true},
+			},
+		}, Actions: content.RuleActions{
+			Favorite: true, LabelIDs: []content.LabelID{4}, Delete: true, PostURL: "https://example.com/rule",
+		}}, false},
+		{"no actions", content.Rule{User: user1, Match: title}, true},
+		{"no user", content.Rule{Match: title, Actions: content.RuleActions{Read: true}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.RuleRepo()
+			got, err := r.Create(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ruleRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+			defer r.Delete(got)
+
+			if got.ID == 0 {
+				t.Errorf("ruleRepo.Create() rule has no id")
+				return
+			}
+
+			fetched, err := r.Get(got.ID, content.User{Login: tt.rule.User})
+			if err != nil {
+				t.Errorf("ruleRepo.Create() post fetch error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(fetched, got) {
+				t.Errorf("ruleRepo.Create() post fetch = %#v, want %#v", fetched, got)
+			}
+
+			if _, err := r.Get(got.ID, content.User{Login: user2}); !content.IsNoContent(err) {
+				t.Errorf("ruleRepo.Create() got rule of another user, error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_ruleRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.RuleRepo()
+	user := content.User{Login: user2}
+
+	rule, err := r.Create(content.Rule{
+		User: user2, Name: "update", Active: true,
+		Match:   content.RuleMatch{FeedIDs: []content.FeedID{1}},
+		Actions: content.RuleActions{Read: true},
+	})
+	if err != nil {
+		t.Fatalf("ruleRepo.Create() error = %v", err)
+	}
+
+	rule.Name = "updated"
+	rule.Active = false
+	rule.Actions = content.RuleActions{Favorite: true}
+	if err := r.Update(rule); err != nil {
+		t.Fatalf("ruleRepo.Update() error = %v", err)
+	}
+
+	got, err := r.Get(rule.ID, user)
+	if err != nil {
+		t.Fatalf("ruleRepo.Get() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, rule) {
+		t.Errorf("ruleRepo.Update() = %#v, want %#v", got, rule)
+	}
+
+	rules, err := r.ForUser(user)
+	if err != nil {
+		t.Fatalf("ruleRepo.ForUser() error = %v", err)
+	}
+
+	if len(rules) != 1 || rules[0].ID != rule.ID {
+		t.Errorf("ruleRepo.ForUser() = %v, want %v", rules, rule)
+	}
+
+	other := rule
+	other.User = user1
+	if err := r.Update(other); !content.IsNoContent(err) {
+		t.Errorf("ruleRepo.Update() updated rule of another user, error = %v", err)
+	}
+
+	if err := r.Delete(rule); err != nil {
+		t.Fatalf("ruleRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(rule.ID, user); !content.IsNoContent(err) {
+		t.Errorf("ruleRepo.Delete() rule still exists, error = %v", err)
+	}
+}
@@ -10,4 +10,10 @@ type Service interface {
 	ExtractRepo() Extract
 	ThumbnailRepo() Thumbnail
 	ScoresRepo() Scores
+	PlaybackRepo() Playback
+	LabelRepo() Label
+	WebhookRepo() Webhook
+	RuleRepo() Rule
+	OutputFeedRepo() OutputFeed
+	AnnotationRepo() Annotation
 }
@@ -0,0 +1,207 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type annotationRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type annotationQuery struct {
+	ID        content.AnnotationID `db:"id"`
+	UserLogin content.Login        `db:"user_login"`
+}
+
+func (r annotationRepo) Get(id content.AnnotationID, user content.User) (content.Annotation, error) {
+	if err := user.Validate(); err != nil {
+		return content.Annotation{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting annotation %d for %s", id, user)
+
+	var annotation content.Annotation
+	if err := r.db.WithNamedStmt(r.db.SQL().Annotation.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&annotation, annotationQuery{ID: id, UserLogin: user.Login})
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Annotation{}, errors.Wrapf(err, "getting annotation %d", id)
+	}
+
+	return annotation, nil
+}
+
+func (r annotationRepo) ForUser(user content.User, filter content.AnnotationFilter) ([]content.Annotation, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Annotation{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting annotations for %s", user)
+
+	args := map[string]interface{}{userLogin: user.Login}
+	whereSlice := []string{}
+
+	if len(filter.FeedIDs) > 0 {
+		whereSlice = append(whereSlice, r.db.WhereMultipleORs("a.feed_id", feedIDPRefix, len(filter.FeedIDs), true))
+		for i := range filter.FeedIDs {
+			args[fmt.Sprintf("%s%d", feedIDPRefix, i)] = filter.FeedIDs[i]
+		}
+	}
+
+	if !filter.After.IsZero() {
+		whereSlice = append(whereSlice, "an.created_at >= :"+afterDate)
+		args[afterDate] = filter.After.UTC()
+	}
+
+	if !filter.Before.IsZero() {
+		whereSlice = append(whereSlice, "an.created_at < :"+beforeDate)
+		args[beforeDate] = filter.Before.UTC()
+	}
+
+	sql := r.db.SQL().Annotation.AllForUser
+	if len(whereSlice) > 0 {
+		sql += " AND " + strings.Join(whereSlice, " AND ")
+	}
+	sql += " ORDER BY an.created_at, an.id"
+
+	var annotations []content.Annotation
+	if err := r.db.WithNamedStmt(sql, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&annotations, args)
+	}); err != nil {
+		return []content.Annotation{}, errors.Wrapf(err, "getting user %s annotations", user)
+	}
+
+	return annotations, nil
+}
+
+func (r annotationRepo) ForArticles(ids []content.ArticleID, user content.User) (map[content.ArticleID][]content.Annotation, error) {
+	annotations := map[content.ArticleID][]content.Annotation{}
+
+	if err := user.Validate(); err != nil {
+		return annotations, errors.WithMessage(err, "validating user")
+	}
+
+	if len(ids) == 0 {
+		return annotations, nil
+	}
+
+	r.log.Infof("Getting annotations of %d articles for %s", len(ids), user)
+
+	args := map[string]interface{}{userLogin: user.Login}
+	for i := range ids {
+		args[fmt.Sprintf("%s%d", idPrefix, i)] = ids[i]
+	}
+
+	sql := r.db.SQL().Annotation.AllForArticles + " AND " +
+		r.db.WhereMultipleORs("an.article_id", idPrefix, len(ids), true) +
+		" ORDER BY an.created_at, an.id"
+
+	var data []content.Annotation
+	if err := r.db.WithNamedStmt(sql, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&data, args)
+	}); err != nil {
+		return annotations, errors.Wrapf(err, "getting article annotations for user %s", user)
+	}
+
+	for _, a := range data {
+		annotations[a.ArticleID] = append(annotations[a.ArticleID], a)
+	}
+
+	return annotations, nil
+}
+
+func (r annotationRepo) All() ([]content.Annotation, error) {
+	r.log.Infoln("Getting all annotations")
+
+	var annotations []content.Annotation
+	if err := r.db.WithStmt(r.db.SQL().Annotation.All, nil, func(stmt *sqlx.Stmt) error {
+		return stmt.Select(&annotations)
+	}); err != nil {
+		return []content.Annotation{}, errors.Wrap(err, "getting all annotations")
+	}
+
+	return annotations, nil
+}
+
+func (r annotationRepo) Create(annotation content.Annotation) (content.Annotation, error) {
+	if err := annotation.Validate(); err != nil {
+		return content.Annotation{}, errors.WithMessage(err, "validating annotation")
+	}
+
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = time.Now()
+	}
+	annotation.CreatedAt = annotation.CreatedAt.UTC()
+	annotation.UpdatedAt = annotation.CreatedAt
+
+	r.log.Infof("Creating annotation %s", annotation)
+
+	err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		id, err := r.db.CreateWithID(tx, r.db.SQL().Annotation.Create, annotation)
+		if err != nil {
+			return errors.Wrap(err, "creating annotation")
+		}
+
+		annotation.ID = content.AnnotationID(id)
+
+		return nil
+	})
+
+	return annotation, err
+}
+
+func (r annotationRepo) Update(annotation content.Annotation) error {
+	if err := annotation.Validate(); err != nil {
+		return errors.WithMessage(err, "validating annotation")
+	}
+
+	if annotation.UpdatedAt.IsZero() {
+		annotation.UpdatedAt = time.Now()
+	}
+	annotation.UpdatedAt = annotation.UpdatedAt.UTC()
+
+	r.log.Infof("Updating annotation %s", annotation)
+
+	return r.db.WithNamedStmt(r.db.SQL().Annotation.Update, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(annotation)
+		if err != nil {
+			return errors.Wrap(err, "executing annotation update stmt")
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Wrapf(content.ErrNoContent, "updating annotation %d", annotation.ID)
+		}
+
+		return nil
+	})
+}
+
+func (r annotationRepo) Delete(annotation content.Annotation) error {
+	if annotation.User == "" {
+		return content.NewValidationError(errors.New("annotation has no user"))
+	}
+
+	r.log.Infof("Deleting annotation %s", annotation)
+
+	return r.db.WithNamedStmt(r.db.SQL().Annotation.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(annotationQuery{ID: annotation.ID, UserLogin: annotation.User}); err != nil {
+			return errors.Wrap(err, "executing annotation delete stmt")
+		}
+
+		return nil
+	})
+}
@@ -24,8 +24,22 @@ var (
 	readStateDeleteTemplate     *template.Template
 	favoriteStateInsertTemplate *template.Template
 	favoriteStateDeleteTemplate *template.Template
+	queueStateInsertTemplate    *template.Template
+	queueStateDeleteTemplate    *template.Template
+	stateChangedInsertTemplate  *template.Template
+	stateChangedDeleteTemplate  *template.Template
+	recentlyReadInsertTemplate  *template.Template
+	recentlyReadDeleteTemplate  *template.Template
 )
 
+// recentlyReadPeriod is the time during which read articles are tracked as
+// recently read.
+const recentlyReadPeriod = 24 * time.Hour
+
+// stateChangePeriod is how long the state changes of the user articles are
+// kept.
+const stateChangePeriod = 30 * 24 * time.Hour
+
 type articleRepo struct {
 	db *db.DB
 
@@ -48,8 +62,11 @@ const (
 	afterDate         = "after_date"
 	beforeScore       = "before_score"
 	afterScore        = "after_score"
+	modifiedSince     = "modified_since"
+	stateChangedAt    = "state_changed_at"
 	idPrefix          = "id"
 	feedIDPRefix      = "feed_id"
+	labelIDPrefix     = "label_id"
 	limit             = "limit"
 	offset            = "offset"
 	filterURLPrefix   = "filterURL"
@@ -156,6 +173,9 @@ func (r articleRepo) Count(user content.User, opts ...content.QueryOpt) (int64,
 	if o.FavoriteOnly {
 		renderData.Join += s.Article.StateFavoriteJoin
 	}
+	if o.QueuedOnly {
+		renderData.Join += s.Article.StateQueueJoin
+	}
 	if o.ReadOnly || o.UnreadOnly {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
@@ -220,6 +240,9 @@ func (r articleRepo) IDs(user content.User, opts ...content.QueryOpt) ([]content
 	if o.FavoriteOnly {
 		renderData.Join += s.Article.StateFavoriteJoin
 	}
+	if o.QueuedOnly || o.SortField == content.SortByQueue {
+		renderData.Join += s.Article.StateQueueJoin
+	}
 	if o.ReadOnly || o.UnreadOnly || o.UnreadFirst {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
@@ -272,6 +295,176 @@ func (r articleRepo) Favor(
 	return articleStateSet(favoriteState, state, user, r.db, r.log, opts)
 }
 
+// Queue adds the articles to the end of the user's read-later queue, or
+// removes them from it.
+func (r articleRepo) Queue(
+	state bool,
+	user content.User,
+	opts ...content.QueryOpt,
+) error {
+	return articleStateSet(queueState, state, user, r.db, r.log, opts)
+}
+
+// ReorderQueue moves the given queued articles to the front of the user's
+// read-later queue, in the given order. The rest of the queue keeps its
+// order.
+func (r articleRepo) ReorderQueue(user content.User, ids []content.ArticleID) error {
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Reordering the article queue of user %s", user)
+
+	s := r.db.SQL()
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		var queued []content.ArticleID
+		if err := r.db.WithNamedStmt(s.Article.GetQueueIDs, tx, func(stmt *sqlx.NamedStmt) error {
+			return stmt.Select(&queued, map[string]interface{}{userLogin: user.Login})
+		}); err != nil {
+			return errors.Wrapf(err, "getting queued article ids for user %s", user)
+		}
+
+		inQueue := map[content.ArticleID]bool{}
+		for _, id := range queued {
+			inQueue[id] = true
+		}
+
+		order := make([]content.ArticleID, 0, len(queued))
+		for _, id := range ids {
+			if inQueue[id] {
+				order = append(order, id)
+				inQueue[id] = false
+			}
+		}
+
+		for _, id := range queued {
+			if inQueue[id] {
+				order = append(order, id)
+			}
+		}
+
+		return r.db.WithNamedStmt(s.Article.UpdateQueuePosition, tx, func(stmt *sqlx.NamedStmt) error {
+			for i, id := range order {
+				if _, err := stmt.Exec(queuePositionArgs{UserLogin: user.Login, ArticleID: id, Position: int64(i + 1)}); err != nil {
+					return errors.Wrapf(err, "updating queue position of article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+type queuePositionArgs struct {
+	UserLogin content.Login     `db:"user_login"`
+	ArticleID content.ArticleID `db:"article_id"`
+	Position  int64             `db:"position"`
+}
+
+// RecentlyReadIDs returns the ids of the articles that were marked as read
+// after the given time. Read articles are only tracked for a day.
+func (r articleRepo) RecentlyReadIDs(user content.User, since time.Time) ([]content.ArticleID, error) {
+	if err := user.Validate(); err != nil {
+		return []content.ArticleID{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting recently read article ids for user %s", user)
+
+	var ids []content.ArticleID
+	if err := r.db.WithNamedStmt(r.db.SQL().Article.GetRecentlyReadIDs, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&ids, recentlyReadArgs{UserLogin: user.Login, ReadAt: since.UTC()})
+	}); err != nil {
+		return []content.ArticleID{}, errors.Wrapf(err, "getting recently read article ids for user %s", user)
+	}
+
+	return ids, nil
+}
+
+type recentlyReadArgs struct {
+	UserLogin content.Login `db:"user_login"`
+	ReadAt    time.Time     `db:"read_at"`
+}
+
+type userArticleArgs struct {
+	UserLogin content.Login     `db:"user_login"`
+	ArticleID content.ArticleID `db:"article_id"`
+}
+
+// Publish sets the published state of the articles, which are shared by
+// the user.
+func (r articleRepo) Publish(state bool, user content.User, ids []content.ArticleID) error {
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Setting published state of %d articles for user %s to %v", len(ids), user, state)
+
+	stmt := r.db.SQL().Article.DeletePublished
+	if state {
+		stmt = r.db.SQL().Article.CreatePublished
+	}
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(stmt, tx, func(stmt *sqlx.NamedStmt) error {
+			for _, id := range ids {
+				if _, err := stmt.Exec(userArticleArgs{UserLogin: user.Login, ArticleID: id}); err != nil {
+					return errors.Wrapf(err, "setting published state of article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+func (r articleRepo) PublishedIDs(user content.User) ([]content.ArticleID, error) {
+	if err := user.Validate(); err != nil {
+		return []content.ArticleID{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting published article ids for user %s", user)
+
+	var ids []content.ArticleID
+	if err := r.db.WithNamedStmt(r.db.SQL().Article.GetPublishedIDs, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&ids, userArticleArgs{UserLogin: user.Login})
+	}); err != nil {
+		return []content.ArticleID{}, errors.Wrapf(err, "getting published article ids for user %s", user)
+	}
+
+	return ids, nil
+}
+
+// Hide removes the articles from all of the user's queries. They are also
+// marked as read, so that they don't linger in the unread counts.
+func (r articleRepo) Hide(user content.User, ids []content.ArticleID) error {
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	r.log.Infof("Hiding %d articles for user %s", len(ids), user)
+
+	if err := articleStateSet(readState, true, user, r.db, r.log, []content.QueryOpt{content.IDs(ids)}); err != nil {
+		return errors.WithMessage(err, "marking hidden articles as read")
+	}
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(r.db.SQL().Article.CreateHidden, tx, func(stmt *sqlx.NamedStmt) error {
+			for _, id := range ids {
+				if _, err := stmt.Exec(userArticleArgs{UserLogin: user.Login, ArticleID: id}); err != nil {
+					return errors.Wrapf(err, "hiding article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
 type staleArgs struct {
 	InsertDate time.Time `db:"insert_date"`
 }
@@ -293,6 +486,110 @@ func (r articleRepo) RemoveStaleUnreadRecords() error {
 	return nil
 }
 
+type stateChangedArgs struct {
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+// RemoveStaleStateChanges forgets the article state changes that are older
+// than the state change period.
+func (r articleRepo) RemoveStaleStateChanges() error {
+	r.log.Infof("Removing stale article state changes")
+
+	if err := r.db.WithNamedStmt(
+		r.db.SQL().Article.DeleteStaleStateChanges,
+		nil,
+		func(stmt *sqlx.NamedStmt) error {
+			_, err := stmt.Exec(stateChangedArgs{time.Now().Add(-stateChangePeriod).UTC()})
+			return err
+		},
+	); err != nil {
+		return errors.Wrap(err, "removing stale article state changes")
+	}
+
+	return nil
+}
+
+type expiredArgs struct {
+	FeedID      content.FeedID `db:"feed_id"`
+	MaxArticles int            `db:"max_articles"`
+	MaxAge      int64          `db:"max_age"`
+	OlderThan   time.Time      `db:"older_than"`
+}
+
+// Expired returns the articles of the feed that fall outside of the given
+// retention. Articles that are favored, queued, labeled or annotated by any
+// user are never returned.
+func (r articleRepo) Expired(feed content.Feed, retention content.Retention) ([]content.Article, error) {
+	if err := feed.Validate(); err != nil {
+		return nil, errors.WithMessage(err, "validating feed")
+	}
+
+	if retention.Empty() {
+		return []content.Article{}, nil
+	}
+
+	r.log.Infof("Getting feed %s articles outside of retention %+v", feed, retention)
+
+	articles := []content.Article{}
+	if err := r.db.WithNamedStmt(r.db.SQL().Article.GetExpired, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&articles, expiredArgs{
+			FeedID:      feed.ID,
+			MaxArticles: retention.MaxArticles,
+			MaxAge:      int64(retention.MaxAge),
+			OlderThan:   time.Now().Add(-retention.MaxAge).UTC(),
+		})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "getting feed %s expired articles", feed)
+	}
+
+	return articles, nil
+}
+
+// Delete removes the articles, along with all of their associated data. The
+// links of the deleted articles are remembered, so that they are not created
+// again while they are still in their feed.
+func (r articleRepo) Delete(articles []content.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	r.log.Infof("Deleting %d articles", len(articles))
+
+	s := r.db.SQL()
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		if err := r.db.WithNamedStmt(s.Article.Delete, tx, func(stmt *sqlx.NamedStmt) error {
+			for i := range articles {
+				if _, err := stmt.Exec(articles[i]); err != nil {
+					return errors.Wrapf(err, "deleting article %d", articles[i].ID)
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return r.db.WithNamedStmt(s.Article.CreatePurged, tx, func(stmt *sqlx.NamedStmt) error {
+			for i := range articles {
+				if articles[i].Link == "" {
+					continue
+				}
+
+				if _, err := stmt.Exec(purgedArgs{FeedID: articles[i].FeedID, Link: articles[i].Link}); err != nil {
+					return errors.Wrapf(err, "recording purged article %d", articles[i].ID)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+type purgedArgs struct {
+	FeedID content.FeedID `db:"feed_id"`
+	Link   string         `db:"link"`
+}
+
 func getArticles(login content.Login, dbo *db.DB, log log.Log, opts content.QueryOptions) ([]content.Article, error) {
 	var err error
 	if getArticlesTemplate == nil {
@@ -313,6 +610,10 @@ func getArticles(login content.Login, dbo *db.DB, log log.Log, opts content.Quer
 		renderData.Columns += ", asco.score"
 	}
 
+	if !opts.ModifiedSince.IsZero() {
+		renderData.Columns += ", asch.changed_at AS state_changed"
+	}
+
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
 
@@ -339,6 +640,7 @@ type stateType int
 const (
 	readState     stateType = iota
 	favoriteState stateType = iota
+	queueState    stateType = iota
 )
 
 func articleStateSet(
@@ -360,24 +662,34 @@ func articleStateSet(
 	o := content.QueryOptions{}
 	o.Apply(opts)
 
-	var tmpl *template.Template
+	var tmpls []*template.Template
 
 	switch stateType {
 	case readState:
 		log.Infof("Setting articles read state")
 
+		// The recently read records have to be changed while the unread
+		// ones are still intact. Read articles leave the queue as well.
 		if state {
-			tmpl = readStateDeleteTemplate
+			tmpls = []*template.Template{recentlyReadInsertTemplate, queueStateDeleteTemplate, readStateDeleteTemplate}
 		} else {
-			tmpl = readStateInsertTemplate
+			tmpls = []*template.Template{recentlyReadDeleteTemplate, readStateInsertTemplate}
 		}
 	case favoriteState:
 		log.Infof("Setting articles favorite state")
 
 		if state {
-			tmpl = favoriteStateInsertTemplate
+			tmpls = []*template.Template{favoriteStateInsertTemplate}
+		} else {
+			tmpls = []*template.Template{favoriteStateDeleteTemplate}
+		}
+	case queueState:
+		log.Infof("Setting articles queue state")
+
+		if state {
+			tmpls = []*template.Template{queueStateInsertTemplate}
 		} else {
-			tmpl = favoriteStateDeleteTemplate
+			tmpls = []*template.Template{queueStateDeleteTemplate}
 		}
 	}
 
@@ -389,26 +701,97 @@ func articleStateSet(
 	if o.FavoriteOnly {
 		renderData.Join += s.Article.StateFavoriteJoin
 	}
+	if o.QueuedOnly {
+		renderData.Join += s.Article.StateQueueJoin
+	}
 	if o.ReadOnly || o.UnreadOnly {
 		renderData.Join += s.Article.StateUnreadJoin
 	}
 
+	// The change is recorded before the state itself is changed, while the
+	// query still matches the articles, and only for the articles whose
+	// state actually changes.
+	changedData := renderData
+	changedData.Where = appendWhere(renderData.Where, stateChangedClause(stateType, state))
+	args[stateChangedAt] = time.Now().UTC()
+
 	buf := pool.Buffer.Get()
 	defer pool.Buffer.Put(buf)
 
-	if err := tmpl.Execute(buf, renderData); err != nil {
-		return errors.Wrap(err, "executing article state template")
+	return db.WithTx(func(tx *sqlx.Tx) error {
+		exec := func(tmpl *template.Template, data getArticlesData) error {
+			buf.Reset()
+			if err := tmpl.Execute(buf, data); err != nil {
+				return errors.Wrap(err, "executing article state template")
+			}
+
+			log.Debugf("Articles state SQL:\n%s\nArgs:%v\n", buf.String(), args)
+			if err := db.WithNamedStmt(buf.String(), tx, func(stmt *sqlx.NamedStmt) error {
+				_, err := stmt.Exec(args)
+				return err
+			}); err != nil {
+				return errors.Wrap(err, "executing article state statement")
+			}
+
+			return nil
+		}
+
+		if !o.InitialState {
+			for _, tmpl := range []*template.Template{stateChangedDeleteTemplate, stateChangedInsertTemplate} {
+				if err := exec(tmpl, changedData); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, tmpl := range tmpls {
+			if err := exec(tmpl, renderData); err != nil {
+				return err
+			}
+		}
+
+		if stateType != readState || !state {
+			return nil
+		}
+
+		return db.WithNamedStmt(s.Article.DeleteExpiredRecentlyRead, tx, func(stmt *sqlx.NamedStmt) error {
+			if _, err := stmt.Exec(recentlyReadArgs{ReadAt: time.Now().Add(-recentlyReadPeriod).UTC()}); err != nil {
+				return errors.Wrap(err, "deleting expired recently read records")
+			}
+
+			return nil
+		})
+	})
+}
+
+// stateChangedClause limits a state query to the articles whose state would
+// be changed by setting it to the given value.
+func stateChangedClause(stateType stateType, state bool) string {
+	var table string
+	switch stateType {
+	case readState:
+		// Read articles have no unread records.
+		table, state = "users_articles_unread", !state
+	case favoriteState:
+		table = "users_articles_favorite"
+	case queueState:
+		table = "users_articles_queue"
+	}
+
+	op := "IN"
+	if state {
+		op = "NOT IN"
 	}
 
-	log.Debugf("Articles state SQL:\n%s\nArgs:%v\n", buf.String(), args)
-	if err := db.WithNamedStmt(buf.String(), nil, func(stmt *sqlx.NamedStmt) error {
-		_, err := stmt.Exec(args)
-		return err
-	}); err != nil {
-		return errors.Wrap(err, "executing article state statement")
+	return fmt.Sprintf("a.id %s (SELECT article_id FROM %s WHERE user_login = :user_login)", op, table)
+}
+
+func appendWhere(where, clause string) string {
+	if where == "" {
+		return "WHERE " + clause
 	}
 
-	return nil
+	return where + " AND " + clause
 }
 
 func constructSQLQueryOptions(
@@ -432,6 +815,10 @@ func constructSQLQueryOptions(
 		if opts.UntaggedOnly {
 			join += s.Article.GetUntaggedJoin
 		}
+
+		if !opts.ModifiedSince.IsZero() {
+			join += s.Article.StateChangedJoin
+		}
 	}
 
 	whereSlice := []string{}
@@ -450,6 +837,21 @@ func constructSQLQueryOptions(
 		if opts.FavoriteOnly {
 			whereSlice = append(whereSlice, "af.article_id IS NOT NULL")
 		}
+
+		if opts.QueuedOnly {
+			whereSlice = append(whereSlice, "aq.article_id IS NOT NULL")
+		}
+
+		if !opts.ModifiedSince.IsZero() {
+			whereSlice = append(whereSlice,
+				"(a.date > :modified_since OR asch.changed_at > :modified_since)",
+			)
+			args[modifiedSince] = opts.ModifiedSince.UTC()
+		}
+
+		whereSlice = append(whereSlice,
+			"a.id NOT IN (SELECT ah.article_id FROM users_articles_hidden ah WHERE ah.user_login = :user_login)",
+		)
 	}
 
 	if clause := createRowValueClause(opts.BeforeID, opts.BeforeDate, opts.BeforeScore, "before", args); clause != "" {
@@ -480,6 +882,16 @@ func constructSQLQueryOptions(
 		}
 	}
 
+	if len(opts.LabelIDs) > 0 {
+		whereSlice = append(whereSlice, fmt.Sprintf(
+			"a.id IN (SELECT al.article_id FROM articles_labels al WHERE %s)",
+			db.WhereMultipleORs("al.label_id", labelIDPrefix, len(opts.LabelIDs), true),
+		))
+		for i := range opts.LabelIDs {
+			args[fmt.Sprintf("%s%d", labelIDPrefix, i)] = opts.LabelIDs[i]
+		}
+	}
+
 	for i, f := range opts.Filters {
 		if !f.Valid() {
 			continue
@@ -573,6 +985,10 @@ func constructSQLQueryOptions(
 		fields = append(fields, "a.id")
 	case content.SortByDate:
 		fields = append(fields, "a.date")
+	case content.SortByQueue:
+		if hasUser {
+			fields = append(fields, "aq.position")
+		}
 	}
 
 	var order string
@@ -653,6 +1069,24 @@ func instantiateStateTemplates(s db.SqlStmts) error {
 		}
 	}
 
+	if recentlyReadInsertTemplate == nil {
+		recentlyReadInsertTemplate, err = template.New("recently-read-insert-sql").
+			Parse(s.Article.RecentlyReadInsertTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating recently-read-insert template")
+		}
+	}
+
+	if recentlyReadDeleteTemplate == nil {
+		recentlyReadDeleteTemplate, err = template.New("recently-read-delete-sql").
+			Parse(s.Article.RecentlyReadDeleteTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating recently-read-delete template")
+		}
+	}
+
 	if favoriteStateInsertTemplate == nil {
 		favoriteStateInsertTemplate, err = template.New("favorite-state-insert-sql").
 			Parse(s.Article.FavoriteStateInsertTemplate)
@@ -671,6 +1105,42 @@ func instantiateStateTemplates(s db.SqlStmts) error {
 		}
 	}
 
+	if queueStateInsertTemplate == nil {
+		queueStateInsertTemplate, err = template.New("queue-state-insert-sql").
+			Parse(s.Article.QueueStateInsertTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating queue-state-insert template")
+		}
+	}
+
+	if stateChangedInsertTemplate == nil {
+		stateChangedInsertTemplate, err = template.New("state-changed-insert-sql").
+			Parse(s.Article.StateChangedInsertTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating state-changed-insert template")
+		}
+	}
+
+	if stateChangedDeleteTemplate == nil {
+		stateChangedDeleteTemplate, err = template.New("state-changed-delete-sql").
+			Parse(s.Article.StateChangedDeleteTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating state-changed-delete template")
+		}
+	}
+
+	if queueStateDeleteTemplate == nil {
+		queueStateDeleteTemplate, err = template.New("queue-state-delete-sql").
+			Parse(s.Article.QueueStateDeleteTemplate)
+
+		if err != nil {
+			return errors.Wrap(err, "generating queue-state-delete template")
+		}
+	}
+
 	return nil
 }
 
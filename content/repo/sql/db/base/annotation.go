@@ -0,0 +1,41 @@
+package base
+
+func init() {
+	sqlStmts.Annotation.Get = getUserAnnotation
+	sqlStmts.Annotation.AllForUser = getUserAnnotations
+	sqlStmts.Annotation.AllForArticles = getArticlesAnnotations
+	sqlStmts.Annotation.All = getAllAnnotations
+	sqlStmts.Annotation.Create = createAnnotation
+	sqlStmts.Annotation.Update = updateAnnotation
+	sqlStmts.Annotation.Delete = deleteAnnotation
+}
+
+const (
+	getUserAnnotation = `
+SELECT an.id, an.user_login, an.article_id, an.note, an.quote, an.range_start, an.range_end, an.created_at, an.updated_at
+FROM annotations an
+WHERE an.id = :id AND an.user_login = :user_login
+`
+	getUserAnnotations = `
+SELECT an.id, an.user_login, an.article_id, an.note, an.quote, an.range_start, an.range_end, an.created_at, an.updated_at
+FROM annotations an INNER JOIN articles a
+	ON an.article_id = a.id
+WHERE an.user_login = :user_login`
+	getArticlesAnnotations = `
+SELECT an.id, an.user_login, an.article_id, an.note, an.quote, an.range_start, an.range_end, an.created_at, an.updated_at
+FROM annotations an
+WHERE an.user_login = :user_login`
+	getAllAnnotations = `
+SELECT an.id, an.user_login, an.article_id, an.note, an.quote, an.range_start, an.range_end, an.created_at, an.updated_at
+FROM annotations an
+ORDER BY an.id
+`
+
+	createAnnotation = `
+INSERT INTO annotations(user_login, article_id, note, quote, range_start, range_end, created_at, updated_at)
+	VALUES(:user_login, :article_id, :note, :quote, :range_start, :range_end, :created_at, :updated_at)`
+	updateAnnotation = `
+UPDATE annotations SET note = :note, quote = :quote, range_start = :range_start, range_end = :range_end, updated_at = :updated_at
+WHERE id = :id AND user_login = :user_login`
+	deleteAnnotation = `DELETE FROM annotations WHERE id = :id AND user_login = :user_login`
+)
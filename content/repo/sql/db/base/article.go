@@ -11,27 +11,58 @@ func init() {
 	sqlStmts.Article.StateReadColumn = stateReadColumn
 	sqlStmts.Article.StateUnreadJoin = stateUnreadJoin
 	sqlStmts.Article.StateFavoriteJoin = stateFavoriteJoin
+	sqlStmts.Article.StateQueueJoin = stateQueueJoin
 	sqlStmts.Article.GetIDsTemplate = getArticleIDsTemplate
 	sqlStmts.Article.DeleteStaleUnreadRecords = deleteStaleUnreadRecords
 	sqlStmts.Article.GetScoreJoin = getArticlesScoreJoin
 	sqlStmts.Article.GetUntaggedJoin = getArticlesUntaggedJoin
+	sqlStmts.Article.StateChangedJoin = stateChangedJoin
 
 	sqlStmts.Article.ReadStateInsertTemplate = readStateInsertTemplate
 	sqlStmts.Article.ReadStateDeleteTemplate = readStateDeleteTemplate
 	sqlStmts.Article.FavoriteStateInsertTemplate = favoriteStateInsertTemplate
 	sqlStmts.Article.FavoriteStateDeleteTemplate = favoriteStateDeleteTemplate
+	sqlStmts.Article.QueueStateInsertTemplate = queueStateInsertTemplate
+	sqlStmts.Article.QueueStateDeleteTemplate = queueStateDeleteTemplate
+
+	sqlStmts.Article.StateChangedInsertTemplate = stateChangedInsertTemplate
+	sqlStmts.Article.StateChangedDeleteTemplate = stateChangedDeleteTemplate
+	sqlStmts.Article.DeleteStaleStateChanges = deleteStaleStateChanges
+
+	sqlStmts.Article.GetQueueIDs = getQueueArticleIDs
+	sqlStmts.Article.UpdateQueuePosition = updateQueuePosition
+
+	sqlStmts.Article.RecentlyReadInsertTemplate = recentlyReadInsertTemplate
+	sqlStmts.Article.RecentlyReadDeleteTemplate = recentlyReadDeleteTemplate
+	sqlStmts.Article.GetRecentlyReadIDs = getRecentlyReadArticleIDs
+	sqlStmts.Article.DeleteExpiredRecentlyRead = deleteExpiredRecentlyRead
+
+	sqlStmts.Article.GetPublishedIDs = getPublishedArticleIDs
+	sqlStmts.Article.CreatePublished = createPublishedArticle
+	sqlStmts.Article.DeletePublished = deletePublishedArticle
+
+	sqlStmts.Article.CreateHidden = createHiddenArticle
+
+	sqlStmts.Article.GetExpired = getExpiredArticles
+	sqlStmts.Article.Delete = deleteArticle
+
+	sqlStmts.Article.CreatePurged = createPurgedArticle
+	sqlStmts.Article.GetPurgedLinks = getPurgedArticleLinks
+	sqlStmts.Article.DeletePurged = deletePurgedArticle
 }
 
 const (
 	createFeedArticle = `
-INSERT INTO articles(feed_id, link, guid, title, description, date)
-	SELECT :feed_id, :link, :guid, :title, :description, :date EXCEPT
-	SELECT feed_id, link, CAST(:guid AS TEXT), CAST(:title as TEXT), CAST(:description AS TEXT), CAST(:date AS TIMESTAMP WITH TIME ZONE)
+INSERT INTO articles(feed_id, link, guid, title, description, date, author, categories, enclosures, media)
+	SELECT :feed_id, :link, :guid, :title, :description, :date, :author, :categories, :enclosures, :media EXCEPT
+	SELECT feed_id, link, CAST(:guid AS TEXT), CAST(:title as TEXT), CAST(:description AS TEXT), CAST(:date AS TIMESTAMP WITH TIME ZONE),
+		CAST(:author AS TEXT), CAST(:categories AS TEXT), CAST(:enclosures AS TEXT), CAST(:media AS TEXT)
 	FROM articles WHERE feed_id = :feed_id AND link = :link
 `
 
 	updateFeedArticle = `
-UPDATE articles SET title = :title, description = :description, date = :date, guid = :guid, link = :link
+UPDATE articles SET title = :title, description = :description, date = :date, guid = :guid, link = :link,
+	author = :author, categories = :categories, enclosures = :enclosures, media = :media
 	WHERE feed_id = :feed_id AND (guid = :guid OR link = :link)
 `
 	articleCountTemplate = `
@@ -42,6 +73,7 @@ FROM articles a
 `
 	getArticlesUserlessTemplate = `
 SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid,
+	a.author, a.categories, a.enclosures, a.media,
 	COALESCE(at.thumbnail, '') as thumbnail,
 	COALESCE(at.link, '') as thumbnail_link
 	{{ .Columns }}
@@ -55,8 +87,10 @@ LEFT OUTER JOIN articles_thumbnails at
 `
 	getArticlesTemplate = `
 SELECT a.feed_id, a.id, a.title, a.description, a.link, a.date, a.guid,
+	a.author, a.categories, a.enclosures, a.media,
 	CASE WHEN au.article_id IS NULL THEN 1 ELSE 0 END AS read,
 	CASE WHEN af.article_id IS NULL THEN 0 ELSE 1 END AS favorite,
+	CASE WHEN aq.article_id IS NULL THEN 0 ELSE 1 END AS queued,
 	COALESCE(at.thumbnail, '') as thumbnail,
 	COALESCE(at.link, '') as thumbnail_link
 	{{ .Columns }}
@@ -68,6 +102,8 @@ LEFT OUTER JOIN users_articles_unread au
     ON a.id = au.article_id AND uf.user_login = au.user_login
 LEFT OUTER JOIN users_articles_favorite af
     ON a.id = af.article_id AND uf.user_login = af.user_login
+LEFT OUTER JOIN users_articles_queue aq
+    ON a.id = aq.article_id AND uf.user_login = aq.user_login
 LEFT OUTER JOIN articles_thumbnails at
     ON a.id = at.article_id
 {{ .Where }}
@@ -83,6 +119,10 @@ INNER JOIN users_feeds uf
 	stateFavoriteJoin = `
 LEFT OUTER JOIN users_articles_favorite af
 	ON a.id = af.article_id AND af.user_login = uf.user_login
+`
+	stateQueueJoin = `
+LEFT OUTER JOIN users_articles_queue aq
+	ON a.id = aq.article_id AND aq.user_login = uf.user_login
 `
 	stateUnreadJoin = `
 LEFT OUTER JOIN users_articles_unread au
@@ -107,6 +147,10 @@ SELECT a.id FROM (
 LEFT OUTER JOIN users_feeds_tags uft
 	ON uft.feed_id = uf.feed_id
 	AND uft.user_login = uf.user_login
+`
+	stateChangedJoin = `
+LEFT OUTER JOIN users_articles_state_changes asch
+	ON a.id = asch.article_id AND asch.user_login = :user_login
 `
 	readStateInsertTemplate = `
 INSERT INTO users_articles_unread (user_login, article_id)
@@ -152,4 +196,151 @@ DELETE FROM users_articles_favorite WHERE user_login = :user_login AND article_i
 	{{ .Where }}
 )
 `
+	// New queue entries are placed right after the existing ones, keeping
+	// the order of their ids.
+	queueStateInsertTemplate = `
+INSERT INTO users_articles_queue (user_login, article_id, position)
+SELECT n.user_login, n.article_id, m.position + ROW_NUMBER() OVER (ORDER BY n.article_id)
+FROM (
+	SELECT uf.user_login, a.id AS article_id
+	FROM users_feeds uf
+	INNER JOIN articles a
+		ON uf.feed_id = a.feed_id AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+	EXCEPT SELECT aq.user_login, aq.article_id
+	FROM users_articles_queue aq
+	WHERE aq.user_login = :user_login
+) n, (
+	SELECT COALESCE(MAX(position), 0) AS position
+	FROM users_articles_queue
+	WHERE user_login = :user_login
+) m
+`
+	queueStateDeleteTemplate = `
+DELETE FROM users_articles_queue WHERE user_login = :user_login AND article_id IN (
+	SELECT a.id
+	FROM users_feeds uf INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+		AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+)
+`
+	stateChangedInsertTemplate = `
+INSERT INTO users_articles_state_changes (user_login, article_id, changed_at)
+SELECT uf.user_login, a.id, :state_changed_at
+FROM users_feeds uf
+INNER JOIN articles a
+	ON uf.feed_id = a.feed_id AND uf.user_login = :user_login
+{{ .Join }}
+{{ .Where }}
+`
+	stateChangedDeleteTemplate = `
+DELETE FROM users_articles_state_changes WHERE user_login = :user_login AND article_id IN (
+	SELECT a.id
+	FROM users_feeds uf INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+		AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+)
+`
+	deleteStaleStateChanges = `DELETE FROM users_articles_state_changes WHERE changed_at < :changed_at`
+	getQueueArticleIDs      = `
+SELECT article_id
+FROM users_articles_queue
+WHERE user_login = :user_login
+ORDER BY position, article_id
+`
+	updateQueuePosition = `
+UPDATE users_articles_queue SET position = :position
+WHERE user_login = :user_login AND article_id = :article_id
+`
+
+	// Only articles that are still unread are recorded as recently read,
+	// before their unread records are removed.
+	recentlyReadInsertTemplate = `
+INSERT INTO users_articles_recently_read (user_login, article_id, read_at)
+SELECT au.user_login, au.article_id, CURRENT_TIMESTAMP
+FROM users_articles_unread au
+WHERE au.user_login = :user_login AND au.article_id IN (
+	SELECT a.id
+	FROM users_feeds uf INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+		AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+) AND au.article_id NOT IN (
+	SELECT article_id FROM users_articles_recently_read WHERE user_login = :user_login
+)
+`
+	recentlyReadDeleteTemplate = `
+DELETE FROM users_articles_recently_read WHERE user_login = :user_login AND article_id IN (
+	SELECT a.id
+	FROM users_feeds uf INNER JOIN articles a
+		ON uf.feed_id = a.feed_id
+		AND uf.user_login = :user_login
+	{{ .Join }}
+	{{ .Where }}
+)
+`
+	getRecentlyReadArticleIDs = `
+SELECT uarr.article_id
+FROM users_articles_recently_read uarr
+WHERE uarr.user_login = :user_login AND uarr.read_at > :read_at
+ORDER BY uarr.article_id
+`
+	deleteExpiredRecentlyRead = `
+DELETE FROM users_articles_recently_read WHERE read_at < :read_at
+`
+	getPublishedArticleIDs = `
+SELECT uap.article_id
+FROM users_articles_published uap
+WHERE uap.user_login = :user_login
+ORDER BY uap.article_id
+`
+	createPublishedArticle = `
+INSERT INTO users_articles_published(user_login, article_id)
+	SELECT :user_login, :article_id EXCEPT SELECT user_login, article_id
+		FROM users_articles_published
+		WHERE user_login = :user_login AND article_id = :article_id
+`
+	deletePublishedArticle = `
+DELETE FROM users_articles_published WHERE user_login = :user_login AND article_id = :article_id
+`
+
+	createHiddenArticle = `
+INSERT INTO users_articles_hidden(user_login, article_id)
+	SELECT :user_login, :article_id EXCEPT SELECT user_login, article_id
+		FROM users_articles_hidden
+		WHERE user_login = :user_login AND article_id = :article_id
+`
+
+	// Articles that any user has favored, queued, labeled or annotated are
+	// never expired.
+	getExpiredArticles = `
+SELECT a.id, a.feed_id, a.link, a.title, a.date
+FROM articles a
+WHERE a.feed_id = :feed_id
+	AND ((:max_articles > 0 AND a.id NOT IN (
+		SELECT id FROM articles WHERE feed_id = :feed_id
+		ORDER BY date DESC, id DESC LIMIT :max_articles
+	)) OR (:max_age > 0 AND a.date < :older_than))
+	AND NOT EXISTS (SELECT 1 FROM users_articles_favorite af WHERE af.article_id = a.id)
+	AND NOT EXISTS (SELECT 1 FROM users_articles_queue aq WHERE aq.article_id = a.id)
+	AND NOT EXISTS (SELECT 1 FROM articles_labels al WHERE al.article_id = a.id)
+	AND NOT EXISTS (SELECT 1 FROM annotations an WHERE an.article_id = a.id)
+ORDER BY a.date, a.id
+`
+	deleteArticle = `DELETE FROM articles WHERE id = :id`
+
+	createPurgedArticle = `
+INSERT INTO articles_purged(feed_id, link)
+	SELECT :feed_id, :link EXCEPT SELECT feed_id, link
+		FROM articles_purged
+		WHERE feed_id = :feed_id AND link = :link
+`
+	getPurgedArticleLinks = `SELECT link FROM articles_purged WHERE feed_id = :feed_id`
+	deletePurgedArticle   = `DELETE FROM articles_purged WHERE feed_id = :feed_id AND link = :link`
 )
@@ -12,25 +12,47 @@ func init() {
 	sqlStmts.Feed.IDs = feedIDs
 	sqlStmts.Feed.Create = createFeed
 	sqlStmts.Feed.Update = updateFeed
+	sqlStmts.Feed.UpdateSchedule = updateFeedSchedule
 	sqlStmts.Feed.Delete = deleteFeed
 	sqlStmts.Feed.GetUsers = getFeedUsers
 	sqlStmts.Feed.Attach = createUserFeed
 	sqlStmts.Feed.Detach = deleteUserFeed
 	sqlStmts.Feed.CreateUserTag = createUserFeedTag
 	sqlStmts.Feed.DeleteUserTags = deleteUserFeedTags
+	sqlStmts.Feed.UpdateExtract = updateFeedExtract
+	sqlStmts.Feed.UpdateUserExtract = updateUserFeedExtract
+	sqlStmts.Feed.UpdateRetention = updateFeedRetention
+	sqlStmts.Feed.UpdateUserRetention = updateUserFeedRetention
+	sqlStmts.Feed.UpdateCredentials = updateFeedCredentials
+	sqlStmts.Feed.UpdateScraper = updateFeedScraper
 }
 
 const (
 	feedIDs    = `SELECT id FROM feeds`
 	createFeed = `
-INSERT INTO feeds(link, title, description, hub_link, site_link, update_error, subscribe_error)
-SELECT :link, :title, :description, :hub_link, :site_link, :update_error, :subscribe_error EXCEPT SELECT link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE link = :link`
-	updateFeed = `UPDATE feeds SET link = :link, title = :title, description = :description, hub_link = :hub_link, site_link = :site_link, update_error = :update_error, subscribe_error = :subscribe_error WHERE id = :id`
-	deleteFeed = `DELETE FROM feeds WHERE id = :id`
+INSERT INTO feeds(link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_check, failure_count, dead, credentials, scraper)
+SELECT :link, :title, :description, :hub_link, :site_link, :update_error, :subscribe_error, :etag, :last_modified, :next_check, :failure_count, :dead, :credentials, :scraper EXCEPT SELECT link, title, description, hub_link, site_link, update_error, subscribe_error, etag, last_modified, next_check, failure_count, dead, credentials, scraper FROM feeds WHERE link = :link`
+	updateFeed         = `UPDATE feeds SET link = :link, title = :title, description = :description, hub_link = :hub_link, site_link = :site_link, update_error = :update_error, subscribe_error = :subscribe_error, etag = :etag, last_modified = :last_modified, next_check = :next_check, failure_count = :failure_count, dead = :dead WHERE id = :id`
+	updateFeedSchedule = `UPDATE feeds SET etag = :etag, last_modified = :last_modified, next_check = :next_check, failure_count = :failure_count, dead = :dead WHERE id = :id`
+	deleteFeed         = `DELETE FROM feeds WHERE id = :id`
+	updateFeedExtract  = `UPDATE feeds SET extract_content = :extract_content WHERE id = :id`
+	// The credentials and the scraper selectors are not part of the regular
+	// update, so that a feed which is being downloaded can't overwrite them
+	// with stale data.
+	updateFeedCredentials = `UPDATE feeds SET credentials = :credentials WHERE id = :id`
+	updateFeedScraper     = `UPDATE feeds SET scraper = :scraper WHERE id = :id`
+	updateFeedRetention   = `UPDATE feeds SET retention = :retention WHERE id = :id`
+
+	updateUserFeedExtract = `
+UPDATE users_feeds SET extract_content = :extract_content
+	WHERE user_login = :user_login AND feed_id = :id`
+	updateUserFeedRetention = `
+UPDATE users_feeds SET retention = :retention
+	WHERE user_login = :user_login AND feed_id = :id`
 
 	getFeedUsers = `
 SELECT u.login, u.first_name, u.last_name, u.email, u.admin, u.active,
-	u.profile_data, u.hash_type, u.salt, u.hash, u.md5_api
+	u.profile_data, u.hash_type, u.salt, u.hash, u.md5_api, u.api_key_rotated, u.api_token
 FROM users u, users_feeds uf
 WHERE u.login = uf.user_login AND uf.feed_id = :id
 `
@@ -50,32 +72,46 @@ INSERT INTO users_feeds_tags(user_login, feed_id, tag_id)
 DELETE FROM users_feeds_tags WHERE user_login = :user_login AND feed_id = :feed_id
 `
 
-	getFeed       = `SELECT link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE id = :id`
-	getFeedByLink = `SELECT id, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds WHERE link = :link`
-	getUserFeed   = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+	getFeed = `
+SELECT f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, EXISTS(SELECT 1 FROM users_feeds uf WHERE uf.feed_id = f.id AND uf.extract_content = '1') AS user_extract_content
+FROM feeds f WHERE f.id = :id`
+	getFeedByLink = `
+SELECT f.id, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, EXISTS(SELECT 1 FROM users_feeds uf WHERE uf.feed_id = f.id AND uf.extract_content = '1') AS user_extract_content
+FROM feeds f WHERE f.link = :link`
+	getUserFeed = `
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, uf.extract_content AS user_extract_content, uf.retention AS user_retention
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND f.id = :id AND uf.user_login = :user_login
 `
-	getFeeds     = `SELECT id, link, title, description, hub_link, site_link, update_error, subscribe_error FROM feeds`
+	getFeeds = `
+SELECT f.id, f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, EXISTS(SELECT 1 FROM users_feeds uf WHERE uf.feed_id = f.id AND uf.extract_content = '1') AS user_extract_content
+FROM feeds f`
 	getUserFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, uf.extract_content AS user_extract_content, uf.retention AS user_retention
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND uf.user_login = :user_login
 ORDER BY LOWER(f.title)
 `
 	getUserTagFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
-FROM feeds f, users_feeds_tags uft, tags t
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, uf.extract_content AS user_extract_content, uf.retention AS user_retention
+FROM feeds f, users_feeds uf, users_feeds_tags uft, tags t
 WHERE f.id = uft.feed_id
+	AND uf.feed_id = uft.feed_id AND uf.user_login = uft.user_login
 	AND t.id = uft.tag_id
 	AND uft.user_login = :user_login AND t.value = :tag_value
 ORDER BY LOWER(f.title)
 `
 	getUnsubscribedFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, EXISTS(SELECT 1 FROM users_feeds uf WHERE uf.feed_id = f.id AND uf.extract_content = '1') AS user_extract_content
 	FROM feeds f LEFT OUTER JOIN hubbub_subscriptions hs
 	ON f.id = hs.feed_id AND hs.subscription_failure = '1'
 	ORDER BY f.title
@@ -0,0 +1,71 @@
+package base
+
+func init() {
+	sqlStmts.Label.Get = getUserLabel
+	sqlStmts.Label.AllForUser = getUserLabels
+	sqlStmts.Label.AllForArticles = getArticlesLabels
+	sqlStmts.Label.GetArticleIDs = getLabelArticleIDs
+	sqlStmts.Label.GetFeedIDs = getLabelFeedIDs
+	sqlStmts.Label.Create = createLabel
+	sqlStmts.Label.Update = updateLabel
+	sqlStmts.Label.Delete = deleteLabel
+	sqlStmts.Label.CreateArticle = createArticleLabel
+	sqlStmts.Label.DeleteArticle = deleteArticleLabel
+}
+
+const (
+	getUserLabel = `
+SELECT l.id, l.user_login, l.caption, l.fg_color, l.bg_color
+FROM labels l
+WHERE l.id = :id AND l.user_login = :user_login
+`
+	getUserLabels = `
+SELECT l.id, l.user_login, l.caption, l.fg_color, l.bg_color
+FROM labels l
+WHERE l.user_login = :user_login
+ORDER BY LOWER(l.caption)
+`
+	getArticlesLabels = `
+SELECT al.article_id, l.id, l.user_login, l.caption, l.fg_color, l.bg_color
+FROM labels l INNER JOIN articles_labels al
+	ON l.id = al.label_id
+WHERE l.user_login = :user_login`
+	getLabelArticleIDs = `
+SELECT al.article_id
+FROM articles_labels al
+WHERE al.label_id = :id
+ORDER BY al.article_id
+`
+	// The feeds of the labeled articles that the user is still subscribed
+	// to.
+	getLabelFeedIDs = `
+SELECT DISTINCT a.feed_id
+FROM articles_labels al
+INNER JOIN labels l
+	ON l.id = al.label_id
+INNER JOIN articles a
+	ON a.id = al.article_id
+INNER JOIN users_feeds uf
+	ON uf.feed_id = a.feed_id AND uf.user_login = l.user_login
+WHERE al.label_id = :id
+ORDER BY a.feed_id
+`
+
+	createLabel = `
+INSERT INTO labels(user_login, caption, fg_color, bg_color)
+	VALUES(:user_login, :caption, :fg_color, :bg_color)`
+	updateLabel = `
+UPDATE labels SET caption = :caption, fg_color = :fg_color, bg_color = :bg_color
+WHERE id = :id AND user_login = :user_login`
+	deleteLabel = `DELETE FROM labels WHERE id = :id AND user_login = :user_login`
+
+	createArticleLabel = `
+INSERT INTO articles_labels(label_id, article_id)
+	SELECT :label_id, :article_id EXCEPT SELECT label_id, article_id
+		FROM articles_labels
+		WHERE label_id = :label_id AND article_id = :article_id
+`
+	deleteArticleLabel = `
+DELETE FROM articles_labels WHERE label_id = :label_id AND article_id = :article_id
+`
+)
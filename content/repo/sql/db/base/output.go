@@ -0,0 +1,26 @@
+package base
+
+func init() {
+	sqlStmts.OutputFeed.Get = getOutputFeed
+	sqlStmts.OutputFeed.AllForUser = getUserOutputFeeds
+	sqlStmts.OutputFeed.Create = createOutputFeed
+	sqlStmts.OutputFeed.Delete = deleteOutputFeed
+}
+
+const (
+	getOutputFeed = `
+SELECT o.token, o.user_login, o.kind, o.tag_id, o.feed_id, o.title, o.created_at
+FROM output_feeds o
+WHERE o.token = :token
+`
+	getUserOutputFeeds = `
+SELECT o.token, o.user_login, o.kind, o.tag_id, o.feed_id, o.title, o.created_at
+FROM output_feeds o
+WHERE o.user_login = :user_login
+ORDER BY o.created_at, o.token
+`
+	createOutputFeed = `
+INSERT INTO output_feeds(token, user_login, kind, tag_id, feed_id, title, created_at)
+	VALUES(:token, :user_login, :kind, :tag_id, :feed_id, :title, :created_at)`
+	deleteOutputFeed = `DELETE FROM output_feeds WHERE token = :token AND user_login = :user_login`
+)
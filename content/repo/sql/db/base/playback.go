@@ -0,0 +1,22 @@
+package base
+
+func init() {
+	sqlStmts.Playback.Get = getArticlePlayback
+	sqlStmts.Playback.Create = createArticlePlayback
+	sqlStmts.Playback.Update = updateArticlePlayback
+}
+
+const (
+	getArticlePlayback = `
+SELECT uap.position, uap.updated_at
+FROM users_articles_playback uap
+WHERE uap.user_login = :user_login AND uap.article_id = :article_id
+`
+	createArticlePlayback = `
+INSERT INTO users_articles_playback(user_login, article_id, position, updated_at)
+	VALUES(:user_login, :article_id, :position, :updated_at)
+`
+	updateArticlePlayback = `
+UPDATE users_articles_playback SET position = :position, updated_at = :updated_at
+WHERE user_login = :user_login AND article_id = :article_id`
+)
@@ -0,0 +1,31 @@
+package base
+
+func init() {
+	sqlStmts.Rule.Get = getUserRule
+	sqlStmts.Rule.AllForUser = getUserRules
+	sqlStmts.Rule.Create = createRule
+	sqlStmts.Rule.Update = updateRule
+	sqlStmts.Rule.Delete = deleteRule
+}
+
+const (
+	getUserRule = `
+SELECT r.id, r.user_login, r.name, r.match_data, r.action_data, r.active
+FROM rules r
+WHERE r.id = :id AND r.user_login = :user_login
+`
+	getUserRules = `
+SELECT r.id, r.user_login, r.name, r.match_data, r.action_data, r.active
+FROM rules r
+WHERE r.user_login = :user_login
+ORDER BY r.id
+`
+
+	createRule = `
+INSERT INTO rules(user_login, name, match_data, action_data, active)
+	VALUES(:user_login, :name, :match_data, :action_data, :active)`
+	updateRule = `
+UPDATE rules SET name = :name, match_data = :match_data, action_data = :action_data, active = :active
+WHERE id = :id AND user_login = :user_login`
+	deleteRule = `DELETE FROM rules WHERE id = :id AND user_login = :user_login`
+)
@@ -0,0 +1,73 @@
+package base
+
+func init() {
+	sqlStmts.Webhook.Get = getUserWebhook
+	sqlStmts.Webhook.AllForUser = getUserWebhooks
+	sqlStmts.Webhook.All = getWebhooks
+	sqlStmts.Webhook.Create = createWebhook
+	sqlStmts.Webhook.Update = updateWebhook
+	sqlStmts.Webhook.Delete = deleteWebhook
+
+	sqlStmts.Webhook.GetPendingDeliveries = getPendingDeliveries
+	sqlStmts.Webhook.GetDeliveries = getWebhookDeliveries
+	sqlStmts.Webhook.CreateDelivery = createDelivery
+	sqlStmts.Webhook.UpdateDelivery = updateDelivery
+	sqlStmts.Webhook.DeleteStaleDeliveries = deleteStaleDeliveries
+}
+
+const (
+	getUserWebhook = `
+SELECT w.id, w.user_login, w.url, w.filter_data, w.active
+FROM webhooks w
+WHERE w.id = :id AND w.user_login = :user_login
+`
+	getUserWebhooks = `
+SELECT w.id, w.user_login, w.url, w.filter_data, w.active
+FROM webhooks w
+WHERE w.user_login = :user_login
+ORDER BY w.id
+`
+	getWebhooks = `
+SELECT w.id, w.user_login, w.url, w.filter_data, w.active
+FROM webhooks w
+ORDER BY w.id
+`
+
+	createWebhook = `
+INSERT INTO webhooks(user_login, url, filter_data, active)
+	VALUES(:user_login, :url, :filter_data, :active)`
+	updateWebhook = `
+UPDATE webhooks SET url = :url, filter_data = :filter_data, active = :active
+WHERE id = :id AND user_login = :user_login`
+	deleteWebhook = `DELETE FROM webhooks WHERE id = :id AND user_login = :user_login`
+
+	getPendingDeliveries = `
+SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts,
+	d.response_code, d.error, d.created_at, d.next_attempt, d.updated_at
+FROM webhook_deliveries d
+WHERE d.status = 'pending' AND d.next_attempt <= :next_attempt
+ORDER BY d.next_attempt, d.id
+LIMIT :limit
+`
+	getWebhookDeliveries = `
+SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts,
+	d.response_code, d.error, d.created_at, d.next_attempt, d.updated_at
+FROM webhook_deliveries d
+WHERE d.webhook_id = :webhook_id
+ORDER BY d.id DESC
+LIMIT :limit
+`
+	createDelivery = `
+INSERT INTO webhook_deliveries(webhook_id, event, payload, status, attempts,
+	response_code, error, created_at, next_attempt, updated_at)
+	VALUES(:webhook_id, :event, :payload, :status, :attempts,
+	:response_code, :error, :created_at, :next_attempt, :updated_at)`
+	updateDelivery = `
+UPDATE webhook_deliveries SET status = :status, attempts = :attempts,
+	response_code = :response_code, error = :error,
+	next_attempt = :next_attempt, updated_at = :updated_at
+WHERE id = :id`
+	deleteStaleDeliveries = `
+DELETE FROM webhook_deliveries WHERE status != 'pending' AND updated_at < :updated_at
+`
+)
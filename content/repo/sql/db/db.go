@@ -28,7 +28,7 @@ type DB struct {
 }
 
 var (
-	dbVersion = 4
+	dbVersion = 24
 
 	helpers = make(map[string]Helper)
 )
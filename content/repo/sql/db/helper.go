@@ -24,15 +24,44 @@ type ArticleStmts struct {
 	StateReadColumn          string
 	StateUnreadJoin          string
 	StateFavoriteJoin        string
+	StateQueueJoin           string
 	GetIDsTemplate           string
 	DeleteStaleUnreadRecords string
 	GetScoreJoin             string
 	GetUntaggedJoin          string
+	StateChangedJoin         string
 
 	ReadStateInsertTemplate     string
 	ReadStateDeleteTemplate     string
 	FavoriteStateInsertTemplate string
 	FavoriteStateDeleteTemplate string
+	QueueStateInsertTemplate    string
+	QueueStateDeleteTemplate    string
+
+	StateChangedInsertTemplate string
+	StateChangedDeleteTemplate string
+	DeleteStaleStateChanges    string
+
+	GetQueueIDs         string
+	UpdateQueuePosition string
+
+	RecentlyReadInsertTemplate string
+	RecentlyReadDeleteTemplate string
+	GetRecentlyReadIDs         string
+	DeleteExpiredRecentlyRead  string
+
+	GetPublishedIDs string
+	CreatePublished string
+	DeletePublished string
+
+	CreateHidden string
+
+	GetExpired string
+	Delete     string
+
+	CreatePurged   string
+	GetPurgedLinks string
+	DeletePurged   string
 }
 
 type ExtractStmts struct {
@@ -50,16 +79,32 @@ type FeedStmts struct {
 	AllForTag    string
 	Unsubscribed string
 
-	IDs    string
-	Create string
-	Update string
-	Delete string
+	IDs            string
+	Create         string
+	Update         string
+	UpdateSchedule string
+	Delete         string
 
 	GetUsers       string
 	Attach         string
 	Detach         string
 	CreateUserTag  string
 	DeleteUserTags string
+
+	UpdateExtract       string
+	UpdateUserExtract   string
+	UpdateRetention     string
+	UpdateUserRetention string
+	UpdateCredentials   string
+	UpdateScraper       string
+}
+
+type RuleStmts struct {
+	Get        string
+	AllForUser string
+	Create     string
+	Update     string
+	Delete     string
 }
 
 type ScoresStmts struct {
@@ -86,6 +131,42 @@ type TagStmts struct {
 	DeleteStale    string
 }
 
+type AnnotationStmts struct {
+	Get            string
+	AllForUser     string
+	AllForArticles string
+	All            string
+	Create         string
+	Update         string
+	Delete         string
+}
+
+type LabelStmts struct {
+	Get            string
+	AllForUser     string
+	AllForArticles string
+	GetArticleIDs  string
+	GetFeedIDs     string
+	Create         string
+	Update         string
+	Delete         string
+	CreateArticle  string
+	DeleteArticle  string
+}
+
+type OutputFeedStmts struct {
+	Get        string
+	AllForUser string
+	Create     string
+	Delete     string
+}
+
+type PlaybackStmts struct {
+	Get    string
+	Create string
+	Update string
+}
+
 type ThumbnailStmts struct {
 	Get    string
 	Create string
@@ -93,24 +174,46 @@ type ThumbnailStmts struct {
 }
 
 type UserStmts struct {
-	Get         string
-	GetByMD5API string
-	All         string
+	Get           string
+	GetByMD5API   string
+	GetByAPIToken string
+	All           string
 
 	Create string
 	Update string
 	Delete string
 }
 
+type WebhookStmts struct {
+	Get        string
+	AllForUser string
+	All        string
+	Create     string
+	Update     string
+	Delete     string
+
+	GetPendingDeliveries  string
+	GetDeliveries         string
+	CreateDelivery        string
+	UpdateDelivery        string
+	DeleteStaleDeliveries string
+}
+
 type SqlStmts struct {
+	Annotation   AnnotationStmts
 	Article      ArticleStmts
 	Extract      ExtractStmts
 	Feed         FeedStmts
+	Label        LabelStmts
+	OutputFeed   OutputFeedStmts
+	Playback     PlaybackStmts
+	Rule         RuleStmts
 	Scores       ScoresStmts
 	Subscription SubscriptionStmts
 	Tag          TagStmts
 	Thumbnail    ThumbnailStmts
 	User         UserStmts
+	Webhook      WebhookStmts
 }
 
 func Register(driver string, helper Helper) {
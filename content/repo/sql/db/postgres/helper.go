@@ -49,6 +49,46 @@ func (h Helper) Upgrade(db *db.DB, old, new int) error {
 			err = upgrade2to3(db)
 		case 3:
 			err = upgrade3to4(db)
+		case 4:
+			err = upgrade4to5(db)
+		case 5:
+			err = upgrade5to6(db)
+		case 6:
+			err = upgrade6to7(db)
+		case 7:
+			err = upgrade7to8(db)
+		case 8:
+			err = upgrade8to9(db)
+		case 9:
+			err = upgrade9to10(db)
+		case 10:
+			err = upgrade10to11(db)
+		case 11:
+			err = upgrade11to12(db)
+		case 12:
+			err = upgrade12to13(db)
+		case 13:
+			err = upgrade13to14(db)
+		case 14:
+			err = upgrade14to15(db)
+		case 15:
+			err = upgrade15to16(db)
+		case 16:
+			err = upgrade16to17(db)
+		case 17:
+			err = upgrade17to18(db)
+		case 18:
+			err = upgrade18to19(db)
+		case 19:
+			err = upgrade19to20(db)
+		case 20:
+			err = upgrade20to21(db)
+		case 21:
+			err = upgrade21to22(db)
+		case 22:
+			err = upgrade22to23(db)
+		case 23:
+			err = upgrade23to24(db)
 		}
 
 		if err != nil {
@@ -177,6 +217,326 @@ func upgrade3to4(db *db.DB) error {
 	return tx.Commit()
 }
 
+func upgrade4to5(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(upgrade4To5FeedETag)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(upgrade4To5FeedLastModified)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade5to6(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade5To6FeedNextCheck, upgrade5To6FeedFailureCount, upgrade5To6FeedDead} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade6to7(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade6To7ArticleAuthor, upgrade6To7ArticleCategories, upgrade6To7ArticleEnclosures} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade7to8(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade7To8ArticleMedia, upgrade7To8CreateArticlePlayback} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade8to9(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade8To9FeedExtractContent, upgrade8To9UserFeedExtractContent} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade9to10(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade9To10FeedCredentials} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade10to11(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade10To11FeedScraper} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade11to12(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{
+		upgrade11To12CreateArticlePublished, upgrade11To12CreateArticleNotes,
+		upgrade11To12CreateLabels, upgrade11To12CreateArticleLabels,
+	} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade12to13(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade12To13CreateArticleRecentlyRead} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade13to14(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{
+		upgrade13To14CreateWebhooks, upgrade13To14CreateWebhookDeliveries,
+		upgrade13To14CreateWebhookDeliveriesIndex,
+	} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade14to15(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade14To15CreateOutputFeeds); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade15to16(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade15To16CreateAnnotations); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(upgrade15To16CreateAnnotationsIndex); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade16to17(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade16To17CreateUsersArticlesQueue); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade17to18(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade17To18FeedRetention, upgrade17To18UserFeedRetention} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade18to19(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade18To19CreateRules, upgrade18To19CreateUsersArticlesHidden} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade19to20(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade19To20UserAPIKeyRotated); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade20to21(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade20To21UserAPIToken); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade21to22(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sql := range []string{upgrade21To22MoveArticleNotes, upgrade21To22DropArticleNotes} {
+		if _, err = tx.Exec(sql); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func upgrade22to23(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade22To23CreateArticleStateChanges); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upgrade23to24(db *db.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(upgrade23To24CreateArticlesPurged); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func init() {
 	helper := &Helper{Helper: base.NewHelper()}
 
@@ -189,7 +549,8 @@ func init() {
 
 const (
 	getUserFeeds = `
-SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error
+SELECT f.id, f.link, f.title, f.description, f.link, f.hub_link, f.site_link, f.update_error, f.subscribe_error, f.etag, f.last_modified, f.next_check, f.failure_count, f.dead,
+	f.extract_content, f.credentials, f.scraper, f.retention, uf.extract_content AS user_extract_content, uf.retention AS user_retention
 FROM feeds f, users_feeds uf
 WHERE f.id = uf.feed_id
 	AND uf.user_login = :user_login
@@ -231,4 +592,212 @@ SELECT uft.user_login, uft.feed_id, t.id
 FROM tags t INNER JOIN users_feeds_tags2 uft
 	ON t.value = uft.tag
 `
+
+	upgrade4To5FeedETag         = `ALTER TABLE feeds ADD COLUMN etag TEXT DEFAULT ''`
+	upgrade4To5FeedLastModified = `ALTER TABLE feeds ADD COLUMN last_modified TEXT DEFAULT ''`
+
+	upgrade5To6FeedNextCheck    = `ALTER TABLE feeds ADD COLUMN next_check TIMESTAMP WITH TIME ZONE DEFAULT '1970-01-01 00:00:00+00'`
+	upgrade5To6FeedFailureCount = `ALTER TABLE feeds ADD COLUMN failure_count INTEGER DEFAULT 0`
+	upgrade5To6FeedDead         = `ALTER TABLE feeds ADD COLUMN dead BOOLEAN DEFAULT 'f'`
+
+	upgrade6To7ArticleAuthor     = `ALTER TABLE articles ADD COLUMN author TEXT DEFAULT ''`
+	upgrade6To7ArticleCategories = `ALTER TABLE articles ADD COLUMN categories TEXT DEFAULT ''`
+	upgrade6To7ArticleEnclosures = `ALTER TABLE articles ADD COLUMN enclosures TEXT DEFAULT ''`
+
+	upgrade7To8ArticleMedia          = `ALTER TABLE articles ADD COLUMN media TEXT`
+	upgrade7To8CreateArticlePlayback = `
+CREATE TABLE IF NOT EXISTS users_articles_playback (
+	user_login TEXT,
+	article_id BIGINT,
+	position DOUBLE PRECISION NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP WITH TIME ZONE,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+
+	upgrade8To9FeedExtractContent     = `ALTER TABLE feeds ADD COLUMN extract_content BOOLEAN DEFAULT 'f'`
+	upgrade8To9UserFeedExtractContent = `ALTER TABLE users_feeds ADD COLUMN extract_content BOOLEAN DEFAULT 'f'`
+
+	upgrade9To10FeedCredentials = `ALTER TABLE feeds ADD COLUMN credentials TEXT DEFAULT ''`
+
+	upgrade10To11FeedScraper = `ALTER TABLE feeds ADD COLUMN scraper TEXT`
+
+	upgrade11To12CreateArticlePublished = `
+CREATE TABLE IF NOT EXISTS users_articles_published (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+	upgrade11To12CreateArticleNotes = `
+CREATE TABLE IF NOT EXISTS users_articles_notes (
+	user_login TEXT,
+	article_id BIGINT,
+	note TEXT NOT NULL DEFAULT '',
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+	upgrade11To12CreateLabels = `
+CREATE TABLE IF NOT EXISTS labels (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	caption TEXT NOT NULL,
+	fg_color TEXT DEFAULT '',
+	bg_color TEXT DEFAULT '',
+
+	UNIQUE(user_login, caption),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`
+	upgrade11To12CreateArticleLabels = `
+CREATE TABLE IF NOT EXISTS articles_labels (
+	label_id INTEGER,
+	article_id BIGINT,
+
+	PRIMARY KEY(label_id, article_id),
+	FOREIGN KEY(label_id) REFERENCES labels(id) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+	upgrade12To13CreateArticleRecentlyRead = `
+CREATE TABLE IF NOT EXISTS users_articles_recently_read (
+	user_login TEXT,
+	article_id BIGINT,
+	read_at TIMESTAMP WITH TIME ZONE,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+	upgrade13To14CreateWebhooks = `
+CREATE TABLE IF NOT EXISTS webhooks (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	url TEXT NOT NULL,
+	filter_data TEXT DEFAULT '',
+	active BOOLEAN DEFAULT 't',
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`
+	upgrade13To14CreateWebhookDeliveries = `
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	webhook_id INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	payload TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER DEFAULT 0,
+	response_code INTEGER DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE,
+	next_attempt TIMESTAMP WITH TIME ZONE,
+	updated_at TIMESTAMP WITH TIME ZONE,
+
+	FOREIGN KEY(webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+)`
+	upgrade13To14CreateWebhookDeliveriesIndex = `
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (status, next_attempt);
+`
+	upgrade14To15CreateOutputFeeds = `
+CREATE TABLE IF NOT EXISTS output_feeds (
+	token TEXT PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	tag_id INTEGER DEFAULT 0,
+	feed_id INTEGER DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP WITH TIME ZONE,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`
+	upgrade15To16CreateAnnotations = `
+CREATE TABLE IF NOT EXISTS annotations (
+	id BIGSERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	article_id BIGINT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	quote TEXT NOT NULL DEFAULT '',
+	range_start INTEGER DEFAULT 0,
+	range_end INTEGER DEFAULT 0,
+	created_at TIMESTAMP WITH TIME ZONE,
+	updated_at TIMESTAMP WITH TIME ZONE,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+	upgrade15To16CreateAnnotationsIndex = `
+CREATE INDEX IF NOT EXISTS annotations_user_article_idx ON annotations (user_login, article_id);
+`
+	upgrade16To17CreateUsersArticlesQueue = `
+CREATE TABLE IF NOT EXISTS users_articles_queue (
+	user_login TEXT,
+	article_id BIGINT,
+	position BIGINT NOT NULL DEFAULT 0,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+`
+
+	upgrade17To18FeedRetention     = `ALTER TABLE feeds ADD COLUMN retention TEXT`
+	upgrade17To18UserFeedRetention = `ALTER TABLE users_feeds ADD COLUMN retention TEXT`
+
+	upgrade18To19CreateRules = `
+CREATE TABLE IF NOT EXISTS rules (
+	id SERIAL PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL DEFAULT '',
+	match_data TEXT DEFAULT '',
+	action_data TEXT DEFAULT '',
+	active BOOLEAN DEFAULT 't',
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`
+	upgrade18To19CreateUsersArticlesHidden = `
+CREATE TABLE IF NOT EXISTS users_articles_hidden (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+
+	upgrade19To20UserAPIKeyRotated = `ALTER TABLE users ADD COLUMN api_key_rotated BOOLEAN DEFAULT 'f'`
+	upgrade20To21UserAPIToken      = `ALTER TABLE users ADD COLUMN api_token BYTEA`
+
+	// The article notes are kept as annotations.
+	upgrade21To22MoveArticleNotes = `
+INSERT INTO annotations (user_login, article_id, note, created_at, updated_at)
+SELECT user_login, article_id, note, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM users_articles_notes
+WHERE note <> ''`
+	upgrade21To22DropArticleNotes = `DROP TABLE users_articles_notes`
+
+	upgrade22To23CreateArticleStateChanges = `
+CREATE TABLE IF NOT EXISTS users_articles_state_changes (
+	user_login TEXT,
+	article_id BIGINT,
+	changed_at TIMESTAMP WITH TIME ZONE,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`
+
+	// Purged articles are remembered while they are still in the feed, so
+	// that they are not created again.
+	upgrade23To24CreateArticlesPurged = `
+CREATE TABLE IF NOT EXISTS articles_purged (
+	feed_id BIGINT,
+	link TEXT,
+
+	PRIMARY KEY(feed_id, link),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`
 )
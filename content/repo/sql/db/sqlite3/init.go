@@ -18,7 +18,9 @@ CREATE TABLE IF NOT EXISTS users (
 	hash_type TEXT,
 	salt BLOB,
 	hash BLOB,
-	md5_api BLOB
+	md5_api BLOB,
+	api_key_rotated INTEGER DEFAULT 0,
+	api_token BLOB
 )`, `
 CREATE TABLE IF NOT EXISTS feeds (
 	id INTEGER PRIMARY KEY,
@@ -28,7 +30,16 @@ CREATE TABLE IF NOT EXISTS feeds (
 	hub_link TEXT,
 	site_link TEXT,
 	update_error TEXT,
-	subscribe_error TEXT
+	subscribe_error TEXT,
+	etag TEXT DEFAULT '',
+	last_modified TEXT DEFAULT '',
+	next_check TIMESTAMP DEFAULT '1970-01-01 00:00:00',
+	failure_count INTEGER DEFAULT 0,
+	dead INTEGER DEFAULT 0,
+	extract_content INTEGER DEFAULT 0,
+	credentials TEXT DEFAULT '',
+	scraper TEXT,
+	retention TEXT
 )`, `
 CREATE TABLE IF NOT EXISTS feed_images (
 	id INTEGER PRIMARY KEY,
@@ -48,6 +59,10 @@ CREATE TABLE IF NOT EXISTS articles (
 	title TEXT,
 	description TEXT,
 	date TIMESTAMP,
+	author TEXT DEFAULT '',
+	categories TEXT DEFAULT '',
+	enclosures TEXT DEFAULT '',
+	media TEXT,
 
 	UNIQUE(feed_id, link),
 	UNIQUE(feed_id, guid),
@@ -56,6 +71,8 @@ CREATE TABLE IF NOT EXISTS articles (
 CREATE TABLE IF NOT EXISTS users_feeds (
 	user_login TEXT,
 	feed_id INTEGER,
+	extract_content INTEGER DEFAULT 0,
+	retention TEXT,
 
 	PRIMARY KEY(user_login, feed_id),
 	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
@@ -75,6 +92,13 @@ CREATE TABLE IF NOT EXISTS users_feeds_tags (
 	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
 	FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS articles_purged (
+	feed_id BIGINT,
+	link TEXT,
+
+	PRIMARY KEY(feed_id, link),
+	FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS users_articles_unread (
 	user_login TEXT,
 	article_id BIGINT,
@@ -92,6 +116,136 @@ CREATE TABLE IF NOT EXISTS users_articles_favorite (
 	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
 	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
 )`, `
+CREATE TABLE IF NOT EXISTS users_articles_queue (
+	user_login TEXT,
+	article_id BIGINT,
+	position BIGINT NOT NULL DEFAULT 0,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_playback (
+	user_login TEXT,
+	article_id BIGINT,
+	position REAL NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_recently_read (
+	user_login TEXT,
+	article_id BIGINT,
+	read_at TIMESTAMP,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_state_changes (
+	user_login TEXT,
+	article_id BIGINT,
+	changed_at TIMESTAMP,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_published (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS users_articles_hidden (
+	user_login TEXT,
+	article_id BIGINT,
+
+	PRIMARY KEY(user_login, article_id),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS labels (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	caption TEXT NOT NULL,
+	fg_color TEXT DEFAULT '',
+	bg_color TEXT DEFAULT '',
+
+	UNIQUE(user_login, caption),
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS articles_labels (
+	label_id INTEGER,
+	article_id BIGINT,
+
+	PRIMARY KEY(label_id, article_id),
+	FOREIGN KEY(label_id) REFERENCES labels(id) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS webhooks (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	url TEXT NOT NULL,
+	filter_data TEXT DEFAULT '',
+	active INTEGER DEFAULT 1,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id INTEGER PRIMARY KEY,
+	webhook_id INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	payload TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER DEFAULT 0,
+	response_code INTEGER DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP,
+	next_attempt TIMESTAMP,
+	updated_at TIMESTAMP,
+
+	FOREIGN KEY(webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS rules (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	name TEXT NOT NULL DEFAULT '',
+	match_data TEXT DEFAULT '',
+	action_data TEXT DEFAULT '',
+	active INTEGER DEFAULT 1,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS output_feeds (
+	token TEXT PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	tag_id INTEGER DEFAULT 0,
+	feed_id INTEGER DEFAULT 0,
+	title TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE
+)`, `
+CREATE TABLE IF NOT EXISTS annotations (
+	id INTEGER PRIMARY KEY,
+	user_login TEXT NOT NULL,
+	article_id BIGINT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	quote TEXT NOT NULL DEFAULT '',
+	range_start INTEGER DEFAULT 0,
+	range_end INTEGER DEFAULT 0,
+	created_at TIMESTAMP,
+	updated_at TIMESTAMP,
+
+	FOREIGN KEY(user_login) REFERENCES users(login) ON DELETE CASCADE,
+	FOREIGN KEY(article_id) REFERENCES articles(id) ON DELETE CASCADE
+)`, `
 CREATE TABLE IF NOT EXISTS articles_scores (
 	article_id BIGINT,
 	score  INTEGER,
@@ -140,6 +294,10 @@ CREATE INDEX IF NOT EXISTS articles_title_idx ON articles (LOWER(title));
 CREATE INDEX IF NOT EXISTS articles_link_idx ON articles (LOWER(link));
 `, `
 CREATE INDEX IF NOT EXISTS articles_date_idx ON articles (date);
+`, `
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (status, next_attempt);
+`, `
+CREATE INDEX IF NOT EXISTS annotations_user_article_idx ON annotations (user_login, article_id);
 `,
 	}
 )
@@ -201,6 +201,22 @@ func (r feedRepo) Update(feed *content.Feed) ([]content.Article, error) {
 	return newArticles, nil
 }
 
+// UpdateSchedule stores only the update schedule data of the feed.
+func (r feedRepo) UpdateSchedule(feed content.Feed) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Updating feed %s schedule", feed)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateSchedule, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(feed); err != nil {
+			return errors.Wrap(err, "executing feed schedule update stmt")
+		}
+		return nil
+	})
+}
+
 // Delete deleted the feed from the database.
 func (r feedRepo) Delete(feed content.Feed) error {
 	if err := feed.Validate(); err != nil {
@@ -362,7 +378,29 @@ func (r feedRepo) SetUserTags(feed content.Feed, user content.User, tags []*cont
 func (r feedRepo) updateFeedArticles(feed content.Feed, tx *sqlx.Tx) ([]content.Article, error) {
 	articles := []content.Article{}
 
+	s := r.db.SQL()
+
+	// Articles that were purged are not created again while they are still
+	// in the feed.
+	var links []string
+	if err := r.db.WithNamedStmt(s.Article.GetPurgedLinks, tx, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&links, purgedArgs{FeedID: feed.ID})
+	}); err != nil {
+		return []content.Article{}, errors.Wrap(err, "getting purged feed article links")
+	}
+
+	purged := make(map[string]bool, len(links))
+	for _, l := range links {
+		purged[l] = true
+	}
+
+	present := map[string]bool{}
 	for _, a := range feed.ParsedArticles() {
+		present[a.Link] = true
+		if purged[a.Link] {
+			continue
+		}
+
 		a.FeedID = feed.ID
 
 		var err error
@@ -375,5 +413,158 @@ func (r feedRepo) updateFeedArticles(feed content.Feed, tx *sqlx.Tx) ([]content.
 		}
 	}
 
+	// Purged links that are no longer in the feed are forgotten.
+	if len(purged) > 0 {
+		if err := r.db.WithNamedStmt(s.Article.DeletePurged, tx, func(stmt *sqlx.NamedStmt) error {
+			for l := range purged {
+				if present[l] {
+					continue
+				}
+
+				if _, err := stmt.Exec(purgedArgs{FeedID: feed.ID, Link: l}); err != nil {
+					return errors.Wrap(err, "deleting purged feed article link")
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return []content.Article{}, err
+		}
+	}
+
 	return articles, nil
 }
+
+type userFeedExtract struct {
+	ID             content.FeedID `db:"id"`
+	UserLogin      content.Login  `db:"user_login"`
+	ExtractContent bool           `db:"extract_content"`
+}
+
+func (r feedRepo) SetExtract(feed content.Feed, extract bool) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Setting feed %s content extraction to %v", feed, extract)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateExtract, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(userFeedExtract{ID: feed.ID, ExtractContent: extract}); err != nil {
+			return errors.Wrapf(err, "updating feed %s content extraction", feed)
+		}
+
+		return nil
+	})
+}
+
+func (r feedRepo) SetUserExtract(feed content.Feed, user content.User, extract bool) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Setting feed %s user %s content extraction to %v", feed, user, extract)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateUserExtract, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(userFeedExtract{ID: feed.ID, UserLogin: user.Login, ExtractContent: extract})
+		if err != nil {
+			return errors.Wrapf(err, "updating feed %s user %s content extraction", feed, user)
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Errorf("feed %s does not belong to user %s", feed, user)
+		}
+
+		return nil
+	})
+}
+
+type userFeedRetention struct {
+	ID        content.FeedID    `db:"id"`
+	UserLogin content.Login     `db:"user_login"`
+	Retention content.Retention `db:"retention"`
+}
+
+func (r feedRepo) SetRetention(feed content.Feed, retention content.Retention) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	if err := retention.Validate(); err != nil {
+		return errors.WithMessage(err, "validating retention")
+	}
+
+	r.log.Infof("Setting feed %s retention to %+v", feed, retention)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateRetention, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(userFeedRetention{ID: feed.ID, Retention: retention}); err != nil {
+			return errors.Wrapf(err, "updating feed %s retention", feed)
+		}
+
+		return nil
+	})
+}
+
+func (r feedRepo) SetUserRetention(feed content.Feed, user content.User, retention content.Retention) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	if err := user.Validate(); err != nil {
+		return errors.WithMessage(err, "validating user")
+	}
+
+	if err := retention.Validate(); err != nil {
+		return errors.WithMessage(err, "validating retention")
+	}
+
+	r.log.Infof("Setting feed %s user %s retention to %+v", feed, user, retention)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateUserRetention, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(userFeedRetention{ID: feed.ID, UserLogin: user.Login, Retention: retention})
+		if err != nil {
+			return errors.Wrapf(err, "updating feed %s user %s retention", feed, user)
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Errorf("feed %s does not belong to user %s", feed, user)
+		}
+
+		return nil
+	})
+}
+
+func (r feedRepo) SetCredentials(feed content.Feed) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Setting feed %s credentials", feed)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateCredentials, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(feed); err != nil {
+			return errors.Wrapf(err, "updating feed %s credentials", feed)
+		}
+
+		return nil
+	})
+}
+
+func (r feedRepo) SetScraper(feed content.Feed) error {
+	if err := feed.Validate(); err != nil {
+		return errors.WithMessage(err, "validating feed")
+	}
+
+	r.log.Infof("Setting feed %s scraper", feed)
+
+	return r.db.WithNamedStmt(r.db.SQL().Feed.UpdateScraper, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(feed); err != nil {
+			return errors.Wrapf(err, "updating feed %s scraper", feed)
+		}
+
+		return nil
+	})
+}
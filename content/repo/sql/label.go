@@ -0,0 +1,228 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type labelRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type labelQuery struct {
+	ID        content.LabelID   `db:"id"`
+	UserLogin content.Login     `db:"user_login"`
+	LabelID   content.LabelID   `db:"label_id"`
+	ArticleID content.ArticleID `db:"article_id"`
+}
+
+type articleLabel struct {
+	content.Label
+	ArticleID content.ArticleID `db:"article_id"`
+}
+
+func (r labelRepo) Get(id content.LabelID, user content.User) (content.Label, error) {
+	if err := user.Validate(); err != nil {
+		return content.Label{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting label %d for %s", id, user)
+
+	var label content.Label
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&label, labelQuery{ID: id, UserLogin: user.Login})
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Label{}, errors.Wrapf(err, "getting label %d", id)
+	}
+
+	return label, nil
+}
+
+func (r labelRepo) ForUser(user content.User) ([]content.Label, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Label{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting labels for %s", user)
+
+	var labels []content.Label
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&labels, labelQuery{UserLogin: user.Login})
+	}); err != nil {
+		return []content.Label{}, errors.Wrapf(err, "getting user %s labels", user)
+	}
+
+	return labels, nil
+}
+
+func (r labelRepo) ForArticles(ids []content.ArticleID, user content.User) (map[content.ArticleID][]content.Label, error) {
+	labels := map[content.ArticleID][]content.Label{}
+
+	if err := user.Validate(); err != nil {
+		return labels, errors.WithMessage(err, "validating user")
+	}
+
+	if len(ids) == 0 {
+		return labels, nil
+	}
+
+	r.log.Infof("Getting labels of %d articles for %s", len(ids), user)
+
+	args := map[string]interface{}{userLogin: user.Login}
+	for i := range ids {
+		args[fmt.Sprintf("%s%d", idPrefix, i)] = ids[i]
+	}
+
+	sql := r.db.SQL().Label.AllForArticles + " AND " + r.db.WhereMultipleORs("al.article_id", idPrefix, len(ids), true)
+
+	var data []articleLabel
+	if err := r.db.WithNamedStmt(sql, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&data, args)
+	}); err != nil {
+		return labels, errors.Wrapf(err, "getting article labels for user %s", user)
+	}
+
+	for _, d := range data {
+		labels[d.ArticleID] = append(labels[d.ArticleID], d.Label)
+	}
+
+	return labels, nil
+}
+
+func (r labelRepo) ArticleIDs(label content.Label) ([]content.ArticleID, error) {
+	if err := label.Validate(); err != nil {
+		return []content.ArticleID{}, errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Getting label %s article ids", label)
+
+	var ids []content.ArticleID
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.GetArticleIDs, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&ids, labelQuery{ID: label.ID})
+	}); err != nil {
+		return []content.ArticleID{}, errors.Wrap(err, "getting label article ids")
+	}
+
+	return ids, nil
+}
+
+// FeedIDs returns the ids of the user feeds that have articles with the
+// label.
+func (r labelRepo) FeedIDs(label content.Label) ([]content.FeedID, error) {
+	if err := label.Validate(); err != nil {
+		return []content.FeedID{}, errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Getting label %s feed ids", label)
+
+	var ids []content.FeedID
+	if err := r.db.WithNamedStmt(r.db.SQL().Label.GetFeedIDs, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&ids, labelQuery{ID: label.ID})
+	}); err != nil {
+		return []content.FeedID{}, errors.Wrap(err, "getting label feed ids")
+	}
+
+	return ids, nil
+}
+
+func (r labelRepo) Create(label content.Label) (content.Label, error) {
+	if err := label.Validate(); err != nil {
+		return content.Label{}, errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Creating label %s", label)
+
+	err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		id, err := r.db.CreateWithID(tx, r.db.SQL().Label.Create, label)
+		if err != nil {
+			return errors.Wrap(err, "creating label")
+		}
+
+		label.ID = content.LabelID(id)
+
+		return nil
+	})
+
+	return label, err
+}
+
+func (r labelRepo) Update(label content.Label) error {
+	if err := label.Validate(); err != nil {
+		return errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Updating label %s", label)
+
+	return r.db.WithNamedStmt(r.db.SQL().Label.Update, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(label)
+		if err != nil {
+			return errors.Wrap(err, "executing label update stmt")
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Wrapf(content.ErrNoContent, "updating label %d", label.ID)
+		}
+
+		return nil
+	})
+}
+
+func (r labelRepo) Delete(label content.Label) error {
+	if err := label.Validate(); err != nil {
+		return errors.WithMessage(err, "validating label")
+	}
+
+	r.log.Infof("Deleting label %s", label)
+
+	return r.db.WithNamedStmt(r.db.SQL().Label.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(labelQuery{ID: label.ID, UserLogin: label.User}); err != nil {
+			return errors.Wrap(err, "executing label delete stmt")
+		}
+
+		return nil
+	})
+}
+
+func (r labelRepo) Assign(label content.Label, ids []content.ArticleID) error {
+	return r.setArticles(label, ids, r.db.SQL().Label.CreateArticle)
+}
+
+func (r labelRepo) Unassign(label content.Label, ids []content.ArticleID) error {
+	return r.setArticles(label, ids, r.db.SQL().Label.DeleteArticle)
+}
+
+func (r labelRepo) setArticles(label content.Label, ids []content.ArticleID, sql string) error {
+	if err := label.Validate(); err != nil {
+		return errors.WithMessage(err, "validating label")
+	}
+
+	if label.ID == 0 {
+		return content.NewValidationError(errors.New("label has no id"))
+	}
+
+	r.log.Infof("Changing label %s of %d articles", label, len(ids))
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		return r.db.WithNamedStmt(sql, tx, func(stmt *sqlx.NamedStmt) error {
+			for _, id := range ids {
+				if _, err := stmt.Exec(labelQuery{LabelID: label.ID, ArticleID: id}); err != nil {
+					return errors.Wrapf(err, "changing label of article %d", id)
+				}
+			}
+
+			return nil
+		})
+	})
+}
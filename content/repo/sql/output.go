@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type outputFeedRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type outputFeedQuery struct {
+	Token     string        `db:"token"`
+	UserLogin content.Login `db:"user_login"`
+}
+
+func (r outputFeedRepo) Get(token string) (content.OutputFeed, error) {
+	if token == "" {
+		return content.OutputFeed{}, content.NewValidationError(errors.New("no output feed token"))
+	}
+
+	r.log.Infoln("Getting output feed by token")
+
+	var output content.OutputFeed
+	if err := r.db.WithNamedStmt(r.db.SQL().OutputFeed.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&output, outputFeedQuery{Token: token})
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.OutputFeed{}, errors.Wrap(err, "getting output feed")
+	}
+
+	return output, nil
+}
+
+func (r outputFeedRepo) ForUser(user content.User) ([]content.OutputFeed, error) {
+	if err := user.Validate(); err != nil {
+		return []content.OutputFeed{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting output feeds for %s", user)
+
+	var outputs []content.OutputFeed
+	if err := r.db.WithNamedStmt(r.db.SQL().OutputFeed.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&outputs, outputFeedQuery{UserLogin: user.Login})
+	}); err != nil {
+		return []content.OutputFeed{}, errors.Wrapf(err, "getting user %s output feeds", user)
+	}
+
+	return outputs, nil
+}
+
+func (r outputFeedRepo) Create(output content.OutputFeed) (content.OutputFeed, error) {
+	if output.Token == "" {
+		if err := output.GenerateToken(); err != nil {
+			return content.OutputFeed{}, err
+		}
+	}
+
+	if err := output.Validate(); err != nil {
+		return content.OutputFeed{}, errors.WithMessage(err, "validating output feed")
+	}
+
+	if output.CreatedAt.IsZero() {
+		output.CreatedAt = time.Now()
+	}
+	output.CreatedAt = output.CreatedAt.UTC()
+
+	r.log.Infof("Creating output feed %s", output)
+
+	err := r.db.WithNamedStmt(r.db.SQL().OutputFeed.Create, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(output); err != nil {
+			return errors.Wrap(err, "executing output feed create stmt")
+		}
+
+		return nil
+	})
+
+	return output, err
+}
+
+func (r outputFeedRepo) Delete(output content.OutputFeed) error {
+	if err := output.Validate(); err != nil {
+		return errors.WithMessage(err, "validating output feed")
+	}
+
+	r.log.Infof("Deleting output feed %s", output)
+
+	return r.db.WithNamedStmt(r.db.SQL().OutputFeed.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(outputFeedQuery{Token: output.Token, UserLogin: output.User})
+		if err != nil {
+			return errors.Wrap(err, "executing output feed delete stmt")
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Wrap(content.ErrNoContent, "deleting output feed")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type playbackRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+func (r playbackRepo) Get(article content.Article, user content.User) (content.Playback, error) {
+	if err := article.Validate(); err != nil {
+		return content.Playback{}, errors.WithMessage(err, "validating article")
+	}
+
+	if err := user.Validate(); err != nil {
+		return content.Playback{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting playback of article %s for user %s", article, user)
+
+	playback := content.Playback{ArticleID: article.ID, User: user.Login}
+	if err := r.db.WithNamedStmt(r.db.SQL().Playback.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&playback, playback)
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Playback{}, errors.Wrapf(err, "getting playback of article %s for user %s", article, user)
+	}
+
+	return playback, nil
+}
+
+func (r playbackRepo) Update(playback content.Playback) error {
+	if err := playback.Validate(); err != nil {
+		return errors.WithMessage(err, "validating playback")
+	}
+
+	r.log.Infof("Updating playback %s", playback)
+
+	return r.db.WithTx(func(tx *sqlx.Tx) error {
+		s := r.db.SQL()
+		return r.db.WithNamedStmt(s.Playback.Update, tx, func(stmt *sqlx.NamedStmt) error {
+			res, err := stmt.Exec(playback)
+			if err != nil {
+				return errors.Wrap(err, "executing playback update stmt")
+			}
+
+			if num, err := res.RowsAffected(); err == nil && num > 0 {
+				return nil
+			}
+
+			return r.db.WithNamedStmt(s.Playback.Create, tx, func(stmt *sqlx.NamedStmt) error {
+				if _, err := stmt.Exec(playback); err != nil {
+					return errors.Wrap(err, "executing playback create stmt")
+				}
+
+				return nil
+			})
+		})
+	})
+}
@@ -0,0 +1,118 @@
+package sql
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type ruleRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type ruleQuery struct {
+	ID        content.RuleID `db:"id"`
+	UserLogin content.Login  `db:"user_login"`
+}
+
+func (r ruleRepo) Get(id content.RuleID, user content.User) (content.Rule, error) {
+	if err := user.Validate(); err != nil {
+		return content.Rule{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting rule %d for %s", id, user)
+
+	var rule content.Rule
+	if err := r.db.WithNamedStmt(r.db.SQL().Rule.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&rule, ruleQuery{ID: id, UserLogin: user.Login})
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Rule{}, errors.Wrapf(err, "getting rule %d", id)
+	}
+
+	return rule, nil
+}
+
+func (r ruleRepo) ForUser(user content.User) ([]content.Rule, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Rule{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting rules for %s", user)
+
+	var rules []content.Rule
+	if err := r.db.WithNamedStmt(r.db.SQL().Rule.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&rules, ruleQuery{UserLogin: user.Login})
+	}); err != nil {
+		return []content.Rule{}, errors.Wrapf(err, "getting user %s rules", user)
+	}
+
+	return rules, nil
+}
+
+func (r ruleRepo) Create(rule content.Rule) (content.Rule, error) {
+	if err := rule.Validate(); err != nil {
+		return content.Rule{}, errors.WithMessage(err, "validating rule")
+	}
+
+	r.log.Infof("Creating rule %s", rule)
+
+	err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		id, err := r.db.CreateWithID(tx, r.db.SQL().Rule.Create, rule)
+		if err != nil {
+			return errors.Wrap(err, "creating rule")
+		}
+
+		rule.ID = content.RuleID(id)
+
+		return nil
+	})
+
+	return rule, err
+}
+
+func (r ruleRepo) Update(rule content.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return errors.WithMessage(err, "validating rule")
+	}
+
+	r.log.Infof("Updating rule %s", rule)
+
+	return r.db.WithNamedStmt(r.db.SQL().Rule.Update, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(rule)
+		if err != nil {
+			return errors.Wrap(err, "executing rule update stmt")
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Wrapf(content.ErrNoContent, "updating rule %d", rule.ID)
+		}
+
+		return nil
+	})
+}
+
+func (r ruleRepo) Delete(rule content.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return errors.WithMessage(err, "validating rule")
+	}
+
+	r.log.Infof("Deleting rule %s", rule)
+
+	return r.db.WithNamedStmt(r.db.SQL().Rule.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(ruleQuery{ID: rule.ID, UserLogin: rule.User}); err != nil {
+			return errors.Wrap(err, "executing rule delete stmt")
+		}
+
+		return nil
+	})
+}
@@ -18,6 +18,12 @@ type Service struct {
 	extract      repo.Extract
 	scores       repo.Scores
 	thumbnail    repo.Thumbnail
+	playback     repo.Playback
+	label        repo.Label
+	webhook      repo.Webhook
+	rule         repo.Rule
+	outputFeed   repo.OutputFeed
+	annotation   repo.Annotation
 }
 
 func NewService(driver, source string, log log.Log) (Service, error) {
@@ -38,6 +44,12 @@ func NewService(driver, source string, log log.Log) (Service, error) {
 			extract:      extractRepo{db, log},
 			scores:       scoresRepo{db, log},
 			thumbnail:    thumbnailRepo{db, log},
+			playback:     playbackRepo{db, log},
+			label:        labelRepo{db, log},
+			webhook:      webhookRepo{db, log},
+			rule:         ruleRepo{db, log},
+			outputFeed:   outputFeedRepo{db, log},
+			annotation:   annotationRepo{db, log},
 		}, nil
 	default:
 		panic(fmt.Sprintf("Cannot provide a repo for driver '%s'\n", driver))
@@ -75,3 +87,27 @@ func (s Service) ScoresRepo() repo.Scores {
 func (s Service) ThumbnailRepo() repo.Thumbnail {
 	return s.thumbnail
 }
+
+func (s Service) PlaybackRepo() repo.Playback {
+	return s.playback
+}
+
+func (s Service) LabelRepo() repo.Label {
+	return s.label
+}
+
+func (s Service) WebhookRepo() repo.Webhook {
+	return s.webhook
+}
+
+func (s Service) RuleRepo() repo.Rule {
+	return s.rule
+}
+
+func (s Service) OutputFeedRepo() repo.OutputFeed {
+	return s.outputFeed
+}
+
+func (s Service) AnnotationRepo() repo.Annotation {
+	return s.annotation
+}
@@ -117,3 +117,28 @@ func (r userRepo) FindByMD5(hash []byte) (content.User, error) {
 
 	return user, nil
 }
+
+func (r userRepo) FindByAPIToken(hash []byte) (content.User, error) {
+	if len(hash) == 0 {
+		return content.User{}, errors.New("no hash")
+	}
+
+	r.log.Infof("Getting user using api token")
+
+	var user content.User
+	if err := r.db.WithNamedStmt(r.db.SQL().User.GetByAPIToken, nil, func(stmt *sqlx.NamedStmt) error {
+		if err := stmt.Get(&user, content.User{APIToken: hash}); err != nil {
+			if err == sql.ErrNoRows {
+				err = content.ErrNoContent
+			}
+
+			return errors.Wrap(err, "getting user by api token")
+		}
+
+		return nil
+	}); err != nil {
+		return content.User{}, err
+	}
+
+	return user, nil
+}
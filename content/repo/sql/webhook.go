@@ -0,0 +1,235 @@
+package sql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/sql/db"
+	"github.com/urandom/readeef/log"
+)
+
+type webhookRepo struct {
+	db *db.DB
+
+	log log.Log
+}
+
+type webhookQuery struct {
+	ID        content.WebhookID `db:"id"`
+	UserLogin content.Login     `db:"user_login"`
+	WebhookID content.WebhookID `db:"webhook_id"`
+
+	NextAttempt time.Time `db:"next_attempt"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	Limit       int       `db:"limit"`
+}
+
+func (r webhookRepo) Get(id content.WebhookID, user content.User) (content.Webhook, error) {
+	if err := user.Validate(); err != nil {
+		return content.Webhook{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting webhook %d for %s", id, user)
+
+	var webhook content.Webhook
+	if err := r.db.WithNamedStmt(r.db.SQL().Webhook.Get, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Get(&webhook, webhookQuery{ID: id, UserLogin: user.Login})
+	}); err != nil {
+		if err == sql.ErrNoRows {
+			err = content.ErrNoContent
+		}
+
+		return content.Webhook{}, errors.Wrapf(err, "getting webhook %d", id)
+	}
+
+	return webhook, nil
+}
+
+func (r webhookRepo) ForUser(user content.User) ([]content.Webhook, error) {
+	if err := user.Validate(); err != nil {
+		return []content.Webhook{}, errors.WithMessage(err, "validating user")
+	}
+
+	r.log.Infof("Getting webhooks for %s", user)
+
+	var webhooks []content.Webhook
+	if err := r.db.WithNamedStmt(r.db.SQL().Webhook.AllForUser, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&webhooks, webhookQuery{UserLogin: user.Login})
+	}); err != nil {
+		return []content.Webhook{}, errors.Wrapf(err, "getting user %s webhooks", user)
+	}
+
+	return webhooks, nil
+}
+
+func (r webhookRepo) All() ([]content.Webhook, error) {
+	r.log.Infoln("Getting all webhooks")
+
+	var webhooks []content.Webhook
+	if err := r.db.WithStmt(r.db.SQL().Webhook.All, nil, func(stmt *sqlx.Stmt) error {
+		return stmt.Select(&webhooks)
+	}); err != nil {
+		return []content.Webhook{}, errors.Wrap(err, "getting all webhooks")
+	}
+
+	return webhooks, nil
+}
+
+func (r webhookRepo) Create(webhook content.Webhook) (content.Webhook, error) {
+	if err := webhook.Validate(); err != nil {
+		return content.Webhook{}, errors.WithMessage(err, "validating webhook")
+	}
+
+	r.log.Infof("Creating webhook %s", webhook)
+
+	err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		id, err := r.db.CreateWithID(tx, r.db.SQL().Webhook.Create, webhook)
+		if err != nil {
+			return errors.Wrap(err, "creating webhook")
+		}
+
+		webhook.ID = content.WebhookID(id)
+
+		return nil
+	})
+
+	return webhook, err
+}
+
+func (r webhookRepo) Update(webhook content.Webhook) error {
+	if err := webhook.Validate(); err != nil {
+		return errors.WithMessage(err, "validating webhook")
+	}
+
+	r.log.Infof("Updating webhook %s", webhook)
+
+	return r.db.WithNamedStmt(r.db.SQL().Webhook.Update, nil, func(stmt *sqlx.NamedStmt) error {
+		res, err := stmt.Exec(webhook)
+		if err != nil {
+			return errors.Wrap(err, "executing webhook update stmt")
+		}
+
+		if num, err := res.RowsAffected(); err == nil && num == 0 {
+			return errors.Wrapf(content.ErrNoContent, "updating webhook %d", webhook.ID)
+		}
+
+		return nil
+	})
+}
+
+func (r webhookRepo) Delete(webhook content.Webhook) error {
+	if err := webhook.Validate(); err != nil {
+		return errors.WithMessage(err, "validating webhook")
+	}
+
+	r.log.Infof("Deleting webhook %s", webhook)
+
+	return r.db.WithNamedStmt(r.db.SQL().Webhook.Delete, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(webhookQuery{ID: webhook.ID, UserLogin: webhook.User}); err != nil {
+			return errors.Wrap(err, "executing webhook delete stmt")
+		}
+
+		return nil
+	})
+}
+
+func (r webhookRepo) Enqueue(delivery content.WebhookDelivery) (content.WebhookDelivery, error) {
+	if err := delivery.Validate(); err != nil {
+		return content.WebhookDelivery{}, errors.WithMessage(err, "validating delivery")
+	}
+
+	now := time.Now().UTC()
+	if delivery.Status == "" {
+		delivery.Status = content.DeliveryPending
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	if delivery.NextAttempt.IsZero() {
+		delivery.NextAttempt = now
+	}
+	delivery.UpdatedAt = now
+
+	r.log.Infof("Enqueuing webhook delivery %s", delivery)
+
+	err := r.db.WithTx(func(tx *sqlx.Tx) error {
+		id, err := r.db.CreateWithID(tx, r.db.SQL().Webhook.CreateDelivery, delivery)
+		if err != nil {
+			return errors.Wrap(err, "creating webhook delivery")
+		}
+
+		delivery.ID = content.WebhookDeliveryID(id)
+
+		return nil
+	})
+
+	return delivery, err
+}
+
+func (r webhookRepo) Pending(before time.Time, limit int) ([]content.WebhookDelivery, error) {
+	r.log.Infof("Getting pending webhook deliveries before %s", before)
+
+	var deliveries []content.WebhookDelivery
+	if err := r.db.WithNamedStmt(r.db.SQL().Webhook.GetPendingDeliveries, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&deliveries, webhookQuery{NextAttempt: before.UTC(), Limit: limit})
+	}); err != nil {
+		return []content.WebhookDelivery{}, errors.Wrap(err, "getting pending webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (r webhookRepo) UpdateDelivery(delivery content.WebhookDelivery) error {
+	if err := delivery.Validate(); err != nil {
+		return errors.WithMessage(err, "validating delivery")
+	}
+
+	if delivery.ID == 0 {
+		return content.NewValidationError(errors.New("delivery has no id"))
+	}
+
+	delivery.NextAttempt = delivery.NextAttempt.UTC()
+	delivery.UpdatedAt = time.Now().UTC()
+
+	r.log.Infof("Updating webhook delivery %s", delivery)
+
+	return r.db.WithNamedStmt(r.db.SQL().Webhook.UpdateDelivery, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(delivery); err != nil {
+			return errors.Wrap(err, "executing webhook delivery update stmt")
+		}
+
+		return nil
+	})
+}
+
+func (r webhookRepo) Deliveries(webhook content.Webhook, limit int) ([]content.WebhookDelivery, error) {
+	if err := webhook.Validate(); err != nil {
+		return []content.WebhookDelivery{}, errors.WithMessage(err, "validating webhook")
+	}
+
+	r.log.Infof("Getting webhook %s deliveries", webhook)
+
+	var deliveries []content.WebhookDelivery
+	if err := r.db.WithNamedStmt(r.db.SQL().Webhook.GetDeliveries, nil, func(stmt *sqlx.NamedStmt) error {
+		return stmt.Select(&deliveries, webhookQuery{WebhookID: webhook.ID, Limit: limit})
+	}); err != nil {
+		return []content.WebhookDelivery{}, errors.Wrap(err, "getting webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (r webhookRepo) DeleteStaleDeliveries(before time.Time) error {
+	r.log.Infof("Deleting webhook deliveries finished before %s", before)
+
+	return r.db.WithNamedStmt(r.db.SQL().Webhook.DeleteStaleDeliveries, nil, func(stmt *sqlx.NamedStmt) error {
+		if _, err := stmt.Exec(webhookQuery{UpdatedAt: before.UTC()}); err != nil {
+			return errors.Wrap(err, "executing stale webhook delivery delete stmt")
+		}
+
+		return nil
+	})
+}
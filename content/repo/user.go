@@ -10,4 +10,5 @@ type User interface {
 	Delete(content.User) error
 
 	FindByMD5([]byte) (content.User, error)
+	FindByAPIToken([]byte) (content.User, error)
 }
@@ -107,6 +107,39 @@ func Test_userRepo_Delete(t *testing.T) {
 	}
 }
 
+func Test_userRepo_FindByAPIToken(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.UserRepo()
+	u, err := r.Get(user2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := u.RotateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.Update(u); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.FindByAPIToken(content.APITokenHash(key))
+	if err != nil {
+		t.Fatalf("userRepo.FindByAPIToken() error = %v", err)
+	}
+
+	if got.Login != user2 || !got.APIKeyRotated || !got.AuthenticateAPIKey(key) {
+		t.Errorf("userRepo.FindByAPIToken() = %#v", got)
+	}
+
+	if _, err = r.FindByAPIToken(content.APITokenHash("invalid")); !content.IsNoContent(err) {
+		t.Errorf("userRepo.FindByAPIToken() expected no content error, got %v", err)
+	}
+}
+
 func createUser(login content.Login) {
 	r := service.UserRepo()
 	u := content.User{Login: login}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// Webhook allows fetching and manipulating content.Webhook objects, as
+// well as their queue of content.WebhookDelivery objects.
+type Webhook interface {
+	Get(content.WebhookID, content.User) (content.Webhook, error)
+	ForUser(content.User) ([]content.Webhook, error)
+	All() ([]content.Webhook, error)
+
+	Create(content.Webhook) (content.Webhook, error)
+	Update(content.Webhook) error
+	Delete(content.Webhook) error
+
+	Enqueue(content.WebhookDelivery) (content.WebhookDelivery, error)
+	Pending(time.Time, int) ([]content.WebhookDelivery, error)
+	UpdateDelivery(content.WebhookDelivery) error
+	Deliveries(content.Webhook, int) ([]content.WebhookDelivery, error)
+	DeleteStaleDeliveries(time.Time) error
+}
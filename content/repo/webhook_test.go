@@ -0,0 +1,195 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_webhookRepo_Create(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	tests := []struct {
+		name    string
+		webhook content.Webhook
+		wantErr bool
+	}{
+		{"valid", content.Webhook{User: user1, URL: "http://example.com/hook", Active: true}, false},
+		{"filter", content.Webhook{User: user1, URL: "https://example.com/hook", Filter: content.WebhookFilter{
+			Events: []string{"feed-update"}, FeedIDs: []content.FeedID{1, 2}, TagIDs: []content.TagID{3},
+		}}, false},
+		{"invalid url", content.Webhook{User: user1, URL: "example.com/hook"}, true},
+		{"no user", content.Webhook{URL: "http://example.com/hook"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := service.WebhookRepo()
+			got, err := r.Create(tt.webhook)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webhookRepo.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.ID == 0 {
+				t.Errorf("webhookRepo.Create() webhook has no id")
+				return
+			}
+
+			fetched, err := r.Get(got.ID, content.User{Login: tt.webhook.User})
+			if err != nil {
+				t.Errorf("webhookRepo.Create() post fetch error = %v", err)
+				return
+			}
+
+			if fetched.String() != got.String() || fetched.Active != got.Active ||
+				len(fetched.Filter.FeedIDs) != len(got.Filter.FeedIDs) ||
+				len(fetched.Filter.TagIDs) != len(got.Filter.TagIDs) ||
+				len(fetched.Filter.Events) != len(got.Filter.Events) {
+				t.Errorf("webhookRepo.Create() post fetch = %#v, want %#v", fetched, got)
+			}
+
+			if _, err := r.Get(got.ID, content.User{Login: user2}); !content.IsNoContent(err) {
+				t.Errorf("webhookRepo.Create() got webhook of another user, error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_webhookRepo_Update(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.WebhookRepo()
+	user := content.User{Login: user2}
+
+	webhook, err := r.Create(content.Webhook{User: user2, URL: "http://example.com/update", Active: true})
+	if err != nil {
+		t.Fatalf("webhookRepo.Create() error = %v", err)
+	}
+
+	webhook.URL = "http://example.com/updated"
+	webhook.Active = false
+	webhook.Filter.Events = []string{"feed-delete"}
+	if err := r.Update(webhook); err != nil {
+		t.Fatalf("webhookRepo.Update() error = %v", err)
+	}
+
+	got, err := r.Get(webhook.ID, user)
+	if err != nil {
+		t.Fatalf("webhookRepo.Get() error = %v", err)
+	}
+
+	if got.URL != webhook.URL || got.Active || len(got.Filter.Events) != 1 {
+		t.Errorf("webhookRepo.Update() = %#v, want %#v", got, webhook)
+	}
+
+	other := webhook
+	other.User = user1
+	if err := r.Update(other); !content.IsNoContent(err) {
+		t.Errorf("webhookRepo.Update() updated webhook of another user, error = %v", err)
+	}
+
+	if err := r.Delete(webhook); err != nil {
+		t.Fatalf("webhookRepo.Delete() error = %v", err)
+	}
+
+	if _, err := r.Get(webhook.ID, user); !content.IsNoContent(err) {
+		t.Errorf("webhookRepo.Delete() webhook still exists, error = %v", err)
+	}
+}
+
+func Test_webhookRepo_Deliveries(t *testing.T) {
+	skipTest(t)
+	setupUser()
+
+	r := service.WebhookRepo()
+
+	webhook, err := r.Create(content.Webhook{User: user1, URL: "http://example.com/deliveries", Active: true})
+	if err != nil {
+		t.Fatalf("webhookRepo.Create() error = %v", err)
+	}
+
+	now := time.Now()
+	first, err := r.Enqueue(content.WebhookDelivery{WebhookID: webhook.ID, Event: "feed-update", Payload: "{}"})
+	if err != nil {
+		t.Fatalf("webhookRepo.Enqueue() error = %v", err)
+	}
+
+	if first.ID == 0 || first.Status != content.DeliveryPending {
+		t.Errorf("webhookRepo.Enqueue() = %#v", first)
+	}
+
+	second, err := r.Enqueue(content.WebhookDelivery{
+		WebhookID: webhook.ID, Event: "feed-delete", NextAttempt: now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("webhookRepo.Enqueue() error = %v", err)
+	}
+
+	if _, err := r.Enqueue(content.WebhookDelivery{WebhookID: webhook.ID}); err == nil {
+		t.Errorf("webhookRepo.Enqueue() expected error for a delivery without an event")
+	}
+
+	pending, err := r.Pending(now.Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("webhookRepo.Pending() error = %v", err)
+	}
+
+	if !hasDelivery(pending, first.ID) || hasDelivery(pending, second.ID) {
+		t.Errorf("webhookRepo.Pending() = %v, want %d only", pending, first.ID)
+	}
+
+	first.Status = content.DeliveryDelivered
+	first.Attempts = 1
+	first.ResponseCode = 200
+	if err := r.UpdateDelivery(first); err != nil {
+		t.Fatalf("webhookRepo.UpdateDelivery() error = %v", err)
+	}
+
+	pending, err = r.Pending(now.Add(2*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("webhookRepo.Pending() error = %v", err)
+	}
+
+	if hasDelivery(pending, first.ID) || !hasDelivery(pending, second.ID) {
+		t.Errorf("webhookRepo.Pending() = %v, want %d only", pending, second.ID)
+	}
+
+	history, err := r.Deliveries(webhook, 10)
+	if err != nil {
+		t.Fatalf("webhookRepo.Deliveries() error = %v", err)
+	}
+
+	if len(history) != 2 || history[0].ID != second.ID || history[1].Status != content.DeliveryDelivered {
+		t.Errorf("webhookRepo.Deliveries() = %v", history)
+	}
+
+	if err := r.DeleteStaleDeliveries(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("webhookRepo.DeleteStaleDeliveries() error = %v", err)
+	}
+
+	history, err = r.Deliveries(webhook, 10)
+	if err != nil {
+		t.Fatalf("webhookRepo.Deliveries() error = %v", err)
+	}
+
+	if len(history) != 1 || history[0].ID != second.ID {
+		t.Errorf("webhookRepo.DeleteStaleDeliveries() left %v, want the pending delivery", history)
+	}
+}
+
+func hasDelivery(deliveries []content.WebhookDelivery, id content.WebhookDeliveryID) bool {
+	for _, d := range deliveries {
+		if d.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// Retention limits the number of stored articles of a feed. Only the
+// MaxArticles newest articles are kept, and articles older than MaxAge are
+// dropped. A zero limit is not enforced.
+type Retention struct {
+	MaxArticles int           `json:"maxArticles,omitempty"`
+	MaxAge      time.Duration `json:"maxAge,omitempty"`
+}
+
+func (r Retention) Validate() error {
+	if r.MaxArticles < 0 {
+		return NewValidationError(errors.New("negative max articles"))
+	}
+
+	if r.MaxAge < 0 {
+		return NewValidationError(errors.New("negative max age"))
+	}
+
+	return nil
+}
+
+// Empty returns true when neither limit is set.
+func (r Retention) Empty() bool {
+	return r == Retention{}
+}
+
+// Or returns the retention, or the fallback if it is empty.
+func (r Retention) Or(fallback Retention) Retention {
+	if r.Empty() {
+		return fallback
+	}
+
+	return r
+}
+
+func (r *Retention) Scan(src interface{}) error {
+	return scanJSON(src, r, "Retention")
+}
+
+// Value stores an empty retention as NULL.
+func (r Retention) Value() (driver.Value, error) {
+	if r.Empty() {
+		return nil, nil
+	}
+
+	return valueJSON(1, r)
+}
@@ -0,0 +1,174 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo"
+	"github.com/urandom/readeef/content/search"
+	"github.com/urandom/readeef/log"
+)
+
+// Expired holds the articles of a feed that fall outside of its retention.
+type Expired struct {
+	Feed     content.Feed
+	Articles []content.Article
+}
+
+// Find returns the expired articles of every feed. The retention of a feed
+// is resolved for each of its subscribers, falling back from the user's
+// subscription to the feed and then to the global retention. Since the
+// articles are shared, only the ones expired for every subscriber are
+// returned.
+func Find(service repo.Service, global content.Retention, log log.Log) ([]Expired, error) {
+	feedRepo := service.FeedRepo()
+
+	feeds, err := feedRepo.All()
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting all feeds")
+	}
+
+	users, err := service.UserRepo().All()
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting all users")
+	}
+
+	subscriptions := map[content.FeedID][]content.Retention{}
+	for _, user := range users {
+		userFeeds, err := feedRepo.ForUser(user)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("getting user %s feeds", user))
+		}
+
+		for _, f := range userFeeds {
+			subscriptions[f.ID] = append(
+				subscriptions[f.ID], f.UserRetention.Or(f.Retention.Or(global)),
+			)
+		}
+	}
+
+	articleRepo := service.ArticleRepo()
+
+	expired := []Expired{}
+	for _, feed := range feeds {
+		policies, ok := subscriptions[feed.ID]
+		if !ok {
+			policies = []content.Retention{feed.Retention.Or(global)}
+		}
+
+		articles, err := expiredForAll(articleRepo, feed, policies)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(articles) > 0 {
+			log.Debugf("Feed %s has %d expired articles", feed, len(articles))
+			expired = append(expired, Expired{Feed: feed, Articles: articles})
+		}
+	}
+
+	return expired, nil
+}
+
+// Purge deletes the expired articles of every feed, removing them from the
+// search index as well, if a provider is given. It returns the number of
+// deleted articles.
+func Purge(service repo.Service, global content.Retention, provider search.Provider, log log.Log) (int, error) {
+	expired, err := Find(service, global, log)
+	if err != nil {
+		return 0, errors.WithMessage(err, "finding expired articles")
+	}
+
+	count := 0
+	for _, e := range expired {
+		log.Infof("Purging %d articles of feed %s", len(e.Articles), e.Feed)
+
+		if err := service.ArticleRepo().Delete(e.Articles); err != nil {
+			return count, errors.WithMessage(err, fmt.Sprintf("deleting feed %s articles", e.Feed))
+		}
+
+		count += len(e.Articles)
+
+		if provider != nil {
+			if err := provider.BatchIndex(e.Articles, search.BatchDelete); err != nil {
+				log.Printf("Error removing feed %s articles from the search index: %+v", e.Feed, err)
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// Schedule purges the expired articles every interval, until the context is
+// canceled.
+func Schedule(
+	ctx context.Context,
+	service repo.Service,
+	global content.Retention,
+	provider search.Provider,
+	interval time.Duration,
+	log log.Log,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if count, err := Purge(service, global, provider, log); err != nil {
+				log.Printf("Error purging expired articles: %+v", err)
+			} else if count > 0 {
+				log.Infof("Purged %d expired articles", count)
+			}
+		}
+	}
+}
+
+// expiredForAll returns the feed articles expired under every policy. An
+// empty policy keeps all articles.
+func expiredForAll(repo repo.Article, feed content.Feed, policies []content.Retention) ([]content.Article, error) {
+	for _, p := range policies {
+		if p.Empty() {
+			return nil, nil
+		}
+	}
+
+	var expired []content.Article
+	seen := map[content.Retention]bool{}
+	for _, p := range policies {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		articles, err := repo.Expired(feed, p)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("getting feed %s expired articles", feed))
+		}
+
+		if len(seen) == 1 {
+			expired = articles
+			continue
+		}
+
+		ids := map[content.ArticleID]bool{}
+		for _, a := range articles {
+			ids[a.ID] = true
+		}
+
+		remaining := expired[:0]
+		for _, a := range expired {
+			if ids[a.ID] {
+				remaining = append(remaining, a)
+			}
+		}
+		expired = remaining
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,166 @@
+package retention
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/content/repo/mock_repo"
+	"github.com/urandom/readeef/log"
+)
+
+var logger log.Log
+
+func TestFind(t *testing.T) {
+	global := content.Retention{MaxAge: 24 * time.Hour}
+	feedRetention := content.Retention{MaxArticles: 10}
+	userRetention := content.Retention{MaxArticles: 20}
+
+	articles := func(ids ...content.ArticleID) []content.Article {
+		a := make([]content.Article, len(ids))
+		for i := range ids {
+			a[i] = content.Article{ID: ids[i]}
+		}
+		return a
+	}
+
+	tests := []struct {
+		name      string
+		noGlobal  bool
+		feed      content.Feed
+		userFeeds map[content.Login]content.Feed
+		expired   map[content.Retention][]content.Article
+		want      []content.ArticleID
+		wantErr   bool
+	}{
+		{
+			name:    "unsubscribed global",
+			feed:    content.Feed{ID: 1},
+			expired: map[content.Retention][]content.Article{global: articles(1, 2)},
+			want:    []content.ArticleID{1, 2},
+		},
+		{
+			name:    "unsubscribed feed",
+			feed:    content.Feed{ID: 1, Retention: feedRetention},
+			expired: map[content.Retention][]content.Article{feedRetention: articles(3)},
+			want:    []content.ArticleID{3},
+		},
+		{
+			name: "subscribed",
+			feed: content.Feed{ID: 1, Retention: feedRetention},
+			userFeeds: map[content.Login]content.Feed{
+				"user1": {ID: 1, Retention: feedRetention},
+				"user2": {ID: 1, Retention: feedRetention, UserRetention: userRetention},
+			},
+			expired: map[content.Retention][]content.Article{
+				feedRetention: articles(1, 2, 3),
+				userRetention: articles(2, 3, 4),
+			},
+			want: []content.ArticleID{2, 3},
+		},
+		{
+			name: "subscribed global",
+			feed: content.Feed{ID: 1},
+			userFeeds: map[content.Login]content.Feed{
+				"user1": {ID: 1},
+				"user2": {ID: 1, UserRetention: userRetention},
+			},
+			expired: map[content.Retention][]content.Article{
+				global:        articles(5),
+				userRetention: articles(4),
+			},
+		},
+		{
+			name:     "subscribed keeping all",
+			noGlobal: true,
+			feed:     content.Feed{ID: 1},
+			userFeeds: map[content.Login]content.Feed{
+				"user1": {ID: 1},
+				"user2": {ID: 1, UserRetention: userRetention},
+			},
+		},
+		{
+			name:    "expired error",
+			feed:    content.Feed{ID: 1},
+			expired: map[content.Retention][]content.Article{global: nil},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_repo.NewMockService(ctrl)
+			feedRepo := mock_repo.NewMockFeed(ctrl)
+			userRepo := mock_repo.NewMockUser(ctrl)
+			articleRepo := mock_repo.NewMockArticle(ctrl)
+
+			service.EXPECT().FeedRepo().Return(feedRepo).AnyTimes()
+			service.EXPECT().UserRepo().Return(userRepo).AnyTimes()
+			service.EXPECT().ArticleRepo().Return(articleRepo).AnyTimes()
+
+			feedRepo.EXPECT().All().Return([]content.Feed{tt.feed}, nil)
+
+			users := []content.User{}
+			for _, login := range []content.Login{"user1", "user2"} {
+				user := content.User{Login: login}
+				users = append(users, user)
+
+				var feeds []content.Feed
+				if f, ok := tt.userFeeds[login]; ok {
+					feeds = append(feeds, f)
+				}
+				feedRepo.EXPECT().ForUser(user).Return(feeds, nil)
+			}
+			userRepo.EXPECT().All().Return(users, nil)
+
+			for r, a := range tt.expired {
+				var err error
+				if tt.wantErr {
+					err = errors.New("expired")
+				}
+				articleRepo.EXPECT().Expired(tt.feed, r).Return(a, err)
+			}
+
+			g := global
+			if tt.noGlobal {
+				g = content.Retention{}
+			}
+
+			got, err := Find(service, g, logger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Find() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var ids []content.ArticleID
+			for _, e := range got {
+				if e.Feed.ID != tt.feed.ID {
+					t.Errorf("Find() feed = %v, want %v", e.Feed, tt.feed)
+				}
+
+				for _, a := range e.Articles {
+					ids = append(ids, a.ID)
+				}
+			}
+
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Find() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func init() {
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+	cfg.Converted.Prefix = "[testing] "
+
+	logger = log.WithStd(cfg)
+}
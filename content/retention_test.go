@@ -0,0 +1,83 @@
+package content_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestRetention_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention content.Retention
+		wantErr   bool
+	}{
+		{"empty", content.Retention{}, false},
+		{"max articles", content.Retention{MaxArticles: 100}, false},
+		{"max age", content.Retention{MaxAge: time.Hour}, false},
+		{"both", content.Retention{MaxArticles: 100, MaxAge: time.Hour}, false},
+		{"negative max articles", content.Retention{MaxArticles: -1}, true},
+		{"negative max age", content.Retention{MaxAge: -time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.retention.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Retention.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetention_Or(t *testing.T) {
+	fallback := content.Retention{MaxArticles: 10}
+
+	tests := []struct {
+		name      string
+		retention content.Retention
+		want      content.Retention
+	}{
+		{"empty", content.Retention{}, fallback},
+		{"set", content.Retention{MaxAge: time.Hour}, content.Retention{MaxAge: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retention.Or(fallback); got != tt.want {
+				t.Errorf("Retention.Or() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetention_Value(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention content.Retention
+		want      interface{}
+	}{
+		{"empty", content.Retention{}, nil},
+		{"set", content.Retention{MaxArticles: 10, MaxAge: time.Second}, `{"maxArticles":10,"maxAge":1000000000}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.retention.Value()
+			if err != nil {
+				t.Fatalf("Retention.Value() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Retention.Value() = %v, want %v", got, tt.want)
+			}
+
+			var r content.Retention
+			if err := r.Scan(got); err != nil {
+				t.Fatalf("Retention.Scan() error = %v", err)
+			}
+
+			if r != tt.retention {
+				t.Errorf("Retention.Scan() = %v, want %v", r, tt.retention)
+			}
+		})
+	}
+}
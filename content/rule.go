@@ -0,0 +1,239 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RuleID int64
+
+// The article fields that a rule condition can be matched against.
+const (
+	RuleFieldTitle    = "title"
+	RuleFieldContent  = "content"
+	RuleFieldAuthor   = "author"
+	RuleFieldLink     = "link"
+	RuleFieldCategory = "category"
+)
+
+// RuleCondition matches an article field against a keyword, or a regular
+// expression. Keywords are matched case-insensitively anywhere in the field.
+type RuleCondition struct {
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Regex   bool   `json:"regex,omitempty"`
+	Inverse bool   `json:"inverse,omitempty"`
+}
+
+// RuleMatch selects the articles a rule applies to. The articles have to
+// belong to one of the feeds, or the feeds of the tags, if any are given,
+// and satisfy all of the conditions, or any of them if Any is set.
+type RuleMatch struct {
+	FeedIDs    []FeedID        `json:"feedIDs,omitempty"`
+	TagIDs     []TagID         `json:"tagIDs,omitempty"`
+	Conditions []RuleCondition `json:"conditions,omitempty"`
+	Any        bool            `json:"any,omitempty"`
+}
+
+// RuleActions are performed on the matching articles.
+type RuleActions struct {
+	Read     bool `json:"read,omitempty"`
+	Favorite bool `json:"favorite,omitempty"`
+	// LabelIDs are assigned to the articles.
+	LabelIDs []LabelID `json:"labelIDs,omitempty"`
+	// Delete hides the articles from the user.
+	Delete bool `json:"delete,omitempty"`
+	// PostURL receives the articles as a json payload.
+	PostURL string `json:"postURL,omitempty"`
+}
+
+// Rule is a user defined set of actions, performed on the new articles that
+// match it.
+type Rule struct {
+	ID      RuleID      `json:"id"`
+	User    Login       `db:"user_login" json:"-"`
+	Name    string      `json:"name"`
+	Match   RuleMatch   `db:"match_data" json:"match"`
+	Actions RuleActions `db:"action_data" json:"actions"`
+	Active  bool        `json:"active"`
+}
+
+type ruleMatcher struct {
+	field   string
+	value   string
+	re      *regexp.Regexp
+	inverse bool
+}
+
+func (r Rule) Validate() error {
+	if r.User == "" {
+		return NewValidationError(errors.New("Rule has no user"))
+	}
+
+	if len(r.Match.FeedIDs) == 0 && len(r.Match.TagIDs) == 0 && len(r.Match.Conditions) == 0 {
+		return NewValidationError(errors.New("Rule has no feeds, tags or conditions"))
+	}
+
+	if _, err := r.matchers(); err != nil {
+		return NewValidationError(err)
+	}
+
+	a := r.Actions
+	if !a.Read && !a.Favorite && !a.Delete && len(a.LabelIDs) == 0 && a.PostURL == "" {
+		return NewValidationError(errors.New("Rule has no actions"))
+	}
+
+	if a.PostURL != "" && !IsPublicURL(a.PostURL) {
+		return NewValidationError(errors.New("Rule has an invalid or non-public post url"))
+	}
+
+	return nil
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s:%d: %s", r.User, r.ID, r.Name)
+}
+
+// Matching returns the articles that match the rule. The tagFeeds hold the
+// ids of the feeds of the rule's tags.
+func (r Rule) Matching(articles []Article, tagFeeds []FeedID) ([]Article, error) {
+	matchers, err := r.matchers()
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := len(r.Match.FeedIDs) > 0 || len(r.Match.TagIDs) > 0
+
+	matching := []Article{}
+	for _, a := range articles {
+		if scoped && !containsFeedID(r.Match.FeedIDs, a.FeedID) && !containsFeedID(tagFeeds, a.FeedID) {
+			continue
+		}
+
+		if matchesAll(a, matchers, r.Match.Any) {
+			matching = append(matching, a)
+		}
+	}
+
+	return matching, nil
+}
+
+func (r Rule) matchers() ([]ruleMatcher, error) {
+	matchers := make([]ruleMatcher, len(r.Match.Conditions))
+
+	for i, c := range r.Match.Conditions {
+		switch c.Field {
+		case RuleFieldTitle, RuleFieldContent, RuleFieldAuthor, RuleFieldLink, RuleFieldCategory:
+		default:
+			return nil, fmt.Errorf("unknown rule condition field '%s'", c.Field)
+		}
+
+		if c.Value == "" {
+			return nil, fmt.Errorf("rule condition on %s has no value", c.Field)
+		}
+
+		m := ruleMatcher{field: c.Field, inverse: c.Inverse}
+		if c.Regex {
+			re, err := regexp.Compile(c.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule condition regex '%s': %v", c.Value, err)
+			}
+			m.re = re
+		} else {
+			m.value = strings.ToLower(c.Value)
+		}
+
+		matchers[i] = m
+	}
+
+	return matchers, nil
+}
+
+func matchesAll(a Article, matchers []ruleMatcher, any bool) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+
+	for _, m := range matchers {
+		if m.matches(a) == any {
+			return any
+		}
+	}
+
+	return !any
+}
+
+func (m ruleMatcher) matches(a Article) bool {
+	var values []string
+	switch m.field {
+	case RuleFieldTitle:
+		values = []string{a.Title}
+	case RuleFieldContent:
+		values = []string{a.Description}
+	case RuleFieldAuthor:
+		values = []string{a.Author}
+	case RuleFieldLink:
+		values = []string{a.Link}
+	case RuleFieldCategory:
+		values = a.Categories
+	}
+
+	found := false
+	for _, v := range values {
+		if m.re != nil {
+			found = m.re.MatchString(v)
+		} else {
+			found = strings.Contains(strings.ToLower(v), m.value)
+		}
+
+		if found {
+			break
+		}
+	}
+
+	return found != m.inverse
+}
+
+func containsFeedID(ids []FeedID, id FeedID) bool {
+	for i := range ids {
+		if ids[i] == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (val *RuleMatch) Scan(src interface{}) error {
+	return scanJSON(src, val, "RuleMatch")
+}
+
+func (val RuleMatch) Value() (driver.Value, error) {
+	return valueJSON(1, val)
+}
+
+func (val *RuleActions) Scan(src interface{}) error {
+	return scanJSON(src, val, "RuleActions")
+}
+
+func (val RuleActions) Value() (driver.Value, error) {
+	return valueJSON(1, val)
+}
+
+func (id *RuleID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (RuleID)", src, src)
+	}
+
+	*id = RuleID(asInt)
+
+	return nil
+}
+
+func (id RuleID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
@@ -0,0 +1,108 @@
+package content_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestRule_Validate(t *testing.T) {
+	title := []content.RuleCondition{{Field: content.RuleFieldTitle, Value: "go"}}
+	read := content.RuleActions{Read: true}
+
+	tests := []struct {
+		name    string
+		rule    content.Rule
+		wantErr bool
+	}{
+		{"valid", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: read}, false},
+		{"feed scope", content.Rule{User: "test", Match: content.RuleMatch{FeedIDs: []content.FeedID{1}}, Actions: read}, false},
+		{"no user", content.Rule{Match: content.RuleMatch{Conditions: title}, Actions: read}, true},
+		{"no match", content.Rule{User: "test", Actions: read}, true},
+		{"no actions", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}}, true},
+		{"unknown field", content.Rule{User: "test", Match: content.RuleMatch{Conditions: []content.RuleCondition{{Field: "guid", Value: "go"}}}, Actions: read}, true},
+		{"no value", content.Rule{User: "test", Match: content.RuleMatch{Conditions: []content.RuleCondition{{Field: content.RuleFieldTitle}}}, Actions: read}, true},
+		{"invalid regex", content.Rule{User: "test", Match: content.RuleMatch{Conditions: []content.RuleCondition{{Field: content.RuleFieldTitle, Value: "(go", Regex: true}}}, Actions: read}, true},
+		{"post url", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: content.RuleActions{PostURL: "https://example.com/rule"}}, false},
+		{"relative post url", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: content.RuleActions{PostURL: "/rule"}}, true},
+		{"loopback post url", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: content.RuleActions{PostURL: "http://127.0.0.1:8080/rule"}}, true},
+		{"private post url", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: content.RuleActions{PostURL: "http://192.168.1.1/rule"}}, true},
+		{"other scheme post url", content.Rule{User: "test", Match: content.RuleMatch{Conditions: title}, Actions: content.RuleActions{PostURL: "ftp://example.com/rule"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Rule.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRule_Matching(t *testing.T) {
+	articles := []content.Article{
+		{ID: 1, FeedID: 1, Title: "Go 1.13 released", Link: "https://golang.org/go1.13", Author: "gopher"},
+		{ID: 2, FeedID: 1, Title: "Rust news", Description: "Something about GO", Categories: content.Categories{"lang", "rust"}},
+		{ID: 3, FeedID: 2, Title: "Weather", Link: "https://example.com/weather", Categories: content.Categories{"news"}},
+		{ID: 4, FeedID: 3, Title: "Go tips", Author: "Someone"},
+	}
+
+	tests := []struct {
+		name     string
+		match    content.RuleMatch
+		tagFeeds []content.FeedID
+		want     []content.ArticleID
+		wantErr  bool
+	}{
+		{name: "feed", match: content.RuleMatch{FeedIDs: []content.FeedID{1}}, want: []content.ArticleID{1, 2}},
+		{name: "tag", match: content.RuleMatch{TagIDs: []content.TagID{1}}, tagFeeds: []content.FeedID{2, 3}, want: []content.ArticleID{3, 4}},
+		{name: "title keyword", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "go"},
+		}}, want: []content.ArticleID{1, 4}},
+		{name: "content keyword", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldContent, Value: "go"},
+		}}, want: []content.ArticleID{2}},
+		{name: "author regex", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldAuthor, Value: "^[A-Z]", Regex: true},
+		}}, want: []content.ArticleID{4}},
+		{name: "category", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldCategory, Value: "rust"},
+		}}, want: []content.ArticleID{2}},
+		{name: "inverse link", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldLink, Value: "example.com", Inverse: true},
+		}}, want: []content.ArticleID{1, 2, 4}},
+		{name: "all conditions", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "go"},
+			{Field: content.RuleFieldAuthor, Value: "gopher"},
+		}}, want: []content.ArticleID{1}},
+		{name: "any condition", match: content.RuleMatch{Any: true, Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "weather"},
+			{Field: content.RuleFieldAuthor, Value: "gopher"},
+		}}, want: []content.ArticleID{1, 3}},
+		{name: "scoped condition", match: content.RuleMatch{FeedIDs: []content.FeedID{3}, Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "go"},
+		}}, want: []content.ArticleID{4}},
+		{name: "invalid regex", match: content.RuleMatch{Conditions: []content.RuleCondition{
+			{Field: content.RuleFieldTitle, Value: "(go", Regex: true},
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := content.Rule{User: "test", Match: tt.match, Actions: content.RuleActions{Read: true}}
+
+			got, err := r.Matching(articles, tt.tagFeeds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rule.Matching() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var ids []content.ArticleID
+			for _, a := range got {
+				ids = append(ids, a.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Rule.Matching() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
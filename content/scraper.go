@@ -0,0 +1,47 @@
+package content
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// Scraper holds the css selectors used to generate a feed from an html page.
+// Every element matched by Item becomes an article. The rest of the
+// selectors are matched within the item.
+type Scraper struct {
+	Item  string `json:"item"`
+	Title string `json:"title"`
+	// Link defaults to the first anchor of the item.
+	Link string `json:"link,omitempty"`
+	// Date is optional. The text of the element is used, unless it has
+	// a datetime attribute.
+	Date string `json:"date,omitempty"`
+	// Content defaults to the whole item.
+	Content string `json:"content,omitempty"`
+}
+
+func (s Scraper) Validate() error {
+	if s.Item == "" {
+		return NewValidationError(errors.New("no item selector"))
+	}
+
+	if s.Title == "" {
+		return NewValidationError(errors.New("no title selector"))
+	}
+
+	return nil
+}
+
+func (val *Scraper) Scan(src interface{}) error {
+	return scanJSON(src, val, "Scraper")
+}
+
+// Value stores an empty scraper as NULL, so that it is scanned back as a nil
+// pointer.
+func (val Scraper) Value() (driver.Value, error) {
+	if val == (Scraper{}) {
+		return nil, nil
+	}
+
+	return valueJSON(1, val)
+}
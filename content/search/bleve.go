@@ -5,9 +5,11 @@ import (
 	"html"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/index/store/goleveldb"
 	"github.com/blevesearch/bleve/index/upsidedown"
 	"github.com/blevesearch/bleve/mapping"
@@ -36,6 +38,21 @@ type indexArticle struct {
 	Date        time.Time `json:"date"`
 }
 
+type indexAnnotation struct {
+	Kind      string    `json:"kind"`
+	User      string    `json:"user"`
+	FeedID    int64     `json:"feed_id"`
+	ArticleID int64     `json:"article_id"`
+	Note      string    `json:"note"`
+	Quote     string    `json:"quote"`
+	Date      time.Time `json:"date"`
+}
+
+const (
+	annotationKind     = "annotation"
+	annotationIDPrefix = "annotation-"
+)
+
 func NewBleve(path string, size int64, service repo.Service, log log.Log) (bleveSearch, error) {
 	var err error
 	var exists bool
@@ -60,6 +77,12 @@ func NewBleve(path string, size int64, service repo.Service, log log.Log) (bleve
 		docMapping.AddFieldMappingsAt("feed_id", idfieldmapping)
 		docMapping.AddFieldMappingsAt("article_id", idfieldmapping)
 
+		keywordfieldmapping := mapping.NewTextFieldMapping()
+		keywordfieldmapping.Analyzer = keyword.Name
+		keywordfieldmapping.IncludeInAll = false
+		docMapping.AddFieldMappingsAt("kind", keywordfieldmapping)
+		docMapping.AddFieldMappingsAt("user", keywordfieldmapping)
+
 		m.AddDocumentMapping(m.DefaultType, docMapping)
 
 		log.Infoln("Creating search index " + path)
@@ -136,13 +159,28 @@ func (b bleveSearch) Search(
 		conjunct = append(conjunct, q)
 	}
 
+	// Annotations are private, so those of other users are excluded
+	kind := query.NewTermQuery(annotationKind)
+	kind.SetField("kind")
+
+	owner := query.NewMatchQuery(string(u.Login))
+	owner.SetField("user")
+	owner.SetOperator(query.MatchQueryOperatorAnd)
+
+	conjunct = append(conjunct, query.NewBooleanQuery(nil, nil, []query.Query{
+		query.NewBooleanQuery([]query.Query{kind}, nil, []query.Query{owner}),
+	}))
+
 	q = query.NewConjunctionQuery(conjunct)
 
 	searchRequest := bleve.NewSearchRequest(q)
 
+	searchRequest.Fields = []string{"article_id", "user"}
 	searchRequest.Highlight = bleve.NewHighlightWithStyle("html")
 	searchRequest.Highlight.AddField("title")
 	searchRequest.Highlight.AddField("description")
+	searchRequest.Highlight.AddField("note")
+	searchRequest.Highlight.AddField("quote")
 
 	searchRequest.Size = o.Limit
 
@@ -179,11 +217,36 @@ func (b bleveSearch) Search(
 	hitMap := map[content.ArticleID]*search.DocumentMatch{}
 
 	for _, hit := range searchResult.Hits {
-		if articleID, err := strconv.ParseInt(hit.ID, 10, 64); err == nil {
-			id := content.ArticleID(articleID)
-			articleIDs = append(articleIDs, id)
-			hitMap[id] = hit
+		var id content.ArticleID
+
+		if strings.HasPrefix(hit.ID, annotationIDPrefix) {
+			user, _ := hit.Fields["user"].(string)
+			articleID, ok := hit.Fields["article_id"].(float64)
+			if !ok || user != string(u.Login) {
+				continue
+			}
+
+			id = content.ArticleID(articleID)
+		} else if articleID, err := strconv.ParseInt(hit.ID, 10, 64); err == nil {
+			id = content.ArticleID(articleID)
+		} else {
+			continue
+		}
+
+		if existing, ok := hitMap[id]; ok {
+			// Both the article and its annotations matched
+			for field, fragments := range hit.Fragments {
+				if existing.Fragments == nil {
+					existing.Fragments = search.FieldFragmentMap{}
+				}
+				existing.Fragments[field] = append(existing.Fragments[field], fragments...)
+			}
+
+			continue
 		}
+
+		articleIDs = append(articleIDs, id)
+		hitMap[id] = hit
 	}
 
 	queryOpts := []content.QueryOpt{
@@ -258,6 +321,60 @@ func (b bleveSearch) BatchIndex(articles []content.Article, op indexOperation) e
 	return nil
 }
 
+func (b bleveSearch) BatchIndexAnnotations(annotations []content.Annotation, op indexOperation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	var articles map[content.ArticleID]content.Article
+	if op == BatchAdd {
+		var err error
+		if articles, err = annotationArticles(b.service, annotations); err != nil {
+			return err
+		}
+	}
+
+	batch := b.index.NewBatch()
+	count := int64(0)
+
+	for i := range annotations {
+		a := annotations[i]
+
+		switch op {
+		case BatchAdd:
+			b.log.Debugf("Indexing annotation %s", a)
+
+			if err := batch.Index(prepareAnnotation(a, articles[a.ArticleID])); err != nil {
+				return errors.Wrapf(err, "indexing annotation %s", a)
+			}
+		case BatchDelete:
+			b.log.Debugf("Removing annotation %s from index", a)
+
+			batch.Delete(annotationDocID(a.ID))
+		default:
+			return errors.Errorf("unknown operation type %v", op)
+		}
+
+		count++
+
+		if count >= b.batchSize {
+			if err := b.index.Batch(batch); err != nil {
+				return errors.Wrap(err, "indexing annotation batch")
+			}
+			batch = b.index.NewBatch()
+			count = 0
+		}
+	}
+
+	if count > 0 {
+		if err := b.index.Batch(batch); err != nil {
+			return errors.Wrap(err, "indexing annotation batch")
+		}
+	}
+
+	return nil
+}
+
 func (b bleveSearch) RemoveFeed(id content.FeedID) error {
 	val := float64(id)
 	inclusive := true
@@ -266,23 +383,26 @@ func (b bleveSearch) RemoveFeed(id content.FeedID) error {
 	q.SetField("feed_id")
 
 	req := bleve.NewSearchRequest(q)
-	req.Fields = []string{"article_id"}
 	req.Size = int(^uint(0) >> 1)
 
 	resp, err := b.index.Search(req)
 	if err != nil {
-		return errors.Wrapf(err, "fetching feed %d article ids", id)
+		return errors.Wrapf(err, "fetching feed %d document ids", id)
 	}
 
-	articles := make([]content.Article, 0, len(resp.Hits))
-
+	// The hits include both the article and the annotation documents
+	batch := b.index.NewBatch()
 	for i := range resp.Hits {
-		if id, ok := resp.Hits[i].Fields["article_id"].(float64); ok {
-			articles = append(articles, content.Article{ID: content.ArticleID(id)})
+		batch.Delete(resp.Hits[i].ID)
+	}
+
+	if batch.Size() > 0 {
+		if err := b.index.Batch(batch); err != nil {
+			return errors.Wrapf(err, "removing feed %d documents", id)
 		}
 	}
 
-	return b.BatchIndex(articles, BatchDelete)
+	return nil
 }
 
 func prepareArticle(article content.Article) (string, indexArticle) {
@@ -298,6 +418,24 @@ func prepareArticle(article content.Article) (string, indexArticle) {
 	return id, ia
 }
 
+func prepareAnnotation(annotation content.Annotation, article content.Article) (string, indexAnnotation) {
+	ia := indexAnnotation{
+		Kind:      annotationKind,
+		User:      string(annotation.User),
+		FeedID:    int64(article.FeedID),
+		ArticleID: int64(annotation.ArticleID),
+		Note:      annotation.Note,
+		Quote:     annotation.Quote,
+		Date:      article.Date,
+	}
+
+	return annotationDocID(annotation.ID), ia
+}
+
+func annotationDocID(id content.AnnotationID) string {
+	return annotationIDPrefix + strconv.FormatInt(int64(id), 10)
+}
+
 func StripTags(text string) string {
 	b := bytes.NewBufferString("")
 	inTag := 0
@@ -16,8 +16,9 @@ import (
 )
 
 const (
-	elasticIndexName   = "readeef"
-	elasticArticleType = "article"
+	elasticIndexName      = "readeef"
+	elasticArticleType    = "article"
+	elasticAnnotationType = "annotation"
 )
 
 type elasticSearch struct {
@@ -107,10 +108,16 @@ func (e elasticSearch) Search(
 		)
 	}
 
-	query = elastic.NewBoolQuery().Must(query).Filter(filter...)
+	// Annotations are private, so those of other users are excluded
+	foreign := elastic.NewBoolQuery().
+		Must(elastic.NewTypeQuery(elasticAnnotationType)).
+		MustNot(elastic.NewTermQuery("user.keyword", string(u.Login)))
+
+	query = elastic.NewBoolQuery().Must(query).Filter(filter...).MustNot(foreign)
 
 	search.Query(query)
-	search.Highlight(elastic.NewHighlight().PreTags("<mark>").PostTags("</mark>").Field("title").Field("description"))
+	search.Highlight(elastic.NewHighlight().PreTags("<mark>").PostTags("</mark>").
+		Field("title").Field("description").Field("note").Field("quote"))
 	search.Size(o.Limit)
 
 	switch o.SortField {
@@ -137,12 +144,35 @@ func (e elasticSearch) Search(
 
 	if res.Hits != nil && res.Hits.Hits != nil {
 		for _, hit := range res.Hits.Hits {
-			a := indexArticle{}
-			if err := json.Unmarshal(*hit.Source, &a); err == nil {
-				articleID := content.ArticleID(a.ArticleID)
-				articleIDs = append(articleIDs, articleID)
-				highlightMap[articleID] = hit.Highlight
+			var articleID content.ArticleID
+
+			if hit.Type == elasticAnnotationType {
+				a := indexAnnotation{}
+				if err := json.Unmarshal(*hit.Source, &a); err != nil || a.User != string(u.Login) {
+					continue
+				}
+
+				articleID = content.ArticleID(a.ArticleID)
+			} else {
+				a := indexArticle{}
+				if err := json.Unmarshal(*hit.Source, &a); err != nil {
+					continue
+				}
+
+				articleID = content.ArticleID(a.ArticleID)
+			}
+
+			if highlight, ok := highlightMap[articleID]; ok {
+				// Both the article and its annotations matched
+				for field, fragments := range hit.Highlight {
+					highlight[field] = append(highlight[field], fragments...)
+				}
+
+				continue
 			}
+
+			articleIDs = append(articleIDs, articleID)
+			highlightMap[articleID] = hit.Highlight
 		}
 	}
 
@@ -171,6 +201,12 @@ func (e elasticSearch) Search(
 			if len(highlight["description"]) > 0 {
 				articles[i].Hit.Fragments["Description"] = highlight["description"]
 			}
+			if len(highlight["note"]) > 0 {
+				articles[i].Hit.Fragments["Note"] = highlight["note"]
+			}
+			if len(highlight["quote"]) > 0 {
+				articles[i].Hit.Fragments["Quote"] = highlight["quote"]
+			}
 		}
 	}
 
@@ -227,6 +263,65 @@ func (e elasticSearch) BatchIndex(articles []content.Article, op indexOperation)
 	return nil
 }
 
+func (e elasticSearch) BatchIndexAnnotations(annotations []content.Annotation, op indexOperation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	var articles map[content.ArticleID]content.Article
+	if op == BatchAdd {
+		var err error
+		if articles, err = annotationArticles(e.service, annotations); err != nil {
+			return err
+		}
+	}
+
+	bulk := e.client.Bulk()
+
+	for i := range annotations {
+		a := annotations[i]
+
+		var req elastic.BulkableRequest
+		switch op {
+		case BatchAdd:
+			e.log.Debugf("Indexing annotation %s", a)
+			id, doc := prepareAnnotation(a, articles[a.ArticleID])
+			req = elastic.NewBulkIndexRequest().Index(elasticIndexName).Type(elasticAnnotationType).Id(id).Doc(doc)
+		case BatchDelete:
+			e.log.Debugf("Removing annotation %s from the index", a)
+
+			req = elastic.NewBulkDeleteRequest().Index(elasticIndexName).Type(elasticAnnotationType).Id(annotationDocID(a.ID))
+		default:
+			return errors.Errorf("unknown operation type %v", op)
+		}
+
+		bulk.Add(req)
+
+		if int64(bulk.NumberOfActions()) >= e.batchSize {
+			if err := e.doBulk(bulk); err != nil {
+				return errors.Wrap(err, "indexing annotation batch")
+			}
+			bulk = e.client.Bulk()
+		}
+	}
+
+	if bulk.NumberOfActions() > 0 {
+		if err := e.doBulk(bulk); err != nil {
+			return errors.Wrap(err, "indexing annotation batch")
+		}
+	}
+
+	return nil
+}
+
+func (e elasticSearch) doBulk(bulk *elastic.BulkService) error {
+	ctx, cancel := timeout(time.Duration(bulk.NumberOfActions()) * time.Second)
+	defer cancel()
+
+	_, err := bulk.Do(ctx)
+	return err
+}
+
 func (e elasticSearch) RemoveFeed(id content.FeedID) error {
 	q := elastic.NewTermQuery("feed_id", int64(id))
 
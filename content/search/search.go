@@ -19,6 +19,7 @@ type Provider interface {
 	IsNewIndex() bool
 	Search(string, content.User, ...content.QueryOpt) ([]content.Article, error)
 	BatchIndex(articles []content.Article, op indexOperation) error
+	BatchIndexAnnotations(annotations []content.Annotation, op indexOperation) error
 	RemoveFeed(content.FeedID) error
 }
 
@@ -46,3 +47,46 @@ func Reindex(p Provider, repo repo.Article) error {
 
 	}
 }
+
+// ReindexAnnotations adds all stored annotations to the index.
+func ReindexAnnotations(p Provider, repo repo.Annotation) error {
+	annotations, err := repo.All()
+	if err != nil {
+		return errors.WithMessage(err, "getting annotations")
+	}
+
+	if err = p.BatchIndexAnnotations(annotations, BatchAdd); err != nil {
+		return errors.WithMessage(err, "adding annotations to index")
+	}
+
+	return nil
+}
+
+// annotationArticles returns the annotated articles, keyed by their id, so
+// that the annotation documents can inherit their feed and date.
+func annotationArticles(service repo.Service, annotations []content.Annotation) (map[content.ArticleID]content.Article, error) {
+	articles := map[content.ArticleID]content.Article{}
+
+	ids := make([]content.ArticleID, 0, len(annotations))
+	for _, a := range annotations {
+		if _, ok := articles[a.ArticleID]; !ok {
+			articles[a.ArticleID] = content.Article{}
+			ids = append(ids, a.ArticleID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return articles, nil
+	}
+
+	all, err := service.ArticleRepo().All(content.IDs(ids))
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting annotated articles")
+	}
+
+	for _, a := range all {
+		articles[a.ID] = a
+	}
+
+	return articles, nil
+}
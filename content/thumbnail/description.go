@@ -25,8 +25,16 @@ func (t description) Generate(a content.Article) error {
 
 	t.log.Debugf("Generating thumbnail for article %s from description", a)
 
-	thumbnail.Thumbnail, thumbnail.Link =
-		generateThumbnailFromDescription(strings.NewReader(a.Description))
+	if link := mediaImage(a); link != "" {
+		t.log.Debugf("Generating thumbnail from media image %s of %s\n", link, a)
+		if t.store {
+			thumbnail.Thumbnail = generateThumbnailFromImageLink(link)
+		}
+		thumbnail.Link = link
+	} else {
+		thumbnail.Thumbnail, thumbnail.Link =
+			generateThumbnailFromDescription(strings.NewReader(a.Description))
+	}
 
 	if !t.store {
 		thumbnail.Thumbnail = ""
@@ -44,8 +44,16 @@ func (t ext) Generate(a content.Article) error {
 
 	t.log.Debugf("Generating thumbnail for article %s from extract", a)
 
-	thumbnail.Thumbnail, thumbnail.Link =
-		generateThumbnailFromDescription(strings.NewReader(a.Description))
+	if link := mediaImage(a); link != "" {
+		t.log.Debugf("Generating thumbnail from media image %s of %s\n", link, a)
+		if t.store {
+			thumbnail.Thumbnail = generateThumbnailFromImageLink(link)
+		}
+		thumbnail.Link = link
+	} else {
+		thumbnail.Thumbnail, thumbnail.Link =
+			generateThumbnailFromDescription(strings.NewReader(a.Description))
+	}
 
 	if thumbnail.Link == "" {
 		t.log.Debugf("%s description doesn't contain suitable link, getting extract\n", a)
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/nfnt/resize"
@@ -137,6 +138,23 @@ func generateThumbnailFromImageLink(link string) (t string) {
 	return
 }
 
+// mediaImage returns the image provided by the podcast metadata or the
+// enclosures of the article, which is preferred over any image found in the
+// content.
+func mediaImage(a content.Article) string {
+	if a.Media != nil && a.Media.Image != "" {
+		return a.Media.Image
+	}
+
+	for _, e := range a.Enclosures {
+		if strings.HasPrefix(e.Type, "image/") {
+			return e.URL
+		}
+	}
+
+	return ""
+}
+
 func generateThumbnailProcessors(articles []content.Article) <-chan content.Article {
 	processors := make(chan content.Article)
 
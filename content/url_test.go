@@ -0,0 +1,40 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestIsPublicURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://93.184.216.34/hook", true},
+		{"http://[2606:2800:220:1::248]/hook", true},
+		{"/hook", false},
+		{"ftp://example.com/hook", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://10.1.2.3/hook", false},
+		{"http://172.20.0.1/hook", false},
+		{"http://192.168.0.10/hook", false},
+		{"http://100.64.0.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[fd00::1]/hook", false},
+		{"http://[fe80::1]/hook", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := content.IsPublicURL(tt.url); got != tt.want {
+				t.Errorf("IsPublicURL(%s) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
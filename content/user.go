@@ -4,8 +4,10 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/mail"
@@ -30,15 +32,23 @@ type User struct {
 	Active    bool   `json:"active"`
 	Salt      []byte `json:"-"`
 	Hash      []byte `json:"-"`
-	MD5API    []byte `db:"md5_api" json:"-"` // "md5(user:pass)"
+	MD5API    []byte `db:"md5_api" json:"-"` // "md5(user:key)"
+	// APIKeyRotated is set once the api key is no longer derived from the
+	// password.
+	APIKeyRotated bool `db:"api_key_rotated" json:"-"`
+	// APIToken is set along with a rotated api key, and allows looking up
+	// the user by the key alone.
+	APIToken []byte `db:"api_token" json:"-"` // "sha256(key)"
 
 	ProfileData ProfileData `db:"profile_data" json:"profileData"`
 }
 
+// Password sets the user password. Unless the api key has been rotated, it
+// is derived from the new password, invalidating the old one.
 func (u *User) Password(password string, secret []byte) error {
-	h := md5.Sum([]byte(fmt.Sprintf("%s:%s", u.Login, password)))
-
-	u.MD5API = h[:]
+	if !u.APIKeyRotated {
+		u.setAPIKey(password)
+	}
 
 	c := 30
 	salt := make([]byte, c)
@@ -58,6 +68,44 @@ func (u *User) Password(password string, secret []byte) error {
 	return nil
 }
 
+// RotateAPIKey replaces the api key with one derived from a new random
+// key, which is returned. Clients like the fever ones use the key in place
+// of the password. A rotated key is kept across password changes.
+func (u *User) RotateAPIKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrap(err, "generating api key")
+	}
+
+	hexKey := hex.EncodeToString(key)
+	u.setAPIKey(hexKey)
+	u.APIKeyRotated = true
+	u.APIToken = APITokenHash(hexKey)
+
+	return hexKey, nil
+}
+
+// AuthenticateAPIKey checks whether the key is the user's api key.
+func (u User) AuthenticateAPIKey(key string) bool {
+	h := md5.Sum([]byte(fmt.Sprintf("%s:%s", u.Login, key)))
+
+	return len(u.MD5API) > 0 && subtle.ConstantTimeCompare(u.MD5API, h[:]) == 1
+}
+
+// APITokenHash returns the hash under which a rotated api key is stored as
+// the user's api token.
+func APITokenHash(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+
+	return h[:]
+}
+
+func (u *User) setAPIKey(key string) {
+	h := md5.Sum([]byte(fmt.Sprintf("%s:%s", u.Login, key)))
+
+	u.MD5API = h[:]
+}
+
 // Validate checks whether all required fields have been provided.
 func (u User) Validate() error {
 	if u.Login == "" {
@@ -38,10 +38,58 @@ func TestUser_Password(t *testing.T) {
 			if !bytes.Equal(u.Hash, scryptHash(t, tt.password, u.Salt)) {
 				t.Error("User.Password() hash not equal")
 			}
+
+			if err := u.Password(tt.password+"new", secret); err != nil {
+				t.Errorf("User.Password() error = %v", err)
+			}
+
+			if u.AuthenticateAPIKey(tt.password) || !u.AuthenticateAPIKey(tt.password+"new") {
+				t.Error("User.Password() kept the api key of the old password")
+			}
 		})
 	}
 }
 
+func TestUser_RotateAPIKey(t *testing.T) {
+	u := content.User{Login: "test1"}
+	if err := u.Password("password1", secret); err != nil {
+		t.Fatalf("User.Password() error = %v", err)
+	}
+
+	key, err := u.RotateAPIKey()
+	if err != nil {
+		t.Fatalf("User.RotateAPIKey() error = %v", err)
+	}
+
+	if key == "" || !bytes.Equal(u.MD5API, md5Sum(string(u.Login), key)) || !u.APIKeyRotated {
+		t.Error("User.RotateAPIKey() md5sum not equal")
+	}
+
+	if !bytes.Equal(u.APIToken, content.APITokenHash(key)) {
+		t.Error("User.RotateAPIKey() api token not equal")
+	}
+
+	if err := u.Password("password2", secret); err != nil {
+		t.Fatalf("User.Password() error = %v", err)
+	}
+
+	if !bytes.Equal(u.MD5API, md5Sum(string(u.Login), key)) {
+		t.Error("User.Password() changed the rotated api key")
+	}
+
+	if !u.AuthenticateAPIKey(key) {
+		t.Error("User.AuthenticateAPIKey() rejected the rotated api key")
+	}
+
+	if u.AuthenticateAPIKey("password1") {
+		t.Error("User.AuthenticateAPIKey() accepted the replaced api key")
+	}
+
+	if other, _ := u.RotateAPIKey(); other == key {
+		t.Error("User.RotateAPIKey() returned the same key")
+	}
+}
+
 func TestUser_Authenticate(t *testing.T) {
 	tests := []struct {
 		name          string
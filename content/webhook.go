@@ -0,0 +1,188 @@
+package content
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type WebhookID int64
+type WebhookDeliveryID int64
+
+// WebhookFilter limits the events that are sent to a webhook. Empty fields
+// match everything.
+type WebhookFilter struct {
+	Events  []string `json:"events,omitempty"`
+	FeedIDs []FeedID `json:"feedIDs,omitempty"`
+	TagIDs  []TagID  `json:"tagIDs,omitempty"`
+}
+
+// Webhook is a user registered url, which receives the events matching
+// its filter.
+type Webhook struct {
+	ID     WebhookID     `json:"id"`
+	User   Login         `db:"user_login" json:"-"`
+	URL    string        `json:"url"`
+	Filter WebhookFilter `db:"filter_data" json:"filter"`
+	Active bool          `json:"active"`
+}
+
+// The states of a webhook delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is a single event payload, queued for a webhook.
+type WebhookDelivery struct {
+	ID           WebhookDeliveryID `json:"id"`
+	WebhookID    WebhookID         `db:"webhook_id" json:"webhookID"`
+	Event        string            `json:"event"`
+	Payload      string            `json:"payload"`
+	Status       string            `json:"status"`
+	Attempts     int               `json:"attempts"`
+	ResponseCode int               `db:"response_code" json:"responseCode"`
+	Error        string            `json:"error"`
+	CreatedAt    time.Time         `db:"created_at" json:"createdAt"`
+	NextAttempt  time.Time         `db:"next_attempt" json:"nextAttempt"`
+	UpdatedAt    time.Time         `db:"updated_at" json:"updatedAt"`
+}
+
+func (w Webhook) Validate() error {
+	if w.User == "" {
+		return NewValidationError(errors.New("Webhook has no user"))
+	}
+
+	if !IsPublicURL(w.URL) {
+		return NewValidationError(errors.New("Webhook has an invalid or non-public url"))
+	}
+
+	return nil
+}
+
+func (w Webhook) String() string {
+	return fmt.Sprintf("%s:%d: %s", w.User, w.ID, w.URL)
+}
+
+// SigningKey returns the key with which the payloads of the webhook are
+// signed. It is derived from the secret, so that it doesn't have to be
+// stored.
+func (w Webhook) SigningKey(secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "webhook:%s:%d", w.User, w.ID)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload, using the
+// webhook's signing key.
+func (w Webhook) Sign(payload []byte, secret []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.SigningKey(secret)))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches checks whether the webhook should receive the event. The feed id
+// is zero for events that don't concern a single feed, and tagFeeds
+// holds the ids of the feeds of the filter's tags.
+func (w Webhook) Matches(event string, feedID FeedID, tagFeeds []FeedID) bool {
+	if !w.Active {
+		return false
+	}
+
+	if len(w.Filter.Events) > 0 && !containsString(w.Filter.Events, event) {
+		return false
+	}
+
+	if len(w.Filter.FeedIDs) == 0 && len(w.Filter.TagIDs) == 0 {
+		return true
+	}
+
+	if feedID == 0 {
+		return false
+	}
+
+	for _, id := range w.Filter.FeedIDs {
+		if id == feedID {
+			return true
+		}
+	}
+
+	for _, id := range tagFeeds {
+		if id == feedID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d WebhookDelivery) Validate() error {
+	if d.WebhookID == 0 {
+		return NewValidationError(errors.New("Delivery has no webhook id"))
+	}
+
+	if d.Event == "" {
+		return NewValidationError(errors.New("Delivery has no event"))
+	}
+
+	return nil
+}
+
+func (d WebhookDelivery) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", d.WebhookID, d.ID, d.Event, d.Status)
+}
+
+func (id *WebhookID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (WebhookID)", src, src)
+	}
+
+	*id = WebhookID(asInt)
+
+	return nil
+}
+
+func (id WebhookID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+func (id *WebhookDeliveryID) Scan(src interface{}) error {
+	asInt, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("Scan source '%#v' (%T) was not of type int64 (WebhookDeliveryID)", src, src)
+	}
+
+	*id = WebhookDeliveryID(asInt)
+
+	return nil
+}
+
+func (id WebhookDeliveryID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+func (val *WebhookFilter) Scan(src interface{}) error {
+	return scanJSON(src, val, "WebhookFilter")
+}
+
+func (val WebhookFilter) Value() (driver.Value, error) {
+	return valueJSON(1, val)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package content_test
+
+import (
+	"testing"
+
+	"github.com/urandom/readeef/content"
+)
+
+func TestWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook content.Webhook
+		wantErr bool
+	}{
+		{"valid", content.Webhook{User: "test", URL: "https://example.com/hook"}, false},
+		{"no user", content.Webhook{URL: "https://example.com/hook"}, true},
+		{"no url", content.Webhook{User: "test"}, true},
+		{"relative url", content.Webhook{User: "test", URL: "/hook"}, true},
+		{"other scheme", content.Webhook{User: "test", URL: "ftp://example.com/hook"}, true},
+		{"localhost url", content.Webhook{User: "test", URL: "http://localhost:8080/hook"}, true},
+		{"link-local url", content.Webhook{User: "test", URL: "http://169.254.169.254/latest"}, true},
+		{"private url", content.Webhook{User: "test", URL: "http://10.0.0.1/hook"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.webhook.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Webhook.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhook_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   content.WebhookFilter
+		inactive bool
+		event    string
+		feedID   content.FeedID
+		tagFeeds []content.FeedID
+		want     bool
+	}{
+		{name: "no filter", event: "feed-update", feedID: 1, want: true},
+		{name: "no filter, no feed", event: "article-state-change", want: true},
+		{name: "inactive", inactive: true, event: "feed-update", feedID: 1},
+		{name: "event", filter: content.WebhookFilter{Events: []string{"feed-update"}}, event: "feed-update", want: true},
+		{name: "other event", filter: content.WebhookFilter{Events: []string{"feed-update"}}, event: "feed-delete"},
+		{name: "feed", filter: content.WebhookFilter{FeedIDs: []content.FeedID{1, 2}}, event: "feed-update", feedID: 2, want: true},
+		{name: "other feed", filter: content.WebhookFilter{FeedIDs: []content.FeedID{1, 2}}, event: "feed-update", feedID: 3},
+		{name: "feed filter, no feed", filter: content.WebhookFilter{FeedIDs: []content.FeedID{1}}, event: "article-state-change"},
+		{name: "tag", filter: content.WebhookFilter{TagIDs: []content.TagID{1}}, event: "feed-update", feedID: 3, tagFeeds: []content.FeedID{3, 4}, want: true},
+		{name: "other tag", filter: content.WebhookFilter{TagIDs: []content.TagID{1}}, event: "feed-update", feedID: 5, tagFeeds: []content.FeedID{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := content.Webhook{User: "test", URL: "http://example.com", Filter: tt.filter, Active: !tt.inactive}
+			if got := w.Matches(tt.event, tt.feedID, tt.tagFeeds); got != tt.want {
+				t.Errorf("Webhook.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhook_Sign(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"event":"feed-update"}`)
+
+	w1 := content.Webhook{ID: 1, User: "test"}
+	w2 := content.Webhook{ID: 2, User: "test"}
+
+	if w1.SigningKey(secret) == w2.SigningKey(secret) {
+		t.Errorf("Webhook.SigningKey() is the same for different webhooks")
+	}
+
+	if w1.SigningKey(secret) == w1.SigningKey([]byte("other")) {
+		t.Errorf("Webhook.SigningKey() doesn't depend on the secret")
+	}
+
+	sig := w1.Sign(payload, secret)
+	if len(sig) != 64 {
+		t.Errorf("Webhook.Sign() = %s, want a hex encoded sha256", sig)
+	}
+
+	if sig != w1.Sign(payload, secret) {
+		t.Errorf("Webhook.Sign() isn't stable")
+	}
+
+	if sig == w1.Sign([]byte(`{}`), secret) || sig == w2.Sign(payload, secret) {
+		t.Errorf("Webhook.Sign() doesn't depend on the payload and webhook")
+	}
+}
@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urandom/readeef/parser"
+)
+
+// Intervals describe the bounds of the adaptive feed update intervals.
+type Intervals struct {
+	// Min is the shortest interval between two consecutive updates.
+	Min time.Duration
+	// Max is the longest interval between two consecutive updates. It also
+	// caps the backoff of failing feeds.
+	Max time.Duration
+	// DeadAfter is the number of consecutive failures after which a feed
+	// is considered dead. A zero value disables the check.
+	DeadAfter int
+}
+
+// recentArticles is the number of most recent articles used to estimate the
+// publishing frequency of a feed.
+const recentArticles = 10
+
+// Adaptive returns the update interval of a feed, based on the publishing
+// dates of its most recent articles. The base interval is returned when the
+// frequency cannot be estimated.
+func (i Intervals) Adaptive(pf parser.Feed, base time.Duration, now time.Time) time.Duration {
+	dates := make([]time.Time, 0, len(pf.Articles))
+	for _, a := range pf.Articles {
+		if a.Date.Unix() <= 0 || a.Date.After(now) {
+			continue
+		}
+
+		dates = append(dates, a.Date)
+	}
+
+	if len(dates) < 2 {
+		return i.clamp(base, pf.TTL)
+	}
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].After(dates[j])
+	})
+
+	if len(dates) > recentArticles {
+		dates = dates[:recentArticles]
+	}
+
+	gap := dates[0].Sub(dates[len(dates)-1]) / time.Duration(len(dates)-1)
+
+	// A feed that has been quiet for longer than its usual gap is likely
+	// slowing down.
+	if since := now.Sub(dates[0]); since > gap {
+		gap = (gap + since) / 2
+	}
+
+	// Poll twice as often as the feed publishes, on average.
+	return i.clamp(gap/2, pf.TTL)
+}
+
+// maxBackoff caps the backoff of failing feeds when there is no maximum
+// update interval.
+const maxBackoff = 7 * 24 * time.Hour
+
+// Backoff returns the update interval of a feed that has failed for the
+// given number of consecutive times.
+func (i Intervals) Backoff(interval time.Duration, failures int) time.Duration {
+	max := i.Max
+	if max <= 0 {
+		max = maxBackoff
+	}
+
+	if interval >= max {
+		return max
+	}
+
+	for n := 0; n < failures; n++ {
+		interval *= 2
+
+		if interval >= max {
+			return max
+		}
+	}
+
+	return interval
+}
+
+// IsDead reports whether the given number of consecutive failures marks a
+// feed as dead.
+func (i Intervals) IsDead(failures int) bool {
+	return i.DeadAfter > 0 && failures >= i.DeadAfter
+}
+
+func (i Intervals) clamp(d, ttl time.Duration) time.Duration {
+	if d < ttl {
+		d = ttl
+	}
+
+	if i.Min > 0 && d < i.Min {
+		d = i.Min
+	}
+
+	if i.Max > 0 && d > i.Max {
+		d = i.Max
+	}
+
+	return d
+}
+
+// retryAfter parses the Retry-After header of the response, which may hold
+// either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
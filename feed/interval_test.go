@@ -0,0 +1,115 @@
+package feed
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/parser"
+)
+
+func TestIntervals_Adaptive(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	articles := func(gaps ...time.Duration) []parser.Article {
+		a := []parser.Article{}
+		date := now
+		for _, g := range gaps {
+			date = date.Add(-g)
+			a = append(a, parser.Article{Date: date})
+		}
+		return a
+	}
+
+	intervals := Intervals{Min: 10 * time.Minute, Max: 24 * time.Hour}
+
+	tests := []struct {
+		name string
+		feed parser.Feed
+		base time.Duration
+		want time.Duration
+	}{
+		{"no articles", parser.Feed{}, 30 * time.Minute, 30 * time.Minute},
+		{"single article", parser.Feed{Articles: articles(time.Hour)}, 30 * time.Minute, 30 * time.Minute},
+		{"hourly", parser.Feed{Articles: articles(0, time.Hour, time.Hour, time.Hour)}, 30 * time.Minute, 30 * time.Minute},
+		{"frequent", parser.Feed{Articles: articles(0, time.Minute, time.Minute)}, 30 * time.Minute, 10 * time.Minute},
+		{"rare", parser.Feed{Articles: articles(0, 7*24*time.Hour, 7*24*time.Hour)}, 30 * time.Minute, 24 * time.Hour},
+		{"quiet", parser.Feed{Articles: articles(3*time.Hour, time.Hour, time.Hour)}, 30 * time.Minute, time.Hour},
+		{"ttl", parser.Feed{TTL: 2 * time.Hour, Articles: articles(0, time.Hour, time.Hour)}, 30 * time.Minute, 2 * time.Hour},
+		{"unknown dates", parser.Feed{Articles: []parser.Article{{Date: time.Unix(0, 0)}, {Date: time.Unix(0, 0)}}}, time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intervals.Adaptive(tt.feed, tt.base, now); got != tt.want {
+				t.Errorf("Intervals.Adaptive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntervals_Backoff(t *testing.T) {
+	intervals := Intervals{Min: 10 * time.Minute, Max: 4 * time.Hour, DeadAfter: 3}
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+		dead     bool
+	}{
+		{"no failures", 0, 30 * time.Minute, false},
+		{"one failure", 1, time.Hour, false},
+		{"two failures", 2, 2 * time.Hour, false},
+		{"capped", 3, 4 * time.Hour, true},
+		{"still capped", 30, 4 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intervals.Backoff(30*time.Minute, tt.failures); got != tt.want {
+				t.Errorf("Intervals.Backoff() = %v, want %v", got, tt.want)
+			}
+
+			if got := intervals.IsDead(tt.failures); got != tt.dead {
+				t.Errorf("Intervals.IsDead() = %v, want %v", got, tt.dead)
+			}
+		})
+	}
+}
+
+func TestIntervals_BackoffUnbounded(t *testing.T) {
+	intervals := Intervals{}
+
+	if got := intervals.Backoff(time.Hour, 2); got != 4*time.Hour {
+		t.Errorf("Intervals.Backoff() = %v, want %v", got, 4*time.Hour)
+	}
+
+	// The doubling would overflow without a ceiling.
+	if got := intervals.Backoff(time.Hour, 100); got != maxBackoff {
+		t.Errorf("Intervals.Backoff() = %v, want %v", got, maxBackoff)
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"negative", "-5", 0},
+		{"date", now.Add(time.Hour).Format(http.TimeFormat), time.Hour},
+		{"past date", now.Add(-time.Hour).Format(http.TimeFormat), 0},
+		{"garbage", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			resp.Header.Set("Retry-After", tt.value)
+
+			if got := retryAfter(resp, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
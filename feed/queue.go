@@ -0,0 +1,257 @@
+package feed
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+// Stats describe the current state of the scheduler.
+type Stats struct {
+	// Feeds is the number of scheduled feeds.
+	Feeds int `json:"feeds"`
+	// Queued is the number of feeds that are due, but are waiting for a
+	// free worker.
+	Queued int `json:"queued"`
+	// Workers is the size of the download worker pool.
+	Workers int `json:"workers"`
+	// Busy is the number of workers currently downloading a feed.
+	Busy int `json:"busy"`
+	// Hosts holds the number of active downloads per host.
+	Hosts map[string]int `json:"hosts"`
+}
+
+// queue keeps the scheduled feeds ordered by their due time, and tracks the
+// downloads in progress. It is only accessed from the scheduler loop.
+type queue struct {
+	feeds   map[content.FeedID]*schedulePayload
+	pending payloadHeap
+	waiting map[string][]*schedulePayload
+	hosts   map[string]int
+	busy    int
+	limits  Limits
+}
+
+func newQueue(limits Limits) *queue {
+	return &queue{
+		feeds:   map[content.FeedID]*schedulePayload{},
+		waiting: map[string][]*schedulePayload{},
+		hosts:   map[string]int{},
+		limits:  limits,
+	}
+}
+
+func (q *queue) workers() int {
+	if q.limits.Workers < 1 {
+		return 1
+	}
+
+	return q.limits.Workers
+}
+
+func (q *queue) add(payload *schedulePayload) {
+	q.feeds[payload.feed.ID] = payload
+	heap.Push(&q.pending, payload)
+}
+
+func (q *queue) remove(id content.FeedID) {
+	payload, ok := q.feeds[id]
+	if !ok {
+		return
+	}
+
+	delete(q.feeds, id)
+
+	if payload.fetching {
+		// The update channel is closed once the download is done.
+		payload.removed = true
+		return
+	}
+
+	if payload.index >= 0 {
+		heap.Remove(&q.pending, payload.index)
+	} else {
+		waiting := q.waiting[payload.host]
+		for i := range waiting {
+			if waiting[i] == payload {
+				q.waiting[payload.host] = append(waiting[:i], waiting[i+1:]...)
+				break
+			}
+		}
+	}
+
+	close(payload.updateData)
+}
+
+// refresh moves the feed to the front of the queue, or of the feeds waiting
+// for its host, and forces its next download. A feed that is being
+// downloaded is left as is. It returns false if the feed is not in the queue.
+func (q *queue) refresh(id content.FeedID) bool {
+	payload, ok := q.feeds[id]
+	if !ok {
+		return false
+	}
+
+	if payload.fetching {
+		return true
+	}
+
+	payload.forced = true
+
+	if payload.index >= 0 {
+		payload.due = time.Time{}
+		heap.Fix(&q.pending, payload.index)
+
+		return true
+	}
+
+	waiting := q.waiting[payload.host]
+	for i := range waiting {
+		if waiting[i] == payload {
+			copy(waiting[1:i+1], waiting[:i])
+			waiting[0] = payload
+			break
+		}
+	}
+
+	return true
+}
+
+// due returns the feeds that should be downloaded now, while respecting the
+// worker pool size and the per-host limit.
+func (q *queue) due(now time.Time) []*schedulePayload {
+	var ready []*schedulePayload
+
+	for q.pending.Len() > 0 && q.busy < q.workers() {
+		payload := q.pending[0]
+		if payload.due.After(now) {
+			break
+		}
+
+		heap.Pop(&q.pending)
+
+		// Feeds whose context is done are no longer downloaded.
+		if payload.ctx.Err() != nil {
+			delete(q.feeds, payload.feed.ID)
+			close(payload.updateData)
+			continue
+		}
+
+		if q.limits.PerHost > 0 && q.hosts[payload.host] >= q.limits.PerHost {
+			q.waiting[payload.host] = append(q.waiting[payload.host], payload)
+			continue
+		}
+
+		payload.fetching = true
+		q.busy++
+		q.hosts[payload.host]++
+
+		ready = append(ready, payload)
+	}
+
+	return ready
+}
+
+// done marks the download of the feed as finished, and reschedules it.
+func (q *queue) done(payload *schedulePayload, next time.Time, state fetchState) {
+	q.busy--
+	if q.hosts[payload.host]--; q.hosts[payload.host] <= 0 {
+		delete(q.hosts, payload.host)
+	}
+
+	if waiting := q.waiting[payload.host]; len(waiting) > 0 {
+		heap.Push(&q.pending, waiting[0])
+
+		if len(waiting) == 1 {
+			delete(q.waiting, payload.host)
+		} else {
+			q.waiting[payload.host] = waiting[1:]
+		}
+	}
+
+	payload.fetching = false
+	payload.forced = false
+	if payload.removed {
+		close(payload.updateData)
+		return
+	}
+
+	payload.state = state
+	payload.due = next
+	heap.Push(&q.pending, payload)
+}
+
+// wait returns the duration until the next feed is due. It returns false if
+// there is nothing to wait for.
+func (q *queue) wait(now time.Time) (time.Duration, bool) {
+	if q.busy >= q.workers() || q.pending.Len() == 0 {
+		return 0, false
+	}
+
+	d := q.pending[0].due.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+func (q *queue) stats(now time.Time) Stats {
+	stats := Stats{
+		Feeds:   len(q.feeds),
+		Workers: q.workers(),
+		Busy:    q.busy,
+		Hosts:   make(map[string]int, len(q.hosts)),
+	}
+
+	for _, payload := range q.pending {
+		if !payload.due.After(now) {
+			stats.Queued++
+		}
+	}
+
+	for _, waiting := range q.waiting {
+		stats.Queued += len(waiting)
+	}
+
+	for host, active := range q.hosts {
+		stats.Hosts[host] = active
+	}
+
+	return stats
+}
+
+// payloadHeap implements heap.Interface, ordering the payloads by due time.
+type payloadHeap []*schedulePayload
+
+func (h payloadHeap) Len() int {
+	return len(h)
+}
+
+func (h payloadHeap) Less(i, j int) bool {
+	return h[i].due.Before(h[j].due)
+}
+
+func (h payloadHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *payloadHeap) Push(x interface{}) {
+	payload := x.(*schedulePayload)
+	payload.index = len(*h)
+	*h = append(*h, payload)
+}
+
+func (h *payloadHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	payload := old[n-1]
+	old[n-1] = nil
+	payload.index = -1
+	*h = old[:n-1]
+
+	return payload
+}
@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+func Test_queue(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	payload := func(id content.FeedID, link string, due time.Duration) *schedulePayload {
+		feed := content.Feed{ID: id, Link: link}
+		return &schedulePayload{
+			ctx:        context.Background(),
+			feed:       feed,
+			host:       feedHost(feed),
+			updateData: make(chan UpdateData),
+			due:        now.Add(due),
+		}
+	}
+
+	q := newQueue(Limits{Workers: 3, PerHost: 2})
+
+	q.add(payload(1, "http://a.com/1", 0))
+	q.add(payload(2, "http://A.com/2", time.Second))
+	q.add(payload(3, "http://a.com/3", 2*time.Second))
+	q.add(payload(4, "http://b.com/4", 3*time.Second))
+	q.add(payload(5, "http://c.com/5", time.Hour))
+
+	if d, ok := q.wait(now.Add(-time.Second)); !ok || d != time.Second {
+		t.Fatalf("queue.wait() = %v, %v, want %v, true", d, ok, time.Second)
+	}
+
+	ready := q.due(now.Add(time.Minute))
+	if len(ready) != 3 {
+		t.Fatalf("queue.due() len = %d, want 3", len(ready))
+	}
+
+	got := []content.FeedID{ready[0].feed.ID, ready[1].feed.ID, ready[2].feed.ID}
+	if got[0] != 1 || got[1] != 2 || got[2] != 4 {
+		t.Fatalf("queue.due() = %v, want [1 2 4]", got)
+	}
+
+	stats := q.stats(now.Add(time.Minute))
+	if stats.Feeds != 5 || stats.Busy != 3 || stats.Queued != 1 || stats.Hosts["a.com"] != 2 || stats.Hosts["b.com"] != 1 {
+		t.Fatalf("queue.stats() = %#v", stats)
+	}
+
+	if _, ok := q.wait(now); ok {
+		t.Fatalf("queue.wait() with busy workers should not wait")
+	}
+
+	q.remove(4)
+	q.done(ready[2], now.Add(time.Hour), fetchState{})
+
+	select {
+	case _, ok := <-ready[2].updateData:
+		if ok {
+			t.Fatalf("removed feed channel not closed")
+		}
+	default:
+		t.Fatalf("removed feed channel not closed")
+	}
+
+	q.done(ready[0], now.Add(2*time.Hour), fetchState{})
+
+	ready = q.due(now.Add(time.Minute))
+	if len(ready) != 1 || ready[0].feed.ID != 3 {
+		t.Fatalf("queue.due() after done = %v, want feed 3", ready)
+	}
+
+	stats = q.stats(now.Add(time.Minute))
+	if stats.Feeds != 4 || stats.Busy != 2 || stats.Queued != 0 || stats.Hosts["a.com"] != 2 {
+		t.Fatalf("queue.stats() = %#v", stats)
+	}
+
+	if q.refresh(10) {
+		t.Fatalf("queue.refresh() found an unscheduled feed")
+	}
+
+	// Feed 3 is being downloaded, and feed 5 is only due in an hour.
+	if !q.refresh(3) || !q.refresh(5) {
+		t.Fatalf("queue.refresh() didn't find the scheduled feeds")
+	}
+
+	ready = q.due(now)
+	if len(ready) != 1 || ready[0].feed.ID != 5 {
+		t.Fatalf("queue.due() after refresh = %v, want feed 5", ready)
+	}
+
+	if !ready[0].forced || q.feeds[3].forced {
+		t.Fatalf("queue.refresh() forced = %v, %v, want true, false", ready[0].forced, q.feeds[3].forced)
+	}
+
+	q.done(ready[0], now.Add(time.Hour), fetchState{})
+	if q.feeds[5].forced {
+		t.Fatalf("queue.done() kept the forced download")
+	}
+}
+
+func Test_queue_cancelled(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	feed := content.Feed{ID: 1, Link: "http://a.com/1"}
+	payload := &schedulePayload{
+		ctx:        ctx,
+		feed:       feed,
+		host:       feedHost(feed),
+		updateData: make(chan UpdateData),
+		due:        now,
+	}
+
+	q := newQueue(Limits{Workers: 1})
+	q.add(payload)
+	cancel()
+
+	if ready := q.due(now); len(ready) != 0 {
+		t.Fatalf("queue.due() = %v, want none", ready)
+	}
+
+	if _, ok := q.feeds[feed.ID]; ok {
+		t.Fatalf("queue.due() kept the cancelled feed")
+	}
+
+	if _, ok := <-payload.updateData; ok {
+		t.Fatalf("cancelled feed channel not closed")
+	}
+}
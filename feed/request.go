@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+
+	"github.com/urandom/readeef/content"
+)
+
+// Source holds the per-feed options used when downloading a feed.
+type Source struct {
+	Auth content.FeedAuth
+	// Scraper is set for feeds generated from an html page.
+	Scraper *content.Scraper
+}
+
+// SourceFunc returns the download options of a feed.
+type SourceFunc func(content.Feed) (Source, error)
+
+// NewRequest creates a GET request for the link, customized with the feed
+// credentials, headers, cookies and user agent.
+func NewRequest(link string, auth content.FeedAuth) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range auth.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if auth.UserAgent != "" {
+		req.Header.Set("User-Agent", auth.UserAgent)
+	}
+
+	if auth.Username != "" || auth.Password != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	} else if auth.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	}
+
+	for name, value := range auth.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	return req, nil
+}
+
+// cookieJars keeps a cookie jar for each feed that asks for one.
+type cookieJars struct {
+	mu   sync.Mutex
+	jars map[content.FeedID]http.CookieJar
+}
+
+func (c *cookieJars) get(id content.FeedID) http.CookieJar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if jar, ok := c.jars[id]; ok {
+		return jar
+	}
+
+	// cookiejar.New never fails without options.
+	jar, _ := cookiejar.New(nil)
+	c.jars[id] = jar
+
+	return jar
+}
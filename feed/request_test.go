@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/log"
+)
+
+func TestNewRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		auth        content.FeedAuth
+		wantAuth    string
+		wantAgent   string
+		wantHeaders map[string]string
+		wantCookies map[string]string
+	}{
+		{name: "empty"},
+		{
+			name:     "basic auth",
+			auth:     content.FeedAuth{Username: "user", Password: "pass", Token: "ignored"},
+			wantAuth: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:     "bearer token",
+			auth:     content.FeedAuth{Token: "secret"},
+			wantAuth: "Bearer secret",
+		},
+		{
+			name: "headers, cookies and agent",
+			auth: content.FeedAuth{
+				Headers:   map[string]string{"X-Api-Key": "key"},
+				Cookies:   map[string]string{"session": "abc"},
+				UserAgent: "readeef-test",
+			},
+			wantAgent:   "readeef-test",
+			wantHeaders: map[string]string{"X-Api-Key": "key"},
+			wantCookies: map[string]string{"session": "abc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewRequest("http://example.com/feed", tt.auth)
+			if err != nil {
+				t.Fatalf("NewRequest() error = %v", err)
+			}
+
+			if got := req.Header.Get("Authorization"); got != tt.wantAuth {
+				t.Errorf("NewRequest() authorization = %q, want %q", got, tt.wantAuth)
+			}
+
+			if got := req.Header.Get("User-Agent"); got != tt.wantAgent {
+				t.Errorf("NewRequest() user agent = %q, want %q", got, tt.wantAgent)
+			}
+
+			for k, v := range tt.wantHeaders {
+				if got := req.Header.Get(k); got != v {
+					t.Errorf("NewRequest() header %s = %q, want %q", k, got, v)
+				}
+			}
+
+			for k, v := range tt.wantCookies {
+				c, err := req.Cookie(k)
+				if err != nil || c.Value != v {
+					t.Errorf("NewRequest() cookie %s = %v, want %q", k, c, v)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduler_cookieJar(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests == 1 {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		} else if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+			t.Errorf("request %d without the stored cookie", requests)
+		}
+
+		fmt.Fprint(w, `{"version": "https://jsonfeed.org/version/1.1", "title": "JSON Feed", "items": [{"id": "1", "content_text": "text"}]}`)
+	}))
+	defer ts.Close()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+
+	source := func(content.Feed) (Source, error) {
+		return Source{Auth: content.FeedAuth{CookieJar: true}}, nil
+	}
+
+	s := NewScheduler(Intervals{}, Limits{}, source, log.WithStd(cfg))
+	feed := content.Feed{ID: 1, Link: ts.URL}
+
+	for i := 0; i < 2; i++ {
+		if data := s.Download(feed, 0); data.IsErr() {
+			t.Fatalf("Scheduler.Download() error = %v", data)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("Scheduler.Download() requests = %d, want 2", requests)
+	}
+}
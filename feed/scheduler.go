@@ -1,15 +1,22 @@
 package feed
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/md5"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/parser"
@@ -17,132 +24,401 @@ import (
 )
 
 type Scheduler struct {
-	ops    chan feedOp
-	client *http.Client
-	log    log.Log
+	ops       chan scheduleOp
+	stopped   chan struct{}
+	client    *http.Client
+	intervals Intervals
+	limits    Limits
+	source    SourceFunc
+	jars      *cookieJars
+	log       log.Log
+}
+
+// Limits bound the number of concurrent feed downloads.
+type Limits struct {
+	// Workers is the number of feeds downloaded at the same time.
+	Workers int
+	// PerHost is the number of feeds from the same host downloaded at the
+	// same time. A zero value disables the limit.
+	PerHost int
 }
 
 type UpdateData struct {
-	Feed    parser.Feed
-	message string
+	Feed parser.Feed
+
+	// ETag and LastModified hold the cache validators sent by the server
+	// along with the feed content.
+	ETag         string
+	LastModified string
+
+	// NextCheck is the time of the next scheduled update of the feed.
+	NextCheck time.Time
+	// Failures is the number of consecutive failed updates.
+	Failures int
+	// Dead is set when the feed has failed too many consecutive times.
+	Dead bool
+
+	retryAfter time.Duration
+	message    string
 }
 
-func NewScheduler(log log.Log) Scheduler {
+// NewScheduler creates a feed scheduler. The optional source function
+// provides the credentials and scraper selectors for each feed download.
+func NewScheduler(intervals Intervals, limits Limits, source SourceFunc, log log.Log) Scheduler {
 	return Scheduler{
-		ops:    make(chan feedOp),
-		client: &http.Client{Timeout: 30 * time.Second},
-		log:    log,
+		ops:       make(chan scheduleOp),
+		stopped:   make(chan struct{}),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		intervals: intervals,
+		limits:    limits,
+		source:    source,
+		jars:      &cookieJars{jars: map[content.FeedID]http.CookieJar{}},
+		log:       log,
 	}
 }
 
-type feedOp func(feedMap)
-type feedMap map[content.FeedID]schedulePayload
+type scheduleOp func(*queue)
 
 type schedulePayload struct {
+	ctx        context.Context
 	feed       content.Feed
+	host       string
 	update     time.Duration
 	updateData chan UpdateData
+
+	state    fetchState
+	due      time.Time
+	index    int
+	fetching bool
+	removed  bool
+	// forced is set for refreshed feeds, which are downloaded even during
+	// their skipped hours and days.
+	forced bool
+}
+
+// fetchState holds the data used to detect whether the feed content has
+// changed since the previous download.
+type fetchState struct {
+	contentHash  []byte
+	etag         string
+	lastModified string
+
+	interval time.Duration
+	failures int
 }
 
 func (s Scheduler) ScheduleFeed(ctx context.Context, feed content.Feed, update time.Duration) <-chan UpdateData {
 	ret := make(chan UpdateData)
 
-	s.ops <- func(feedMap feedMap) {
-		if _, ok := feedMap[feed.ID]; ok {
+	s.ops <- func(q *queue) {
+		if _, ok := q.feeds[feed.ID]; ok {
 			return
 		}
 
-		payload := schedulePayload{
+		due := time.Now()
+		// Honor the schedule computed before a restart.
+		if feed.NextCheck.After(due) {
+			due = feed.NextCheck
+		}
+
+		q.add(&schedulePayload{
+			ctx:        ctx,
 			feed:       feed,
+			host:       feedHost(feed),
 			update:     update,
 			updateData: ret,
-		}
-		feedMap[feed.ID] = payload
+			state:      fetchState{etag: feed.ETag, lastModified: feed.LastModified, failures: feed.FailureCount},
+			due:        due,
+		})
 
-		go s.updateFeed(ctx, payload, []byte{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.unscheduleFeed(feed)
+			case <-s.stopped:
+			}
+		}()
 	}
 
 	return ret
 }
 
-func (s Scheduler) unscheduleFeed(ctx context.Context, feed content.Feed) {
-	s.ops <- func(feedMap feedMap) {
-		s.log.Infof("Unscheduling updates for feed %s", feed)
-		payload := feedMap[feed.ID]
-		close(payload.updateData)
+// Refresh moves the scheduled feed to the front of the queue, so that it is
+// downloaded as soon as a worker and its host are free, regardless of its
+// skipped hours and days. The update is sent
+// to the feed's regular channel. It returns false if the feed is not
+// scheduled.
+func (s Scheduler) Refresh(feed content.Feed) bool {
+	ret := make(chan bool)
 
-		delete(feedMap, feed.ID)
+	s.ops <- func(q *queue) {
+		ret <- q.refresh(feed.ID)
 	}
+
+	return <-ret
+}
+
+// Download downloads a feed that is not scheduled, outside of the queue.
+// The feed cache validators and failure count are taken from the feed
+// itself.
+func (s Scheduler) Download(feed content.Feed, update time.Duration) UpdateData {
+	state := fetchState{etag: feed.ETag, lastModified: feed.LastModified, failures: feed.FailureCount}
+
+	data, state := s.downloadFeed(feed, state)
+	data, _ = s.schedule(data, state, update, time.Now())
+
+	return data
+}
+
+// Stats returns the current state of the scheduling queue and the download
+// workers.
+func (s Scheduler) Stats() Stats {
+	ret := make(chan Stats)
+
+	s.ops <- func(q *queue) {
+		ret <- q.stats(time.Now())
+	}
+
+	return <-ret
 }
 
-func (s Scheduler) updateFeed(ctx context.Context, payload schedulePayload, contentHash []byte) {
+func (s Scheduler) unscheduleFeed(feed content.Feed) {
 	select {
-	case <-ctx.Done():
-		s.unscheduleFeed(ctx, payload.feed)
-		return
-	default:
-		var data UpdateData
-		feed := payload.feed
-		now := time.Now()
+	case s.ops <- func(q *queue) {
+		s.log.Infof("Unscheduling updates for feed %s", feed)
+		q.remove(feed.ID)
+	}:
+	case <-s.stopped:
+	}
+}
 
-		if len(contentHash) == 0 || (!feed.SkipHours[now.Hour()] && !feed.SkipDays[now.Weekday().String()]) {
-			data, contentHash = s.downloadFeed(payload, contentHash)
-		}
+func (s fetchState) empty() bool {
+	return len(s.contentHash) == 0 && s.etag == "" && s.lastModified == ""
+}
 
+func (s Scheduler) worker(ctx context.Context, jobs <-chan *schedulePayload) {
+	for {
 		select {
-		case <-ctx.Done():
-			s.unscheduleFeed(ctx, payload.feed)
-			return
-		default:
-			s.log.Debugf("Sending update data for feed %s", payload.feed)
-			if data.isUpdated() || data.IsErr() {
-				payload.updateData <- data
+		case payload := <-jobs:
+			data, state := s.updateFeed(payload)
+
+			if payload.ctx.Err() == nil {
+				s.log.Debugf("Sending update data for feed %s", payload.feed)
+				select {
+				case payload.updateData <- data:
+				case <-payload.ctx.Done():
+				}
 			}
 
-			<-time.After(payload.update)
-			s.updateFeed(ctx, payload, contentHash)
+			select {
+			case s.ops <- func(q *queue) {
+				q.done(payload, data.NextCheck, state)
+			}:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
-func (s Scheduler) downloadFeed(payload schedulePayload, contentHash []byte) (UpdateData, []byte) {
+func (s Scheduler) updateFeed(payload *schedulePayload) (UpdateData, fetchState) {
 	feed := payload.feed
+	state := payload.state
+	now := time.Now()
+
+	if !payload.forced && !state.empty() && (feed.SkipHours[now.Hour()] || feed.SkipDays[now.Weekday().String()]) {
+		return s.skip(state, payload.update, now)
+	}
+
+	data, state := s.downloadFeed(feed, state)
+
+	return s.schedule(data, state, payload.update, now)
+}
+
+// skip computes the time of the next update of a feed that was not
+// downloaded. The failures of the feed are left as they are.
+func (s Scheduler) skip(state fetchState, base time.Duration, now time.Time) (UpdateData, fetchState) {
+	if state.interval == 0 {
+		state.interval = s.intervals.clamp(base, 0)
+	}
+
+	interval := state.interval
+	if state.failures > 0 {
+		interval = s.intervals.Backoff(state.interval, state.failures)
+	}
+
+	return UpdateData{
+		ETag:         state.etag,
+		LastModified: state.lastModified,
+		NextCheck:    now.Add(interval),
+		Failures:     state.failures,
+		Dead:         s.intervals.IsDead(state.failures),
+	}, state
+}
 
+func (s Scheduler) downloadFeed(feed content.Feed, state fetchState) (UpdateData, fetchState) {
 	s.log.Infof("Downloading content for feed %s", feed)
-	resp, err := s.client.Get(feed.Link)
+
+	source := Source{Scraper: feed.Scraper}
+	if s.source != nil {
+		var err error
+		if source, err = s.source(feed); err != nil {
+			return UpdateData{message: err.Error()}, state
+		}
+	}
+
+	req, err := NewRequest(feed.Link, source.Auth)
+	if err != nil {
+		return UpdateData{message: err.Error()}, state
+	}
+
+	var jar http.CookieJar
+	if source.Auth.CookieJar {
+		jar = s.jars.get(feed.ID)
+		for _, c := range jar.Cookies(req.URL) {
+			req.AddCookie(c)
+		}
+	}
+
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	if state.etag != "" {
+		req.Header.Set("If-None-Match", state.etag)
+	}
+	if state.lastModified != "" {
+		req.Header.Set("If-Modified-Since", state.lastModified)
+	}
+
+	resp, err := s.client.Do(req)
+
+	if err == nil && jar != nil {
+		jar.SetCookies(req.URL, resp.Cookies())
+	}
 
 	if err != nil {
-		return UpdateData{message: err.Error()}, contentHash
+		return UpdateData{message: err.Error()}, state
+	} else if resp.StatusCode == http.StatusNotModified {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		s.log.Debugf("Feed %s not modified", feed)
+		return UpdateData{}, state
 	} else if resp.StatusCode != http.StatusOK {
 		io.Copy(ioutil.Discard, resp.Body)
 		resp.Body.Close()
 
-		return UpdateData{message: "HTTP Status: " + strconv.Itoa(resp.StatusCode)}, contentHash
+		return UpdateData{
+			message:    "HTTP Status: " + strconv.Itoa(resp.StatusCode),
+			retryAfter: retryAfter(resp, time.Now()),
+		}, state
 	} else {
 		defer resp.Body.Close()
 
+		body, err := decodeBody(resp)
+		if err != nil {
+			return UpdateData{message: err.Error()}, state
+		}
+		defer body.Close()
+
 		buf := pool.Buffer.Get()
 		defer pool.Buffer.Put(buf)
 
-		if _, err := buf.ReadFrom(resp.Body); err == nil {
+		if _, err := buf.ReadFrom(body); err == nil {
+			state.etag = resp.Header.Get("ETag")
+			state.lastModified = resp.Header.Get("Last-Modified")
+
 			hash := md5.Sum(buf.Bytes())
-			if bytes.Equal(contentHash, hash[:]) {
-				return UpdateData{}, contentHash
+			if bytes.Equal(state.contentHash, hash[:]) {
+				return UpdateData{}, state
 			}
 
-			contentHash = hash[:]
-			if pf, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
-				return UpdateData{Feed: pf}, contentHash
+			state.contentHash = hash[:]
+
+			var pf parser.Feed
+			if source.Scraper != nil {
+				pf, err = Scrape(buf.Bytes(), feed.Link, *source.Scraper)
+			} else {
+				pf, err = parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1)
+			}
+
+			if err == nil {
+				return UpdateData{Feed: pf, ETag: state.etag, LastModified: state.lastModified}, state
 			} else {
-				return UpdateData{message: err.Error()}, contentHash
+				return UpdateData{message: err.Error()}, state
 			}
 		} else {
-			return UpdateData{message: err.Error()}, contentHash
+			return UpdateData{message: err.Error()}, state
+		}
+	}
+}
+
+// schedule computes the time of the next update of the feed, depending on
+// the outcome of the current one.
+func (s Scheduler) schedule(data UpdateData, state fetchState, base time.Duration, now time.Time) (UpdateData, fetchState) {
+	if state.interval == 0 {
+		state.interval = s.intervals.clamp(base, 0)
+	}
+
+	interval := state.interval
+	if data.IsErr() {
+		state.failures++
+		interval = s.intervals.Backoff(state.interval, state.failures)
+
+		if data.retryAfter > interval {
+			interval = data.retryAfter
+		}
+
+		data.Dead = s.intervals.IsDead(state.failures)
+	} else {
+		if data.IsUpdated() {
+			state.interval = s.intervals.Adaptive(data.Feed, base, now)
+			interval = state.interval
 		}
+
+		state.failures = 0
+		data.ETag = state.etag
+		data.LastModified = state.lastModified
+	}
+
+	data.Failures = state.failures
+	data.NextCheck = now.Add(interval)
+
+	return data, state
+}
+
+// decodeBody wraps the response body with a decompressing reader, depending
+// on the response content encoding.
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating gzip reader")
+		}
+
+		return r, nil
+	case "deflate":
+		// Some servers send a raw deflate stream instead of the zlib
+		// format, as mandated by the spec.
+		br := bufio.NewReader(resp.Body)
+		if header, err := br.Peek(2); err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			r, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating zlib reader")
+			}
+
+			return r, nil
+		}
+
+		return flate.NewReader(br), nil
+	default:
+		return ioutil.NopCloser(resp.Body), nil
 	}
 }
 
-func (u UpdateData) isUpdated() bool {
+// IsUpdated reports whether the update contains new feed content.
+func (u UpdateData) IsUpdated() bool {
 	return len(u.Feed.Articles) > 0 && !u.IsErr()
 }
 
@@ -155,14 +431,49 @@ func (u UpdateData) Error() string {
 }
 
 func (s Scheduler) Start(ctx context.Context) {
-	feedMap := feedMap{}
+	defer close(s.stopped)
+
+	q := newQueue(s.limits)
+
+	jobs := make(chan *schedulePayload, q.workers())
+	for i := 0; i < q.workers(); i++ {
+		go s.worker(ctx, jobs)
+	}
 
 	for {
+		now := time.Now()
+		for _, payload := range q.due(now) {
+			jobs <- payload
+		}
+
+		var timer *time.Timer
+		var wake <-chan time.Time
+		if d, ok := q.wait(now); ok {
+			timer = time.NewTimer(d)
+			wake = timer.C
+		}
+
 		select {
 		case op := <-s.ops:
-			op(feedMap)
+			op(q)
+		case <-wake:
 		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
 			return
 		}
+
+		if timer != nil {
+			timer.Stop()
+		}
 	}
 }
+
+func feedHost(feed content.Feed) string {
+	if u, err := url.Parse(feed.Link); err == nil {
+		return strings.ToLower(u.Hostname())
+	}
+
+	return ""
+}
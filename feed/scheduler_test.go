@@ -1,10 +1,13 @@
 package feed
 
 import (
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +35,10 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 		{"404", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/404"}, time.Second}, []int{-1}},
 		{"http error then update", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/error-update"}, time.Second}, []int{-1, 2}},
 		{"same content", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/same-content"}, 100 * time.Millisecond}, []int{2, 1}},
+		{"not modified", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/not-modified"}, 100 * time.Millisecond}, []int{2, 1}},
+		{"stored validators", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/stored-validators", ETag: `"v1"`}, 100 * time.Millisecond}, []int{1}},
+		{"gzip", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/gzip"}, time.Second}, []int{2}},
+		{"deflate", time.Second, args{2 * time.Second, content.Feed{ID: 100, Link: "/deflate"}, time.Second}, []int{2}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -60,6 +67,46 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 					} else {
 						w.Write([]byte(rss2Xmlv2))
 					}
+				case "/not-modified":
+					switch {
+					case iter == 0:
+						w.Header().Set("ETag", `"v1"`)
+						w.Write([]byte(rss2Xml))
+					case iter == 1 && r.Header.Get("If-None-Match") == `"v1"`:
+						w.WriteHeader(http.StatusNotModified)
+					case iter == 1:
+						w.WriteHeader(http.StatusInternalServerError)
+					default:
+						w.Header().Set("ETag", `"v2"`)
+						w.Write([]byte(rss2Xmlv2))
+					}
+				case "/stored-validators":
+					if iter == 0 && r.Header.Get("If-None-Match") == `"v1"` {
+						w.WriteHeader(http.StatusNotModified)
+					} else if iter == 0 {
+						w.WriteHeader(http.StatusInternalServerError)
+					} else {
+						w.Header().Set("ETag", `"v2"`)
+						w.Write([]byte(rss2Xmlv2))
+					}
+				case "/gzip":
+					if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+						w.WriteHeader(http.StatusInternalServerError)
+						break
+					}
+					w.Header().Set("Content-Encoding", "gzip")
+					gw := gzip.NewWriter(w)
+					gw.Write([]byte(rss2Xml))
+					gw.Close()
+				case "/deflate":
+					if !strings.Contains(r.Header.Get("Accept-Encoding"), "deflate") {
+						w.WriteHeader(http.StatusInternalServerError)
+						break
+					}
+					w.Header().Set("Content-Encoding", "deflate")
+					zw := zlib.NewWriter(w)
+					zw.Write([]byte(rss2Xml))
+					zw.Close()
 				}
 				iter++
 			}))
@@ -71,9 +118,12 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 			cfg := config.Log{}
 			cfg.Converted.Writer = os.Stderr
 			s := Scheduler{
-				ops:    make(chan feedOp),
-				client: &http.Client{Timeout: tt.connectTimeout},
-				log:    log.WithStd(cfg),
+				ops:       make(chan scheduleOp),
+				stopped:   make(chan struct{}),
+				client:    &http.Client{Timeout: tt.connectTimeout},
+				intervals: Intervals{Min: tt.args.update, Max: tt.args.update},
+				limits:    Limits{Workers: 2, PerHost: 1},
+				log:       log.WithStd(cfg),
 			}
 
 			go s.Start(ctx)
@@ -98,6 +148,10 @@ func TestScheduler_ScheduleFeed(t *testing.T) {
 			for {
 				select {
 				case data, ok := <-up:
+					if ok && !data.IsUpdated() && !data.IsErr() {
+						continue
+					}
+
 					if tt.want[i] == -1 {
 						if !ok {
 							return
@@ -207,3 +261,40 @@ const (
 </rss>
 `
 )
+
+func TestScheduler_updateFeedSkipped(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+
+	s := NewScheduler(Intervals{}, Limits{}, nil, log.WithStd(cfg))
+
+	skipHours := map[int]bool{}
+	for h := 0; h < 24; h++ {
+		skipHours[h] = true
+	}
+
+	payload := &schedulePayload{
+		feed:   content.Feed{ID: 1, Link: ts.URL, SkipHours: skipHours},
+		update: time.Hour,
+		state:  fetchState{etag: `"v1"`, failures: 2},
+	}
+
+	// A skipped poll keeps the failures of the feed.
+	data, state := s.updateFeed(payload)
+	if requests != 0 || data.Failures != 2 || state.failures != 2 {
+		t.Errorf("Scheduler.updateFeed() requests = %d, failures = %d, want 0, 2", requests, data.Failures)
+	}
+
+	// Refreshed feeds are downloaded regardless.
+	payload.forced = true
+	if data, _ = s.updateFeed(payload); requests != 1 || data.Failures != 3 {
+		t.Errorf("Scheduler.updateFeed() forced requests = %d, failures = %d, want 1, 3", requests, data.Failures)
+	}
+}
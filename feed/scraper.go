@@ -0,0 +1,126 @@
+package feed
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/parser"
+	"github.com/urandom/readeef/pool"
+)
+
+// Scrape generates a feed from the html page found at the link, using the
+// selectors of the scraper.
+func Scrape(source []byte, link string, s content.Scraper) (parser.Feed, error) {
+	if err := s.Validate(); err != nil {
+		return parser.Feed{}, err
+	}
+
+	base, err := url.Parse(link)
+	if err != nil {
+		return parser.Feed{}, errors.Wrapf(err, "parsing link %s", link)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(source))
+	if err != nil {
+		return parser.Feed{}, errors.Wrap(err, "parsing html")
+	}
+
+	pf := parser.Feed{
+		Title:    strings.TrimSpace(doc.Find("title").First().Text()),
+		SiteLink: link,
+	}
+
+	if desc, ok := doc.Find(`meta[name="description"]`).Attr("content"); ok {
+		pf.Description = strings.TrimSpace(desc)
+	}
+
+	now := time.Now()
+	doc.Find(s.Item).Each(func(i int, item *goquery.Selection) {
+		article := parser.Article{
+			Title: strings.TrimSpace(item.Find(s.Title).First().Text()),
+		}
+
+		linkSel := item.Find("a[href]").First()
+		if s.Link != "" {
+			linkSel = item.Find(s.Link).First()
+		}
+
+		if href, ok := linkSel.Attr("href"); ok {
+			if u, err := base.Parse(strings.TrimSpace(href)); err == nil {
+				article.Link = u.String()
+			}
+		}
+
+		if article.Title == "" || article.Link == "" {
+			return
+		}
+
+		article.Guid = article.Link
+
+		contentSel := item
+		if s.Content != "" {
+			contentSel = item.Find(s.Content).First()
+		}
+		article.Description, _ = contentSel.Html()
+		article.Description = strings.TrimSpace(article.Description)
+
+		if s.Date != "" {
+			dateSel := item.Find(s.Date).First()
+			date, ok := dateSel.Attr("datetime")
+			if !ok {
+				date = dateSel.Text()
+			}
+
+			if d, err := parser.ParseDate(date); err == nil {
+				article.Date = d
+			}
+		}
+
+		// Keep the page order for the items without a date.
+		if article.Date.IsZero() {
+			article.Date = now.Add(-time.Duration(i) * time.Second)
+		}
+
+		pf.Articles = append(pf.Articles, article)
+	})
+
+	if len(pf.Articles) == 0 {
+		return pf, errors.New("no items found with the scraper selectors")
+	}
+
+	return pf, nil
+}
+
+// ScrapeLink downloads the html page at the link and generates a feed from
+// it.
+func ScrapeLink(link string, s content.Scraper, auth content.FeedAuth) (parser.Feed, error) {
+	req, err := NewRequest(link, auth)
+	if err != nil {
+		return parser.Feed{}, errors.Wrapf(err, "creating request for link %s", link)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return parser.Feed{}, errors.Wrapf(err, "getting link %s", link)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return parser.Feed{}, errors.Errorf("getting link %s, invalid status code: %d (%s)", link, resp.StatusCode, resp.Status)
+	}
+
+	buf := pool.Buffer.Get()
+	defer pool.Buffer.Put(buf)
+
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return parser.Feed{}, errors.Wrapf(err, "reading link %s", link)
+	}
+
+	return Scrape(buf.Bytes(), link, s)
+}
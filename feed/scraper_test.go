@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/urandom/readeef/content"
+)
+
+const scraperPage = `<html>
+<head><title> News page </title><meta name="description" content="Latest news"></head>
+<body>
+	<div class="post">
+		<h2>First post</h2>
+		<a class="more" href="/posts/1">Read more</a>
+		<time datetime="2020-01-02T15:04:05Z">Jan 2</time>
+		<p class="body">First <b>body</b></p>
+	</div>
+	<div class="post">
+		<h2>Second post</h2>
+		<a href="http://other.com/2">Read more</a>
+		<p class="body">Second body</p>
+	</div>
+	<div class="post">
+		<h2>No link</h2>
+	</div>
+</body>
+</html>`
+
+func TestScrape(t *testing.T) {
+	tests := []struct {
+		name      string
+		scraper   content.Scraper
+		wantLinks []string
+		wantDesc  []string
+		wantDate  time.Time
+		wantErr   bool
+	}{
+		{name: "invalid", scraper: content.Scraper{Item: "div.post"}, wantErr: true},
+		{name: "no items", scraper: content.Scraper{Item: "li", Title: "h2"}, wantErr: true},
+		{
+			name:      "defaults",
+			scraper:   content.Scraper{Item: "div.post", Title: "h2"},
+			wantLinks: []string{"http://example.com/posts/1", "http://other.com/2"},
+		},
+		{
+			name:      "all selectors",
+			scraper:   content.Scraper{Item: "div.post", Title: "h2", Link: "a.more", Date: "time", Content: "p.body"},
+			wantLinks: []string{"http://example.com/posts/1"},
+			wantDesc:  []string{"First <b>body</b>"},
+			wantDate:  time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Scrape([]byte(scraperPage), "http://example.com/news", tt.scraper)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scrape() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.Title != "News page" || got.Description != "Latest news" || got.SiteLink != "http://example.com/news" {
+				t.Errorf("Scrape() feed = %q, %q, %q", got.Title, got.Description, got.SiteLink)
+			}
+
+			if len(got.Articles) != len(tt.wantLinks) {
+				t.Fatalf("Scrape() articles = %d, want %d", len(got.Articles), len(tt.wantLinks))
+			}
+
+			for i, a := range got.Articles {
+				if a.Link != tt.wantLinks[i] || a.Guid != a.Link {
+					t.Errorf("Scrape() article %d link = %q, want %q", i, a.Link, tt.wantLinks[i])
+				}
+
+				if i < len(tt.wantDesc) && a.Description != tt.wantDesc[i] {
+					t.Errorf("Scrape() article %d description = %q, want %q", i, a.Description, tt.wantDesc[i])
+				}
+
+				if a.Date.IsZero() {
+					t.Errorf("Scrape() article %d without a date", i)
+				}
+			}
+
+			if !tt.wantDate.IsZero() && !got.Articles[0].Date.Equal(tt.wantDate) {
+				t.Errorf("Scrape() date = %v, want %v", got.Articles[0].Date, tt.wantDate)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/pkg/errors"
+	"github.com/urandom/readeef/content"
 	"github.com/urandom/readeef/log"
 	"github.com/urandom/readeef/parser"
 	"github.com/urandom/readeef/pool"
@@ -19,34 +20,43 @@ var (
 	domainPattern  = regexp.MustCompile(`^(?:[a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}$`)
 	commentPattern = regexp.MustCompile("<!--.*?-->")
 	linkPattern    = regexp.MustCompile(`<link ([^>]+)>`)
+
+	// feedLinkTypes are the alternate link types that point to a feed.
+	feedLinkTypes = []string{"application/rss+xml", "application/feed+json"}
 )
 
 func Search(query string, log log.Log) (map[string]parser.Feed, error) {
+	return SearchWithAuth(query, content.FeedAuth{}, log)
+}
+
+// SearchWithAuth searches for feeds like Search, using the provided
+// credentials when the query is a url.
+func SearchWithAuth(query string, auth content.FeedAuth, log log.Log) (map[string]parser.Feed, error) {
 	if u, err := url.Parse(query); err == nil && (u.IsAbs() || domainPattern.MatchString(u.String())) {
 		if u.Scheme == "" {
 			u.Scheme = "http"
 		}
 
-		return searchByURL(u, log)
+		return searchByURL(u, auth, log)
 	}
 
 	// Assume the query is not a url
 	return searchByQuery(query, log)
 }
 
-func searchByURL(u *url.URL, log log.Log) (map[string]parser.Feed, error) {
+func searchByURL(u *url.URL, auth content.FeedAuth, log log.Log) (map[string]parser.Feed, error) {
 	log.Infof("Searching for feeds from url %s", u)
 	if u.Scheme == "http" {
 		u.Scheme = "https"
 
-		if feeds, err := downloadLinkContent(u, log); err == nil {
+		if feeds, err := downloadLinkContent(u, auth, log); err == nil {
 			return feeds, nil
 		}
 
 		u.Scheme = "http"
 	}
 
-	feeds, err := downloadLinkContent(u, log)
+	feeds, err := downloadLinkContent(u, auth, log)
 	if err != nil {
 		return nil, errors.WithMessage(err, "searching by url "+u.String())
 	}
@@ -114,7 +124,7 @@ func searchByQuery(query string, log log.Log) (map[string]parser.Feed, error) {
 	for i := 0; i < numProviders; i++ {
 		go func() {
 			for u := range input {
-				res, err := downloadLinkContent(u, log)
+				res, err := downloadLinkContent(u, content.FeedAuth{}, log)
 				log.Debugf("Ending download for %s", u)
 				output <- out{res, err}
 			}
@@ -152,9 +162,14 @@ func searchByQuery(query string, log log.Log) (map[string]parser.Feed, error) {
 	return parsed, nil
 }
 
-func downloadLinkContent(u *url.URL, log log.Log) (map[string]parser.Feed, error) {
+func downloadLinkContent(u *url.URL, auth content.FeedAuth, log log.Log) (map[string]parser.Feed, error) {
 	log.Debugf("Downloading content from %s", u)
-	resp, err := http.Get(u.String())
+	req, err := NewRequest(u.String(), auth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating request for link %s", u)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, errors.Wrapf(err, "getting link %s", u)
 	}
@@ -168,7 +183,7 @@ func downloadLinkContent(u *url.URL, log log.Log) (map[string]parser.Feed, error
 
 	buf.ReadFrom(resp.Body)
 
-	if feed, err := parser.ParseFeed(buf.Bytes(), parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
+	if feed, err := parser.ParseFeed(buf.Bytes(), parser.ParseJSONFeed, parser.ParseRss2, parser.ParseAtom, parser.ParseRss1); err == nil {
 		return map[string]parser.Feed{u.String(): feed}, nil
 	}
 
@@ -178,7 +193,7 @@ func downloadLinkContent(u *url.URL, log log.Log) (map[string]parser.Feed, error
 	feeds := map[string]parser.Feed{}
 	for _, l := range links {
 		attrs := l[1]
-		if strings.Contains(attrs, `"application/rss+xml"`) || strings.Contains(attrs, `'application/rss+xml'`) {
+		if isFeedLink(attrs) {
 			index := strings.Index(attrs, "href=")
 			attr := attrs[index+6:]
 			index = strings.IndexByte(attr, attrs[index+5])
@@ -196,7 +211,7 @@ func downloadLinkContent(u *url.URL, log log.Log) (map[string]parser.Feed, error
 
 				}
 
-				feedMap, err := downloadLinkContent(docURL, log)
+				feedMap, err := downloadLinkContent(docURL, auth, log)
 				if err != nil {
 					return nil, err
 				}
@@ -210,3 +225,13 @@ func downloadLinkContent(u *url.URL, log log.Log) (map[string]parser.Feed, error
 
 	return feeds, nil
 }
+
+func isFeedLink(attrs string) bool {
+	for _, t := range feedLinkTypes {
+		if strings.Contains(attrs, `"`+t+`"`) || strings.Contains(attrs, `'`+t+`'`) {
+			return true
+		}
+	}
+
+	return false
+}
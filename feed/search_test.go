@@ -0,0 +1,50 @@
+package feed
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/urandom/readeef/config"
+	"github.com/urandom/readeef/content"
+	"github.com/urandom/readeef/log"
+)
+
+func Test_downloadLinkContent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head>
+<link rel="alternate" type="application/feed+json" title="JSON" href="/feed.json">
+</head><body></body></html>`)
+		case "/feed.json":
+			w.Header().Set("Content-Type", "application/feed+json")
+			fmt.Fprint(w, `{"version": "https://jsonfeed.org/version/1.1", "title": "JSON Feed", "items": [{"id": "1", "content_text": "text"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := config.Log{}
+	cfg.Converted.Writer = os.Stderr
+
+	u, _ := url.Parse(ts.URL + "/")
+
+	feeds, err := downloadLinkContent(u, content.FeedAuth{}, log.WithStd(cfg))
+	if err != nil {
+		t.Fatalf("downloadLinkContent() error = %v", err)
+	}
+
+	pf, ok := feeds[ts.URL+"/feed.json"]
+	if !ok || len(feeds) != 1 {
+		t.Fatalf("downloadLinkContent() = %v, want the json feed", feeds)
+	}
+
+	if pf.Title != "JSON Feed" || len(pf.Articles) != 1 {
+		t.Errorf("downloadLinkContent() feed = %#v", pf)
+	}
+}
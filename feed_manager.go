@@ -3,7 +3,9 @@ package readeef
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"regexp"
+	"sync"
 	"time"
 
 	"net/url"
@@ -28,6 +30,16 @@ type FeedManager struct {
 	hubbub           *Hubbub
 	scheduler        feed.Scheduler
 	parserProcessors []processor.Feed
+
+	started   bool
+	refreshMu sync.Mutex
+	refreshes map[content.FeedID][]chan refreshResult
+}
+
+// refreshResult is the outcome of a requested feed refresh.
+type refreshResult struct {
+	count int
+	err   error
 }
 
 var (
@@ -40,11 +52,22 @@ var (
 )
 
 func NewFeedManager(repo repo.Feed, c config.Config, l log.Log) *FeedManager {
-	return &FeedManager{
+	fm := &FeedManager{
 		repo: repo, config: c, log: l,
 		ops:       make(chan func(context.Context, *FeedManager)),
-		scheduler: feed.NewScheduler(l),
+		refreshes: map[content.FeedID][]chan refreshResult{},
 	}
+
+	fm.scheduler = feed.NewScheduler(feed.Intervals{
+		Min:       c.FeedManager.Converted.MinUpdateInterval,
+		Max:       c.FeedManager.Converted.MaxUpdateInterval,
+		DeadAfter: c.FeedManager.DeadAfter,
+	}, feed.Limits{
+		Workers: c.FeedManager.FetchWorkers,
+		PerHost: c.FeedManager.FetchWorkersPerHost,
+	}, fm.feedSource, l)
+
+	return fm
 }
 
 func (fm *FeedManager) SetHubbub(hubbub *Hubbub) {
@@ -58,6 +81,7 @@ func (fm *FeedManager) AddFeedProcessor(p processor.Feed) {
 func (fm *FeedManager) Start(ctx context.Context) error {
 	fm.log.Infoln("Starting the feed manager")
 
+	fm.started = true
 	go fm.loop(ctx)
 	go fm.scheduler.Start(ctx)
 
@@ -66,15 +90,27 @@ func (fm *FeedManager) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Spread out the initial downloads of the feeds that are already due.
+	now := time.Now()
+	jitter := fm.config.FeedManager.Converted.StartupJitter
 	for _, f := range feeds {
 		fm.log.Infoln("Scheduling feed " + f.String())
 
+		if jitter > 0 && !f.NextCheck.After(now) {
+			f.NextCheck = now.Add(time.Duration(rand.Int63n(int64(jitter))))
+		}
+
 		fm.AddFeed(f)
 	}
 
 	return nil
 }
 
+// SchedulerStats returns the state of the feed update queue.
+func (fm *FeedManager) SchedulerStats() feed.Stats {
+	return fm.scheduler.Stats()
+}
+
 func (fm *FeedManager) AddFeed(feed content.Feed) {
 	fm.ops <- func(ctx context.Context, fm *FeedManager) {
 		fm.startUpdatingFeed(ctx, feed)
@@ -87,7 +123,46 @@ func (fm *FeedManager) RemoveFeed(feed content.Feed) {
 	}
 }
 
-func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
+// RefreshFeed moves the feed to the front of the update queue, and waits for
+// the result to be stored. Feeds that are not scheduled, such as when the
+// manager is not started, are downloaded right away. It returns the number
+// of new articles.
+func (fm *FeedManager) RefreshFeed(f content.Feed) (int, error) {
+	fm.log.Infof("Refreshing feed %s", f)
+
+	if fm.started {
+		c := make(chan refreshResult, 1)
+
+		fm.refreshMu.Lock()
+		fm.refreshes[f.ID] = append(fm.refreshes[f.ID], c)
+		fm.refreshMu.Unlock()
+
+		if fm.scheduler.Refresh(f) {
+			res := <-c
+			return res.count, errors.WithMessage(res.err, "refreshing feed "+f.String())
+		}
+
+		fm.refreshMu.Lock()
+		for i, w := range fm.refreshes[f.ID] {
+			if w == c {
+				fm.refreshes[f.ID] = append(fm.refreshes[f.ID][:i], fm.refreshes[f.ID][i+1:]...)
+				break
+			}
+		}
+		fm.refreshMu.Unlock()
+	}
+
+	_, articles, err := fm.applyUpdate(f, fm.scheduler.Download(f, fm.updateInterval(f)))
+	if err != nil {
+		return 0, errors.WithMessage(err, "refreshing feed "+f.String())
+	}
+
+	return len(articles), nil
+}
+
+// AddFeedByLink adds the feed found at the given link. The optional
+// credentials are stored along with the feed and used for its downloads.
+func (fm *FeedManager) AddFeedByLink(link string, auth content.FeedAuth) (content.Feed, error) {
 	u, err := url.Parse(link)
 	if err == nil {
 		if !u.IsAbs() {
@@ -104,10 +179,15 @@ func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
 		return f, err
 	}
 
+	credentials, cerr := auth.Encrypt(fm.secret())
+	if cerr != nil {
+		return content.Feed{}, errors.WithMessage(cerr, "encrypting feed credentials")
+	}
+
 	if err != nil {
 		fm.log.Infoln("Discovering feeds in " + link)
 
-		parsedFeeds, err := feed.Search(link, fm.log)
+		parsedFeeds, err := feed.SearchWithAuth(link, fm.defaultAuth(auth), fm.log)
 		if err != nil {
 			return content.Feed{}, errors.WithMessage(err, "searching for feeds")
 		}
@@ -119,9 +199,24 @@ func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
 			break
 		}
 
+		f.Credentials = credentials
 		if _, err = fm.repo.Update(&f); err != nil {
 			return content.Feed{}, errors.WithMessage(err, "updating feed with parsed data")
 		}
+	} else if !auth.Empty() {
+		// The feed is shared by all of its subscribers, so credentials that
+		// are already set, possibly by another user, are not replaced.
+		if f.Credentials != "" {
+			existing, err := content.DecryptFeedAuth(f.Credentials, fm.secret())
+			if err != nil || !existing.Equal(auth) {
+				return content.Feed{}, content.NewValidationError(errors.New("feed already has different credentials"))
+			}
+		} else {
+			f.Credentials = credentials
+			if err = fm.repo.SetCredentials(f); err != nil {
+				return content.Feed{}, errors.WithMessage(err, "setting feed credentials")
+			}
+		}
 	}
 
 	fm.log.Infoln("Adding feed " + f.String() + " to manager")
@@ -130,6 +225,87 @@ func (fm *FeedManager) AddFeedByLink(link string) (content.Feed, error) {
 	return f, nil
 }
 
+// PreviewScraper generates a feed from the html page at the link, without
+// storing it.
+func (fm *FeedManager) PreviewScraper(link string, scraper content.Scraper, auth content.FeedAuth) (content.Feed, error) {
+	pf, err := feed.ScrapeLink(link, scraper, fm.defaultAuth(auth))
+	if err != nil {
+		return content.Feed{}, errors.WithMessage(err, "scraping "+link)
+	}
+
+	f := content.Feed{Link: link, Scraper: &scraper}
+	f.Refresh(fm.processParserFeed(pf))
+
+	return f, nil
+}
+
+// AddScraperFeed adds a feed generated from the html page at the link. An
+// existing scraper feed with the same link is reused as is.
+func (fm *FeedManager) AddScraperFeed(link string, scraper content.Scraper, auth content.FeedAuth) (content.Feed, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return content.Feed{}, err
+	}
+
+	if !u.IsAbs() {
+		return content.Feed{}, errors.New("link not absolute")
+	}
+	u.Fragment = ""
+	link = u.String()
+
+	f, err := fm.repo.FindByLink(link)
+	if err == nil {
+		if f.Scraper == nil {
+			return content.Feed{}, errors.New("a regular feed with the same link exists")
+		}
+	} else if !content.IsNoContent(err) {
+		return f, err
+	} else {
+		fm.log.Infoln("Scraping " + link)
+
+		if f, err = fm.PreviewScraper(link, scraper, auth); err != nil {
+			return content.Feed{}, err
+		}
+
+		if f.Credentials, err = auth.Encrypt(fm.secret()); err != nil {
+			return content.Feed{}, errors.WithMessage(err, "encrypting feed credentials")
+		}
+
+		if _, err = fm.repo.Update(&f); err != nil {
+			return content.Feed{}, errors.WithMessage(err, "updating feed with scraped data")
+		}
+	}
+
+	fm.log.Infoln("Adding feed " + f.String() + " to manager")
+	fm.AddFeed(f)
+
+	return f, nil
+}
+
+// SetScraper changes the selectors of a scraper feed. The selectors are
+// tested against the current page content before they are stored.
+func (fm *FeedManager) SetScraper(f content.Feed, scraper content.Scraper) (content.Feed, error) {
+	if f.Scraper == nil {
+		return f, errors.New("not a scraper feed")
+	}
+
+	source, err := fm.feedSource(f)
+	if err != nil {
+		return f, err
+	}
+
+	if _, err := feed.ScrapeLink(f.Link, scraper, source.Auth); err != nil {
+		return f, errors.WithMessage(err, "scraping "+f.Link)
+	}
+
+	f.Scraper = &scraper
+	if err := fm.repo.SetScraper(f); err != nil {
+		return f, errors.WithMessage(err, "setting feed scraper")
+	}
+
+	return f, nil
+}
+
 func (fm *FeedManager) RemoveFeedByLink(link string) (content.Feed, error) {
 	f, err := fm.repo.FindByLink(link)
 	if err != nil && !content.IsNoContent(err) {
@@ -147,9 +323,10 @@ func (fm *FeedManager) RemoveFeedByLink(link string) (content.Feed, error) {
 	return f, nil
 }
 
-func (fm *FeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
-
-	parsedFeeds, err := feed.Search(link, fm.log)
+// DiscoverFeeds searches for feeds using the link. The optional credentials
+// are used when downloading the link content.
+func (fm *FeedManager) DiscoverFeeds(link string, auth content.FeedAuth) ([]content.Feed, error) {
+	parsedFeeds, err := feed.SearchWithAuth(link, fm.defaultAuth(auth), fm.log)
 	if err != nil {
 		return []content.Feed{}, errors.WithMessage(err, "discovering feeds")
 	}
@@ -165,6 +342,36 @@ func (fm *FeedManager) DiscoverFeeds(link string) ([]content.Feed, error) {
 	return feeds, nil
 }
 
+// feedSource returns the stored credentials and scraper selectors of the
+// feed. The feed is reloaded, since the scheduled copy might predate
+// a change of either.
+func (fm *FeedManager) feedSource(f content.Feed) (feed.Source, error) {
+	if stored, err := fm.repo.FindByLink(f.Link); err == nil {
+		f = stored
+	} else if !content.IsNoContent(err) {
+		return feed.Source{}, errors.WithMessage(err, "getting feed by link")
+	}
+
+	auth, err := content.DecryptFeedAuth(f.Credentials, fm.secret())
+	if err != nil {
+		return feed.Source{}, errors.WithMessage(err, "decrypting feed credentials")
+	}
+
+	return feed.Source{Auth: fm.defaultAuth(auth), Scraper: f.Scraper}, nil
+}
+
+func (fm *FeedManager) defaultAuth(auth content.FeedAuth) content.FeedAuth {
+	if auth.UserAgent == "" {
+		auth.UserAgent = fm.config.FeedManager.UserAgent
+	}
+
+	return auth
+}
+
+func (fm *FeedManager) secret() []byte {
+	return []byte(fm.config.Auth.Secret)
+}
+
 func (fm *FeedManager) loop(ctx context.Context) {
 	for {
 		select {
@@ -185,6 +392,10 @@ func (fm *FeedManager) startUpdatingFeed(ctx context.Context, feed content.Feed)
 		}
 	}
 
+	go fm.scheduleFeed(ctx, feed, fm.updateInterval(feed))
+}
+
+func (fm *FeedManager) updateInterval(feed content.Feed) time.Duration {
 	d := 30 * time.Minute
 	if fm.config.FeedManager.Converted.UpdateInterval != 0 {
 		if feed.TTL != 0 && feed.TTL > fm.config.FeedManager.Converted.UpdateInterval {
@@ -194,21 +405,67 @@ func (fm *FeedManager) startUpdatingFeed(ctx context.Context, feed content.Feed)
 		}
 	}
 
-	go fm.scheduleFeed(ctx, feed, d)
+	return d
 }
 
 func (fm *FeedManager) scheduleFeed(ctx context.Context, feed content.Feed, update time.Duration) {
 	fm.log.Infof("Scheduling update of feed %s", feed)
 	for update := range fm.scheduler.ScheduleFeed(ctx, feed, update) {
-		fm.log.Infof("Update for feed %s", feed)
-		if update.IsErr() {
-			feed.AddUpdateError(fmt.Sprintf("%s: %s", time.Now().Format(time.UnixDate), update.Error()))
-		} else {
-			feed.Refresh(fm.processParserFeed(update.Feed))
-		}
+		var articles []content.Article
+		var err error
 
-		fm.updateFeed(feed)
+		feed, articles, err = fm.applyUpdate(feed, update)
+		fm.refreshed(feed.ID, refreshResult{count: len(articles), err: err})
 	}
+
+	fm.refreshed(feed.ID, refreshResult{err: errors.New("feed is no longer scheduled")})
+}
+
+// refreshed sends the result of a feed update to the pending refreshes.
+func (fm *FeedManager) refreshed(id content.FeedID, res refreshResult) {
+	fm.refreshMu.Lock()
+	waiting := fm.refreshes[id]
+	delete(fm.refreshes, id)
+	fm.refreshMu.Unlock()
+
+	for _, c := range waiting {
+		c <- res
+	}
+}
+
+// applyUpdate stores the outcome of a feed download, returning the modified
+// feed and its new articles.
+func (fm *FeedManager) applyUpdate(f content.Feed, update feed.UpdateData) (content.Feed, []content.Article, error) {
+	if update.Dead && !f.Dead {
+		fm.log.Infof("Feed %s failed %d consecutive times, marking as dead", f, update.Failures)
+	}
+
+	f.NextCheck = update.NextCheck
+	f.FailureCount = update.Failures
+	f.Dead = update.Dead
+
+	if update.IsErr() {
+		fm.log.Infof("Update for feed %s", f)
+		f.AddUpdateError(fmt.Sprintf("%s: %s", time.Now().Format(time.UnixDate), update.Error()))
+
+		fm.updateFeed(f)
+
+		return f, nil, errors.New(update.Error())
+	}
+
+	f.ETag = update.ETag
+	f.LastModified = update.LastModified
+
+	if !update.IsUpdated() {
+		return f, nil, fm.updateFeedSchedule(f)
+	}
+
+	fm.log.Infof("Update for feed %s", f)
+	f.Refresh(fm.processParserFeed(update.Feed))
+
+	articles, err := fm.updateFeed(f)
+
+	return f, articles, err
 }
 
 func (fm *FeedManager) stopUpdatingFeed(feed content.Feed) {
@@ -232,14 +489,25 @@ func (fm *FeedManager) stopUpdatingFeed(feed content.Feed) {
 	}
 }
 
-func (fm FeedManager) updateFeed(feed content.Feed) {
-	if _, err := fm.repo.Update(&feed); err != nil {
+func (fm *FeedManager) updateFeed(feed content.Feed) ([]content.Article, error) {
+	articles, err := fm.repo.Update(&feed)
+	if err != nil {
 		fm.log.Printf("Error updating feed '%s' database record: %+v", feed, err)
 	}
 
+	return articles, err
+}
+
+func (fm *FeedManager) updateFeedSchedule(feed content.Feed) error {
+	err := fm.repo.UpdateSchedule(feed)
+	if err != nil {
+		fm.log.Printf("Error updating feed '%s' schedule: %+v", feed, err)
+	}
+
+	return err
 }
 
-func (fm FeedManager) processParserFeed(pf parser.Feed) parser.Feed {
+func (fm *FeedManager) processParserFeed(pf parser.Feed) parser.Feed {
 	for _, p := range fm.parserProcessors {
 		pf = p.ProcessFeed(pf)
 	}
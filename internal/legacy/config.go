@@ -221,7 +221,9 @@ func (c Config) convertToV2() (config.Config, error) {
 	cfg.Timeout = config.Timeout(c.Timeout)
 	cfg.DB = config.DB(c.DB)
 	cfg.FeedParser = config.FeedParser(c.FeedParser)
-	cfg.FeedManager = config.FeedManager(c.FeedManager)
+	cfg.FeedManager.UpdateInterval = c.FeedManager.UpdateInterval
+	cfg.FeedManager.Monitors = c.FeedManager.Monitors
+	cfg.FeedManager.Converted.UpdateInterval = c.FeedManager.Converted.UpdateInterval
 
 	cfg.Content.Article.Processors = c.Content.ArticleProcessors
 	cfg.Content.Article.ProxyHTTPURLTemplate = c.Content.ProxyHTTPURLTemplate
@@ -4,32 +4,51 @@ import (
 	"bytes"
 	"encoding/xml"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type atomFeed struct {
-	XMLName     xml.Name   `xml:"feed"`
-	Title       string     `xml:"title"`
-	Description string     `xml:"description"`
-	Link        atomLink   `xml:"link"`
-	Image       rssImage   `xml:"image"`
-	Items       []atomItem `xml:"entry"`
+	XMLName     xml.Name     `xml:"feed"`
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	Link        atomLink     `xml:"link"`
+	Image       rssImage     `xml:"image"`
+	Authors     []atomPerson `xml:"author"`
+	Items       []atomItem   `xml:"entry"`
 }
 
 type atomItem struct {
-	XMLName     xml.Name   `xml:"entry"`
-	Id          string     `xml:"id"`
-	Title       string     `xml:"title"`
-	Description rssContent `xml:"summary"`
-	Content     rssContent `xml:"content"`
-	Link        atomLink   `xml:"link"`
-	Date        string     `xml:"updated"`
-	PubDate     string     `xml:"published"`
+	podcastItem
+
+	XMLName     xml.Name       `xml:"entry"`
+	Id          string         `xml:"id"`
+	Title       string         `xml:"title"`
+	Description rssContent     `xml:"summary"`
+	Content     rssContent     `xml:"content"`
+	Links       []atomLink     `xml:"link"`
+	Date        string         `xml:"updated"`
+	PubDate     string         `xml:"published"`
+	Authors     []atomPerson   `xml:"author"`
+	Categories  []atomCategory `xml:"category"`
 }
 
 type atomLink struct {
-	Rel  string `xml:"rel,attr,omitempty"`
-	Href string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
 }
 
 func ParseAtom(b []byte) (Feed, error) {
@@ -52,10 +71,20 @@ func ParseAtom(b []byte) (Feed, error) {
 			rss.Image.Width, rss.Image.Height},
 	}
 
+	feedAuthor := atomAuthors(rss.Authors)
+
 	var lastValidDate time.Time
 	for _, i := range rss.Items {
-		article := Article{Title: i.Title, Link: i.Link.Href, Guid: i.Id}
+		article := Article{Title: i.Title, Link: i.link(), Guid: i.Id}
 		article.Description = getLargerContent(i.Content, i.Description)
+		article.Categories = i.categories()
+		article.Enclosures = i.enclosures()
+
+		if article.Author = atomAuthors(i.Authors); article.Author == "" {
+			article.Author = feedAuthor
+		}
+
+		i.apply(&article)
 
 		var err error
 		if i.PubDate != "" {
@@ -80,3 +109,61 @@ func ParseAtom(b []byte) (Feed, error) {
 
 	return f, nil
 }
+
+// link returns the alternate link of the entry, falling back to the first
+// non-enclosure link.
+func (i atomItem) link() string {
+	var fallback string
+	for _, l := range i.Links {
+		switch l.Rel {
+		case "", "alternate":
+			return l.Href
+		case "enclosure":
+		default:
+			if fallback == "" {
+				fallback = l.Href
+			}
+		}
+	}
+
+	return fallback
+}
+
+func (i atomItem) categories() []string {
+	categories := make([]string, 0, len(i.Categories))
+	for _, c := range i.Categories {
+		if c.Label != "" {
+			categories = append(categories, c.Label)
+		} else {
+			categories = append(categories, c.Term)
+		}
+	}
+
+	return trimCategories(categories)
+}
+
+func (i atomItem) enclosures() []Enclosure {
+	var enclosures []Enclosure
+
+	for _, l := range i.Links {
+		if l.Rel != "enclosure" || l.Href == "" {
+			continue
+		}
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(l.Length), 10, 64)
+		enclosures = append(enclosures, Enclosure{URL: l.Href, Type: l.Type, Title: l.Title, Length: length})
+	}
+
+	return enclosures
+}
+
+func atomAuthors(authors []atomPerson) string {
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -17,6 +17,7 @@ func TestParseAtom(t *testing.T) {
 		{"single publish date", []byte(singlePubAtomXML), singleAtomFeed, false},
 		{"single no date", []byte(singleNoDateAtomXML), singleNoDateAtomFeed, false},
 		{"multi last no date", []byte(multiLastNoDateAtomXML), multiLastNoDateAtomFeed, false},
+		{"enclosures", []byte(enclosuresAtomXML), enclosuresAtomFeed, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -33,6 +34,25 @@ func TestParseAtom(t *testing.T) {
 }
 
 var (
+	enclosuresAtomFeed = Feed{
+		Title:    "Example Feed",
+		SiteLink: "http://example.org/",
+		Articles: []Article{
+			{
+				Title:       "Episode",
+				Link:        "http://example.org/episode",
+				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6b",
+				Description: "Some text.",
+				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "Mary Major",
+				Categories:  []string{"Technology", "news"},
+				Enclosures: []Enclosure{
+					{URL: "http://example.org/episode.mp3", Type: "audio/mpeg", Title: "Audio", Length: 1337},
+				},
+			},
+		},
+	}
+
 	singleAtomFeed = Feed{
 		Title:    "Example Feed",
 		SiteLink: "http://example.org/",
@@ -43,6 +63,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "John Doe",
 			},
 		},
 	}
@@ -57,6 +78,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Unix(0, 0),
+				Author:      "John Doe",
 			},
 		},
 	}
@@ -71,6 +93,7 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a",
 				Description: "Some text.",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 02, 0, time.UTC),
+				Author:      "John Doe",
 			},
 			{
 				Title:       "Atom-Powered Robots Run Amok 2",
@@ -78,12 +101,31 @@ var (
 				Guid:        "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a 2",
 				Description: "Some text. 2",
 				Date:        time.Date(2003, time.December, 13, 18, 30, 03, 0, time.UTC),
+				Author:      "John Doe",
 			},
 		},
 	}
 )
 
 const (
+	enclosuresAtomXML = `
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Example Feed</title>
+	<link href="http://example.org/"></link>
+	<author><name>John Doe</name></author>
+	<entry>
+		<title>Episode</title>
+		<id>urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6b</id>
+		<link rel="enclosure" type="audio/mpeg" title="Audio" length="1337" href="http://example.org/episode.mp3"/>
+		<link rel="alternate" href="http://example.org/episode"/>
+		<updated>2003-12-13T18:30:02Z</updated>
+		<author><name>Mary Major</name></author>
+		<category term="tech" label="Technology"/>
+		<category term="news"/>
+		<summary>Some text.</summary>
+	</entry>
+</feed>
+`
 	singleAtomXML = `
 <feed xmlns="http://www.w3.org/2005/Atom" updated="2003-12-13T18:30:02Z">
 	<title>Example Feed</title>
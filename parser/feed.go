@@ -20,6 +20,26 @@ type Article struct {
 	Link        string
 	Guid        string
 	Date        time.Time
+	Author      string
+	Categories  []string
+	Enclosures  []Enclosure
+
+	// Thumbnail is the article image, provided by the itunes or media rss
+	// extensions.
+	Thumbnail string
+	Duration  time.Duration
+	Episode   int
+	Season    int
+	Explicit  bool
+}
+
+// Enclosure is a media file attached to an article.
+type Enclosure struct {
+	URL      string
+	Type     string
+	Title    string
+	Length   int64
+	Duration time.Duration
 }
 
 type Image struct {
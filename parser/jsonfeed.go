@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotJSONFeed is returned by ParseJSONFeed when the source is not a JSON
+// document, allowing ParseFeed to try the next parser.
+var ErrNotJSONFeed = errors.New("not a json feed")
+
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/1"
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Favicon     string           `json:"favicon"`
+	Author      *jsonFeedAuthor  `json:"author"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Hubs        []jsonFeedHub    `json:"hubs"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            jsonFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	Image         string               `json:"image"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonFeedAuthor      `json:"author"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedHub struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string  `json:"url"`
+	MimeType string  `json:"mime_type"`
+	Title    string  `json:"title"`
+	Size     int64   `json:"size_in_bytes"`
+	Duration float64 `json:"duration_in_seconds"`
+}
+
+// jsonFeedID accepts both string and numeric item ids, since a lot of
+// publishers ignore the spec requirement for strings.
+type jsonFeedID string
+
+func (id *jsonFeedID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*id = jsonFeedID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+
+	*id = jsonFeedID(n.String())
+
+	return nil
+}
+
+// ParseJSONFeed parses a JSON Feed document, as described by
+// https://jsonfeed.org/version/1.1 and its predecessor.
+func ParseJSONFeed(b []byte) (Feed, error) {
+	var f Feed
+	var jf jsonFeed
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) == 0 || trimmed[0] != '{' {
+		return f, ErrNotJSONFeed
+	}
+
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return f, err
+	}
+
+	if !strings.HasPrefix(jf.Version, jsonFeedVersionPrefix) {
+		return f, ErrNotJSONFeed
+	}
+
+	f = Feed{
+		Title:       jf.Title,
+		Description: jf.Description,
+		SiteLink:    jf.HomePageURL,
+	}
+
+	if jf.Icon != "" {
+		f.Image = Image{Title: jf.Title, Url: jf.Icon}
+	} else if jf.Favicon != "" {
+		f.Image = Image{Title: jf.Title, Url: jf.Favicon}
+	}
+
+	for _, hub := range jf.Hubs {
+		if hub.URL == "" {
+			continue
+		}
+
+		if f.HubLink == "" || strings.EqualFold(hub.Type, "websub") {
+			f.HubLink = hub.URL
+		}
+	}
+
+	feedAuthor := jsonFeedAuthors(jf.Author, jf.Authors)
+
+	var lastValidDate time.Time
+	for _, i := range jf.Items {
+		article := Article{
+			Title:     i.Title,
+			Link:      i.URL,
+			Guid:      string(i.ID),
+			Author:    jsonFeedAuthors(i.Author, i.Authors),
+			Thumbnail: i.Image,
+		}
+
+		if article.Link == "" {
+			article.Link = i.ExternalURL
+		}
+
+		if article.Author == "" {
+			article.Author = feedAuthor
+		}
+
+		switch {
+		case i.ContentHTML != "":
+			article.Description = i.ContentHTML
+		case i.ContentText != "":
+			article.Description = strings.Replace(html.EscapeString(i.ContentText), "\n", "<br>", -1)
+		default:
+			article.Description = html.EscapeString(i.Summary)
+		}
+
+		for _, a := range i.Attachments {
+			if a.URL == "" {
+				continue
+			}
+
+			if article.Duration == 0 {
+				article.Duration = time.Duration(a.Duration * float64(time.Second))
+			}
+
+			article.Enclosures = append(article.Enclosures, Enclosure{
+				URL:      a.URL,
+				Type:     a.MimeType,
+				Title:    a.Title,
+				Length:   a.Size,
+				Duration: time.Duration(a.Duration * float64(time.Second)),
+			})
+		}
+
+		var err error
+		if i.DatePublished != "" {
+			article.Date, err = parseDate(i.DatePublished)
+		} else if i.DateModified != "" {
+			article.Date, err = parseDate(i.DateModified)
+		} else {
+			err = io.EOF
+		}
+
+		if err == nil {
+			lastValidDate = article.Date.Add(time.Second)
+		} else if lastValidDate.IsZero() {
+			article.Date = unknownTime
+		} else {
+			article.Date = lastValidDate
+		}
+
+		f.Articles = append(f.Articles, article)
+	}
+
+	return f, nil
+}
+
+// jsonFeedAuthors joins the names of the version 1.1 authors, falling back
+// to the deprecated version 1.0 author.
+func jsonFeedAuthors(author *jsonFeedAuthor, authors []jsonFeedAuthor) string {
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		if a.Name != "" {
+			names = append(names, a.Name)
+		}
+	}
+
+	if len(names) == 0 && author != nil && author.Name != "" {
+		names = append(names, author.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
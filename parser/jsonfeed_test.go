@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    Feed
+		wantErr error
+		err     bool
+	}{
+		{"version 1.1", []byte(jsonFeed11), jsonFeed11Feed, nil, false},
+		{"version 1.0", []byte(jsonFeed10), jsonFeed10Feed, nil, false},
+		{"xml", []byte(singleRss2XML), Feed{}, ErrNotJSONFeed, true},
+		{"unknown version", []byte(`{"version": "https://example.com/2", "items": []}`), Feed{}, ErrNotJSONFeed, true},
+		{"broken", []byte(`{"version": `), Feed{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSONFeed(tt.b)
+			if (err != nil) != tt.err {
+				t.Errorf("ParseJSONFeed() error = %v, wantErr %v", err, tt.err)
+				return
+			}
+			if tt.wantErr != nil && err != tt.wantErr {
+				t.Errorf("ParseJSONFeed() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJSONFeed() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+const (
+	jsonFeed11 = `
+{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "My Example Feed",
+	"home_page_url": "https://example.org/",
+	"feed_url": "https://example.org/feed.json",
+	"description": "An example feed",
+	"icon": "https://example.org/icon.png",
+	"authors": [{"name": "Jane"}],
+	"hubs": [{"type": "rssCloud", "url": "https://cloud.example.org/"}, {"type": "WebSub", "url": "https://hub.example.org/"}],
+	"items": [
+		{
+			"id": "2",
+			"content_text": "This is a second item.\nWith two lines.",
+			"url": "https://example.org/second-item",
+			"date_published": "2020-08-07T11:44:36-05:00",
+			"authors": [{"name": "John"}, {"name": "Mary"}],
+			"attachments": [
+				{
+					"url": "https://example.org/second-item.mp3",
+					"mime_type": "audio/mpeg",
+					"size_in_bytes": 1024,
+					"duration_in_seconds": 90
+				}
+			]
+		},
+		{
+			"id": 1,
+			"title": "First",
+			"content_html": "<p>Hello, world!</p>",
+			"external_url": "https://example.com/initial-post"
+		}
+	]
+}`
+
+	jsonFeed10 = `
+{
+	"version": "https://jsonfeed.org/version/1",
+	"title": "Old Feed",
+	"home_page_url": "https://example.org/",
+	"author": {"name": "Jane"},
+	"items": [
+		{
+			"id": "1",
+			"summary": "A summary & more",
+			"url": "https://example.org/first",
+			"date_modified": "2017-05-17T08:02:12Z"
+		}
+	]
+}`
+)
+
+var (
+	jsonFeed11Feed = Feed{
+		Title:       "My Example Feed",
+		Description: "An example feed",
+		SiteLink:    "https://example.org/",
+		HubLink:     "https://hub.example.org/",
+		Image:       Image{Title: "My Example Feed", Url: "https://example.org/icon.png"},
+		Articles: []Article{
+			{
+				Guid:        "2",
+				Link:        "https://example.org/second-item",
+				Description: "This is a second item.<br>With two lines.",
+				Date:        time.Date(2020, time.August, 7, 11, 44, 36, 0, time.FixedZone("", -5*60*60)),
+				Author:      "John, Mary",
+				Duration:    90 * time.Second,
+				Enclosures: []Enclosure{
+					{URL: "https://example.org/second-item.mp3", Type: "audio/mpeg", Length: 1024, Duration: 90 * time.Second},
+				},
+			},
+			{
+				Guid:        "1",
+				Title:       "First",
+				Link:        "https://example.com/initial-post",
+				Description: "<p>Hello, world!</p>",
+				Date:        time.Date(2020, time.August, 7, 11, 44, 37, 0, time.FixedZone("", -5*60*60)),
+				Author:      "Jane",
+			},
+		},
+	}
+
+	jsonFeed10Feed = Feed{
+		Title:    "Old Feed",
+		SiteLink: "https://example.org/",
+		Articles: []Article{
+			{
+				Guid:        "1",
+				Link:        "https://example.org/first",
+				Description: "A summary &amp; more",
+				Date:        time.Date(2017, time.May, 17, 8, 2, 12, 0, time.UTC),
+				Author:      "Jane",
+			},
+		},
+	}
+)
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// podcastItem holds the itunes and media rss extensions of a feed item.
+// It has to be embedded before any field without a namespace, so that
+// elements such as itunes:title don't overwrite the regular ones.
+type podcastItem struct {
+	ItunesTitle    string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd title"`
+	ItunesAuthor   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	ItunesImage    itunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	ItunesDuration string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ItunesEpisode  string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ItunesSeason   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	ItunesExplicit string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+
+	MediaTitle      string           `xml:"http://search.yahoo.com/mrss/ title"`
+	MediaThumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaContents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups     []mediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type mediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type mediaContent struct {
+	URL        string           `xml:"url,attr"`
+	Type       string           `xml:"type,attr"`
+	Medium     string           `xml:"medium,attr"`
+	FileSize   string           `xml:"fileSize,attr"`
+	Duration   string           `xml:"duration,attr"`
+	Thumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type mediaGroup struct {
+	Thumbnails []mediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Contents   []mediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// apply copies the podcast metadata onto the article. Media contents that
+// aren't images are added as enclosures, unless already present.
+func (p podcastItem) apply(a *Article) {
+	a.Duration = parseDuration(p.ItunesDuration)
+	a.Episode, _ = strconv.Atoi(strings.TrimSpace(p.ItunesEpisode))
+	a.Season, _ = strconv.Atoi(strings.TrimSpace(p.ItunesSeason))
+
+	switch strings.ToLower(strings.TrimSpace(p.ItunesExplicit)) {
+	case "yes", "true", "explicit":
+		a.Explicit = true
+	}
+
+	thumbnails := p.MediaThumbnails
+	contents := p.MediaContents
+	for _, g := range p.MediaGroups {
+		thumbnails = append(thumbnails, g.Thumbnails...)
+		contents = append(contents, g.Contents...)
+	}
+
+	for _, c := range contents {
+		thumbnails = append(thumbnails, c.Thumbnails...)
+	}
+
+	a.Thumbnail = strings.TrimSpace(p.ItunesImage.Href)
+	for _, t := range thumbnails {
+		if a.Thumbnail != "" {
+			break
+		}
+
+		a.Thumbnail = t.URL
+	}
+
+	existing := map[string]bool{}
+	for _, e := range a.Enclosures {
+		existing[e.URL] = true
+	}
+
+	for _, c := range contents {
+		if c.URL == "" {
+			continue
+		}
+
+		if c.Medium == "image" || strings.HasPrefix(c.Type, "image/") {
+			if a.Thumbnail == "" {
+				a.Thumbnail = c.URL
+			}
+			continue
+		}
+
+		duration := parseDuration(c.Duration)
+		if a.Duration == 0 {
+			a.Duration = duration
+		}
+
+		if existing[c.URL] {
+			continue
+		}
+		existing[c.URL] = true
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(c.FileSize), 10, 64)
+		a.Enclosures = append(a.Enclosures, Enclosure{
+			URL: c.URL, Type: c.Type, Title: p.MediaTitle, Length: length, Duration: duration,
+		})
+	}
+
+	if a.Duration == 0 {
+		for _, e := range a.Enclosures {
+			if e.Duration > 0 {
+				a.Duration = e.Duration
+				break
+			}
+		}
+	}
+
+	if a.Author == "" {
+		a.Author = strings.TrimSpace(p.ItunesAuthor)
+	}
+}
+
+// parseDuration parses the itunes duration formats: a number of seconds,
+// or [HH:]MM:SS.
+func parseDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	var total float64
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+
+		total = total*60 + n
+	}
+
+	return time.Duration(total * float64(time.Second))
+}
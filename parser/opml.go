@@ -13,6 +13,13 @@ type OpmlFeed struct {
 	Title string
 	URL   string
 	Tags  []string
+
+	// Username, Password, Token and UserAgent customize the feed
+	// downloads.
+	Username  string
+	Password  string
+	Token     string
+	UserAgent string
 }
 
 type OpmlXml struct {
@@ -39,6 +46,11 @@ type OpmlOutline struct {
 	URL      string        `xml:"url,attr,omitempty"`
 	Category string        `xml:"category,attr,omitempty"`
 	Outline  []OpmlOutline `xml:"outline"`
+
+	Username  string `xml:"username,attr,omitempty"`
+	Password  string `xml:"password,attr,omitempty"`
+	Token     string `xml:"token,attr,omitempty"`
+	UserAgent string `xml:"userAgent,attr,omitempty"`
 }
 
 func ParseOpml(content []byte) (Opml, error) {
@@ -57,7 +69,13 @@ func ParseOpml(content []byte) (Opml, error) {
 func processOutline(opml *Opml, outlines []OpmlOutline, text string) {
 	for _, outline := range outlines {
 		if len(outline.Outline) == 0 {
-			feed := OpmlFeed{Title: outline.Text}
+			feed := OpmlFeed{
+				Title:     outline.Text,
+				Username:  outline.Username,
+				Password:  outline.Password,
+				Token:     outline.Token,
+				UserAgent: outline.UserAgent,
+			}
 			if outline.URL == "" {
 				feed.URL = outline.XmlUrl
 			} else {
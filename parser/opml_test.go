@@ -16,6 +16,7 @@ func TestParseOpml(t *testing.T) {
 		{"single", []byte(singleOmplXML), singleOpml, false},
 		{"single url", []byte(singleUrlOmplXML), singleTagsOpml, false},
 		{"deep", []byte(deepOpmlXML), deepOpml, false},
+		{"auth", []byte(authOpmlXML), authOpml, false},
 		{"error", []byte("<foobar/>"), Opml{}, true},
 	}
 	for _, tt := range tests {
@@ -47,6 +48,20 @@ var (
 			},
 		},
 	}
+	authOpml = Opml{
+		Feeds: []OpmlFeed{
+			{
+				Title:     "Item 1 text",
+				URL:       "http://www.item1.com/rss",
+				Tags:      []string{},
+				Username:  "user",
+				Password:  "pass",
+				Token:     "token",
+				UserAgent: "agent",
+			},
+		},
+	}
+
 	singleTagsOpml = Opml{
 		Feeds: []OpmlFeed{
 			{
@@ -98,6 +113,20 @@ const (
         <outline type="rss" text="Item 1 text" title="Item 1 title" url="http://www.item1.com/rss" htmlUrl="http://www.item1.com" category="cat1, cat2"></outline>
     </body>
 </opml>
+`
+
+	authOpmlXML = `
+<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.1">
+    <head>
+        <title>
+			OPML title
+		</title>
+    </head>
+    <body>
+        <outline type="rss" text="Item 1 text" xmlUrl="http://www.item1.com/rss" username="user" password="pass" token="token" userAgent="agent"></outline>
+    </body>
+</opml>
 `
 
 	deepOpmlXML = `
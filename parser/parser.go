@@ -22,7 +22,7 @@ func ParseFeed(source []byte, funcs ...func([]byte) (Feed, error)) (Feed, error)
 	for _, f := range funcs {
 		feed, err = f(source)
 		if err != nil {
-			if _, ok := err.(xml.UnmarshalError); !ok {
+			if _, ok := err.(xml.UnmarshalError); !ok && err != ErrNotJSONFeed {
 				return feed, err
 			}
 		} else {
@@ -33,6 +33,11 @@ func ParseFeed(source []byte, funcs ...func([]byte) (Feed, error)) (Feed, error)
 	return feed, err
 }
 
+// ParseDate parses a date in one of the formats commonly found in feeds.
+func ParseDate(date string) (time.Time, error) {
+	return parseDate(date)
+}
+
 func parseDate(date string) (time.Time, error) {
 	formats := []string{
 		time.ANSIC,
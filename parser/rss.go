@@ -2,6 +2,7 @@ package parser
 
 import (
 	"encoding/xml"
+	"strconv"
 	"strings"
 )
 
@@ -16,17 +17,29 @@ type rssImage struct {
 // RssItem is the base content for both rss1 and rss2 feeds. The only reason
 // it's public is because of the refrect package
 type RssItem struct {
-	XMLName     xml.Name   `xml:"item"`
-	Id          string     `xml:"guid"`
-	Title       string     `xml:"title"`
-	Link        string     `xml:"link"`
-	Description rssContent `xml:"description"`
-	Content     rssContent `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
-	PubDate     string     `xml:"pubDate"`
-	Date        string     `xml:"date"`
-	TTL         int        `xml:"ttl"`
-	SkipHours   []int      `xml:"skipHours>hour"`
-	SkipDays    []string   `xml:"skipDays>day"`
+	podcastItem
+
+	XMLName     xml.Name       `xml:"item"`
+	Id          string         `xml:"guid"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description rssContent     `xml:"description"`
+	Content     rssContent     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string         `xml:"pubDate"`
+	Date        string         `xml:"date"`
+	Author      string         `xml:"author"`
+	Creator     []string       `xml:"creator"`
+	Categories  []string       `xml:"category"`
+	Enclosures  []rssEnclosure `xml:"enclosure"`
+	TTL         int            `xml:"ttl"`
+	SkipHours   []int          `xml:"skipHours>hour"`
+	SkipDays    []string       `xml:"skipDays>day"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
 }
 
 type rssContent struct {
@@ -52,3 +65,62 @@ func getLargerContent(first, second rssContent) string {
 		return c1
 	}
 }
+
+// author returns the item author, preferring the dublin core creators over
+// the rss author, which is supposed to be an email address.
+func (i RssItem) author() string {
+	creators := make([]string, 0, len(i.Creator))
+	for _, c := range i.Creator {
+		if c = strings.TrimSpace(c); c != "" {
+			creators = append(creators, c)
+		}
+	}
+
+	if len(creators) > 0 {
+		return strings.Join(creators, ", ")
+	}
+
+	author := strings.TrimSpace(i.Author)
+
+	// The common "email (Name)" form.
+	if start, end := strings.IndexByte(author, '('), strings.LastIndexByte(author, ')'); start > 0 && end > start {
+		if name := strings.TrimSpace(author[start+1 : end]); name != "" {
+			return name
+		}
+	}
+
+	return author
+}
+
+func (i RssItem) categories() []string {
+	return trimCategories(i.Categories)
+}
+
+func (i RssItem) enclosures() []Enclosure {
+	var enclosures []Enclosure
+
+	for _, e := range i.Enclosures {
+		if e.URL == "" {
+			continue
+		}
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(e.Length), 10, 64)
+		enclosures = append(enclosures, Enclosure{URL: e.URL, Type: e.Type, Length: length})
+	}
+
+	return enclosures
+}
+
+func trimCategories(categories []string) []string {
+	var trimmed []string
+	seen := map[string]bool{}
+
+	for _, c := range categories {
+		if c = strings.TrimSpace(c); c != "" && !seen[c] {
+			seen[c] = true
+			trimmed = append(trimmed, c)
+		}
+	}
+
+	return trimmed
+}
@@ -14,15 +14,16 @@ type rss2Feed struct {
 }
 
 type rss2Channel struct {
-	XMLName     xml.Name  `xml:"channel"`
-	Title       string    `xml:"title"`
-	Link        string    `xml:"parserfeed link"`
-	Description string    `xml:"description"`
-	Image       rssImage  `xml:"image"`
-	Items       []RssItem `xml:"item"`
-	TTL         int       `xml:"ttl"`
-	SkipHours   []int     `xml:"skipHours>hour"`
-	SkipDays    []string  `xml:"skipDays>day"`
+	XMLName     xml.Name    `xml:"channel"`
+	Title       string      `xml:"title"`
+	Link        string      `xml:"parserfeed link"`
+	Description string      `xml:"description"`
+	ItunesImage itunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Image       rssImage    `xml:"image"`
+	Items       []RssItem   `xml:"item"`
+	TTL         int         `xml:"ttl"`
+	SkipHours   []int       `xml:"skipHours>hour"`
+	SkipDays    []string    `xml:"skipDays>day"`
 }
 
 func ParseRss2(b []byte) (Feed, error) {
@@ -45,6 +46,10 @@ func ParseRss2(b []byte) (Feed, error) {
 			rss.Channel.Image.Width, rss.Channel.Image.Height},
 	}
 
+	if f.Image.Url == "" && rss.Channel.ItunesImage.Href != "" {
+		f.Image = Image{Title: rss.Channel.Title, Url: rss.Channel.ItunesImage.Href}
+	}
+
 	if rss.Channel.TTL != 0 {
 		f.TTL = time.Duration(rss.Channel.TTL) * time.Minute
 	}
@@ -63,6 +68,10 @@ func ParseRss2(b []byte) (Feed, error) {
 	for _, i := range rss.Channel.Items {
 		article := Article{Title: i.Title, Link: i.Link, Guid: i.Id}
 		article.Description = getLargerContent(i.Content, i.Description)
+		article.Author = i.author()
+		article.Categories = i.categories()
+		article.Enclosures = i.enclosures()
+		i.apply(&article)
 
 		var err error
 		if i.PubDate != "" {
@@ -19,6 +19,8 @@ func TestParseRss2(t *testing.T) {
 		{"single no date", []byte(singleNoDateRss2XML), singleNoDateRss2Feed, false},
 		{"multi last no date", []byte(multiLastNoDateRss2XML), multiLastNoDateRss2Feed, false},
 		{"html escapes in xml", []byte(htmlEscapesInXML), htmlEscapesInXMLFeed, false},
+		{"enclosures", []byte(enclosuresRss2XML), enclosuresRss2Feed, false},
+		{"podcast", []byte(podcastRss2XML), podcastRss2Feed, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -37,6 +39,74 @@ func TestParseRss2(t *testing.T) {
 var (
 	gmt, _ = time.LoadLocation("GMT")
 
+	podcastRss2Feed = Feed{
+		Title:     "Podcast",
+		SiteLink:  "http://example.com/",
+		Image:     Image{Title: "Podcast", Url: "http://example.com/cover.jpg"},
+		SkipHours: map[int]bool{},
+		SkipDays:  map[string]bool{},
+		Articles: []Article{
+			{
+				Title:       "Episode 1: The beginning",
+				Link:        "http://example.com/1",
+				Guid:        "http://example.com/1",
+				Description: "First episode",
+				Date:        time.Date(2003, time.June, 3, 9, 39, 21, 0, gmt),
+				Author:      "John Doe",
+				Enclosures: []Enclosure{
+					{URL: "http://example.com/1.mp3", Type: "audio/mpeg", Length: 12345},
+					{URL: "http://example.com/1.ogg", Type: "audio/ogg", Length: 2000, Duration: 3723 * time.Second},
+				},
+				Thumbnail: "http://example.com/1.jpg",
+				Duration:  3723 * time.Second,
+				Episode:   1,
+				Season:    2,
+				Explicit:  true,
+			},
+			{
+				Title:       "Video",
+				Link:        "http://example.com/2",
+				Guid:        "http://example.com/2",
+				Description: "A video",
+				Date:        time.Date(2003, time.June, 3, 9, 39, 22, 0, gmt),
+				Enclosures: []Enclosure{
+					{URL: "http://example.com/2.mp4", Type: "video/mp4", Duration: 90 * time.Second},
+				},
+				Thumbnail: "http://example.com/2-thumb.jpg",
+				Duration:  90 * time.Second,
+			},
+		},
+	}
+
+	enclosuresRss2Feed = Feed{
+		Title:     "Podcast",
+		SiteLink:  "http://example.com/",
+		SkipHours: map[int]bool{},
+		SkipDays:  map[string]bool{},
+		Articles: []Article{
+			{
+				Title:       "Episode 1",
+				Link:        "http://example.com/1",
+				Guid:        "http://example.com/1",
+				Description: "First episode",
+				Date:        time.Date(2003, time.June, 3, 9, 39, 21, 0, gmt),
+				Author:      "John Doe",
+				Categories:  []string{"Tech", "News"},
+				Enclosures: []Enclosure{
+					{URL: "http://example.com/1.mp3", Type: "audio/mpeg", Length: 12345},
+				},
+			},
+			{
+				Title:       "Episode 2",
+				Link:        "http://example.com/2",
+				Guid:        "http://example.com/2",
+				Description: "Second episode",
+				Date:        time.Date(2003, time.June, 4, 9, 39, 21, 0, gmt),
+				Author:      "Jane Roe, Richard Roe",
+			},
+		},
+	}
+
 	singleRss2Feed = Feed{
 		Title:       "Liftoff News",
 		SiteLink:    "http://liftoff.msfc.nasa.gov/",
@@ -124,6 +194,79 @@ var (
 )
 
 const (
+	podcastRss2XML = `
+<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:media="http://search.yahoo.com/mrss/">
+   <channel>
+      <title>Podcast</title>
+      <link>http://example.com/</link>
+      <itunes:image href="http://example.com/cover.jpg"/>
+      <item>
+         <title>Episode 1: The beginning</title>
+         <itunes:title>The beginning</itunes:title>
+         <link>http://example.com/1</link>
+         <description>First episode</description>
+         <pubDate>Tue, 03 Jun 2003 09:39:21 GMT</pubDate>
+         <guid>http://example.com/1</guid>
+         <itunes:author>John Doe</itunes:author>
+         <itunes:duration>1:02:03</itunes:duration>
+         <itunes:episode>1</itunes:episode>
+         <itunes:season>2</itunes:season>
+         <itunes:explicit>yes</itunes:explicit>
+         <itunes:image href="http://example.com/1.jpg"/>
+         <enclosure url="http://example.com/1.mp3" length="12345" type="audio/mpeg" />
+         <media:content url="http://example.com/1.mp3" type="audio/mpeg" />
+         <media:content url="http://example.com/1.ogg" type="audio/ogg" fileSize="2000" duration="3723" />
+      </item>
+      <item>
+         <title>Video</title>
+         <link>http://example.com/2</link>
+         <description>A video</description>
+         <guid>http://example.com/2</guid>
+         <media:group>
+            <media:content url="http://example.com/2.jpg" medium="image" />
+            <media:content url="http://example.com/2.mp4" type="video/mp4" duration="90">
+               <media:thumbnail url="http://example.com/2-thumb.jpg" />
+            </media:content>
+         </media:group>
+      </item>
+   </channel>
+</rss>
+`
+
+	enclosuresRss2XML = `
+<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <channel>
+      <title>Podcast</title>
+      <link>http://example.com/</link>
+      <item>
+         <title>Episode 1</title>
+         <link>http://example.com/1</link>
+         <description>First episode</description>
+         <pubDate>Tue, 03 Jun 2003 09:39:21 GMT</pubDate>
+         <guid>http://example.com/1</guid>
+         <author>john@example.com (John Doe)</author>
+         <category>Tech</category>
+         <category domain="http://example.com/tags"> News </category>
+         <category>Tech</category>
+         <enclosure url="http://example.com/1.mp3" length="12345" type="audio/mpeg" />
+      </item>
+      <item>
+         <title>Episode 2</title>
+         <link>http://example.com/2</link>
+         <description>Second episode</description>
+         <pubDate>Wed, 04 Jun 2003 09:39:21 GMT</pubDate>
+         <guid>http://example.com/2</guid>
+         <author>jane@example.com</author>
+         <dc:creator>Jane Roe</dc:creator>
+         <dc:creator>Richard Roe</dc:creator>
+         <enclosure url="" length="" type="audio/mpeg" />
+      </item>
+   </channel>
+</rss>
+`
+
 	singleRss2XML = `
 
 <?xml version="1.0"?>